@@ -0,0 +1,14 @@
+resource "talos_machine_secrets" "this" {}
+
+data "talos_cluster_members" "this" {
+  client_configuration = talos_machine_secrets.this.client_configuration
+  node                 = "10.5.0.2"
+  machine_type         = "worker"
+}
+
+# for example, this could be used to iterate over the workers actually discovered in the cluster
+output "workers" {
+  value = {
+    for member in data.talos_cluster_members.this.members : member.hostname => member.addresses[0]
+  }
+}
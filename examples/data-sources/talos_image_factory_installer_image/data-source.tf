@@ -0,0 +1,23 @@
+data "talos_image_factory_installer_image" "this" {
+  talos_version = "v1.7.5"
+  extensions = [
+    "siderolabs/gvisor",
+    "siderolabs/iscsi-tools",
+  ]
+}
+
+data "talos_machine_configuration" "this" {
+  cluster_name     = "example-cluster"
+  machine_type     = "controlplane"
+  cluster_endpoint = "https://cluster.local:6443"
+  machine_secrets  = talos_machine_secrets.this.machine_secrets
+  config_patches = [
+    yamlencode({
+      machine = {
+        install = {
+          image = data.talos_image_factory_installer_image.this.image
+        }
+      }
+    }),
+  ]
+}
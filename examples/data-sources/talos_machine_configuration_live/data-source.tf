@@ -0,0 +1,13 @@
+resource "talos_machine_secrets" "this" {}
+
+data "talos_machine_configuration_live" "this" {
+  client_configuration = talos_machine_secrets.this.client_configuration
+  node                 = "10.5.0.2"
+  redact_secrets       = true
+}
+
+# for example, this could be used to compare the rendered and the deployed machine configuration in CI
+output "live_machine_configuration" {
+  value     = data.talos_machine_configuration_live.this.machine_configuration
+  sensitive = true
+}
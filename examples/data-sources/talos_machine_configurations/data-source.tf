@@ -0,0 +1,24 @@
+resource "talos_machine_secrets" "this" {}
+
+data "talos_machine_configurations" "this" {
+  cluster_name     = "example-cluster"
+  cluster_endpoint = "https://cluster.local:6443"
+  machine_secrets  = talos_machine_secrets.this.machine_secrets
+  nodes = {
+    "cp-1" = {
+      machine_type = "controlplane"
+      config_patches = [
+        yamlencode({
+          machine = {
+            network = {
+              hostname = "cp-1"
+            }
+          }
+        }),
+      ]
+    }
+    "worker-1" = {
+      machine_type = "worker"
+    }
+  }
+}
@@ -0,0 +1,22 @@
+resource "talos_machine_secrets" "this" {}
+
+resource "talos_machine_bootstrap" "this" {
+  client_configuration = talos_machine_secrets.this.client_configuration
+  node                 = "10.5.0.2"
+}
+
+# wait for the node to report it is running and ready
+data "talos_machine_event" "ready" {
+  depends_on = [
+    talos_machine_bootstrap.this
+  ]
+
+  client_configuration = talos_machine_secrets.this.client_configuration
+  node                 = "10.5.0.2"
+  machine_stage        = "RUNNING"
+  machine_ready        = true
+
+  timeouts = {
+    read = "15m"
+  }
+}
@@ -0,0 +1,27 @@
+resource "talos_machine_secrets" "this" {}
+
+data "talos_image_factory_extensions_versions" "this" {
+  talos_version = "v1.7.5"
+  filters = {
+    names = [
+      "siderolabs/gvisor",
+      "siderolabs/iscsi-tools",
+    ]
+  }
+}
+
+data "talos_machine_extensions" "this" {
+  client_configuration = talos_machine_secrets.this.client_configuration
+  node                 = "10.5.0.2"
+
+  lifecycle {
+    # the image factory extension names are prefixed with their organization
+    postcondition {
+      condition = alltrue([
+        for extension in data.talos_image_factory_extensions_versions.this.extensions_info :
+        contains(self.extensions[*].name, element(split("/", extension.name), 1))
+      ])
+      error_message = "The node doesn't run all the extensions of its schematic."
+    }
+  }
+}
@@ -0,0 +1,16 @@
+resource "talos_machine_secrets" "this" {}
+
+data "talos_machine_network_interfaces" "this" {
+  client_configuration = talos_machine_secrets.this.client_configuration
+  node                 = "10.5.0.2"
+  filters = {
+    physical = true
+  }
+}
+
+# for example, this could be used to bind the network configuration to the interface by its MAC address
+output "physical_interfaces" {
+  value = {
+    for iface in data.talos_machine_network_interfaces.this.interfaces : iface.name => iface.permanent_addr
+  }
+}
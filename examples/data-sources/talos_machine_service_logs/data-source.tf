@@ -0,0 +1,13 @@
+resource "talos_machine_secrets" "this" {}
+
+# fetch the last kubelet log lines, e.g. when the node never becomes ready after an apply
+data "talos_machine_service_logs" "kubelet" {
+  client_configuration = talos_machine_secrets.this.client_configuration
+  node                 = "10.5.0.2"
+  service              = "kubelet"
+  tail_lines           = 50
+}
+
+output "kubelet_logs" {
+  value = data.talos_machine_service_logs.kubelet.logs
+}
@@ -0,0 +1,21 @@
+resource "talos_machine_secrets" "this" {}
+
+resource "talos_machine_bootstrap" "this" {
+  client_configuration = talos_machine_secrets.this.client_configuration
+  node                 = "10.5.0.2"
+}
+
+# wait for etcd and kubelet before installing the CNI
+data "talos_machine_service_status" "this" {
+  depends_on = [
+    talos_machine_bootstrap.this
+  ]
+
+  client_configuration = talos_machine_secrets.this.client_configuration
+  node                 = "10.5.0.2"
+  services             = ["etcd", "kubelet"]
+
+  timeouts = {
+    read = "15m"
+  }
+}
@@ -0,0 +1,12 @@
+resource "talos_machine_secrets" "this" {}
+
+# the node can be in maintenance mode
+data "talos_machine_system_info" "this" {
+  client_configuration = talos_machine_secrets.this.client_configuration
+  node                 = "10.5.0.2"
+}
+
+locals {
+  # the largest machines run the control plane
+  role = data.talos_machine_system_info.this.memory_mib >= 32768 ? "controlplane" : "worker"
+}
@@ -0,0 +1,10 @@
+data "talos_machines_discover" "this" {
+  cidrs = ["10.5.0.0/24"]
+}
+
+# for example, this could be used to map the freshly racked machines to their roles by their serial number
+output "machines" {
+  value = {
+    for machine in data.talos_machines_discover.this.machines : machine.serial_number => machine.address
+  }
+}
@@ -0,0 +1,16 @@
+# fail the plan if the nodes can't be upgraded directly to the target versions
+data "talos_upgrade_path" "this" {
+  current_talos_version      = "v1.7.6"
+  target_talos_version       = "v1.8.0"
+  current_kubernetes_version = "v1.29.3"
+  target_kubernetes_version  = "v1.30.0"
+}
+
+resource "talos_cluster_upgrade" "this" {
+  depends_on = [
+    data.talos_upgrade_path.this
+  ]
+
+  nodes = ["10.5.0.2"]
+  image = "ghcr.io/siderolabs/installer:${data.talos_upgrade_path.this.target_talos_version}"
+}
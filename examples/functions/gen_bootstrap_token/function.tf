@@ -0,0 +1,18 @@
+resource "random_password" "bootstrap_token" {
+  length = 32
+}
+
+locals {
+  bootstrap_token = provider::talos::gen_bootstrap_token(random_password.bootstrap_token.result)
+}
+
+# register the token in Kubernetes as a bootstrap token
+output "bootstrap_token_secret_name" {
+  value     = "bootstrap-token-${local.bootstrap_token.id}"
+  sensitive = true
+}
+
+output "bootstrap_token" {
+  value     = local.bootstrap_token.token
+  sensitive = true
+}
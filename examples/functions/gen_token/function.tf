@@ -0,0 +1,21 @@
+resource "talos_machine_secrets" "this" {}
+
+# rotate the trustd token by changing the keepers of the seed
+resource "random_password" "trustd_token" {
+  length = 32
+
+  keepers = {
+    rotation = "2024"
+  }
+}
+
+data "talos_machine_configuration" "this" {
+  cluster_name     = "example-cluster"
+  machine_type     = "worker"
+  cluster_endpoint = "https://cluster.local:6443"
+  machine_secrets = merge(talos_machine_secrets.this.machine_secrets, {
+    trustdinfo = {
+      token = provider::talos::gen_token(random_password.trustd_token.result)
+    }
+  })
+}
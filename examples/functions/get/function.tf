@@ -0,0 +1,18 @@
+resource "talos_machine_secrets" "this" {}
+
+data "talos_machine_configuration" "this" {
+  cluster_name     = "example-cluster"
+  machine_type     = "controlplane"
+  cluster_endpoint = "https://cluster.local:6443"
+  machine_secrets  = talos_machine_secrets.this.machine_secrets
+}
+
+output "install_disk" {
+  value     = provider::talos::get(data.talos_machine_configuration.this.machine_configuration, "machine.install.disk")
+  sensitive = true
+}
+
+output "cluster_endpoint" {
+  value     = provider::talos::get(data.talos_machine_configuration.this.machine_configuration, "cluster.controlPlane.endpoint")
+  sensitive = true
+}
@@ -0,0 +1,22 @@
+resource "talos_machine_secrets" "this" {}
+
+data "talos_machine_configuration" "this" {
+  cluster_name     = "example-cluster"
+  machine_type     = "worker"
+  cluster_endpoint = "https://cluster.local:6443"
+  machine_secrets  = talos_machine_secrets.this.machine_secrets
+}
+
+# render the configuration of a node the provider never contacts, e.g. to pass it as user data
+output "worker_user_data" {
+  value = provider::talos::patch(data.talos_machine_configuration.this.machine_configuration, [
+    yamlencode({
+      machine = {
+        network = {
+          hostname = "worker-1"
+        }
+      }
+    }),
+  ])
+  sensitive = true
+}
@@ -0,0 +1,25 @@
+resource "talos_machine_secrets" "this" {}
+
+data "talos_machine_configuration" "this" {
+  cluster_name     = "example-cluster"
+  machine_type     = "controlplane"
+  cluster_endpoint = "https://cluster.local:6443"
+  machine_secrets  = talos_machine_secrets.this.machine_secrets
+  config_patches = [
+    file("${path.module}/patches/controlplane.yaml"),
+  ]
+}
+
+resource "terraform_data" "validate" {
+  lifecycle {
+    precondition {
+      condition     = provider::talos::validate(data.talos_machine_configuration.this.machine_configuration, "metal").valid
+      error_message = "machine configuration is invalid"
+    }
+  }
+}
+
+output "validation_warnings" {
+  value     = provider::talos::validate(data.talos_machine_configuration.this.machine_configuration, "metal").warnings
+  sensitive = true
+}
@@ -0,0 +1,10 @@
+variable "next_talos_ca_certificate" {
+  description = "The base64 encoded PEM of the Talos API CA being rotated to"
+  type        = string
+}
+
+provider "talos" {
+  # keep the connection to the nodes while the Talos API CA is being rotated
+  additional_ca_certificates       = [var.next_talos_ca_certificate]
+  additional_ca_certificates_until = "2025-01-01T00:00:00Z"
+}
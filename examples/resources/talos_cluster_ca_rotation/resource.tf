@@ -0,0 +1,24 @@
+resource "talos_machine_secrets" "this" {}
+
+resource "talos_cluster_ca_rotation" "this" {
+  client_configuration = talos_machine_secrets.this.client_configuration
+  control_plane_nodes  = ["10.5.0.2", "10.5.0.3", "10.5.0.4"]
+  worker_nodes         = ["10.5.0.5", "10.5.0.6"]
+
+  # change the value to rotate the CAs again
+  triggers = {
+    rotation = "2024-09"
+  }
+}
+
+# generate the machine configuration with the rotated Kubernetes API CA, so that applying it doesn't roll the rotation back
+data "talos_machine_configuration" "controlplane" {
+  cluster_name     = "example-cluster"
+  machine_type     = "controlplane"
+  cluster_endpoint = "https://cluster.local:6443"
+  machine_secrets = merge(talos_machine_secrets.this.machine_secrets, {
+    certs = merge(talos_machine_secrets.this.machine_secrets.certs, {
+      k8s = talos_cluster_ca_rotation.this.kubernetes_ca
+    })
+  })
+}
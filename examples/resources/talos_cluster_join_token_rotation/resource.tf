@@ -0,0 +1,26 @@
+resource "talos_machine_secrets" "this" {}
+
+resource "talos_cluster_join_token_rotation" "this" {
+  client_configuration = talos_machine_secrets.this.client_configuration
+  control_plane_nodes  = ["10.5.0.2", "10.5.0.3", "10.5.0.4"]
+  worker_nodes         = ["10.5.0.5", "10.5.0.6"]
+
+  # change the value to rotate the token again
+  triggers = {
+    rotation = "2024-09"
+  }
+}
+
+# generate the machine configuration with the rotated token, applying a machine
+# configuration generated from talos_machine_secrets.this.machine_secrets alone
+# would set the old token back
+data "talos_machine_configuration" "worker" {
+  cluster_name     = "example-cluster"
+  machine_type     = "worker"
+  cluster_endpoint = "https://cluster.local:6443"
+  machine_secrets = merge(talos_machine_secrets.this.machine_secrets, {
+    trustdinfo = {
+      token = talos_cluster_join_token_rotation.this.token
+    }
+  })
+}
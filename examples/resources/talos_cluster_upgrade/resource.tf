@@ -0,0 +1,17 @@
+resource "talos_machine_secrets" "this" {}
+
+# upgrade the control plane nodes one at a time, then the workers two at a time
+resource "talos_cluster_upgrade" "controlplane" {
+  client_configuration = talos_machine_secrets.this.client_configuration
+  nodes                = ["10.5.0.2", "10.5.0.3", "10.5.0.4"]
+  image                = "ghcr.io/siderolabs/installer:v1.8.0"
+}
+
+resource "talos_cluster_upgrade" "worker" {
+  depends_on = [talos_cluster_upgrade.controlplane]
+
+  client_configuration = talos_machine_secrets.this.client_configuration
+  nodes                = ["10.5.0.5", "10.5.0.6", "10.5.0.7", "10.5.0.8"]
+  image                = "ghcr.io/siderolabs/installer:v1.8.0"
+  max_unavailable      = 2
+}
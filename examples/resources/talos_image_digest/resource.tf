@@ -0,0 +1,28 @@
+data "talos_image_factory_urls" "this" {
+  talos_version = "v1.7.5"
+  schematic_id  = "376567988ad370138ad8b2698212367b8edcb69b5fd68c80be1f2ec7d603b4ba"
+  platform      = "metal"
+}
+
+resource "talos_image_digest" "installer" {
+  image         = data.talos_image_factory_urls.this.urls.installer
+  fail_on_retag = true
+}
+
+data "talos_machine_configuration" "this" {
+  cluster_name     = "example-cluster"
+  machine_type     = "controlplane"
+  cluster_endpoint = "https://cluster.local:6443"
+  machine_secrets  = talos_machine_secrets.this.machine_secrets
+  config_patches = [
+    yamlencode({
+      machine = {
+        install = {
+          image = talos_image_digest.installer.pinned_image
+        }
+      }
+    }),
+  ]
+}
+
+resource "talos_machine_secrets" "this" {}
@@ -0,0 +1,9 @@
+resource "talos_local_cluster" "this" {
+  name    = "example-cluster"
+  workers = 2
+}
+
+output "kubeconfig" {
+  value     = talos_local_cluster.this.kubeconfig_raw
+  sensitive = true
+}
@@ -0,0 +1,19 @@
+resource "talos_machine_secrets" "this" {}
+
+# only manage the kubelet extra arguments of a node, the rest of its machine configuration is owned by another system
+resource "talos_machine_configuration_patch" "kubelet" {
+  client_configuration = talos_machine_secrets.this.client_configuration
+  node                 = "10.5.0.2"
+  apply_mode           = "no_reboot"
+  config_patches = [
+    yamlencode({
+      machine = {
+        kubelet = {
+          extraArgs = {
+            max-pods = "150"
+          }
+        }
+      }
+    }),
+  ]
+}
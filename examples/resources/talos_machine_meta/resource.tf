@@ -0,0 +1,33 @@
+resource "talos_machine_secrets" "this" {}
+
+# write the platform network configuration of the metal platform (META key 0x0a),
+# so that the node gets its static network configuration on the next boot
+resource "talos_machine_meta" "network" {
+  client_configuration = talos_machine_secrets.this.client_configuration
+  node                 = "10.5.0.2"
+  key                  = 10
+  value = yamlencode({
+    addresses = [
+      {
+        address  = "10.5.0.2/24"
+        linkName = "eth0"
+        family   = "inet4"
+        scope    = "global"
+        flags    = "permanent"
+        layer    = "platform"
+      },
+    ]
+    routes = [
+      {
+        family      = "inet4"
+        gateway     = "10.5.0.1"
+        outLinkName = "eth0"
+        table       = "main"
+        scope       = "global"
+        type        = "unicast"
+        protocol    = "static"
+        layer       = "platform"
+      },
+    ]
+  })
+}
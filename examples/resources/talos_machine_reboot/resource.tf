@@ -0,0 +1,22 @@
+resource "talos_machine_secrets" "this" {}
+
+# apply the machine configuration without rebooting the node
+resource "talos_machine_configuration_apply" "this" {
+  client_configuration        = talos_machine_secrets.this.client_configuration
+  machine_configuration_input = var.machine_configuration
+  node                        = "10.5.0.2"
+  apply_mode                  = "staged"
+}
+
+# reboot the node to activate the staged configuration
+resource "talos_machine_reboot" "this" {
+  client_configuration = talos_machine_secrets.this.client_configuration
+  node                 = "10.5.0.2"
+  triggers = {
+    machine_configuration = sha256(talos_machine_configuration_apply.this.machine_configuration)
+  }
+}
+
+variable "machine_configuration" {
+  type = string
+}
@@ -1 +1,22 @@
 resource "talos_machine_secrets" "machine_secrets" {}
+
+# use intermediate CAs issued by a corporate PKI for the Talos and Kubernetes APIs
+resource "talos_machine_secrets" "corporate_pki" {
+  ca_certificates = {
+    os = {
+      cert = file("talos-ca.crt")
+      key  = file("talos-ca.key")
+    }
+    k8s = {
+      cert = file("kubernetes-ca.crt")
+      key  = file("kubernetes-ca.key")
+    }
+  }
+}
+
+# generate new machine secrets every year
+resource "talos_machine_secrets" "yearly" {
+  triggers = {
+    rotation = formatdate("YYYY", plantimestamp())
+  }
+}
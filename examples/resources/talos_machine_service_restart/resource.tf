@@ -0,0 +1,21 @@
+resource "talos_machine_secrets" "this" {}
+
+resource "talos_machine_configuration_apply" "this" {
+  client_configuration        = talos_machine_secrets.this.client_configuration
+  machine_configuration_input = var.machine_configuration
+  node                        = "10.5.0.2"
+}
+
+# restart kubelet whenever the applied machine configuration changes
+resource "talos_machine_service_restart" "kubelet" {
+  client_configuration = talos_machine_secrets.this.client_configuration
+  node                 = "10.5.0.2"
+  service              = "kubelet"
+  triggers = {
+    machine_configuration = sha256(talos_machine_configuration_apply.this.machine_configuration)
+  }
+}
+
+variable "machine_configuration" {
+  type = string
+}
@@ -0,0 +1,41 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos
+
+import (
+	"context"
+
+	"golang.org/x/sync/semaphore"
+)
+
+type operationLimiterContextKey struct{}
+
+type operationSlotContextKey struct{}
+
+// withOperationLimiter returns a context limiting the number of concurrent Talos API operations with the semaphore, if set.
+func withOperationLimiter(ctx context.Context, limiter *semaphore.Weighted) context.Context {
+	if limiter == nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, operationLimiterContextKey{}, limiter)
+}
+
+// acquireOperation waits for a free operation slot of the provider, the returned context marks the slot as held
+// so that the operations nested in the operation don't wait for another slot, which could deadlock.
+// The release function must be called once the operation is done.
+func acquireOperation(ctx context.Context) (context.Context, func(), error) {
+	limiter, _ := ctx.Value(operationLimiterContextKey{}).(*semaphore.Weighted)
+
+	if limiter == nil || ctx.Value(operationSlotContextKey{}) != nil {
+		return ctx, func() {}, nil
+	}
+
+	if err := limiter.Acquire(ctx, 1); err != nil {
+		return ctx, nil, err
+	}
+
+	return context.WithValue(ctx, operationSlotContextKey{}, true), func() { limiter.Release(1) }, nil
+}
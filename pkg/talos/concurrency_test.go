@@ -0,0 +1,54 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"golang.org/x/sync/semaphore"
+)
+
+func TestAcquireOperation(t *testing.T) {
+	t.Parallel()
+
+	ctx := withOperationLimiter(context.Background(), semaphore.NewWeighted(1))
+
+	opCtx, release, err := acquireOperation(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the nested operations run in the slot of the operation
+	_, releaseNested, err := acquireOperation(opCtx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	releaseNested()
+
+	waitCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+
+	if _, _, err = acquireOperation(waitCtx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the operation to wait for a free slot, got %v", err)
+	}
+
+	release()
+
+	_, release, err = acquireOperation(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	release()
+
+	// no limit if the limiter isn't configured
+	if _, _, err = acquireOperation(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
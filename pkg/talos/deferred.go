@@ -0,0 +1,93 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/siderolabs/talos/pkg/machinery/client/resolver"
+	"github.com/siderolabs/talos/pkg/machinery/constants"
+)
+
+// deferReachabilityTimeout bounds the check of the endpoints reachability before deferring a data source read.
+const deferReachabilityTimeout = 5 * time.Second
+
+// deferCreate defers the creation of the resource if Terraform allows it, used when the endpoint or node is not known yet.
+func deferCreate(req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.ClientCapabilities.DeferralAllowed && req.State.Raw.IsNull() {
+		resp.Deferred = &resource.Deferred{
+			Reason: resource.DeferredReasonResourceConfigUnknown,
+		}
+	}
+}
+
+// deferRead defers the read of the data source if Terraform allows it and the endpoints are unknown or unreachable,
+// e.g. when the machines are created in the same plan, instead of waiting for the read timeout.
+func (d *talosProviderData) deferRead(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse, endpoints ...types.String) bool {
+	if !req.ClientCapabilities.DeferralAllowed {
+		return false
+	}
+
+	for _, endpoint := range endpoints {
+		if endpoint.IsUnknown() {
+			resp.Deferred = &datasource.Deferred{
+				Reason: datasource.DeferredReasonDataSourceConfigUnknown,
+			}
+
+			return true
+		}
+	}
+
+	for _, endpoint := range endpoints {
+		if d.endpointReachable(ctx, endpoint.ValueString()) {
+			return false
+		}
+	}
+
+	resp.Deferred = &datasource.Deferred{
+		Reason: datasource.DeferredReasonAbsentPrereq,
+	}
+
+	return true
+}
+
+// endpointReachable returns true if a connection to the Talos API of the endpoint can be established.
+func (d *talosProviderData) endpointReachable(ctx context.Context, endpoint string) bool {
+	// the nodes behind Omni are not dialed directly
+	if d != nil && d.omniEndpoint != nil {
+		return true
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, deferReachabilityTimeout)
+	defer cancel()
+
+	address := resolver.EnsureEndpointsHavePorts([]string{endpoint}, constants.ApidPort)[0]
+
+	var (
+		conn net.Conn
+		err  error
+	)
+
+	if d != nil && d.proxyDialer != nil {
+		conn, err = d.proxyDialer.DialContext(ctx, "tcp", address)
+	} else {
+		var dialer net.Dialer
+
+		conn, err = dialer.DialContext(ctx, "tcp", address)
+	}
+
+	if err != nil {
+		return false
+	}
+
+	conn.Close() //nolint:errcheck
+
+	return true
+}
@@ -0,0 +1,89 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestDeferRead(t *testing.T) {
+	t.Parallel()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { listener.Close() }) //nolint:errcheck
+
+	closedListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	closedAddress := closedListener.Addr().String()
+
+	if err = closedListener.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	providerData := &talosProviderData{}
+
+	for _, tt := range []struct {
+		name            string
+		deferralAllowed bool
+		endpoints       []types.String
+		expectedReason  datasource.DeferredReason
+	}{
+		{
+			name:      "deferral not allowed",
+			endpoints: []types.String{types.StringUnknown()},
+		},
+		{
+			name:            "unknown endpoint",
+			deferralAllowed: true,
+			endpoints:       []types.String{types.StringUnknown()},
+			expectedReason:  datasource.DeferredReasonDataSourceConfigUnknown,
+		},
+		{
+			name:            "unreachable endpoint",
+			deferralAllowed: true,
+			endpoints:       []types.String{types.StringValue(closedAddress)},
+			expectedReason:  datasource.DeferredReasonAbsentPrereq,
+		},
+		{
+			name:            "reachable endpoint",
+			deferralAllowed: true,
+			endpoints:       []types.String{types.StringValue(closedAddress), types.StringValue(listener.Addr().String())},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := datasource.ReadRequest{
+				ClientCapabilities: datasource.ReadClientCapabilities{
+					DeferralAllowed: tt.deferralAllowed,
+				},
+			}
+
+			var resp datasource.ReadResponse
+
+			deferred := providerData.deferRead(context.Background(), req, &resp, tt.endpoints...)
+
+			if deferred != (tt.expectedReason != datasource.DeferredReasonUnknown) {
+				t.Fatalf("expected deferred %v, got %v", tt.expectedReason != datasource.DeferredReasonUnknown, deferred)
+			}
+
+			if deferred && resp.Deferred.Reason != tt.expectedReason {
+				t.Errorf("expected reason %s, got %s", tt.expectedReason, resp.Deferred.Reason)
+			}
+		})
+	}
+}
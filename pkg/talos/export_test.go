@@ -0,0 +1,187 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos
+
+import (
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	machineapi "github.com/siderolabs/talos/pkg/machinery/api/machine"
+	"github.com/siderolabs/talos/pkg/machinery/config/generate"
+	"github.com/siderolabs/talos/pkg/machinery/config/generate/secrets"
+	"github.com/siderolabs/talos/pkg/machinery/config/machine"
+	"github.com/siderolabs/talos/pkg/machinery/config/types/v1alpha1"
+	"github.com/siderolabs/talos/pkg/machinery/gendata"
+)
+
+// Export the machine configuration fixtures of the unit tests.
+const (
+	KmsgLogConfig            = testKmsgLogConfig
+	MachineConfigurationJSON = testMachineConfigurationJSON
+	MachineConfigurationYAML = testMachineConfiguration
+)
+
+// Export the models of the resources and data sources.
+type (
+	HealthCheckFailure                            = healthCheckFailure
+	MachineConfigurationDiskEncryption            = machineConfigurationDiskEncryption
+	MachineConfigurationEncryptionKey             = machineConfigurationEncryptionKey
+	MachineConfigurationInlineManifest            = machineConfigurationInlineManifest
+	MachineConfigurationNodeTaint                 = machineConfigurationNodeTaint
+	MachineConfigurationPartitionEncryption       = machineConfigurationPartitionEncryption
+	MachineConfigurationRegistry                  = machineConfigurationRegistry
+	MachineConfigurationRegistryAuth              = machineConfigurationRegistryAuth
+	MachineConfigurationRegistryTLS               = machineConfigurationRegistryTLS
+	MachineConfigurationVolume                    = machineConfigurationVolume
+	MachineSecretsCACertificates                  = machineSecretsCACertificates
+	MachineSecretsCertKeyPair                     = machineSecretsCertKeyPair
+	TalosMachineConfigurationApplyResourceModelV1 = talosMachineConfigurationApplyResourceModelV1
+	TalosMachineExtension                         = talosMachineExtension
+)
+
+// Export the helpers of the resources and data sources for the tests living next to them.
+var (
+	ApplyConfigPatches                = applyConfigPatches
+	BytesToBase64                     = bytesToBase64
+	CPUCount                          = cpuCount
+	CertificateAuthorityFromPEM       = certificateAuthorityFromPEM
+	CheckKubernetesUpgradePath        = checkKubernetesUpgradePath
+	CheckTalosUpgradePath             = checkTalosUpgradePath
+	ClusterEndpointWithPort           = clusterEndpointWithPort
+	ClusterManifestsPatch             = clusterManifestsPatch
+	ConfigurationDiffSummary          = configurationDiffSummary
+	DiscoverAddresses                 = discoverAddresses
+	DryRunSummary                     = dryRunSummary
+	ErrMaintenanceMode                = errMaintenanceMode
+	ErrSequenceFailed                 = errSequenceFailed
+	EtcdAlreadyBootstrapped           = etcdAlreadyBootstrapped
+	EtcdSingleMember                  = etcdSingleMember
+	GenBootstrapToken                 = genBootstrapToken
+	InstallTimeChanges                = installTimeChanges
+	InstallerImage                    = installerImage
+	IsTransientError                  = isTransientError
+	LoadTalosConfig                   = loadTalosConfig
+	MachineConfigurationApplyRequired = machineConfigurationApplyRequired
+	MachineConfigurationEqual         = machineConfigurationEqual
+	MachineConfigurationObject        = machineConfigurationObject
+	MachineConfigurationWithToken     = machineConfigurationWithToken
+	MachineEventMatches               = machineEventMatches
+	MachineExtensions                 = machineExtensions
+	MemorySize                        = memorySize
+	NewHealthCheckFailure             = newHealthCheckFailure
+	NewHealthCheckFailures            = newHealthCheckFailures
+	NodeMetadataPatch                 = nodeMetadataPatch
+	NodeReinstalled                   = nodeReinstalled
+	NodesToUpgrade                    = nodesToUpgrade
+	ParseTalosCompatibilityVersion    = parseTalosCompatibilityVersion
+	PatchMachineConfiguration         = patchMachineConfiguration
+	PlannedMachineConfiguration       = plannedMachineConfiguration
+	RegistriesGenerateOptions         = registriesGenerateOptions
+	RotatedClientConfiguration        = rotatedClientConfiguration
+	SecretsBundleToMachineSecrets     = secretsBundleTomachineSecrets
+	SecretsDivergence                 = secretsDivergence
+	SetCACertificates                 = setCACertificates
+	SystemDiskEncryptionConfig        = systemDiskEncryptionConfig
+	UpgradeBatches                    = upgradeBatches
+	UpgradeSkipVersion                = upgradeSkipVersion
+	ValidateAdditionalDocument        = validateAdditionalDocument
+	ValidateMachineConfiguration      = validateMachineConfiguration
+	VolumeConfigDocuments             = volumeConfigDocuments
+	WorkerJoinConfigPatch             = workerJoinConfigPatch
+	WorkerJoinTokenExpired            = workerJoinTokenExpired
+)
+
+// GenerateOption sets an option of the machine configuration generated by GenerateMachineConfiguration.
+type GenerateOption func(*machineConfigGenerateOptions)
+
+// WithConfigPatches applies the config patches to the generated machine configuration.
+func WithConfigPatches(configPatches ...string) GenerateOption {
+	return func(m *machineConfigGenerateOptions) {
+		m.configPatches = append(m.configPatches, configPatches...)
+	}
+}
+
+// WithRegistryOptions sets the registries of the generated machine configuration.
+func WithRegistryOptions(registryOptions ...generate.Option) GenerateOption {
+	return func(m *machineConfigGenerateOptions) {
+		m.registryOptions = append(m.registryOptions, registryOptions...)
+	}
+}
+
+// WithDiskEncryption sets the system disk encryption of the generated machine configuration.
+func WithDiskEncryption(diskEncryption *v1alpha1.SystemDiskEncryptionConfig) GenerateOption {
+	return func(m *machineConfigGenerateOptions) {
+		m.diskEncryption = diskEncryption
+	}
+}
+
+// GenerateMachineConfiguration generates the machine configuration of a test cluster with the default options.
+func GenerateMachineConfiguration(machineType machine.Type, secretsBundle *secrets.Bundle, opts ...GenerateOption) (string, error) {
+	genOptions := &machineConfigGenerateOptions{
+		machineType:     machineType,
+		clusterName:     "test",
+		clusterEndpoint: "https://cluster.local:6443",
+		machineSecrets:  secretsBundle,
+		talosVersion:    gendata.VersionTag,
+	}
+
+	for _, opt := range opts {
+		opt(genOptions)
+	}
+
+	return genOptions.generate()
+}
+
+// AddNode records the failure of a node check.
+func (f *healthCheckFailures) AddNode(node, check string, err error, duration time.Duration) {
+	f.addNode(node, check, err, duration)
+}
+
+// AddCheck records the failure of a cluster check.
+func (f *healthCheckFailures) AddCheck(nodes []string, status string, err error, duration time.Duration) {
+	f.addCheck(nodes, status, err, duration)
+}
+
+// Healthy reports whether no failure was recorded.
+func (f *healthCheckFailures) Healthy() bool {
+	return f.healthy()
+}
+
+// Nodes returns the failures recorded per node.
+func (f *healthCheckFailures) Nodes() map[string][]healthCheckFailure {
+	return f.nodes
+}
+
+// Cluster returns the failures recorded for the cluster.
+func (f *healthCheckFailures) Cluster() []healthCheckFailure {
+	return f.cluster
+}
+
+// SetApplyResult sets the reboot state from the apply configuration response.
+func (s *talosMachineConfigurationApplyResourceModelV1) SetApplyResult(applyResp *machineapi.ApplyConfigurationResponse) diag.Diagnostics {
+	return s.setApplyResult(applyResp)
+}
+
+// KubeConfigOptions are the options applied to the kubeconfig of the cluster.
+type KubeConfigOptions struct {
+	ClusterEndpoint       string
+	ControlPlaneEndpoints []string
+	ClusterName           string
+	ContextName           string
+	UserName              string
+	Namespace             string
+}
+
+// Apply applies the options to the kubeconfig.
+func (o KubeConfigOptions) Apply(kubeConfigBytes []byte) ([]byte, error) {
+	return kubeConfigOptions{
+		clusterEndpoint:       o.ClusterEndpoint,
+		controlPlaneEndpoints: o.ControlPlaneEndpoints,
+		clusterName:           o.ClusterName,
+		contextName:           o.ContextName,
+		userName:              o.UserName,
+		namespace:             o.Namespace,
+	}.apply(kubeConfigBytes)
+}
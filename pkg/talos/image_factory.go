@@ -0,0 +1,80 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/siderolabs/image-factory/pkg/client"
+)
+
+// headerTransport sets the configured headers on each request, e.g. to authenticate to a self-hosted Image Factory.
+type headerTransport struct {
+	transport http.RoundTripper
+	headers   map[string]string
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+
+	for k, v := range t.headers {
+		req.Header.Set(k, v)
+	}
+
+	return t.transport.RoundTrip(req)
+}
+
+// newImageFactoryClient returns an Image Factory client sending the headers with each request
+// and trusting the PEM encoded CA certificate on top of the system CAs, if set.
+func newImageFactoryClient(baseURL string, headers map[string]string, caCertificate string) (*client.Client, error) {
+	// the URL is empty if it is not known yet, the provider is configured again with the known value before apply
+	if baseURL != "" {
+		u, err := url.Parse(baseURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the Image Factory URL: %w", err)
+		}
+
+		if (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return nil, errors.New("the Image Factory URL must be an http:// or https:// URL")
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone() //nolint:forcetypeassert
+
+	if caCertificate != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+
+		if !pool.AppendCertsFromPEM([]byte(caCertificate)) {
+			return nil, errors.New("failed to parse the Image Factory CA certificate")
+		}
+
+		transport.TLSClientConfig = &tls.Config{
+			RootCAs:    pool,
+			MinVersion: tls.VersionTLS12,
+		}
+	}
+
+	var roundTripper http.RoundTripper = transport
+
+	if len(headers) > 0 {
+		roundTripper = &headerTransport{
+			transport: transport,
+			headers:   headers,
+		}
+	}
+
+	return client.New(baseURL, client.WithClient(http.Client{
+		Transport: roundTripper,
+	}))
+}
@@ -0,0 +1,91 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos
+
+import (
+	"context"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNewImageFactoryClient(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		fmt.Fprint(w, `["v1.7.5"]`) //nolint:errcheck
+	}))
+	t.Cleanup(server.Close)
+
+	caCertificate := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+
+	for _, tt := range []struct {
+		name          string
+		baseURL       string
+		headers       map[string]string
+		caCertificate string
+		expectedErr   string
+	}{
+		{
+			name:          "authenticated",
+			baseURL:       server.URL,
+			headers:       map[string]string{"Authorization": "Bearer token"},
+			caCertificate: caCertificate,
+		},
+		{
+			name:          "unauthenticated",
+			baseURL:       server.URL,
+			caCertificate: caCertificate,
+			expectedErr:   "401",
+		},
+		{
+			name:        "untrusted",
+			baseURL:     server.URL,
+			headers:     map[string]string{"Authorization": "Bearer token"},
+			expectedErr: "certificate",
+		},
+		{
+			name:          "invalid CA",
+			baseURL:       server.URL,
+			caCertificate: "invalid",
+			expectedErr:   "failed to parse the Image Factory CA certificate",
+		},
+		{
+			name:        "invalid URL",
+			baseURL:     "factory.example.com",
+			expectedErr: "must be an http:// or https:// URL",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			c, err := newImageFactoryClient(tt.baseURL, tt.headers, tt.caCertificate)
+			if err == nil {
+				_, err = c.Versions(context.Background())
+			}
+
+			if tt.expectedErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+				t.Errorf("expected error containing %q, got %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
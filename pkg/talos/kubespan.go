@@ -0,0 +1,77 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+
+	"github.com/cosi-project/runtime/pkg/safe"
+	"github.com/siderolabs/talos/pkg/machinery/client"
+	"github.com/siderolabs/talos/pkg/machinery/resources/cluster"
+)
+
+// kubeSpanPrefix contains the KubeSpan addresses, which are unique local addresses derived from the cluster ID.
+var kubeSpanPrefix = netip.MustParsePrefix("fd00::/8")
+
+type kubeSpanResolutionContextKey struct{}
+
+// withKubeSpanResolution returns a context enabling the resolution of the nodes by hostname or node ID in talosClientOp.
+func withKubeSpanResolution(ctx context.Context, enabled bool) context.Context {
+	if !enabled {
+		return ctx
+	}
+
+	return context.WithValue(ctx, kubeSpanResolutionContextKey{}, true)
+}
+
+func kubeSpanResolutionFromContext(ctx context.Context) bool {
+	enabled, _ := ctx.Value(kubeSpanResolutionContextKey{}).(bool)
+
+	return enabled
+}
+
+// resolveKubeSpanNode resolves the node through the cluster members known to the endpoints, opts carry the client configuration.
+func resolveKubeSpanNode(ctx context.Context, endpoints []string, node string, opts []client.OptionFunc) (string, error) {
+	c, err := client.New(ctx, append(opts, client.WithEndpoints(endpoints...))...)
+	if err != nil {
+		return "", err
+	}
+
+	defer c.Close() //nolint:errcheck
+
+	members, err := safe.StateListAll[*cluster.Member](ctx, c.COSI)
+	if err != nil {
+		return "", fmt.Errorf("failed to list the cluster members: %w", err)
+	}
+
+	return nodeAddressFromMembers(safe.ToSlice(members, func(m *cluster.Member) *cluster.Member { return m }), node)
+}
+
+// nodeAddressFromMembers returns the address of the member with the node as hostname or node ID, preferring its KubeSpan address.
+func nodeAddressFromMembers(members []*cluster.Member, node string) (string, error) {
+	for _, member := range members {
+		spec := member.TypedSpec()
+
+		if member.Metadata().ID() != node && spec.Hostname != node && spec.NodeID != node {
+			continue
+		}
+
+		if len(spec.Addresses) == 0 {
+			return "", fmt.Errorf("cluster member %q has no addresses", node)
+		}
+
+		for _, address := range spec.Addresses {
+			if kubeSpanPrefix.Contains(address) {
+				return address.String(), nil
+			}
+		}
+
+		return spec.Addresses[0].String(), nil
+	}
+
+	return "", fmt.Errorf("node %q is not a member of the cluster", node)
+}
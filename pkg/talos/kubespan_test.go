@@ -0,0 +1,84 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos
+
+import (
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/siderolabs/talos/pkg/machinery/resources/cluster"
+)
+
+func TestNodeAddressFromMembers(t *testing.T) {
+	t.Parallel()
+
+	newMember := func(id, hostname string, addresses ...string) *cluster.Member {
+		member := cluster.NewMember(cluster.NamespaceName, id)
+		member.TypedSpec().Hostname = hostname
+
+		for _, address := range addresses {
+			member.TypedSpec().Addresses = append(member.TypedSpec().Addresses, netip.MustParseAddr(address))
+		}
+
+		return member
+	}
+
+	members := []*cluster.Member{
+		newMember("talos-cp-1", "cp-1.example.com", "203.0.113.10", "fdc8:8aee:4e2d:1202:f073:9cff:fe6c:4d67"),
+		newMember("talos-worker-1", "worker-1.example.com", "192.168.1.20"),
+		newMember("talos-worker-2", "worker-2.example.com"),
+	}
+
+	for _, tt := range []struct {
+		name          string
+		node          string
+		expected      string
+		expectedError string
+	}{
+		{
+			name:     "kubespan address by hostname",
+			node:     "cp-1.example.com",
+			expected: "fdc8:8aee:4e2d:1202:f073:9cff:fe6c:4d67",
+		},
+		{
+			name:     "first address by id",
+			node:     "talos-worker-1",
+			expected: "192.168.1.20",
+		},
+		{
+			name:          "no addresses",
+			node:          "talos-worker-2",
+			expectedError: "has no addresses",
+		},
+		{
+			name:          "unknown",
+			node:          "talos-worker-3",
+			expectedError: "is not a member of the cluster",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			address, err := nodeAddressFromMembers(members, tt.node)
+
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectedError, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if address != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, address)
+			}
+		})
+	}
+}
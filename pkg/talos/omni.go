@@ -0,0 +1,87 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+
+	"github.com/siderolabs/go-api-signature/pkg/client/interceptor"
+	"github.com/siderolabs/go-api-signature/pkg/serviceaccount"
+	"github.com/siderolabs/talos/pkg/machinery/client"
+	"google.golang.org/grpc"
+)
+
+// omniEndpoint routes the Talos API requests through the Talos API proxy of Omni,
+// the requests are signed with the service account key and the nodes are addressed by their Omni machine names.
+type omniEndpoint struct {
+	endpoint    string
+	interceptor *interceptor.Interceptor
+}
+
+type omniEndpointContextKey struct{}
+
+func newOmniEndpoint(endpoint, serviceAccountKey string) (*omniEndpoint, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the Omni endpoint: %w", err)
+	}
+
+	if u.Scheme != "https" || u.Host == "" {
+		return nil, errors.New("the Omni endpoint must be an https:// URL")
+	}
+
+	if serviceAccountKey == "" {
+		var envKey string
+
+		if envKey, serviceAccountKey = serviceaccount.GetFromEnv(); envKey == "" {
+			return nil, fmt.Errorf("omni_service_account_key or the %s environment variable is required", serviceaccount.OmniServiceAccountKeyEnvVar)
+		}
+	}
+
+	if _, err = serviceaccount.Decode(serviceAccountKey); err != nil {
+		return nil, fmt.Errorf("failed to decode the Omni service account key: %w", err)
+	}
+
+	return &omniEndpoint{
+		endpoint: endpoint,
+		interceptor: interceptor.New(interceptor.Options{
+			InfoWriter:           io.Discard,
+			ServiceAccountBase64: serviceAccountKey,
+			ClientName:           "Talos",
+		}),
+	}, nil
+}
+
+// withOmniEndpoint returns a context carrying the Omni endpoint, talosClientOp routes the requests through it if it is present in the context.
+func withOmniEndpoint(ctx context.Context, omni *omniEndpoint) context.Context {
+	if omni == nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, omniEndpointContextKey{}, omni)
+}
+
+// omniClientOptionsFromContext returns the Talos client options to connect through the Omni endpoint in the context, if any.
+func omniClientOptionsFromContext(ctx context.Context) ([]client.OptionFunc, bool) {
+	omni, ok := ctx.Value(omniEndpointContextKey{}).(*omniEndpoint)
+	if !ok {
+		return nil, false
+	}
+
+	return []client.OptionFunc{
+		client.WithEndpoints(omni.endpoint),
+		// Omni serves the Talos API with a publicly trusted certificate, the client is authenticated by the signed requests
+		client.WithTLSConfig(&tls.Config{}),
+		client.WithGRPCDialOptions(
+			grpc.WithUnaryInterceptor(omni.interceptor.Unary()),
+			grpc.WithStreamInterceptor(omni.interceptor.Stream()),
+		),
+	}, true
+}
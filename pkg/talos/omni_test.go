@@ -0,0 +1,80 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/siderolabs/go-api-signature/pkg/pgp"
+	"github.com/siderolabs/go-api-signature/pkg/serviceaccount"
+)
+
+func TestNewOmniEndpoint(t *testing.T) {
+	t.Parallel()
+
+	key, err := pgp.GenerateKey("terraform", "", "terraform@serviceaccount.omni.sidero.dev", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	serviceAccountKey, err := serviceaccount.Encode("terraform", key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name              string
+		endpoint          string
+		serviceAccountKey string
+		expectedError     string
+	}{
+		{
+			name:              "valid",
+			endpoint:          "https://example.omni.siderolabs.io",
+			serviceAccountKey: serviceAccountKey,
+		},
+		{
+			name:              "plain http",
+			endpoint:          "http://example.omni.siderolabs.io",
+			serviceAccountKey: serviceAccountKey,
+			expectedError:     "must be an https:// URL",
+		},
+		{
+			name:              "invalid key",
+			endpoint:          "https://example.omni.siderolabs.io",
+			serviceAccountKey: "invalid",
+			expectedError:     "failed to decode the Omni service account key",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			omni, err := newOmniEndpoint(tt.endpoint, tt.serviceAccountKey)
+
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectedError, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, ok := omniClientOptionsFromContext(context.Background()); ok {
+				t.Error("expected no Omni client options without the Omni endpoint in the context")
+			}
+
+			if _, ok := omniClientOptionsFromContext(withOmniEndpoint(context.Background(), omni)); !ok {
+				t.Error("expected the Omni client options with the Omni endpoint in the context")
+			}
+		})
+	}
+}
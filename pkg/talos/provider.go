@@ -7,13 +7,26 @@ package talos
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
 	"github.com/siderolabs/image-factory/pkg/client"
+	clientconfig "github.com/siderolabs/talos/pkg/machinery/client/config"
+	"golang.org/x/sync/semaphore"
 )
 
 const (
@@ -24,8 +37,92 @@ const (
 // talosProvider is the provider implementation.
 type talosProvider struct{}
 
+var _ provider.ProviderWithFunctions = &talosProvider{}
+
 type talosProviderModelV0 struct {
-	ImageFactoryURL types.String `tfsdk:"image_factory_url"`
+	ImageFactoryURL               types.String      `tfsdk:"image_factory_url"`
+	ImageFactoryHeaders           types.Map         `tfsdk:"image_factory_headers"`
+	ImageFactoryCACertificate     types.String      `tfsdk:"image_factory_ca_certificate"`
+	AdditionalCACertificates      types.List        `tfsdk:"additional_ca_certificates"`
+	AdditionalCACertificatesUntil types.String      `tfsdk:"additional_ca_certificates_until"`
+	ProxyURL                      types.String      `tfsdk:"proxy_url"`
+	SSHPrivateKey                 types.String      `tfsdk:"ssh_private_key"`
+	SSHHostKey                    types.String      `tfsdk:"ssh_host_key"`
+	OmniEndpoint                  types.String      `tfsdk:"omni_endpoint"`
+	OmniServiceAccountKey         types.String      `tfsdk:"omni_service_account_key"`
+	KubeSpanNodeResolution        types.Bool        `tfsdk:"kubespan_node_resolution"`
+	TLSServerName                 types.String      `tfsdk:"tls_server_name"`
+	InsecureSkipVerify            types.Bool        `tfsdk:"insecure_skip_verify"`
+	TalosConfigPath               types.String      `tfsdk:"talosconfig_path"`
+	TalosConfigContext            types.String      `tfsdk:"talosconfig_context"`
+	Retry                         *retryPolicyModel `tfsdk:"retry"`
+	SupportBundleDirectory        types.String      `tfsdk:"support_bundle_directory"`
+	MaxConcurrentOperations       types.Int64       `tfsdk:"max_concurrent_operations"`
+}
+
+// talosProviderData is the data shared by the provider with data sources and resources.
+type talosProviderData struct {
+	imageFactoryClient            *client.Client
+	additionalCACertificates      []string
+	additionalCACertificatesUntil time.Time
+	proxyDialer                   proxyDialer
+	omniEndpoint                  *omniEndpoint
+	kubeSpanNodeResolution        bool
+	tlsOverrides                  tlsOverrides
+	talosConfig                   *clientconfig.Config
+	retryPolicy                   *retryPolicy
+	supportBundleDirectory        string
+	operationLimiter              *semaphore.Weighted
+}
+
+// additionalCAs returns the additional CA certificates to trust when connecting to the Talos API.
+//
+// Once the configured overlap period has passed, no additional CA certificates are returned.
+func (d *talosProviderData) additionalCAs() []string {
+	if d == nil {
+		return nil
+	}
+
+	if !d.additionalCACertificatesUntil.IsZero() && OverridableTimeFunc().After(d.additionalCACertificatesUntil) {
+		return nil
+	}
+
+	return d.additionalCACertificates
+}
+
+// talosClientConfig returns the Talos client configuration for the client configuration of a resource,
+// falling back to the talosconfig of the provider if the resource has none.
+func (d *talosProviderData) talosClientConfig(clusterName string, cc *clientConfiguration) (*clientconfig.Config, error) {
+	if cc != nil {
+		return talosClientTFConfigToTalosClientConfig(clusterName, cc.CA.ValueString(), cc.Cert.ValueString(), cc.Key.ValueString(), d.additionalCAs()...)
+	}
+
+	if d == nil || d.talosConfig == nil {
+		return nil, errors.New("client_configuration is required if the provider talosconfig_path is not set")
+	}
+
+	return d.talosConfig, nil
+}
+
+// withClientOptions returns a context carrying the configured proxy dialer, Omni endpoint, node resolution, TLS overrides, retry policy and operation limiter, so that the Talos API is reached through them.
+func (d *talosProviderData) withClientOptions(ctx context.Context) context.Context {
+	if d == nil {
+		return ctx
+	}
+
+	ctx = withProxyDialer(ctx, d.proxyDialer)
+	ctx = withOmniEndpoint(ctx, d.omniEndpoint)
+	ctx = withKubeSpanResolution(ctx, d.kubeSpanNodeResolution)
+
+	ctx = withTLSOverrides(ctx, d.tlsOverrides)
+	ctx = withOperationLimiter(ctx, d.operationLimiter)
+
+	return withRetryPolicy(ctx, d.retryPolicy)
+}
+
+// retryContext retries the Talos API operation following the configured retry policy.
+func (d *talosProviderData) retryContext(ctx context.Context, timeout time.Duration, f retry.RetryFunc) error {
+	return retryContext(d.withClientOptions(ctx), timeout, f)
 }
 
 // New is a helper function to simplify provider server and testing implementation.
@@ -46,6 +143,112 @@ func (p *talosProvider) Schema(_ context.Context, _ provider.SchemaRequest, resp
 				Optional:    true,
 				Description: "The URL of Image Factory to generate schematics. If not set defaults to https://factory.talos.dev.",
 			},
+			"image_factory_headers": schema.MapAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Sensitive:   true,
+				Description: "The HTTP headers to send with each Image Factory request, e.g. an `Authorization` header to authenticate to a self-hosted Image Factory.",
+			},
+			"image_factory_ca_certificate": schema.StringAttribute{
+				Optional:    true,
+				Description: "The PEM encoded CA certificate to trust, on top of the system CAs, when connecting to a self-hosted Image Factory.",
+			},
+			"additional_ca_certificates": schema.ListAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Description: "Additional CA certificates (base64 encoded PEM) to trust when connecting to the Talos API. Useful to keep the connection to the nodes while the Talos API CA is being rotated.",
+			},
+			"additional_ca_certificates_until": schema.StringAttribute{
+				Optional:    true,
+				Description: "The time (RFC3339) until which the additional CA certificates are trusted. If not set, the additional CA certificates are trusted indefinitely.",
+			},
+			"proxy_url": schema.StringAttribute{
+				Optional: true,
+				Description: "The proxy to connect to the Talos API through, either an HTTP(S) proxy (`http(s)://[user:password@]host:port`), a SOCKS5 proxy (`socks5://[user:password@]host:port`) or an SSH bastion (`ssh://user@host[:port]`). " +
+					"If not set, the HTTP proxy set by the `HTTPS_PROXY` and `NO_PROXY` environment variables is used. " +
+					"Use a provider alias to connect to some nodes through a different proxy.",
+			},
+			"ssh_private_key": schema.StringAttribute{
+				Optional:    true,
+				Sensitive:   true,
+				Description: "The PEM encoded private key to authenticate to the SSH bastion with.",
+			},
+			"ssh_host_key": schema.StringAttribute{
+				Optional:    true,
+				Description: "The public host key of the SSH bastion in the authorized keys format (e.g. `ssh-ed25519 AAAA...`), the connection fails if the bastion presents another key.",
+			},
+			"insecure_skip_verify": schema.BoolAttribute{
+				Optional: true,
+				Description: "**INSECURE**: skip the verification of the Talos API certificate, the client certificate is still presented to the nodes. " +
+					"The connection is open to man-in-the-middle attacks, prefer `tls_server_name` when the certificate doesn't match the dialed address.",
+			},
+			"retry": schema.SingleNestedAttribute{
+				Optional:    true,
+				Description: "Tunes the retries of the Talos API operations, e.g. for nodes reached through flaky out-of-band networks. If not set, the operations are retried until their timeout. The delay between the retries doubles on each retry and is randomized between half and all of its value. The invalid requests, the rejected client credentials and the certificates of an unexpected CA are never retried.",
+				Attributes: map[string]schema.Attribute{
+					"max_retries": schema.Int64Attribute{
+						Optional:    true,
+						Description: "The maximum number of retries of an operation, the operations are retried until their timeout if not set or 0.",
+						Validators: []validator.Int64{
+							int64validator.AtLeast(0),
+						},
+					},
+					"initial_backoff": schema.StringAttribute{
+						Optional:    true,
+						Description: "The delay before the first retry, doubled on each retry up to `max_backoff`. Defaults to `500ms`.",
+					},
+					"max_backoff": schema.StringAttribute{
+						Optional:    true,
+						Description: "The maximum delay between retries. Defaults to `10s`.",
+					},
+					"retryable_codes": schema.ListAttribute{
+						ElementType: types.StringType,
+						Optional:    true,
+						Description: "The gRPC codes to retry (e.g. `Unavailable`, `DeadlineExceeded`), errors with another code fail the operation. Errors without a gRPC code are always retried. If not set, all the errors the operation considers transient are retried.",
+					},
+				},
+			},
+			"support_bundle_directory": schema.StringAttribute{
+				Optional: true,
+				Description: "The directory to write a support bundle (service logs, kernel logs and resources) of the node to when a machine configuration apply, a bootstrap or an upgrade fails, " +
+					"e.g. to keep the postmortem data of CI-driven cluster builds. No support bundle is collected if not set.",
+			},
+			"talosconfig_context": schema.StringAttribute{
+				Optional:    true,
+				Description: "The context of the talosconfig to use, defaults to the current context of the talosconfig.",
+			},
+			"talosconfig_path": schema.StringAttribute{
+				Optional: true,
+				Description: "The path of a talosconfig file (e.g. `~/.talos/config`) providing the credentials of the resources and data sources which don't set `client_configuration`. " +
+					"Only contexts with a client certificate are supported, the endpoints of the context are not used as each resource sets its `endpoint` and `node`.",
+			},
+			"tls_server_name": schema.StringAttribute{
+				Optional:    true,
+				Description: "The server name to verify the Talos API certificate against instead of the dialed address, when connecting through NAT, port-forwards or shared load balancers.",
+			},
+			"kubespan_node_resolution": schema.BoolAttribute{
+				Optional: true,
+				Description: "Resolve the `node` values which are not IP addresses as the hostname or node ID of a cluster member, as reported by the discovery service through the `endpoint`. " +
+					"The KubeSpan address of the member is used if it has one, so the node is reached over KubeSpan whatever its public or DHCP address is.",
+			},
+			"max_concurrent_operations": schema.Int64Attribute{
+				Optional: true,
+				Description: "The maximum number of Talos API operations the provider runs concurrently across all the resources and data sources, " +
+					"e.g. to keep Terraform parallelism from overwhelming apid and trustd on large fleets. Not limited if not set.",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"omni_endpoint": schema.StringAttribute{
+				Optional: true,
+				Description: "The URL of the Omni instance (e.g. `https://example.omni.siderolabs.io`) to route the Talos API requests through, instead of connecting to the nodes directly. " +
+					"The nodes are addressed by their Omni machine names, the `endpoint` and `client_configuration` of the resources are not used for the connection.",
+			},
+			"omni_service_account_key": schema.StringAttribute{
+				Optional:    true,
+				Sensitive:   true,
+				Description: "The Omni service account key used to sign the Talos API requests. If not set, the `OMNI_SERVICE_ACCOUNT_KEY` environment variable is used.",
+			},
 		},
 	}
 }
@@ -66,29 +269,181 @@ func (p *talosProvider) Configure(ctx context.Context, req provider.ConfigureReq
 		imageFactoryURL = ImageFactoryURL
 	}
 
-	imageFactoryClient, err := client.New(imageFactoryURL)
+	var imageFactoryHeaders map[string]string
+
+	if !config.ImageFactoryHeaders.IsUnknown() {
+		resp.Diagnostics.Append(config.ImageFactoryHeaders.ElementsAs(ctx, &imageFactoryHeaders, false)...)
+
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	imageFactoryClient, err := newImageFactoryClient(imageFactoryURL, imageFactoryHeaders, config.ImageFactoryCACertificate.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError("failed to create Image Factory client", err.Error())
 
 		return
 	}
 
-	resp.DataSourceData = imageFactoryClient
-	resp.ResourceData = imageFactoryClient
+	var additionalCACertificates []string
+
+	// the additional CA certificates might not be known yet at plan time (e.g. they come from a resource being rotated),
+	// in that case the provider is configured again with the known values before apply
+	if !config.AdditionalCACertificates.IsUnknown() {
+		var cas []types.String
+
+		resp.Diagnostics.Append(config.AdditionalCACertificates.ElementsAs(ctx, &cas, true)...)
+
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		for i, ca := range cas {
+			if ca.IsUnknown() || ca.IsNull() {
+				continue
+			}
+
+			if _, err = base64ToBytes(ca.ValueString()); err != nil {
+				resp.Diagnostics.AddAttributeError(
+					path.Root("additional_ca_certificates").AtListIndex(i),
+					"failed to decode additional CA certificate",
+					err.Error(),
+				)
+
+				return
+			}
+
+			additionalCACertificates = append(additionalCACertificates, ca.ValueString())
+		}
+	}
+
+	var additionalCACertificatesUntil time.Time
+
+	if config.AdditionalCACertificatesUntil.ValueString() != "" {
+		additionalCACertificatesUntil, err = time.Parse(time.RFC3339, config.AdditionalCACertificatesUntil.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("additional_ca_certificates_until"),
+				"failed to parse additional_ca_certificates_until",
+				err.Error(),
+			)
+
+			return
+		}
+	}
+
+	var dialer proxyDialer
+
+	if config.ProxyURL.ValueString() != "" {
+		dialer, err = newProxyDialer(config.ProxyURL.ValueString(), config.SSHPrivateKey.ValueString(), config.SSHHostKey.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("proxy_url"),
+				"failed to configure the proxy",
+				err.Error(),
+			)
+
+			return
+		}
+	}
+
+	var omni *omniEndpoint
+
+	if config.OmniEndpoint.ValueString() != "" {
+		omni, err = newOmniEndpoint(config.OmniEndpoint.ValueString(), config.OmniServiceAccountKey.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("omni_endpoint"),
+				"failed to configure the Omni endpoint",
+				err.Error(),
+			)
+
+			return
+		}
+	}
+
+	var talosConfig *clientconfig.Config
+
+	if config.TalosConfigPath.ValueString() != "" {
+		talosConfig, err = loadTalosConfig(config.TalosConfigPath.ValueString(), config.TalosConfigContext.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("talosconfig_path"),
+				"failed to load the talosconfig",
+				err.Error(),
+			)
+
+			return
+		}
+	}
+
+	var policy *retryPolicy
+
+	if config.Retry != nil {
+		policy, err = newRetryPolicy(config.Retry)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("retry"),
+				"failed to configure the retry policy",
+				err.Error(),
+			)
+
+			return
+		}
+	}
+
+	var operationLimiter *semaphore.Weighted
+
+	if !config.MaxConcurrentOperations.IsNull() && !config.MaxConcurrentOperations.IsUnknown() {
+		operationLimiter = semaphore.NewWeighted(config.MaxConcurrentOperations.ValueInt64())
+	}
+
+	providerData := &talosProviderData{
+		imageFactoryClient:            imageFactoryClient,
+		additionalCACertificates:      additionalCACertificates,
+		additionalCACertificatesUntil: additionalCACertificatesUntil,
+		proxyDialer:                   dialer,
+		omniEndpoint:                  omni,
+		kubeSpanNodeResolution:        config.KubeSpanNodeResolution.ValueBool(),
+		tlsOverrides: tlsOverrides{
+			serverName:         config.TLSServerName.ValueString(),
+			insecureSkipVerify: config.InsecureSkipVerify.ValueBool(),
+		},
+		talosConfig:            talosConfig,
+		retryPolicy:            policy,
+		supportBundleDirectory: config.SupportBundleDirectory.ValueString(),
+		operationLimiter:       operationLimiter,
+	}
+
+	resp.DataSourceData = providerData
+	resp.ResourceData = providerData
 }
 
 // DataSources defines the data sources implemented in the provider.
 func (p *talosProvider) DataSources(_ context.Context) []func() datasource.DataSource {
 	return []func() datasource.DataSource{
 		NewTalosMachineDisksDataSource,
+		NewTalosMachineNetworkInterfacesDataSource,
+		NewTalosMachineExtensionsDataSource,
+		NewTalosMachineSystemInfoDataSource,
+		NewTalosMachinesDiscoverDataSource,
+		NewTalosMachineServiceStatusDataSource,
+		NewTalosMachineServiceLogsDataSource,
+		NewTalosMachineEventDataSource,
 		NewTalosMachineConfigurationDataSource,
+		NewTalosMachineConfigurationsDataSource,
+		NewTalosMachineConfigurationLiveDataSource,
 		NewTalosClientConfigurationDataSource,
 		NewTalosClusterHealthDataSource,
 		NewTalosClusterKubeConfigDataSource,
+		NewTalosClusterMembersDataSource,
 		NewTalosImageFactoryVersionsDataSource,
 		NewTalosImageFactoryExtensionsVersionsDataSource,
 		NewTalosImageFactoryOverlaysVersionsDataSource,
 		NewTalosImageFactoryURLSDataSource,
+		NewTalosImageFactoryInstallerImageDataSource,
+		NewTalosUpgradePathDataSource,
 	}
 }
 
@@ -97,8 +452,65 @@ func (p *talosProvider) Resources(_ context.Context) []func() resource.Resource
 	return []func() resource.Resource{
 		NewTalosMachineSecretsResource,
 		NewTalosMachineConfigurationApplyResource,
+		NewTalosMachineConfigurationPatchResource,
 		NewTalosMachineBootstrapResource,
+		NewTalosMachineServiceRestartResource,
+		NewTalosMachineRebootResource,
+		NewTalosMachineMetaResource,
 		NewTalosClusterKubeConfigResource,
+		NewTalosClusterUpgradeResource,
+		NewTalosClusterCARotationResource,
+		NewTalosClusterJoinTokenRotationResource,
 		NewTalosImageFactorySchematicResource,
+		NewTalosImageDigestResource,
+		NewTalosLocalClusterResource,
+	}
+}
+
+// Functions defines the functions implemented in the provider.
+func (p *talosProvider) Functions(_ context.Context) []func() function.Function {
+	return []func() function.Function{
+		NewTalosValidateFunction,
+		NewTalosPatchFunction,
+		NewTalosGetFunction,
+		NewTalosGenTokenFunction,
+		NewTalosGenBootstrapTokenFunction,
+	}
+}
+
+// loadTalosConfig reads the talosconfig file the way talosctl does, switching to the context if it is set.
+func loadTalosConfig(talosConfigPath, contextName string) (*clientconfig.Config, error) {
+	if rest, ok := strings.CutPrefix(talosConfigPath, "~/"); ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+
+		talosConfigPath = filepath.Join(home, rest)
 	}
+
+	data, err := os.ReadFile(talosConfigPath)
+	if err != nil {
+		return nil, err
+	}
+
+	talosConfig, err := clientconfig.FromBytes(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the talosconfig: %w", err)
+	}
+
+	if contextName != "" {
+		talosConfig.Context = contextName
+	}
+
+	configContext, ok := talosConfig.Contexts[talosConfig.Context]
+	if !ok {
+		return nil, fmt.Errorf("context %q is not defined in the talosconfig", talosConfig.Context)
+	}
+
+	if configContext.CA == "" || configContext.Crt == "" || configContext.Key == "" {
+		return nil, fmt.Errorf("context %q has no client certificate, only certificate authentication is supported", talosConfig.Context)
+	}
+
+	return talosConfig, nil
 }
@@ -5,14 +5,23 @@
 package talos_test
 
 import (
+	"encoding/base64"
 	"fmt"
 	"os"
+	"path/filepath"
+	"regexp"
 	"strings"
+	"testing"
 	"text/template"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
 	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	clientconfig "github.com/siderolabs/talos/pkg/machinery/client/config"
+	"github.com/siderolabs/talos/pkg/machinery/config/generate/secrets"
 	"github.com/siderolabs/talos/pkg/machinery/gendata"
+	"github.com/siderolabs/talos/pkg/machinery/role"
 
 	"github.com/siderolabs/terraform-provider-talos/pkg/talos"
 )
@@ -32,6 +41,7 @@ type dynamicConfig struct {
 	CPUMode                string
 	DiskSizeFilter         string
 	WithApplyConfig        bool
+	WithPostApplyChecks    bool
 	WithBootstrap          bool
 	WithRetrieveKubeConfig bool
 	WithClusterHealth      bool
@@ -176,6 +186,17 @@ resource "talos_machine_configuration_apply" "this" {
       }
     }),
   ]
+{{ if .WithPostApplyChecks }}
+  post_apply_checks = {
+    services = ["apid"]
+    resources = [
+      {
+        type = "nodename"
+        id   = "nodename"
+      },
+    ]
+  }
+{{ end }}
 }
 {{ end }}
 
@@ -223,3 +244,137 @@ data "talos_cluster_health" "this" {
 
 	return config.String()
 }
+
+func TestAccTalosProviderAdditionalCACertificates(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		IsUnitTest:               true, // this is a local only test, so can be unit tested
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// additional CA certificates which are not known at plan time
+			{
+				Config: testAccTalosProviderAdditionalCACertificatesConfig("[terraform_data.ca.output]"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrWith("data.talos_client_configuration.this", "talos_config", testAccTalosConfigCACertificates(2)),
+				),
+			},
+			{
+				Config:      testAccTalosProviderAdditionalCACertificatesConfig(`["not base64"]`),
+				ExpectError: regexp.MustCompile("failed to decode additional CA certificate"),
+			},
+		},
+	})
+}
+
+func testAccTalosConfigCACertificates(expected int) resource.CheckResourceAttrWithFunc {
+	return func(value string) error {
+		talosConfig, err := clientconfig.FromString(value)
+		if err != nil {
+			return err
+		}
+
+		ca, err := base64.StdEncoding.DecodeString(talosConfig.Contexts[talosConfig.Context].CA)
+		if err != nil {
+			return err
+		}
+
+		if count := strings.Count(string(ca), "BEGIN CERTIFICATE"); count != expected {
+			return fmt.Errorf("expected %d CA certificates, got %d", expected, count)
+		}
+
+		return nil
+	}
+}
+
+func testAccTalosProviderAdditionalCACertificatesConfig(additionalCACertificates string) string {
+	return fmt.Sprintf(`
+provider "talos" {
+  additional_ca_certificates = %s
+}
+
+resource "talos_machine_secrets" "this" {}
+
+# the output of terraform_data is only known after apply
+resource "terraform_data" "ca" {
+  input = base64encode("-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n")
+}
+
+data "talos_client_configuration" "this" {
+  cluster_name         = "example-cluster"
+  client_configuration = talos_machine_secrets.this.client_configuration
+}
+`, additionalCACertificates)
+}
+
+func TestLoadTalosConfig(t *testing.T) {
+	t.Parallel()
+
+	secretsBundle, err := secrets.NewBundle(secrets.NewFixedClock(time.Now()), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clientCert, err := secretsBundle.GenerateTalosAPIClientCertificate(role.MakeSet(role.Admin))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tc := clientconfig.NewConfig("prod", []string{"10.5.0.2"}, secretsBundle.Certs.OS.Crt, clientCert)
+	tc.Contexts["omni"] = &clientconfig.Context{
+		Endpoints: []string{"https://example.omni.siderolabs.io"},
+	}
+
+	talosConfigPath := filepath.Join(t.TempDir(), "talosconfig")
+
+	if err = tc.Save(talosConfigPath); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name            string
+		context         string
+		expectedContext string
+		expectedError   string
+	}{
+		{
+			name:            "current context",
+			expectedContext: "prod",
+		},
+		{
+			name:            "explicit context",
+			context:         "prod",
+			expectedContext: "prod",
+		},
+		{
+			name:          "unknown context",
+			context:       "staging",
+			expectedError: `context "staging" is not defined`,
+		},
+		{
+			name:          "context without client certificate",
+			context:       "omni",
+			expectedError: "only certificate authentication is supported",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			talosConfig, err := talos.LoadTalosConfig(talosConfigPath, tt.context)
+
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectedError, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if talosConfig.Context != tt.expectedContext {
+				t.Errorf("expected context %q, got %q", tt.expectedContext, talosConfig.Context)
+			}
+		})
+	}
+}
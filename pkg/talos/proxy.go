@@ -0,0 +1,288 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/net/proxy"
+	"google.golang.org/grpc"
+)
+
+// proxyDialer dials the Talos API through an HTTP(S) proxy, a SOCKS5 proxy or an SSH bastion.
+//
+// Without a proxy dialer, gRPC dials through the HTTP proxy set by the HTTPS_PROXY and NO_PROXY environment variables.
+type proxyDialer interface {
+	DialContext(ctx context.Context, network, address string) (net.Conn, error)
+}
+
+type proxyDialerContextKey struct{}
+
+// withProxyDialer returns a context carrying the proxy dialer, talosClientOp dials the Talos API through it if it is present in the context.
+func withProxyDialer(ctx context.Context, dialer proxyDialer) context.Context {
+	if dialer == nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, proxyDialerContextKey{}, dialer)
+}
+
+// proxyDialOptionsFromContext returns the gRPC dial options to dial through the proxy dialer in the context, if any.
+func proxyDialOptionsFromContext(ctx context.Context) []grpc.DialOption {
+	dialer, ok := ctx.Value(proxyDialerContextKey{}).(proxyDialer)
+	if !ok {
+		return nil
+	}
+
+	return []grpc.DialOption{
+		grpc.WithContextDialer(func(ctx context.Context, address string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "tcp", address)
+		}),
+	}
+}
+
+// newProxyDialer returns the dialer for the proxy URL, either `http(s)://[user:password@]host:port`, `socks5://[user:password@]host:port` or `ssh://user@host[:port]`.
+func newProxyDialer(proxyURL, sshPrivateKey, sshHostKey string) (proxyDialer, error) {
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the proxy URL: %w", err)
+	}
+
+	switch u.Scheme {
+	case "http", "https":
+		return newHTTPConnectDialer(u), nil
+	case "socks5", "socks5h":
+		dialer, err := proxy.FromURL(u, proxy.Direct)
+		if err != nil {
+			return nil, err
+		}
+
+		contextDialer, ok := dialer.(proxyDialer)
+		if !ok {
+			return nil, errors.New("the SOCKS5 dialer doesn't support contexts")
+		}
+
+		return contextDialer, nil
+	case "ssh":
+		return newSSHDialer(u, sshPrivateKey, sshHostKey)
+	default:
+		return nil, fmt.Errorf("unsupported proxy URL scheme %q, expected http, https, socks5 or ssh", u.Scheme)
+	}
+}
+
+// httpConnectDialer tunnels the connections through an HTTP(S) proxy with the CONNECT method.
+type httpConnectDialer struct {
+	proxyURL *url.URL
+	address  string
+}
+
+func newHTTPConnectDialer(u *url.URL) *httpConnectDialer {
+	address := u.Host
+
+	if u.Port() == "" {
+		port := "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+
+		address = net.JoinHostPort(u.Hostname(), port)
+	}
+
+	return &httpConnectDialer{
+		proxyURL: u,
+		address:  address,
+	}
+}
+
+func (d *httpConnectDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	var netDialer net.Dialer
+
+	conn, err := netDialer.DialContext(ctx, network, d.address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the HTTP proxy: %w", err)
+	}
+
+	if d.proxyURL.Scheme == "https" {
+		conn = tls.Client(conn, &tls.Config{
+			ServerName: d.proxyURL.Hostname(),
+		})
+	}
+
+	// the CONNECT request must not outlive the dial
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline) //nolint:errcheck
+
+		defer conn.SetDeadline(time.Time{}) //nolint:errcheck
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Host: address},
+		Host:   address,
+		Header: http.Header{},
+	}
+
+	if d.proxyURL.User != nil {
+		password, _ := d.proxyURL.User.Password()
+
+		req.Header.Set("Proxy-Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(d.proxyURL.User.Username()+":"+password)))
+	}
+
+	if err = req.Write(conn); err != nil {
+		conn.Close() //nolint:errcheck
+
+		return nil, fmt.Errorf("failed to send the CONNECT request to the HTTP proxy: %w", err)
+	}
+
+	reader := bufio.NewReader(conn)
+
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		conn.Close() //nolint:errcheck
+
+		return nil, fmt.Errorf("failed to read the CONNECT response of the HTTP proxy: %w", err)
+	}
+
+	resp.Body.Close() //nolint:errcheck
+
+	if resp.StatusCode != http.StatusOK {
+		conn.Close() //nolint:errcheck
+
+		return nil, fmt.Errorf("the HTTP proxy failed to connect to %s: %s", address, resp.Status)
+	}
+
+	if reader.Buffered() > 0 {
+		return &bufferedConn{Conn: conn, reader: reader}, nil
+	}
+
+	return conn, nil
+}
+
+// bufferedConn reads the data the proxy sent after the CONNECT response before reading from the connection.
+type bufferedConn struct {
+	net.Conn
+
+	reader *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
+
+// sshDialer tunnels the connections through an SSH bastion, the SSH connection is established on first use and shared.
+type sshDialer struct {
+	config  *ssh.ClientConfig
+	address string
+
+	mu     sync.Mutex
+	client *ssh.Client
+}
+
+func newSSHDialer(u *url.URL, sshPrivateKey, sshHostKey string) (*sshDialer, error) {
+	if u.User == nil || u.User.Username() == "" {
+		return nil, errors.New("the SSH proxy URL must contain the user name")
+	}
+
+	if sshPrivateKey == "" {
+		return nil, errors.New("ssh_private_key is required for an SSH proxy")
+	}
+
+	if sshHostKey == "" {
+		return nil, errors.New("ssh_host_key is required for an SSH proxy")
+	}
+
+	signer, err := ssh.ParsePrivateKey([]byte(sshPrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the SSH private key: %w", err)
+	}
+
+	hostKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(sshHostKey)) //nolint:dogsled
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the SSH host key: %w", err)
+	}
+
+	address := u.Host
+	if u.Port() == "" {
+		address = net.JoinHostPort(u.Hostname(), "22")
+	}
+
+	return &sshDialer{
+		config: &ssh.ClientConfig{
+			User:            u.User.Username(),
+			Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+			HostKeyCallback: ssh.FixedHostKey(hostKey),
+		},
+		address: address,
+	}, nil
+}
+
+func (d *sshDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	client, err := d.sshClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := client.DialContext(ctx, network, address)
+	if err != nil {
+		// unless the bastion failed to connect to the address, the bastion connection might be broken, so it is established again on the next dial
+		var openChannelErr *ssh.OpenChannelError
+		if !errors.As(err, &openChannelErr) {
+			d.reset(client)
+		}
+
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+func (d *sshDialer) sshClient(ctx context.Context) (*ssh.Client, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.client != nil {
+		return d.client, nil
+	}
+
+	var netDialer net.Dialer
+
+	conn, err := netDialer.DialContext(ctx, "tcp", d.address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the SSH bastion: %w", err)
+	}
+
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, d.address, d.config)
+	if err != nil {
+		conn.Close() //nolint:errcheck
+
+		return nil, fmt.Errorf("failed to connect to the SSH bastion: %w", err)
+	}
+
+	d.client = ssh.NewClient(sshConn, chans, reqs)
+
+	return d.client, nil
+}
+
+func (d *sshDialer) reset(client *ssh.Client) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.client == client {
+		d.client.Close() //nolint:errcheck
+
+		d.client = nil
+	}
+}
@@ -0,0 +1,191 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultRetryInitialBackoff = 500 * time.Millisecond
+	defaultRetryMaxBackoff     = 10 * time.Second
+)
+
+type retryPolicyModel struct {
+	MaxRetries     types.Int64    `tfsdk:"max_retries"`
+	InitialBackoff types.String   `tfsdk:"initial_backoff"`
+	MaxBackoff     types.String   `tfsdk:"max_backoff"`
+	RetryableCodes []types.String `tfsdk:"retryable_codes"`
+}
+
+// retryPolicy tunes the retries of the Talos API operations, the operations are retried until their timeout if no policy is set.
+type retryPolicy struct {
+	maxRetries     int64
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	retryableCodes []codes.Code
+}
+
+type retryPolicyContextKey struct{}
+
+// defaultRetryPolicy retries the operations until their timeout.
+var defaultRetryPolicy = &retryPolicy{
+	initialBackoff: defaultRetryInitialBackoff,
+	maxBackoff:     defaultRetryMaxBackoff,
+}
+
+// fatalCodes are the gRPC codes of the requests which fail the same way however many times they are retried.
+var fatalCodes = []codes.Code{codes.InvalidArgument, codes.PermissionDenied, codes.Unauthenticated}
+
+// fatalCertificateErrors are the messages of the TLS handshake failures caused by a CA mismatch,
+// they reach the client as the message of an Unavailable status.
+var fatalCertificateErrors = []string{
+	"certificate signed by unknown authority",
+	"tls: unknown certificate authority",
+	"tls: bad certificate",
+}
+
+func newRetryPolicy(model *retryPolicyModel) (*retryPolicy, error) {
+	policy := &retryPolicy{
+		maxRetries:     model.MaxRetries.ValueInt64(),
+		initialBackoff: defaultRetryInitialBackoff,
+		maxBackoff:     defaultRetryMaxBackoff,
+	}
+
+	var err error
+
+	if model.InitialBackoff.ValueString() != "" {
+		if policy.initialBackoff, err = time.ParseDuration(model.InitialBackoff.ValueString()); err != nil {
+			return nil, fmt.Errorf("failed to parse initial_backoff: %w", err)
+		}
+	}
+
+	if model.MaxBackoff.ValueString() != "" {
+		if policy.maxBackoff, err = time.ParseDuration(model.MaxBackoff.ValueString()); err != nil {
+			return nil, fmt.Errorf("failed to parse max_backoff: %w", err)
+		}
+	}
+
+	if policy.initialBackoff <= 0 || policy.maxBackoff < policy.initialBackoff {
+		return nil, fmt.Errorf("expected 0 < initial_backoff <= max_backoff, got %s and %s", policy.initialBackoff, policy.maxBackoff)
+	}
+
+	for _, name := range model.RetryableCodes {
+		code, err := parseGRPCCode(name.ValueString())
+		if err != nil {
+			return nil, err
+		}
+
+		policy.retryableCodes = append(policy.retryableCodes, code)
+	}
+
+	return policy, nil
+}
+
+// parseGRPCCode parses a gRPC code name, either as `Unavailable` or `UNAVAILABLE`.
+func parseGRPCCode(name string) (codes.Code, error) {
+	normalize := func(s string) string { return strings.ToLower(strings.ReplaceAll(s, "_", "")) }
+
+	for code := codes.OK; code <= codes.Unauthenticated; code++ {
+		if normalize(code.String()) == normalize(name) {
+			return code, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown gRPC code %q", name)
+}
+
+// retryable returns true if the policy allows to retry the error, errors without a gRPC status are always retryable.
+func (p *retryPolicy) retryable(err error) bool {
+	if len(p.retryableCodes) == 0 {
+		return true
+	}
+
+	s, ok := status.FromError(err)
+	if !ok {
+		return true
+	}
+
+	return slices.Contains(p.retryableCodes, s.Code())
+}
+
+// withRetryPolicy returns a context carrying the retry policy, retryContext applies it if it is present in the context.
+func withRetryPolicy(ctx context.Context, policy *retryPolicy) context.Context {
+	if policy == nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, retryPolicyContextKey{}, policy)
+}
+
+// fatalError returns true if retrying the error is pointless: the request is invalid, the client credentials are rejected
+// or the certificates are not signed by the expected CA, so that a misconfiguration fails right away instead of at the timeout.
+// The other certificate errors (e.g. a certificate not valid yet for the node address) are transient while the node regenerates its certificate.
+func fatalError(err error) bool {
+	if slices.Contains(fatalCodes, status.Code(err)) {
+		return true
+	}
+
+	var unknownAuthorityErr x509.UnknownAuthorityError
+	if errors.As(err, &unknownAuthorityErr) {
+		return true
+	}
+
+	return slices.ContainsFunc(fatalCertificateErrors, func(msg string) bool { return strings.Contains(err.Error(), msg) })
+}
+
+// withJitter returns a random delay between half the backoff and the backoff, so that the concurrent operations don't retry in lockstep.
+func withJitter(backoff time.Duration) time.Duration {
+	return backoff/2 + rand.N(backoff/2+1) //nolint:gosec
+}
+
+// retryContext calls f until it succeeds, returns a non retryable or fatal error or the timeout is reached, following the retry policy in the context if any.
+// The delay between the calls doubles after each call, with jitter.
+func retryContext(ctx context.Context, timeout time.Duration, f retry.RetryFunc) error {
+	policy, ok := ctx.Value(retryPolicyContextKey{}).(*retryPolicy)
+	if !ok {
+		policy = defaultRetryPolicy
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	backoff := policy.initialBackoff
+
+	for retries := int64(0); ; retries++ {
+		retryErr := f()
+		if retryErr == nil {
+			return nil
+		}
+
+		if !retryErr.Retryable || fatalError(retryErr.Err) || !policy.retryable(retryErr.Err) {
+			return retryErr.Err
+		}
+
+		if policy.maxRetries > 0 && retries >= policy.maxRetries {
+			return fmt.Errorf("giving up after %d retries: %w", retries, retryErr.Err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timeout while waiting for the operation to succeed: %w", retryErr.Err)
+		case <-time.After(withJitter(backoff)):
+		}
+
+		backoff = min(backoff*2, policy.maxBackoff)
+	}
+}
@@ -0,0 +1,174 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRetryContext(t *testing.T) {
+	t.Parallel()
+
+	policy, err := newRetryPolicy(&retryPolicyModel{
+		MaxRetries:     types.Int64Value(2),
+		InitialBackoff: types.StringValue("1ms"),
+		MaxBackoff:     types.StringValue("2ms"),
+		RetryableCodes: []types.String{types.StringValue("UNAVAILABLE")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name          string
+		err           error
+		expectedCalls int
+	}{
+		{
+			name:          "retryable code",
+			err:           status.Error(codes.Unavailable, "connection refused"),
+			expectedCalls: 3,
+		},
+		{
+			name:          "non retryable code",
+			err:           status.Error(codes.PermissionDenied, "not authorized"),
+			expectedCalls: 1,
+		},
+		{
+			name:          "no code",
+			err:           errors.New("post apply checks are not satisfied"),
+			expectedCalls: 3,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var calls int
+
+			err := retryContext(withRetryPolicy(context.Background(), policy), time.Minute, func() *retry.RetryError {
+				calls++
+
+				return retry.RetryableError(tt.err)
+			})
+			if !errors.Is(err, tt.err) {
+				t.Errorf("expected error %v, got %v", tt.err, err)
+			}
+
+			if calls != tt.expectedCalls {
+				t.Errorf("expected %d calls, got %d", tt.expectedCalls, calls)
+			}
+		})
+	}
+
+	if _, err = newRetryPolicy(&retryPolicyModel{RetryableCodes: []types.String{types.StringValue("Unreachable")}}); err == nil || !strings.Contains(err.Error(), `unknown gRPC code "Unreachable"`) {
+		t.Errorf("expected unknown gRPC code error, got %v", err)
+	}
+
+	if _, err = newRetryPolicy(&retryPolicyModel{InitialBackoff: types.StringValue("1m")}); err == nil {
+		t.Error("expected an error for initial_backoff greater than max_backoff")
+	}
+}
+
+func TestFatalError(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name  string
+		err   error
+		fatal bool
+	}{
+		{
+			name:  "invalid argument",
+			err:   status.Error(codes.InvalidArgument, "failed to validate configuration"),
+			fatal: true,
+		},
+		{
+			name:  "permission denied",
+			err:   fmt.Errorf("failed to apply: %w", status.Error(codes.PermissionDenied, "not authorized")),
+			fatal: true,
+		},
+		{
+			name:  "unauthenticated",
+			err:   status.Error(codes.Unauthenticated, "missing client certificate"),
+			fatal: true,
+		},
+		{
+			name:  "unknown authority",
+			err:   status.Error(codes.Unavailable, "connection error: desc = \"transport: authentication handshake failed: tls: failed to verify certificate: x509: certificate signed by unknown authority\""),
+			fatal: true,
+		},
+		{
+			name:  "client certificate rejected",
+			err:   status.Error(codes.Unavailable, "connection error: desc = \"error reading server preface: remote error: tls: bad certificate\""),
+			fatal: true,
+		},
+		{
+			name: "certificate not valid for the address yet",
+			err:  status.Error(codes.Unavailable, "connection error: desc = \"transport: authentication handshake failed: tls: failed to verify certificate: x509: certificate is valid for 10.5.0.2, not 10.5.0.3\""),
+		},
+		{
+			name: "unavailable",
+			err:  status.Error(codes.Unavailable, "connection refused"),
+		},
+		{
+			name: "no code",
+			err:  errors.New("post apply checks are not satisfied"),
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if fatal := fatalError(tt.err); fatal != tt.fatal {
+				t.Errorf("expected fatal %t, got %t", tt.fatal, fatal)
+			}
+		})
+	}
+}
+
+func TestRetryContextDefaultPolicy(t *testing.T) {
+	t.Parallel()
+
+	var calls int
+
+	fatalErr := status.Error(codes.Unauthenticated, "missing client certificate")
+
+	if err := retryContext(context.Background(), time.Minute, func() *retry.RetryError {
+		calls++
+
+		return retry.RetryableError(fatalErr)
+	}); !errors.Is(err, fatalErr) || calls != 1 {
+		t.Errorf("expected the fatal error to fail right away, got %v after %d calls", err, calls)
+	}
+
+	calls = 0
+
+	if err := retryContext(context.Background(), time.Minute, func() *retry.RetryError {
+		calls++
+
+		if calls < 3 {
+			return retry.RetryableError(status.Error(codes.Unavailable, "connection refused"))
+		}
+
+		return nil
+	}); err != nil || calls != 3 {
+		t.Errorf("expected the operation to succeed after 3 calls, got %v after %d calls", err, calls)
+	}
+
+	for range 100 {
+		if delay := withJitter(time.Second); delay < 500*time.Millisecond || delay > time.Second {
+			t.Fatalf("expected the delay to be between half the backoff and the backoff, got %s", delay)
+		}
+	}
+}
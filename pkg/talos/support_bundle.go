@@ -0,0 +1,222 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/cosi-project/runtime/pkg/resource"
+	"github.com/cosi-project/runtime/pkg/resource/meta"
+	"github.com/cosi-project/runtime/pkg/safe"
+	"github.com/cosi-project/runtime/pkg/state"
+	"github.com/siderolabs/talos/pkg/machinery/api/common"
+	"github.com/siderolabs/talos/pkg/machinery/client"
+	clientconfig "github.com/siderolabs/talos/pkg/machinery/client/config"
+	"github.com/siderolabs/talos/pkg/machinery/constants"
+	"github.com/siderolabs/talos/pkg/machinery/resources/cluster"
+	"github.com/siderolabs/talos/pkg/machinery/resources/k8s"
+	"github.com/siderolabs/talos/pkg/machinery/resources/network"
+	"github.com/siderolabs/talos/pkg/machinery/resources/runtime"
+	"github.com/siderolabs/talos/pkg/machinery/resources/v1alpha1"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// supportBundleTimeout bounds the collection of a support bundle, the operation it documents already failed.
+	supportBundleTimeout = 2 * time.Minute
+
+	// supportBundleLogLines is the number of log lines collected for each service.
+	supportBundleLogLines = 1000
+)
+
+var supportBundleUnsafeChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// withSupportBundle collects a support bundle of the node if the operation failed and the support bundle directory is configured,
+// the error is returned annotated with the path of the bundle or with the reason the bundle couldn't be collected.
+func (d *talosProviderData) withSupportBundle(ctx context.Context, endpoints []string, node string, tc *clientconfig.Config, err error) error {
+	if err == nil || d == nil || d.supportBundleDirectory == "" {
+		return err
+	}
+
+	// the operation context might have expired, the bundle gets its own deadline
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), supportBundleTimeout)
+	defer cancel()
+
+	bundlePath, bundleErr := writeSupportBundle(d.withClientOptions(ctx), d.supportBundleDirectory, endpoints, node, tc)
+	if bundleErr != nil {
+		return fmt.Errorf("%w\n\nfailed to collect the support bundle of node %s: %w", err, node, bundleErr)
+	}
+
+	return fmt.Errorf("%w\n\nthe support bundle of node %s was written to %s", err, node, bundlePath)
+}
+
+// writeSupportBundle writes a zip archive with the service logs, the kernel logs and the main resources of the node to the directory.
+// The errors collecting the parts of the bundle are recorded in the archive, so that a partial bundle is still written.
+func writeSupportBundle(ctx context.Context, dir string, endpoints []string, node string, tc *clientconfig.Config) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+
+	bundlePath := filepath.Join(dir, fmt.Sprintf("support-%s-%s.zip", supportBundleUnsafeChars.ReplaceAllString(node, "_"), time.Now().UTC().Format("20060102T150405Z")))
+
+	f, err := os.Create(bundlePath)
+	if err != nil {
+		return "", err
+	}
+
+	defer f.Close() //nolint:errcheck
+
+	archive := zip.NewWriter(f)
+
+	var collectErrs []error
+
+	if err = talosClientOpEndpoints(ctx, endpoints, node, tc, func(nodeCtx context.Context, c *client.Client) error {
+		collectErrs = collectSupportBundle(nodeCtx, c, archive)
+
+		return nil
+	}); err != nil {
+		collectErrs = append(collectErrs, err)
+	}
+
+	if len(collectErrs) > 0 {
+		w, err := archive.Create("errors.txt")
+		if err != nil {
+			return "", err
+		}
+
+		if _, err = io.WriteString(w, errors.Join(collectErrs...).Error()+"\n"); err != nil {
+			return "", err
+		}
+	}
+
+	if err = archive.Close(); err != nil {
+		return "", err
+	}
+
+	return bundlePath, f.Close()
+}
+
+// collectSupportBundle adds the parts of the support bundle to the archive, returning the errors of the parts which couldn't be collected.
+func collectSupportBundle(ctx context.Context, c *client.Client, archive *zip.Writer) []error {
+	var errs []error
+
+	services, err := c.ServiceList(ctx)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("failed to list services: %w", err))
+	}
+
+	for _, msg := range services.GetMessages() {
+		for _, svc := range msg.GetServices() {
+			if err = addSupportBundleStream(archive, "logs/"+svc.GetId()+".log", func() (client.MachineStream, error) {
+				return c.Logs(ctx, constants.SystemContainerdNamespace, common.ContainerDriver_CONTAINERD, svc.GetId(), false, supportBundleLogLines)
+			}); err != nil {
+				errs = append(errs, fmt.Errorf("failed to get the logs of service %s: %w", svc.GetId(), err))
+			}
+		}
+	}
+
+	if err = addSupportBundleStream(archive, "dmesg.log", func() (client.MachineStream, error) {
+		return c.Dmesg(ctx, false, false)
+	}); err != nil {
+		errs = append(errs, fmt.Errorf("failed to get the kernel logs: %w", err))
+	}
+
+	for name, dump := range map[string]func() ([]resource.Resource, error){
+		"machinestatuses":  listSupportBundleResources[*runtime.MachineStatus](ctx, c.COSI),
+		"services":         listSupportBundleResources[*v1alpha1.Service](ctx, c.COSI),
+		"extensions":       listSupportBundleResources[*runtime.ExtensionStatus](ctx, c.COSI),
+		"members":          listSupportBundleResources[*cluster.Member](ctx, c.COSI),
+		"addresses":        listSupportBundleResources[*network.AddressStatus](ctx, c.COSI),
+		"links":            listSupportBundleResources[*network.LinkStatus](ctx, c.COSI),
+		"routes":           listSupportBundleResources[*network.RouteStatus](ctx, c.COSI),
+		"staticpodstatus":  listSupportBundleResources[*k8s.StaticPodStatus](ctx, c.COSI),
+		"kubeletspecs":     listSupportBundleResources[*k8s.KubeletSpec](ctx, c.COSI),
+		"hostnamestatuses": listSupportBundleResources[*network.HostnameStatus](ctx, c.COSI),
+	} {
+		if err = addSupportBundleResources(archive, "resources/"+name+".yaml", dump); err != nil {
+			errs = append(errs, fmt.Errorf("failed to get the %s resources: %w", name, err))
+		}
+	}
+
+	return errs
+}
+
+// listSupportBundleResources returns a function listing the resources of the type.
+func listSupportBundleResources[T meta.ResourceWithRD](ctx context.Context, st state.State) func() ([]resource.Resource, error) {
+	return func() ([]resource.Resource, error) {
+		list, err := safe.StateListAll[T](ctx, st)
+		if err != nil {
+			return nil, err
+		}
+
+		resources := make([]resource.Resource, 0, list.Len())
+
+		for it := list.Iterator(); it.Next(); {
+			resources = append(resources, it.Value())
+		}
+
+		return resources, nil
+	}
+}
+
+// addSupportBundleStream adds the content of the stream to the archive.
+func addSupportBundleStream(archive *zip.Writer, name string, open func() (client.MachineStream, error)) error {
+	stream, err := open()
+	if err != nil {
+		return err
+	}
+
+	r, err := client.ReadStream(stream)
+	if err != nil {
+		return err
+	}
+
+	defer r.Close() //nolint:errcheck
+
+	w, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(w, r)
+
+	return err
+}
+
+// addSupportBundleResources adds the resources to the archive as a YAML stream.
+func addSupportBundleResources(archive *zip.Writer, name string, list func() ([]resource.Resource, error)) error {
+	resources, err := list()
+	if err != nil {
+		return err
+	}
+
+	w, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+
+	encoder := yaml.NewEncoder(w)
+
+	for _, r := range resources {
+		out, err := resource.MarshalYAML(r)
+		if err != nil {
+			return err
+		}
+
+		if err = encoder.Encode(out); err != nil {
+			return err
+		}
+	}
+
+	return encoder.Close()
+}
@@ -0,0 +1,71 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	clientconfig "github.com/siderolabs/talos/pkg/machinery/client/config"
+	"github.com/siderolabs/talos/pkg/machinery/config/generate/secrets"
+	"github.com/siderolabs/talos/pkg/machinery/role"
+)
+
+func TestWithSupportBundle(t *testing.T) {
+	t.Parallel()
+
+	secretsBundle, err := secrets.NewBundle(secrets.NewFixedClock(time.Now()), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := secretsBundle.GenerateTalosAPIClientCertificate(role.MakeSet(role.Admin))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tc := clientconfig.NewConfig("dynamic", nil, secretsBundle.Certs.OS.Crt, cert)
+	opErr := errors.New("apply failed")
+
+	if err = (&talosProviderData{}).withSupportBundle(context.Background(), []string{"127.0.0.1:1"}, "127.0.0.1", tc, opErr); err != opErr { //nolint:errorlint
+		t.Errorf("expected the error to be returned as is without a support bundle directory, got %v", err)
+	}
+
+	dir := filepath.Join(t.TempDir(), "bundles")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err = (&talosProviderData{supportBundleDirectory: dir}).withSupportBundle(ctx, []string{"127.0.0.1:1"}, "127.0.0.1", tc, opErr)
+	if !errors.Is(err, opErr) || !strings.Contains(err.Error(), "the support bundle of node 127.0.0.1 was written to "+dir) {
+		t.Fatalf("expected the error to reference the support bundle, got %v", err)
+	}
+
+	bundles, err := filepath.Glob(filepath.Join(dir, "support-127.0.0.1-*.zip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(bundles) != 1 {
+		t.Fatalf("expected a support bundle, got %v", bundles)
+	}
+
+	archive, err := zip.OpenReader(bundles[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer archive.Close() //nolint:errcheck
+
+	// the node is unreachable, the bundle records the errors
+	if _, err = archive.Open("errors.txt"); err != nil {
+		t.Errorf("expected the bundle to record the collection errors: %v", err)
+	}
+}
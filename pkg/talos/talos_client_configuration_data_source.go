@@ -6,27 +6,44 @@ package talos
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	clientconfig "github.com/siderolabs/talos/pkg/machinery/client/config"
 )
 
-type talosClientConfigurationDataSource struct{}
+type talosClientConfigurationDataSource struct {
+	providerData *talosProviderData
+}
 
 type talosClientConfigurationDataSourceModelV0 struct {
-	ID                  types.String        `tfsdk:"id"`
-	ClusterName         types.String        `tfsdk:"cluster_name"`
-	ClientConfiguration clientConfiguration `tfsdk:"client_configuration"`
-	Endpoints           types.List          `tfsdk:"endpoints"`
-	Nodes               types.List          `tfsdk:"nodes"`
-	TalosConfig         types.String        `tfsdk:"talos_config"`
+	ID                  types.String         `tfsdk:"id"`
+	ClusterName         types.String         `tfsdk:"cluster_name"`
+	ClientConfiguration clientConfiguration  `tfsdk:"client_configuration"`
+	Endpoints           types.List           `tfsdk:"endpoints"`
+	Nodes               types.List           `tfsdk:"nodes"`
+	Contexts            []talosConfigContext `tfsdk:"contexts"`
+	MergeTalosConfig    types.String         `tfsdk:"merge_talos_config"`
+	TalosConfig         types.String         `tfsdk:"talos_config"`
 }
 
-var _ datasource.DataSource = &talosClientConfigurationDataSource{}
+type talosConfigContext struct {
+	Name                types.String         `tfsdk:"name"`
+	ClientConfiguration *clientConfiguration `tfsdk:"client_configuration"`
+	Endpoints           []types.String       `tfsdk:"endpoints"`
+	Nodes               []types.String       `tfsdk:"nodes"`
+}
+
+var (
+	_ datasource.DataSource              = &talosClientConfigurationDataSource{}
+	_ datasource.DataSourceWithConfigure = &talosClientConfigurationDataSource{}
+)
 
 // NewTalosClientConfigurationDataSource implements the datasource.DataSource interface.
 func NewTalosClientConfigurationDataSource() datasource.DataSource {
@@ -81,6 +98,56 @@ func (d *talosClientConfigurationDataSource) Schema(_ context.Context, _ datasou
 				Optional:    true,
 				Description: "nodes to set in the generated config",
 			},
+			"contexts": schema.ListNestedAttribute{
+				Optional:    true,
+				Description: "Additional contexts to set in the generated config, e.g. for the other clusters managed by the operator",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							Required:    true,
+							Description: "The name of the context",
+							Validators: []validator.String{
+								stringvalidator.LengthAtLeast(1),
+							},
+						},
+						"client_configuration": schema.SingleNestedAttribute{
+							Attributes: map[string]schema.Attribute{
+								"ca_certificate": schema.StringAttribute{
+									Required:    true,
+									Description: "The client CA certificate",
+								},
+								"client_certificate": schema.StringAttribute{
+									Required:    true,
+									Description: "The client certificate",
+								},
+								"client_key": schema.StringAttribute{
+									Required:    true,
+									Sensitive:   true,
+									Description: "The client key",
+								},
+							},
+							Optional:    true,
+							Description: "The client configuration data of the context, defaults to `client_configuration`",
+						},
+						"endpoints": schema.ListAttribute{
+							ElementType: types.StringType,
+							Optional:    true,
+							Description: "endpoints to set in the context",
+						},
+						"nodes": schema.ListAttribute{
+							ElementType: types.StringType,
+							Optional:    true,
+							Description: "nodes to set in the context",
+						},
+					},
+				},
+			},
+			"merge_talos_config": schema.StringAttribute{
+				Optional:  true,
+				Sensitive: true,
+				Description: "An existing client configuration to merge the generated contexts into, as `talosctl config merge` does. " +
+					"The generated contexts are renamed with a numeric suffix if their name is already taken, and the current context is set to the `cluster_name` context",
+			},
 			"talos_config": schema.StringAttribute{
 				Computed:    true,
 				Description: "The generated client configuration",
@@ -90,6 +157,24 @@ func (d *talosClientConfigurationDataSource) Schema(_ context.Context, _ datasou
 	}
 }
 
+func (d *talosClientConfigurationDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*talosProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"failed to get provider data",
+			fmt.Sprintf("Expected *talosProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.providerData = providerData
+}
+
 func (d *talosClientConfigurationDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
 	var state talosClientConfigurationDataSourceModelV0
 
@@ -121,6 +206,7 @@ func (d *talosClientConfigurationDataSource) Read(ctx context.Context, req datas
 		state.ClientConfiguration.CA.ValueString(),
 		state.ClientConfiguration.Cert.ValueString(),
 		state.ClientConfiguration.Key.ValueString(),
+		d.providerData.additionalCAs()...,
 	)
 	if err != nil {
 		resp.Diagnostics.AddError("failed to generate talos config", err.Error())
@@ -136,6 +222,56 @@ func (d *talosClientConfigurationDataSource) Read(ctx context.Context, req datas
 		talosConfig.Contexts[state.ClusterName.ValueString()].Nodes = nodes
 	}
 
+	for i, talosContext := range state.Contexts {
+		attrPath := path.Root("contexts").AtListIndex(i)
+
+		name := talosContext.Name.ValueString()
+
+		if _, exists := talosConfig.Contexts[name]; exists {
+			resp.Diagnostics.AddAttributeError(attrPath.AtName("name"), "duplicate context name", fmt.Sprintf("context %q is already set", name))
+
+			return
+		}
+
+		clientConfig := talosContext.ClientConfiguration
+		if clientConfig == nil {
+			clientConfig = &state.ClientConfiguration
+		}
+
+		contextConfig, err := talosClientTFConfigToTalosClientConfig(
+			name,
+			clientConfig.CA.ValueString(),
+			clientConfig.Cert.ValueString(),
+			clientConfig.Key.ValueString(),
+			d.providerData.additionalCAs()...,
+		)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(attrPath, "failed to generate talos config", err.Error())
+
+			return
+		}
+
+		contextConfig.Contexts[name].Endpoints = stringValues(talosContext.Endpoints)
+		contextConfig.Contexts[name].Nodes = stringValues(talosContext.Nodes)
+
+		talosConfig.Contexts[name] = contextConfig.Contexts[name]
+	}
+
+	if !state.MergeTalosConfig.IsNull() {
+		mergedConfig, err := clientconfig.FromString(state.MergeTalosConfig.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("merge_talos_config"), "failed to parse talos config", err.Error())
+
+			return
+		}
+
+		for _, rename := range mergedConfig.Merge(talosConfig) {
+			resp.Diagnostics.AddAttributeWarning(path.Root("merge_talos_config"), "context renamed", rename.String())
+		}
+
+		talosConfig = mergedConfig
+	}
+
 	talosConfigStringBytes, err := talosConfig.Bytes()
 	if err != nil {
 		resp.Diagnostics.AddError("failed to generate talos config", err.Error())
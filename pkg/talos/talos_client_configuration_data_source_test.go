@@ -51,10 +51,67 @@ func TestAccTalosClientConfigurationDataSource(t *testing.T) {
 					}),
 				),
 			},
+			// test data source with additional contexts merged into an existing config
+			{
+				Config: testAccTalosClientConfigurationDataSourceContextsConfig(),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.talos_client_configuration.this", "id", "test-cluster"),
+					resource.TestCheckResourceAttrWith("data.talos_client_configuration.this", "talos_config", func(value string) error {
+						var talosConfig config.Config
+
+						if err := yaml.Unmarshal([]byte(value), &talosConfig); err != nil {
+							return err
+						}
+
+						assert.Equal(t, "test-cluster-1", talosConfig.Context)
+						assert.Len(t, talosConfig.Contexts, 4)
+						assert.Equal(t, []string{"10.5.0.2"}, talosConfig.Contexts["test-cluster-1"].Endpoints)
+						assert.Equal(t, []string{"10.6.0.2", "10.6.0.3"}, talosConfig.Contexts["other-cluster"].Endpoints)
+						assert.Equal(t, []string{"10.6.0.4"}, talosConfig.Contexts["other-cluster"].Nodes)
+						assert.Equal(t, []string{"10.7.0.2"}, talosConfig.Contexts["test-cluster"].Endpoints)
+
+						return nil
+					}),
+				),
+			},
 		},
 	})
 }
 
+func testAccTalosClientConfigurationDataSourceContextsConfig() string {
+	return `
+resource "talos_machine_secrets" "this" {}
+
+resource "talos_machine_secrets" "other" {}
+
+data "talos_client_configuration" "existing" {
+  cluster_name         = "test-cluster"
+  client_configuration = talos_machine_secrets.this.client_configuration
+  endpoints            = ["10.7.0.2"]
+  contexts = [
+    {
+      name = "existing-cluster"
+    },
+  ]
+}
+
+data "talos_client_configuration" "this" {
+  cluster_name         = "test-cluster"
+  client_configuration = talos_machine_secrets.this.client_configuration
+  endpoints            = ["10.5.0.2"]
+  contexts = [
+    {
+      name                 = "other-cluster"
+      client_configuration = talos_machine_secrets.other.client_configuration
+      endpoints            = ["10.6.0.2", "10.6.0.3"]
+      nodes                = ["10.6.0.4"]
+    },
+  ]
+  merge_talos_config = data.talos_client_configuration.existing.talos_config
+}
+`
+}
+
 func testAccTalosClientConfigurationDataSourceConfig(clusterName string, endpoints, nodes []string) string {
 	configTemplate := `
 resource "talos_machine_secrets" "this" {}
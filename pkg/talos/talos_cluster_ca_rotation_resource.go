@@ -0,0 +1,450 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/mapplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/objectplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/siderolabs/talos/pkg/machinery/client"
+	clientconfig "github.com/siderolabs/talos/pkg/machinery/client/config"
+	"github.com/siderolabs/talos/pkg/machinery/config"
+	"github.com/siderolabs/talos/pkg/machinery/config/encoder"
+	"github.com/siderolabs/talos/pkg/machinery/config/generate/secrets"
+	"github.com/siderolabs/talos/pkg/rotate/pki/kubernetes"
+	"github.com/siderolabs/talos/pkg/rotate/pki/talos"
+)
+
+type talosClusterCARotationResource struct {
+	providerData *talosProviderData
+}
+
+var (
+	_ resource.Resource                   = &talosClusterCARotationResource{}
+	_ resource.ResourceWithConfigure      = &talosClusterCARotationResource{}
+	_ resource.ResourceWithValidateConfig = &talosClusterCARotationResource{}
+)
+
+type talosClusterCARotationResourceModelV0 struct {
+	ID                         types.String               `tfsdk:"id"`
+	ControlPlaneNodes          []types.String             `tfsdk:"control_plane_nodes"`
+	WorkerNodes                []types.String             `tfsdk:"worker_nodes"`
+	Endpoints                  []types.String             `tfsdk:"endpoints"`
+	ClientConfiguration        *clientConfiguration       `tfsdk:"client_configuration"`
+	RotateKubernetes           types.Bool                 `tfsdk:"rotate_kubernetes"`
+	RotateTalos                types.Bool                 `tfsdk:"rotate_talos"`
+	Triggers                   types.Map                  `tfsdk:"triggers"`
+	KubernetesCA               *machineSecretsCertKeyPair `tfsdk:"kubernetes_ca"`
+	TalosCA                    *machineSecretsCertKeyPair `tfsdk:"talos_ca"`
+	RotatedClientConfiguration *clientConfiguration       `tfsdk:"rotated_client_configuration"`
+	Timeouts                   timeouts.Value             `tfsdk:"timeouts"`
+}
+
+// NewTalosClusterCARotationResource implements the resource.Resource interface.
+func NewTalosClusterCARotationResource() resource.Resource {
+	return &talosClusterCARotationResource{}
+}
+
+func (r *talosClusterCARotationResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_cluster_ca_rotation"
+}
+
+func (r *talosClusterCARotationResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	certKeyPairAttributes := func(name string) map[string]schema.Attribute {
+		return map[string]schema.Attribute{
+			"cert": schema.StringAttribute{
+				Computed:    true,
+				Description: fmt.Sprintf("The base64 encoded certificate of the new %s CA", name),
+			},
+			"key": schema.StringAttribute{
+				Computed:    true,
+				Sensitive:   true,
+				Description: fmt.Sprintf("The base64 encoded key of the new %s CA", name),
+			},
+		}
+	}
+
+	resp.Schema = schema.Schema{
+		Description: "The cluster CA rotation resource rotates the root CA of the Kubernetes API and optionally of the Talos API across the nodes of a cluster, " +
+			"like `talosctl rotate-ca`: the new CA is first accepted by all the nodes, then used to issue the certificates and the old CA is finally dropped. " +
+			"The rotation runs when the resource is created and again when `triggers` changes. " +
+			"The new CAs are exposed so that they can replace the ones of the machine secrets used to generate the machine configuration, " +
+			"otherwise the next machine configuration apply rolls the rotation back.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:    true,
+				Description: "This is a unique identifier for the rotation",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"control_plane_nodes": schema.ListAttribute{
+				ElementType: types.StringType,
+				Required:    true,
+				Description: "The control plane nodes of the cluster",
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+					listvalidator.UniqueValues(),
+				},
+			},
+			"worker_nodes": schema.ListAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Description: "The worker nodes of the cluster",
+				Validators: []validator.List{
+					listvalidator.UniqueValues(),
+				},
+			},
+			"endpoints": schema.ListAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Description: "The endpoints to reach the nodes through, defaults to the control plane nodes",
+			},
+			"client_configuration": schema.SingleNestedAttribute{
+				Attributes: map[string]schema.Attribute{
+					"ca_certificate": schema.StringAttribute{
+						Required:    true,
+						Description: "The client CA certificate",
+					},
+					"client_certificate": schema.StringAttribute{
+						Required:    true,
+						Description: "The client certificate",
+					},
+					"client_key": schema.StringAttribute{
+						Required:    true,
+						Sensitive:   true,
+						Description: "The client key",
+					},
+				},
+				Optional:    true,
+				Description: "The client configuration data, defaults to the credentials of the provider `talosconfig_path`",
+			},
+			"rotate_kubernetes": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(true),
+				Description: "Rotate the Kubernetes API CA",
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.RequiresReplace(),
+				},
+			},
+			"rotate_talos": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+				Description: "Rotate the Talos API CA, the nodes are then only reachable with `rotated_client_configuration`",
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.RequiresReplace(),
+				},
+			},
+			"triggers": schema.MapAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Description: "A map of arbitrary values that, when changed, will rotate the CAs again",
+				PlanModifiers: []planmodifier.Map{
+					mapplanmodifier.RequiresReplace(),
+				},
+			},
+			"kubernetes_ca": schema.SingleNestedAttribute{
+				Attributes:  certKeyPairAttributes("Kubernetes API"),
+				Computed:    true,
+				Description: "The new Kubernetes API CA, null unless `rotate_kubernetes` is set",
+				PlanModifiers: []planmodifier.Object{
+					objectplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"talos_ca": schema.SingleNestedAttribute{
+				Attributes:  certKeyPairAttributes("Talos API"),
+				Computed:    true,
+				Description: "The new Talos API CA, null unless `rotate_talos` is set. It is saved as soon as the nodes accept it, even if the Kubernetes API CA rotation fails afterwards",
+				PlanModifiers: []planmodifier.Object{
+					objectplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"rotated_client_configuration": schema.SingleNestedAttribute{
+				Attributes: map[string]schema.Attribute{
+					"ca_certificate": schema.StringAttribute{
+						Computed:    true,
+						Description: "The client CA certificate",
+					},
+					"client_certificate": schema.StringAttribute{
+						Computed:    true,
+						Description: "The client certificate",
+					},
+					"client_key": schema.StringAttribute{
+						Computed:    true,
+						Sensitive:   true,
+						Description: "The client key",
+					},
+				},
+				Computed:    true,
+				Description: "The client configuration issued by the new Talos API CA, null unless `rotate_talos` is set",
+				PlanModifiers: []planmodifier.Object{
+					objectplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"timeouts": timeouts.Attributes(ctx, timeouts.Opts{
+				Create: true,
+			}),
+		},
+	}
+}
+
+func (r *talosClusterCARotationResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*talosProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"failed to get provider data",
+			fmt.Sprintf("Expected *talosProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.providerData = providerData
+}
+
+func (r *talosClusterCARotationResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var rotateKubernetes, rotateTalos types.Bool
+
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("rotate_kubernetes"), &rotateKubernetes)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("rotate_talos"), &rotateTalos)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if rotateKubernetes.IsUnknown() || rotateTalos.IsUnknown() {
+		return
+	}
+
+	// rotate_kubernetes defaults to true
+	if (!rotateKubernetes.IsNull() && !rotateKubernetes.ValueBool()) && !rotateTalos.ValueBool() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("rotate_kubernetes"),
+			"nothing to rotate",
+			"at least one of rotate_kubernetes and rotate_talos must be set",
+		)
+	}
+}
+
+func (r *talosClusterCARotationResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var state talosClusterCARotationResourceModelV0
+
+	diags := req.Plan.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+
+	if diags.HasError() {
+		return
+	}
+
+	createTimeout, diags := state.Timeouts.Create(ctx, 30*time.Minute)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctxDeadline, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
+	if err := r.rotate(ctxDeadline, &state); err != nil {
+		resp.Diagnostics.AddError("Error rotating the cluster CAs", err.Error())
+
+		// the nodes only accept the new Talos API CA once it is rotated, it is saved in the (tainted) state so that the cluster stays reachable
+		if state.TalosCA == nil {
+			return
+		}
+
+		resp.Diagnostics.AddWarning(
+			"the Talos API CA was rotated",
+			"The nodes only accept the new Talos API CA, use `rotated_client_configuration` and `talos_ca` to reach them.",
+		)
+	}
+
+	state.ID = basetypes.NewStringValue("cluster_ca_rotation")
+
+	// Set state to fully populated data
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r *talosClusterCARotationResource) Read(_ context.Context, _ resource.ReadRequest, _ *resource.ReadResponse) {
+}
+
+func (r *talosClusterCARotationResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var state talosClusterCARotationResourceModelV0
+
+	diags := req.Plan.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+
+	if diags.HasError() {
+		return
+	}
+
+	// Set state to fully populated data
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r *talosClusterCARotationResource) Delete(_ context.Context, _ resource.DeleteRequest, _ *resource.DeleteResponse) {
+}
+
+// rotate rotates the Talos API CA first, so that the Kubernetes API CA is rotated with a client of the new Talos API PKI.
+// The new Talos API CA and client configuration are set in the state as soon as they are accepted by the nodes, even if the Kubernetes API CA rotation fails.
+func (r *talosClusterCARotationResource) rotate(ctx context.Context, state *talosClusterCARotationResourceModelV0) error {
+	ctx, release, err := acquireOperation(r.providerData.withClientOptions(ctx))
+	if err != nil {
+		return err
+	}
+
+	defer release()
+
+	endpoints := stringValues(state.Endpoints)
+	if len(endpoints) == 0 {
+		endpoints = stringValues(state.ControlPlaneNodes)
+	}
+
+	clusterInfo, err := newClusterNodes(stringValues(state.ControlPlaneNodes), stringValues(state.WorkerNodes))
+	if err != nil {
+		return err
+	}
+
+	talosClientConfig, err := r.providerData.talosClientConfig("dynamic", state.ClientConfiguration)
+	if err != nil {
+		return err
+	}
+
+	newBundle, err := secrets.NewBundle(secrets.NewFixedClock(time.Now()), config.TalosVersionCurrent)
+	if err != nil {
+		return fmt.Errorf("failed to generate the new CAs: %w", err)
+	}
+
+	encoderOption := encoder.WithComments(encoder.CommentsDisabled)
+	printf := func(format string, args ...any) {
+		tflog.Info(ctx, strings.TrimSpace(fmt.Sprintf(format, args...)))
+	}
+
+	c, err := caRotationClient(ctx, talosClientConfig, endpoints)
+	if err != nil {
+		return err
+	}
+
+	// the client is replaced once the Talos API CA is rotated
+	defer func() { c.Close() }() //nolint:errcheck
+
+	state.TalosCA = nil
+	state.KubernetesCA = nil
+	state.RotatedClientConfiguration = nil
+
+	if state.RotateTalos.ValueBool() {
+		newTalosClientConfig, err := talos.Rotate(ctx, talos.Options{
+			CurrentClient: c,
+			ClusterInfo:   clusterInfo,
+			ContextName:   "dynamic",
+			Endpoints:     endpoints,
+			NewTalosCA:    newBundle.Certs.OS,
+			EncoderOption: encoderOption,
+			Printf:        printf,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to rotate the Talos API CA: %w", err)
+		}
+
+		state.TalosCA = &machineSecretsCertKeyPair{
+			Cert: basetypes.NewStringValue(bytesToBase64(newBundle.Certs.OS.Crt)),
+			Key:  basetypes.NewStringValue(bytesToBase64(newBundle.Certs.OS.Key)),
+		}
+
+		if state.RotatedClientConfiguration, err = rotatedClientConfiguration(newTalosClientConfig); err != nil {
+			return err
+		}
+
+		c.Close() //nolint:errcheck
+
+		if c, err = caRotationClient(ctx, newTalosClientConfig, endpoints); err != nil {
+			return fmt.Errorf("failed to create a client with the rotated Talos API CA: %w", err)
+		}
+	}
+
+	if state.RotateKubernetes.ValueBool() {
+		if err = kubernetes.Rotate(ctx, kubernetes.Options{
+			TalosClient:     c,
+			ClusterInfo:     clusterInfo,
+			NewKubernetesCA: newBundle.Certs.K8s,
+			EncoderOption:   encoderOption,
+			Printf:          printf,
+		}); err != nil {
+			return fmt.Errorf("failed to rotate the Kubernetes API CA: %w", err)
+		}
+
+		state.KubernetesCA = &machineSecretsCertKeyPair{
+			Cert: basetypes.NewStringValue(bytesToBase64(newBundle.Certs.K8s.Crt)),
+			Key:  basetypes.NewStringValue(bytesToBase64(newBundle.Certs.K8s.Key)),
+		}
+	}
+
+	return nil
+}
+
+// caRotationClient returns a client load balancing over the endpoints, the rotation addresses each node through it.
+func caRotationClient(ctx context.Context, tc *clientconfig.Config, endpoints []string) (*client.Client, error) {
+	clientOpts, err := configClientOptions(ctx, tc)
+	if err != nil {
+		return nil, err
+	}
+
+	clientOpts = append(clientOpts, client.WithEndpoints(normalizeEndpoints(endpoints)...))
+
+	if omniOpts, ok := omniClientOptionsFromContext(ctx); ok {
+		clientOpts = omniOpts
+	}
+
+	return client.New(ctx, append(clientOpts, client.WithGRPCDialOptions(proxyDialOptionsFromContext(ctx)...), client.WithGRPCDialOptions(traceDialOptions()...))...)
+}
+
+// rotatedClientConfiguration returns the credentials of the current context of the client configuration.
+func rotatedClientConfiguration(tc *clientconfig.Config) (*clientConfiguration, error) {
+	configContext := tc.Contexts[tc.Context]
+	if configContext == nil {
+		return nil, errors.New("the rotated client configuration has no current context")
+	}
+
+	return &clientConfiguration{
+		CA:   basetypes.NewStringValue(configContext.CA),
+		Cert: basetypes.NewStringValue(configContext.Crt),
+		Key:  basetypes.NewStringValue(configContext.Key),
+	}, nil
+}
@@ -0,0 +1,144 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos_test
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	clientconfig "github.com/siderolabs/talos/pkg/machinery/client/config"
+	"github.com/siderolabs/talos/pkg/machinery/config/generate/secrets"
+	"github.com/siderolabs/talos/pkg/machinery/role"
+
+	"github.com/siderolabs/terraform-provider-talos/pkg/talos"
+)
+
+func TestAccTalosClusterCARotationResource(t *testing.T) {
+	rName := acctest.RandStringFromCharSet(10, acctest.CharSetAlpha)
+
+	resource.ParallelTest(t, resource.TestCase{
+		ExternalProviders: map[string]resource.ExternalProvider{
+			"libvirt": {
+				Source: "dmacvicar/libvirt",
+			},
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccTalosClusterCARotationResourceConfig("talos", rName, "1"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("talos_cluster_ca_rotation.this", "id", "cluster_ca_rotation"),
+					resource.TestCheckResourceAttr("talos_cluster_ca_rotation.this", "rotate_kubernetes", "true"),
+					resource.TestCheckResourceAttr("talos_cluster_ca_rotation.this", "rotate_talos", "false"),
+					resource.TestCheckResourceAttrSet("talos_cluster_ca_rotation.this", "kubernetes_ca.cert"),
+					resource.TestCheckResourceAttrSet("talos_cluster_ca_rotation.this", "kubernetes_ca.key"),
+					resource.TestCheckNoResourceAttr("talos_cluster_ca_rotation.this", "talos_ca"),
+					resource.TestCheckNoResourceAttr("talos_cluster_ca_rotation.this", "rotated_client_configuration"),
+				),
+			},
+			// make sure there are no changes
+			{
+				Config:   testAccTalosClusterCARotationResourceConfig("talos", rName, "1"),
+				PlanOnly: true,
+			},
+			// changing the triggers rotates the CAs again
+			{
+				Config: testAccTalosClusterCARotationResourceConfig("talos", rName, "2"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("talos_cluster_ca_rotation.this", plancheck.ResourceActionReplace),
+					},
+				},
+			},
+		},
+	})
+}
+
+func TestAccTalosClusterCARotationResourceNothingToRotate(t *testing.T) {
+	resource.ParallelTest(t, resource.TestCase{
+		IsUnitTest:               true, // the configuration is rejected before any node is reached
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "talos_machine_secrets" "this" {}
+
+resource "talos_cluster_ca_rotation" "this" {
+  client_configuration = talos_machine_secrets.this.client_configuration
+  control_plane_nodes  = ["10.5.0.2"]
+  rotate_kubernetes    = false
+}
+`,
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("nothing to rotate"),
+			},
+		},
+	})
+}
+
+func testAccTalosClusterCARotationResourceConfig(providerName, rName, trigger string) string {
+	config := dynamicConfig{
+		Provider:               providerName,
+		ResourceName:           rName,
+		WithApplyConfig:        true,
+		WithBootstrap:          true,
+		WithRetrieveKubeConfig: true,
+		WithClusterHealth:      true,
+	}
+
+	return config.render() + fmt.Sprintf(`
+resource "talos_cluster_ca_rotation" "this" {
+  depends_on = [
+    data.talos_cluster_health.this
+  ]
+  client_configuration = talos_machine_secrets.this.client_configuration
+  control_plane_nodes  = libvirt_domain.cp.network_interface[0].addresses
+  triggers = {
+    rotation = "%s"
+  }
+}
+`, trigger)
+}
+
+func TestRotatedClientConfiguration(t *testing.T) {
+	t.Parallel()
+
+	secretsBundle, err := secrets.NewBundle(secrets.NewFixedClock(time.Now()), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := secretsBundle.GenerateTalosAPIClientCertificate(role.MakeSet(role.Admin))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tc := clientconfig.NewConfig("dynamic", []string{"10.5.0.2"}, secretsBundle.Certs.OS.Crt, cert)
+
+	cc, err := talos.RotatedClientConfiguration(tc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cc.CA.ValueString() != talos.BytesToBase64(secretsBundle.Certs.OS.Crt) {
+		t.Errorf("expected the CA of the client configuration to be the Talos API CA")
+	}
+
+	if cc.Cert.ValueString() != talos.BytesToBase64(cert.Crt) || cc.Key.ValueString() != talos.BytesToBase64(cert.Key) {
+		t.Errorf("expected the credentials of the client configuration to be the client certificate")
+	}
+
+	tc.Context = "missing"
+
+	if _, err = talos.RotatedClientConfiguration(tc); err == nil || !strings.Contains(err.Error(), "no current context") {
+		t.Errorf("expected a missing context error, got %v", err)
+	}
+}
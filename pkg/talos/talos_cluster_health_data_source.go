@@ -9,11 +9,16 @@ import (
 	"fmt"
 	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
 	"github.com/siderolabs/talos/pkg/cluster"
@@ -23,18 +28,30 @@ import (
 	"github.com/siderolabs/talos/pkg/machinery/config/machine"
 )
 
-type talosClusterHealthDataSource struct{}
+type talosClusterHealthDataSource struct {
+	providerData *talosProviderData
+}
 
-var _ datasource.DataSource = &talosClusterHealthDataSource{}
+var (
+	_ datasource.DataSource              = &talosClusterHealthDataSource{}
+	_ datasource.DataSourceWithConfigure = &talosClusterHealthDataSource{}
+)
 
 type talosClusterHealthDataSourceModelV0 struct {
-	ID                   types.String        `tfsdk:"id"`
-	Endpoints            types.List          `tfsdk:"endpoints"`
-	ControlPlaneNodes    types.List          `tfsdk:"control_plane_nodes"`
-	WorkerNodes          types.List          `tfsdk:"worker_nodes"`
-	ClientConfiguration  clientConfiguration `tfsdk:"client_configuration"`
-	Timeouts             timeouts.Value      `tfsdk:"timeouts"`
-	SkipKubernetesChecks types.Bool          `tfsdk:"skip_kubernetes_checks"`
+	ID                   types.String                    `tfsdk:"id"`
+	Endpoints            types.List                      `tfsdk:"endpoints"`
+	Port                 types.Int64                     `tfsdk:"port"`
+	ControlPlaneNodes    types.List                      `tfsdk:"control_plane_nodes"`
+	WorkerNodes          types.List                      `tfsdk:"worker_nodes"`
+	ClientConfiguration  *clientConfiguration            `tfsdk:"client_configuration"`
+	Timeouts             timeouts.Value                  `tfsdk:"timeouts"`
+	SkipKubernetesChecks types.Bool                      `tfsdk:"skip_kubernetes_checks"`
+	MaxConcurrency       types.Int64                     `tfsdk:"max_concurrency"`
+	ReadinessGates       *postApplyChecks                `tfsdk:"readiness_gates"`
+	FailOnUnhealthy      types.Bool                      `tfsdk:"fail_on_unhealthy"`
+	Healthy              types.Bool                      `tfsdk:"healthy"`
+	NodeFailures         map[string][]healthCheckFailure `tfsdk:"node_failures"`
+	ClusterFailures      []healthCheckFailure            `tfsdk:"cluster_failures"`
 }
 
 type clusterNodes struct {
@@ -74,8 +91,10 @@ func (c *clusterNodes) NodesByType(t machine.Type) []cluster.NodeInfo {
 }
 
 type reporter struct {
-	lastLine string
-	s        strings.Builder
+	condition conditions.Condition
+	started   time.Time
+	lastLine  string
+	s         strings.Builder
 }
 
 func newReporter() *reporter {
@@ -84,6 +103,11 @@ func newReporter() *reporter {
 
 // Update implements the conditions.Reporter interface.
 func (r *reporter) Update(condition conditions.Condition) {
+	if condition != r.condition {
+		r.condition = condition
+		r.started = time.Now()
+	}
+
 	if condition.String() != r.lastLine {
 		r.s.WriteString(fmt.Sprintf("waiting for %s\n", condition.String()))
 		r.lastLine = condition.String()
@@ -95,6 +119,15 @@ func (r *reporter) String() string {
 	return r.s.String()
 }
 
+// lastStatus returns the status of the last reported condition and the time it was waited for.
+func (r *reporter) lastStatus() (string, time.Duration) {
+	if r.condition == nil {
+		return "", 0
+	}
+
+	return r.condition.String(), time.Since(r.started)
+}
+
 // NewTalosClusterHealthDataSource implements the datasource.DataSource interface.
 func NewTalosClusterHealthDataSource() datasource.DataSource {
 	return &talosClusterHealthDataSource{}
@@ -117,6 +150,13 @@ func (d *talosClusterHealthDataSource) Schema(ctx context.Context, _ datasource.
 				ElementType: types.StringType,
 				Description: "endpoints to use for the health check client. Use at least one control plane endpoint.",
 			},
+			"port": schema.Int64Attribute{
+				Optional:    true,
+				Description: "The port of the Talos API, overrides the port of `endpoints` (e.g. when apid is exposed on a different port through NAT), defaults to `50000`",
+				Validators: []validator.Int64{
+					int64validator.Between(1, 65535),
+				},
+			},
 			"control_plane_nodes": schema.ListAttribute{
 				Required:    true,
 				ElementType: types.StringType,
@@ -131,6 +171,82 @@ func (d *talosClusterHealthDataSource) Schema(ctx context.Context, _ datasource.
 				Optional:    true,
 				Description: "Skip Kubernetes component checks, this is useful to check if the nodes has finished booting up and kubelet is running. Default is false.",
 			},
+			"max_concurrency": schema.Int64Attribute{
+				Optional:    true,
+				Description: "The maximum number of nodes to wait for concurrently before running the cluster checks. Default is 10.",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"readiness_gates": schema.SingleNestedAttribute{
+				Description: "Conditions to be satisfied on every node before the cluster checks run, on top of the node being ready",
+				Optional:    true,
+				Attributes: map[string]schema.Attribute{
+					"services": schema.ListAttribute{
+						ElementType: types.StringType,
+						Optional:    true,
+						Description: "The list of services which should be running and healthy",
+					},
+					"resources": schema.ListNestedAttribute{
+						Optional:    true,
+						Description: "The list of resources which should exist (e.g. a mounted user volume)",
+						NestedObject: schema.NestedAttributeObject{
+							Attributes: map[string]schema.Attribute{
+								"namespace": schema.StringAttribute{
+									Optional:    true,
+									Description: "The namespace of the resource, defaults to the resource type default namespace",
+								},
+								"type": schema.StringAttribute{
+									Required:    true,
+									Description: "The type of the resource, aliases are supported (e.g. `members`, `nodename`)",
+								},
+								"id": schema.StringAttribute{
+									Required:    true,
+									Description: "The ID of the resource",
+								},
+							},
+						},
+					},
+				},
+			},
+			"fail_on_unhealthy": schema.BoolAttribute{
+				Optional: true,
+				Description: "Fail if the cluster isn't healthy once the read timeout is reached. " +
+					"If false, the failed checks are reported in `node_failures` and `cluster_failures` instead. Default is true.",
+			},
+			"healthy": schema.BoolAttribute{
+				Computed:    true,
+				Description: "Whether all the checks passed",
+			},
+			"node_failures": schema.MapAttribute{
+				Computed: true,
+				ElementType: types.ListType{
+					ElemType: types.ObjectType{
+						AttrTypes: healthCheckFailureAttrTypes,
+					},
+				},
+				Description: "The failed checks by node, each failure has the `check` name, the error `message` and the `duration` the check was waited for",
+			},
+			"cluster_failures": schema.ListNestedAttribute{
+				Computed:    true,
+				Description: "The failed checks which can't be attributed to a node",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"check": schema.StringAttribute{
+							Computed:    true,
+							Description: "The name of the check",
+						},
+						"message": schema.StringAttribute{
+							Computed:    true,
+							Description: "The last error of the check",
+						},
+						"duration": schema.StringAttribute{
+							Computed:    true,
+							Description: "The duration the check was waited for",
+						},
+					},
+				},
+			},
 			"client_configuration": schema.SingleNestedAttribute{
 				Attributes: map[string]schema.Attribute{
 					"ca_certificate": schema.StringAttribute{
@@ -147,8 +263,8 @@ func (d *talosClusterHealthDataSource) Schema(ctx context.Context, _ datasource.
 						Description: "The client key",
 					},
 				},
-				Required:    true,
-				Description: "The client configuration data",
+				Optional:    true,
+				Description: "The client configuration data, defaults to the credentials of the provider `talosconfig_path`",
 			},
 			"timeouts": timeouts.Attributes(ctx, timeouts.Opts{
 				Read: true,
@@ -157,6 +273,24 @@ func (d *talosClusterHealthDataSource) Schema(ctx context.Context, _ datasource.
 	}
 }
 
+func (d *talosClusterHealthDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*talosProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"failed to get provider data",
+			fmt.Sprintf("Expected *talosProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.providerData = providerData
+}
+
 func (d *talosClusterHealthDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
 	var state talosClusterHealthDataSourceModelV0
 
@@ -173,6 +307,20 @@ func (d *talosClusterHealthDataSource) Read(ctx context.Context, req datasource.
 		workerNodes       []string
 	)
 
+	endpointValues := []types.String{types.StringUnknown()}
+
+	if !state.Endpoints.IsUnknown() {
+		resp.Diagnostics.Append(state.Endpoints.ElementsAs(ctx, &endpointValues, true)...)
+
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	if d.providerData.deferRead(ctx, req, resp, endpointValues...) {
+		return
+	}
+
 	resp.Diagnostics.Append(state.Endpoints.ElementsAs(ctx, &endpoints, true)...)
 
 	if resp.Diagnostics.HasError() {
@@ -191,6 +339,9 @@ func (d *talosClusterHealthDataSource) Read(ctx context.Context, req datasource.
 		return
 	}
 
+	controlPlaneNodes = normalizeNodes(controlPlaneNodes)
+	workerNodes = normalizeNodes(workerNodes)
+
 	readTimeout, diags := state.Timeouts.Read(ctx, 10*time.Minute)
 	resp.Diagnostics.Append(diags...)
 
@@ -198,19 +349,37 @@ func (d *talosClusterHealthDataSource) Read(ctx context.Context, req datasource.
 		return
 	}
 
-	talosConfig, err := talosClientTFConfigToTalosClientConfig(
-		"dynamic",
-		state.ClientConfiguration.CA.ValueString(),
-		state.ClientConfiguration.Cert.ValueString(),
-		state.ClientConfiguration.Key.ValueString(),
-	)
+	talosConfig, err := d.providerData.talosClientConfig("dynamic", state.ClientConfiguration)
 	if err != nil {
 		resp.Diagnostics.AddError("failed to generate talos config", err.Error())
 
 		return
 	}
 
-	c, err := client.New(ctx, client.WithConfig(talosConfig), client.WithEndpoints(endpoints...))
+	// the checks share a client, they hold a single operation slot
+	proxyCtx, release, err := acquireOperation(d.providerData.withClientOptions(ctx))
+	if err != nil {
+		resp.Diagnostics.AddError("failed to wait for a Talos API operation slot", err.Error())
+
+		return
+	}
+
+	defer release()
+
+	clientOpts, err := configClientOptions(proxyCtx, talosConfig)
+	if err != nil {
+		resp.Diagnostics.AddError("failed to create talos client", err.Error())
+
+		return
+	}
+
+	clientOpts = append(clientOpts, client.WithEndpoints(normalizeEndpoints(endpointsWithPort(endpoints, state.Port))...))
+
+	if omniOpts, ok := omniClientOptionsFromContext(proxyCtx); ok {
+		clientOpts = omniOpts
+	}
+
+	c, err := client.New(ctx, append(clientOpts, client.WithGRPCDialOptions(proxyDialOptionsFromContext(proxyCtx)...), client.WithGRPCDialOptions(traceDialOptions()...))...)
 	if err != nil {
 		resp.Diagnostics.AddError("failed to create talos client", err.Error())
 
@@ -247,22 +416,77 @@ func (d *talosClusterHealthDataSource) Read(ctx context.Context, req datasource.
 	checkCtx, checkCtxCancel := context.WithTimeout(ctx, readTimeout)
 	defer checkCtxCancel()
 
-	reporter := newReporter()
+	maxConcurrency := defaultHealthCheckConcurrency
 
-	checks := slices.Concat(check.PreBootSequenceChecks(), check.K8sComponentsReadinessChecks())
-
-	if !state.SkipKubernetesChecks.ValueBool() {
-		checks = check.DefaultClusterChecks()
+	if !state.MaxConcurrency.IsNull() {
+		maxConcurrency = int(state.MaxConcurrency.ValueInt64())
 	}
 
-	if err := check.Wait(checkCtx, &clusterState, checks, reporter); err != nil {
-		resp.Diagnostics.AddWarning("failed checks", reporter.String())
-		resp.Diagnostics.AddError("cluster health check failed", err.Error())
+	failOnUnhealthy := state.FailOnUnhealthy.IsNull() || state.FailOnUnhealthy.ValueBool()
+	failures := newHealthCheckFailures()
+	nodes := slices.Concat(controlPlaneNodes, workerNodes)
+
+	// wait for every node to boot concurrently, so that the cluster checks don't wait for the nodes one by one
+	nodesErr := runConcurrently(checkCtx, nodes, maxConcurrency, func(ctx context.Context, node string) error {
+		started := time.Now()
+
+		if err := waitNodeReady(ctx, c, node); err != nil {
+			failures.addNode(node, "node ready", err, time.Since(started))
+
+			return err
+		}
+
+		if state.ReadinessGates == nil {
+			return nil
+		}
+
+		started = time.Now()
+
+		if err := waitReadinessGates(ctx, c, node, state.ReadinessGates); err != nil {
+			failures.addNode(node, "readiness gates", err, time.Since(started))
+
+			return err
+		}
+
+		return nil
+	})
+
+	switch {
+	case nodesErr != nil && failOnUnhealthy:
+		resp.Diagnostics.AddError("nodes are not ready", nodesErr.Error())
 
 		return
+	case nodesErr != nil:
+		// the cluster checks would only time out again
+		resp.Diagnostics.AddWarning("nodes are not ready", nodesErr.Error())
+	default:
+		reporter := newReporter()
+
+		checks := slices.Concat(check.PreBootSequenceChecks(), check.K8sComponentsReadinessChecks())
+
+		if !state.SkipKubernetesChecks.ValueBool() {
+			checks = check.DefaultClusterChecks()
+		}
+
+		if err := check.Wait(checkCtx, &clusterState, checks, reporter); err != nil {
+			if failOnUnhealthy {
+				resp.Diagnostics.AddWarning("failed checks", reporter.String())
+				resp.Diagnostics.AddError("cluster health check failed", err.Error())
+
+				return
+			}
+
+			lastStatus, duration := reporter.lastStatus()
+			failures.addCheck(nodes, lastStatus, err, duration)
+
+			resp.Diagnostics.AddWarning("cluster health check failed", reporter.String())
+		}
 	}
 
 	state.ID = basetypes.NewStringValue("cluster_health")
+	state.Healthy = types.BoolValue(failures.healthy())
+	state.NodeFailures = failures.nodes
+	state.ClusterFailures = failures.cluster
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, state)...)
 
@@ -270,3 +494,142 @@ func (d *talosClusterHealthDataSource) Read(ctx context.Context, req datasource.
 		return
 	}
 }
+
+// healthCheckFailureAttrTypes are the attribute types of a failed check of the cluster health.
+var healthCheckFailureAttrTypes = map[string]attr.Type{
+	"check":    types.StringType,
+	"message":  types.StringType,
+	"duration": types.StringType,
+}
+
+// waitReadinessGates waits for the readiness gates to be satisfied on the node.
+func waitReadinessGates(ctx context.Context, c *client.Client, node string, gates *postApplyChecks) error {
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		diags := gates.evaluate(client.WithNode(ctx, node), c, path.Root("readiness_gates"))
+		if !diags.HasError() {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			unsatisfied := make([]string, 0, len(diags.Errors()))
+
+			for _, d := range diags.Errors() {
+				unsatisfied = append(unsatisfied, fmt.Sprintf("%s: %s", d.Summary(), d.Detail()))
+			}
+
+			return fmt.Errorf("node %s readiness gates are not satisfied: %s", node, strings.Join(unsatisfied, ", "))
+		case <-ticker.C:
+		}
+	}
+}
+
+// healthCheckFailure is a failed check of the cluster health.
+type healthCheckFailure struct {
+	Check    types.String `tfsdk:"check"`
+	Message  types.String `tfsdk:"message"`
+	Duration types.String `tfsdk:"duration"`
+}
+
+// healthCheckFailures collects the failed checks of the cluster health by node,
+// the failures which can't be attributed to a node are collected as cluster failures.
+type healthCheckFailures struct {
+	nodes   map[string][]healthCheckFailure
+	cluster []healthCheckFailure
+	mu      sync.Mutex
+}
+
+func newHealthCheckFailures() *healthCheckFailures {
+	return &healthCheckFailures{
+		nodes:   map[string][]healthCheckFailure{},
+		cluster: []healthCheckFailure{},
+	}
+}
+
+func newHealthCheckFailure(check, message string, duration time.Duration) healthCheckFailure {
+	return healthCheckFailure{
+		Check:    types.StringValue(check),
+		Message:  types.StringValue(message),
+		Duration: types.StringValue(duration.Round(time.Second).String()),
+	}
+}
+
+// addNode records the failed check of the node.
+func (f *healthCheckFailures) addNode(node, check string, err error, duration time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.nodes[node] = append(f.nodes[node], newHealthCheckFailure(check, err.Error(), duration))
+}
+
+// addCheck records the failed cluster check from the last status of its condition (`description: error`),
+// the errors prefixed with one of the nodes are attributed to that node.
+func (f *healthCheckFailures) addCheck(nodes []string, status string, err error, duration time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	check, message, _ := strings.Cut(status, ": ")
+	if message == "" || message == conditions.OK {
+		// the checks timed out before the condition was waited for
+		f.cluster = append(f.cluster, newHealthCheckFailure("cluster health", err.Error(), duration))
+
+		return
+	}
+
+	for _, msg := range multiErrorMessages(message) {
+		node := ""
+
+		for _, n := range nodes {
+			if strings.HasPrefix(msg, n+": ") {
+				node = n
+
+				break
+			}
+		}
+
+		if node == "" {
+			f.cluster = append(f.cluster, newHealthCheckFailure(check, msg, duration))
+
+			continue
+		}
+
+		f.nodes[node] = append(f.nodes[node], newHealthCheckFailure(check, strings.TrimPrefix(msg, node+": "), duration))
+	}
+}
+
+// healthy returns true if no check failed.
+func (f *healthCheckFailures) healthy() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return len(f.nodes) == 0 && len(f.cluster) == 0
+}
+
+// multiErrorMessages splits the message of a multierror (`N errors occurred:` followed by a `* message` line per error) into the messages of the errors.
+func multiErrorMessages(message string) []string {
+	lines := strings.Split(strings.TrimSpace(message), "\n")
+	if len(lines) < 2 || !strings.HasSuffix(lines[0], " occurred:") {
+		return []string{message}
+	}
+
+	var messages []string
+
+	for _, line := range lines[1:] {
+		line = strings.TrimSpace(line)
+
+		if msg, ok := strings.CutPrefix(line, "* "); ok {
+			messages = append(messages, msg)
+
+			continue
+		}
+
+		if len(messages) > 0 && line != "" {
+			messages[len(messages)-1] += "\n" + line
+		}
+	}
+
+	return messages
+}
@@ -5,10 +5,16 @@
 package talos_test
 
 import (
+	"context"
+	"errors"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+
+	"github.com/siderolabs/terraform-provider-talos/pkg/talos"
 )
 
 func TestAccTalosClusterHealthDataSource(t *testing.T) {
@@ -26,6 +32,13 @@ func TestAccTalosClusterHealthDataSource(t *testing.T) {
 				Config: testAccTalosClusterHealthDataSourceConfig("talos", rName),
 				Check: resource.ComposeAggregateTestCheckFunc(
 					resource.TestCheckResourceAttr("data.talos_cluster_health.this", "id", "cluster_health"),
+					resource.TestCheckResourceAttr("data.talos_cluster_health.this", "healthy", "true"),
+					resource.TestCheckResourceAttr("data.talos_cluster_health.this", "node_failures.%", "0"),
+					resource.TestCheckResourceAttr("data.talos_cluster_health.this", "cluster_failures.#", "0"),
+					resource.TestCheckResourceAttr("data.talos_cluster_health.gates", "id", "cluster_health"),
+					resource.TestCheckResourceAttr("data.talos_cluster_health.gates", "readiness_gates.services.#", "2"),
+					resource.TestCheckResourceAttr("data.talos_cluster_health.gates", "readiness_gates.resources.#", "1"),
+					resource.TestCheckResourceAttr("data.talos_cluster_health.gates", "healthy", "true"),
 				),
 			},
 			// make sure there are no changes
@@ -47,5 +60,76 @@ func testAccTalosClusterHealthDataSourceConfig(providerName, rName string) strin
 		WithClusterHealth:      true,
 	}
 
-	return config.render()
+	return config.render() + `
+data "talos_cluster_health" "gates" {
+  depends_on = [
+    talos_cluster_kubeconfig.this
+  ]
+
+  client_configuration   = talos_machine_secrets.this.client_configuration
+  endpoints              = libvirt_domain.cp.network_interface[0].addresses
+  control_plane_nodes    = libvirt_domain.cp.network_interface[0].addresses
+  skip_kubernetes_checks = true
+  fail_on_unhealthy      = false
+
+  readiness_gates = {
+    services = ["etcd", "kubelet"]
+    resources = [
+      {
+        type = "nodename"
+        id   = "nodename"
+      },
+    ]
+  }
+}
+`
+}
+
+func TestHealthCheckFailures(t *testing.T) {
+	t.Parallel()
+
+	nodes := []string{"10.5.0.2", "10.5.0.3", "10.5.0.4"}
+
+	failures := talos.NewHealthCheckFailures()
+
+	if !failures.Healthy() {
+		t.Fatal("expected no failures to be healthy")
+	}
+
+	failures.AddNode("10.5.0.4", "node ready", errors.New("machine stage is booting"), 90*time.Second+300*time.Millisecond)
+	failures.AddCheck(
+		nodes,
+		"etcd to be healthy: 2 errors occurred:\n\t* 10.5.0.2: service \"etcd\" not in expected state \"Running\"\n\t* 10.5.0.20: no events recorded yet for service \"etcd\"\n\n",
+		context.DeadlineExceeded,
+		time.Minute,
+	)
+	failures.AddCheck(nodes, "all k8s nodes to report ready: some nodes are not ready: [talos-worker-1]", context.DeadlineExceeded, 2*time.Minute)
+	failures.AddCheck(nodes, "etcd to be healthy: OK", context.DeadlineExceeded, time.Second)
+
+	if failures.Healthy() {
+		t.Fatal("expected failures to be unhealthy")
+	}
+
+	expectedNodes := map[string][]talos.HealthCheckFailure{
+		"10.5.0.2": {talos.NewHealthCheckFailure("etcd to be healthy", "service \"etcd\" not in expected state \"Running\"", time.Minute)},
+		"10.5.0.4": {talos.NewHealthCheckFailure("node ready", "machine stage is booting", 90*time.Second)},
+	}
+
+	if !reflect.DeepEqual(failures.Nodes(), expectedNodes) {
+		t.Errorf("expected node failures %v, got %v", expectedNodes, failures.Nodes())
+	}
+
+	expectedCluster := []talos.HealthCheckFailure{
+		talos.NewHealthCheckFailure("etcd to be healthy", "10.5.0.20: no events recorded yet for service \"etcd\"", time.Minute),
+		talos.NewHealthCheckFailure("all k8s nodes to report ready", "some nodes are not ready: [talos-worker-1]", 2*time.Minute),
+		talos.NewHealthCheckFailure("cluster health", "context deadline exceeded", time.Second),
+	}
+
+	if !reflect.DeepEqual(failures.Cluster(), expectedCluster) {
+		t.Errorf("expected cluster failures %v, got %v", expectedCluster, failures.Cluster())
+	}
+
+	if failures.Nodes()["10.5.0.4"][0].Duration.ValueString() != "1m30s" {
+		t.Errorf("expected the duration to be rounded to the second, got %s", failures.Nodes()["10.5.0.4"][0].Duration)
+	}
 }
@@ -0,0 +1,284 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cosi-project/runtime/pkg/safe"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/mapplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	machineapi "github.com/siderolabs/talos/pkg/machinery/api/machine"
+	"github.com/siderolabs/talos/pkg/machinery/client"
+	clientconfig "github.com/siderolabs/talos/pkg/machinery/client/config"
+	talosconfig "github.com/siderolabs/talos/pkg/machinery/config"
+	"github.com/siderolabs/talos/pkg/machinery/config/encoder"
+	"github.com/siderolabs/talos/pkg/machinery/config/types/v1alpha1"
+	"github.com/siderolabs/talos/pkg/machinery/resources/config"
+)
+
+type talosClusterJoinTokenRotationResource struct {
+	providerData *talosProviderData
+}
+
+var (
+	_ resource.Resource              = &talosClusterJoinTokenRotationResource{}
+	_ resource.ResourceWithConfigure = &talosClusterJoinTokenRotationResource{}
+)
+
+type talosClusterJoinTokenRotationResourceModelV0 struct {
+	ID                  types.String         `tfsdk:"id"`
+	ControlPlaneNodes   []types.String       `tfsdk:"control_plane_nodes"`
+	WorkerNodes         []types.String       `tfsdk:"worker_nodes"`
+	Endpoints           []types.String       `tfsdk:"endpoints"`
+	ClientConfiguration *clientConfiguration `tfsdk:"client_configuration"`
+	Triggers            types.Map            `tfsdk:"triggers"`
+	Token               types.String         `tfsdk:"token"`
+	Timeouts            timeouts.Value       `tfsdk:"timeouts"`
+}
+
+// NewTalosClusterJoinTokenRotationResource implements the resource.Resource interface.
+func NewTalosClusterJoinTokenRotationResource() resource.Resource {
+	return &talosClusterJoinTokenRotationResource{}
+}
+
+func (r *talosClusterJoinTokenRotationResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_cluster_join_token_rotation"
+}
+
+func (r *talosClusterJoinTokenRotationResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "The cluster join token rotation resource rotates the trustd token the worker nodes use to request their certificates from the control plane nodes. " +
+			"A new token is generated and set in the machine configuration of the control plane nodes, then of the worker nodes, without rebooting them. " +
+			"The rotation runs when the resource is created and again when `triggers` changes. " +
+			"The new token is exposed so that it can replace `trustdinfo.token` of the machine secrets used to generate the machine configuration " +
+			"of all the nodes, control plane nodes included: " +
+			"a later `talos_machine_configuration_apply` of a machine configuration generated from the old secrets sets the old token back on the node, " +
+			"and the worker nodes presenting the new token are then rejected.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:    true,
+				Description: "This is a unique identifier for the rotation",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"control_plane_nodes": schema.ListAttribute{
+				ElementType: types.StringType,
+				Required:    true,
+				Description: "The control plane nodes to accept the new token",
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+					listvalidator.UniqueValues(),
+				},
+			},
+			"worker_nodes": schema.ListAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Description: "The worker nodes to present the new token, once the control plane nodes accept it",
+				Validators: []validator.List{
+					listvalidator.UniqueValues(),
+				},
+			},
+			"endpoints": schema.ListAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Description: "The endpoints to reach the nodes through, each node is reached directly if not set",
+			},
+			"client_configuration": schema.SingleNestedAttribute{
+				Attributes: map[string]schema.Attribute{
+					"ca_certificate": schema.StringAttribute{
+						Required:    true,
+						Description: "The client CA certificate",
+					},
+					"client_certificate": schema.StringAttribute{
+						Required:    true,
+						Description: "The client certificate",
+					},
+					"client_key": schema.StringAttribute{
+						Required:    true,
+						Sensitive:   true,
+						Description: "The client key",
+					},
+				},
+				Optional:    true,
+				Description: "The client configuration data, defaults to the credentials of the provider `talosconfig_path`",
+			},
+			"triggers": schema.MapAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Description: "A map of arbitrary values that, when changed, will rotate the token again",
+				PlanModifiers: []planmodifier.Map{
+					mapplanmodifier.RequiresReplace(),
+				},
+			},
+			"token": schema.StringAttribute{
+				Computed:    true,
+				Sensitive:   true,
+				Description: "The new trustd token",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"timeouts": timeouts.Attributes(ctx, timeouts.Opts{
+				Create: true,
+			}),
+		},
+	}
+}
+
+func (r *talosClusterJoinTokenRotationResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*talosProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"failed to get provider data",
+			fmt.Sprintf("Expected *talosProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.providerData = providerData
+}
+
+func (r *talosClusterJoinTokenRotationResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var state talosClusterJoinTokenRotationResourceModelV0
+
+	diags := req.Plan.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+
+	if diags.HasError() {
+		return
+	}
+
+	createTimeout, diags := state.Timeouts.Create(ctx, 10*time.Minute)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	talosClientConfig, err := r.providerData.talosClientConfig("dynamic", state.ClientConfiguration)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error converting config to talos client config",
+			err.Error(),
+		)
+
+		return
+	}
+
+	token, err := genBootstrapToken()
+	if err != nil {
+		resp.Diagnostics.AddError("Error generating the join token", err.Error())
+
+		return
+	}
+
+	ctxDeadline, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
+	// the control plane nodes accept the new token before the worker nodes present it
+	for _, nodes := range [][]types.String{state.ControlPlaneNodes, state.WorkerNodes} {
+		if err = runConcurrently(ctxDeadline, stringValues(nodes), defaultHealthCheckConcurrency, func(ctx context.Context, node string) error {
+			return r.setNodeToken(ctx, &state, talosClientConfig, node, token)
+		}); err != nil {
+			resp.Diagnostics.AddError("Error rotating the join token", err.Error())
+
+			return
+		}
+	}
+
+	state.ID = basetypes.NewStringValue("cluster_join_token_rotation")
+	state.Token = basetypes.NewStringValue(token)
+
+	// Set state to fully populated data
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r *talosClusterJoinTokenRotationResource) Read(_ context.Context, _ resource.ReadRequest, _ *resource.ReadResponse) {
+}
+
+func (r *talosClusterJoinTokenRotationResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var state talosClusterJoinTokenRotationResourceModelV0
+
+	diags := req.Plan.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+
+	if diags.HasError() {
+		return
+	}
+
+	// Set state to fully populated data
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r *talosClusterJoinTokenRotationResource) Delete(_ context.Context, _ resource.DeleteRequest, _ *resource.DeleteResponse) {
+}
+
+// setNodeToken applies the current machine configuration of the node with the token replaced.
+func (r *talosClusterJoinTokenRotationResource) setNodeToken(ctx context.Context, state *talosClusterJoinTokenRotationResourceModelV0, tc *clientconfig.Config, node, token string) error {
+	endpoints := []string{node}
+	if len(state.Endpoints) > 0 {
+		endpoints = stringValues(state.Endpoints)
+	}
+
+	return talosClientOpEndpoints(r.providerData.withClientOptions(ctx), endpoints, node, tc, func(nodeCtx context.Context, c *client.Client) error {
+		machineConfig, err := safe.StateGetByID[*config.MachineConfig](nodeCtx, c.COSI, config.V1Alpha1ID)
+		if err != nil {
+			return err
+		}
+
+		cfgBytes, err := machineConfigurationWithToken(machineConfig.Provider(), token)
+		if err != nil {
+			return err
+		}
+
+		_, err = c.ApplyConfiguration(nodeCtx, &machineapi.ApplyConfigurationRequest{
+			Data: cfgBytes,
+			Mode: machineapi.ApplyConfigurationRequest_NO_REBOOT,
+		})
+
+		return err
+	})
+}
+
+// machineConfigurationWithToken returns the machine configuration with the trustd token replaced.
+func machineConfigurationWithToken(cfg talosconfig.Provider, token string) ([]byte, error) {
+	patched, err := cfg.PatchV1Alpha1(func(c *v1alpha1.Config) error {
+		c.MachineConfig.MachineToken = token
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return patched.EncodeBytes(encoder.WithComments(encoder.CommentsDisabled))
+}
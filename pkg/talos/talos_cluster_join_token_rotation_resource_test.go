@@ -0,0 +1,127 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos_test
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/siderolabs/talos/pkg/machinery/config/configloader"
+	"github.com/siderolabs/talos/pkg/machinery/config/generate/secrets"
+	"github.com/siderolabs/talos/pkg/machinery/config/machine"
+
+	"github.com/siderolabs/terraform-provider-talos/pkg/talos"
+)
+
+func TestAccTalosClusterJoinTokenRotationResource(t *testing.T) {
+	rName := acctest.RandStringFromCharSet(10, acctest.CharSetAlpha)
+
+	resource.ParallelTest(t, resource.TestCase{
+		ExternalProviders: map[string]resource.ExternalProvider{
+			"libvirt": {
+				Source: "dmacvicar/libvirt",
+			},
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccTalosClusterJoinTokenRotationResourceConfig("talos", rName, "1"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("talos_cluster_join_token_rotation.this", "id", "cluster_join_token_rotation"),
+					resource.TestMatchResourceAttr("talos_cluster_join_token_rotation.this", "token", regexp.MustCompile(`^[a-z0-9]{6}\.[a-z0-9]{16}$`)),
+				),
+			},
+			// make sure there are no changes
+			{
+				Config:   testAccTalosClusterJoinTokenRotationResourceConfig("talos", rName, "1"),
+				PlanOnly: true,
+			},
+			// changing the triggers rotates the token again
+			{
+				Config: testAccTalosClusterJoinTokenRotationResourceConfig("talos", rName, "2"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("talos_cluster_join_token_rotation.this", plancheck.ResourceActionReplace),
+					},
+				},
+			},
+		},
+	})
+}
+
+func testAccTalosClusterJoinTokenRotationResourceConfig(providerName, rName, trigger string) string {
+	config := dynamicConfig{
+		Provider:          providerName,
+		ResourceName:      rName,
+		WithApplyConfig:   true,
+		WithBootstrap:     true,
+		WithClusterHealth: true,
+	}
+
+	return config.render() + fmt.Sprintf(`
+resource "talos_cluster_join_token_rotation" "this" {
+  depends_on = [
+    data.talos_cluster_health.this
+  ]
+  client_configuration = talos_machine_secrets.this.client_configuration
+  control_plane_nodes  = libvirt_domain.cp.network_interface[0].addresses
+  triggers = {
+    rotation = "%s"
+  }
+}
+`, trigger)
+}
+
+func TestMachineConfigurationWithToken(t *testing.T) {
+	t.Parallel()
+
+	secretsBundle, err := secrets.NewBundle(secrets.NewFixedClock(time.Now()), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	machineConfiguration, err := talos.GenerateMachineConfiguration(machine.TypeWorker, secretsBundle)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := configloader.NewFromBytes([]byte(machineConfiguration))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := talos.GenBootstrapToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfgBytes, err := talos.MachineConfigurationWithToken(cfg, token)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	patched, err := configloader.NewFromBytes(cfgBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if patched.Machine().Security().Token() != token {
+		t.Errorf("expected token %q, got %q", token, patched.Machine().Security().Token())
+	}
+
+	if cfg.Machine().Security().Token() != secretsBundle.TrustdInfo.Token {
+		t.Errorf("expected the original machine configuration to be left unchanged")
+	}
+
+	if !talos.MachineConfigurationEqual(machineConfiguration, strings.Replace(string(cfgBytes), token, secretsBundle.TrustdInfo.Token, 1)) {
+		t.Errorf("expected only the token to change")
+	}
+}
@@ -6,6 +6,7 @@ package talos
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
@@ -20,20 +21,26 @@ import (
 	"k8s.io/client-go/tools/clientcmd"
 )
 
-type talosClusterKubeConfigDataSource struct{}
+type talosClusterKubeConfigDataSource struct {
+	providerData *talosProviderData
+}
 
 type talosClusterKubeConfigDataSourceModelV0 struct { //nolint:govet
 	ID                            types.String                  `tfsdk:"id"`
 	Node                          types.String                  `tfsdk:"node"`
 	Endpoint                      types.String                  `tfsdk:"endpoint"`
-	ClientConfiguration           clientConfiguration           `tfsdk:"client_configuration"`
+	Endpoints                     []types.String                `tfsdk:"endpoints"`
+	ClientConfiguration           *clientConfiguration          `tfsdk:"client_configuration"`
 	KubeConfigRaw                 types.String                  `tfsdk:"kubeconfig_raw"`
 	KubernetesClientConfiguration kubernetesClientConfiguration `tfsdk:"kubernetes_client_configuration"`
 	Wait                          types.Bool                    `tfsdk:"wait"`
 	Timeouts                      timeouts.Value                `tfsdk:"timeouts"`
 }
 
-var _ datasource.DataSource = &talosClusterKubeConfigDataSource{}
+var (
+	_ datasource.DataSource              = &talosClusterKubeConfigDataSource{}
+	_ datasource.DataSourceWithConfigure = &talosClusterKubeConfigDataSource{}
+)
 
 // NewTalosClusterKubeConfigDataSource implements the datasource.DataSource interface.
 func NewTalosClusterKubeConfigDataSource() datasource.DataSource {
@@ -61,6 +68,11 @@ func (d *talosClusterKubeConfigDataSource) Schema(ctx context.Context, _ datasou
 				Computed:    true,
 				Description: "endpoint to use for the talosclient. If not set, the node value will be used",
 			},
+			"endpoints": schema.ListAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Description: "The additional endpoints to fail over to if `endpoint` is unreachable, the Talos API client load balances the requests over the reachable endpoints",
+			},
 			"client_configuration": schema.SingleNestedAttribute{
 				Attributes: map[string]schema.Attribute{
 					"ca_certificate": schema.StringAttribute{
@@ -77,8 +89,8 @@ func (d *talosClusterKubeConfigDataSource) Schema(ctx context.Context, _ datasou
 						Description: "The client key",
 					},
 				},
-				Required:    true,
-				Description: "The client configuration data",
+				Optional:    true,
+				Description: "The client configuration data, defaults to the credentials of the provider `talosconfig_path`",
 			},
 			"wait": schema.BoolAttribute{
 				Optional:           true,
@@ -120,6 +132,24 @@ func (d *talosClusterKubeConfigDataSource) Schema(ctx context.Context, _ datasou
 	}
 }
 
+func (d *talosClusterKubeConfigDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*talosProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"failed to get provider data",
+			fmt.Sprintf("Expected *talosProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.providerData = providerData
+}
+
 // Read implements the datasource.DataSource interface.
 //
 //nolint:dupl
@@ -144,12 +174,7 @@ func (d *talosClusterKubeConfigDataSource) Read(ctx context.Context, req datasou
 		return
 	}
 
-	talosConfig, err := talosClientTFConfigToTalosClientConfig(
-		"dynamic",
-		state.ClientConfiguration.CA.ValueString(),
-		state.ClientConfiguration.Cert.ValueString(),
-		state.ClientConfiguration.Key.ValueString(),
-	)
+	talosConfig, err := d.providerData.talosClientConfig("dynamic", state.ClientConfiguration)
 	if err != nil {
 		resp.Diagnostics.AddError("failed to generate talos config", err.Error())
 
@@ -160,6 +185,10 @@ func (d *talosClusterKubeConfigDataSource) Read(ctx context.Context, req datasou
 		state.Endpoint = state.Node
 	}
 
+	if d.providerData.deferRead(ctx, req, resp, state.Endpoint) {
+		return
+	}
+
 	readTimeout, diags := state.Timeouts.Read(ctx, 10*time.Minute)
 	resp.Diagnostics.Append(diags...)
 
@@ -170,8 +199,8 @@ func (d *talosClusterKubeConfigDataSource) Read(ctx context.Context, req datasou
 	ctxDeadline, cancel := context.WithTimeout(ctx, readTimeout)
 	defer cancel()
 
-	if retryErr := retry.RetryContext(ctxDeadline, readTimeout, func() *retry.RetryError {
-		if clientOpErr := talosClientOp(ctx, state.Endpoint.ValueString(), state.Node.ValueString(), talosConfig, func(nodeCtx context.Context, c *client.Client) error {
+	if retryErr := d.providerData.retryContext(ctxDeadline, readTimeout, func() *retry.RetryError {
+		if clientOpErr := talosClientOpEndpoints(d.providerData.withClientOptions(ctx), clientEndpoints(state.Endpoint, state.Endpoints, types.Int64Null()), state.Node.ValueString(), talosConfig, func(nodeCtx context.Context, c *client.Client) error {
 			kubeConfigBytes, clientErr := c.Kubeconfig(nodeCtx)
 			if clientErr != nil {
 				return clientErr
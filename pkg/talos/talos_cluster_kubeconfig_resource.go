@@ -8,12 +8,21 @@ import (
 	"context"
 	"crypto/x509"
 	"encoding/pem"
+	"fmt"
+	"net/url"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
@@ -24,20 +33,32 @@ import (
 	"k8s.io/client-go/tools/clientcmd"
 )
 
-type talosClusterKubeConfigResource struct{}
+type talosClusterKubeConfigResource struct {
+	providerData *talosProviderData
+}
 
 var (
 	_ resource.Resource               = &talosClusterKubeConfigResource{}
 	_ resource.ResourceWithModifyPlan = &talosClusterKubeConfigResource{}
+	_ resource.ResourceWithConfigure  = &talosClusterKubeConfigResource{}
 )
 
 type talosClusterKubeConfigResourceModelV0 struct {
 	ID                            types.String                  `tfsdk:"id"`
 	Node                          types.String                  `tfsdk:"node"`
 	Endpoint                      types.String                  `tfsdk:"endpoint"`
-	ClientConfiguration           clientConfiguration           `tfsdk:"client_configuration"`
+	Endpoints                     []types.String                `tfsdk:"endpoints"`
+	Port                          types.Int64                   `tfsdk:"port"`
+	ClusterEndpoint               types.String                  `tfsdk:"cluster_endpoint"`
+	ControlPlaneEndpoints         []types.String                `tfsdk:"control_plane_endpoints"`
+	ClusterName                   types.String                  `tfsdk:"cluster_name"`
+	ContextName                   types.String                  `tfsdk:"context_name"`
+	UserName                      types.String                  `tfsdk:"user_name"`
+	Namespace                     types.String                  `tfsdk:"namespace"`
+	ClientConfiguration           *clientConfiguration          `tfsdk:"client_configuration"`
 	KubeConfigRaw                 types.String                  `tfsdk:"kubeconfig_raw"`
 	KubernetesClientConfiguration kubernetesClientConfiguration `tfsdk:"kubernetes_client_configuration"`
+	Wait                          types.Bool                    `tfsdk:"wait"`
 	Timeouts                      timeouts.Value                `tfsdk:"timeouts"`
 }
 
@@ -73,6 +94,74 @@ func (r *talosClusterKubeConfigResource) Schema(ctx context.Context, _ resource.
 				Computed:    true,
 				Description: "endpoint to use for the talosclient. If not set, the node value will be used",
 			},
+			"endpoints": schema.ListAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Description: "The additional endpoints to fail over to if `endpoint` is unreachable, the Talos API client load balances the requests over the reachable endpoints",
+			},
+			"port": schema.Int64Attribute{
+				Optional:    true,
+				Description: "The port of the Talos API, overrides the port of `endpoint` and `endpoints` (e.g. when apid is exposed on a different port through NAT), defaults to `50000`",
+				Validators: []validator.Int64{
+					int64validator.Between(1, 65535),
+				},
+			},
+			"cluster_endpoint": schema.StringAttribute{
+				Optional:    true,
+				Description: "The Kubernetes API server URL to set in the kubeconfig instead of the cluster endpoint of the machine configuration (e.g. a load balancer `https://kube.example.com:6443`)",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"control_plane_endpoints": schema.ListAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Description: "The Kubernetes API server URLs of the control plane nodes (e.g. `https://10.5.0.2:6443`) to add a cluster and a context for to the kubeconfig, " +
+					"so that `kubectl` can be switched to another control plane node if the cluster endpoint is unreachable",
+				Validators: []validator.List{
+					listvalidator.UniqueValues(),
+				},
+				PlanModifiers: []planmodifier.List{
+					listplanmodifier.RequiresReplace(),
+				},
+			},
+			"cluster_name": schema.StringAttribute{
+				Optional:    true,
+				Description: "The name of the cluster in the kubeconfig, defaults to the cluster name of the machine configuration",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"context_name": schema.StringAttribute{
+				Optional:    true,
+				Description: "The name of the context in the kubeconfig, defaults to `admin@<cluster name>`",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"user_name": schema.StringAttribute{
+				Optional:    true,
+				Description: "The name of the user in the kubeconfig, defaults to `admin@<cluster name>`",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"namespace": schema.StringAttribute{
+				Optional:    true,
+				Description: "The default namespace of the contexts in the kubeconfig",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
 			"client_configuration": schema.SingleNestedAttribute{
 				Attributes: map[string]schema.Attribute{
 					"ca_certificate": schema.StringAttribute{
@@ -89,8 +178,8 @@ func (r *talosClusterKubeConfigResource) Schema(ctx context.Context, _ resource.
 						Description: "The client key",
 					},
 				},
-				Required:    true,
-				Description: "The client configuration data",
+				Optional:    true,
+				Description: "The client configuration data, defaults to the credentials of the provider `talosconfig_path`",
 			},
 			"kubeconfig_raw": schema.StringAttribute{
 				Computed:    true,
@@ -120,6 +209,10 @@ func (r *talosClusterKubeConfigResource) Schema(ctx context.Context, _ resource.
 				Computed:    true,
 				Description: "The kubernetes client configuration",
 			},
+			"wait": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Wait for the Kubernetes API server of the kubeconfig to be ready, i.e. `/readyz` to succeed, within the create timeout",
+			},
 			"timeouts": timeouts.Attributes(ctx, timeouts.Opts{
 				Create: true,
 				Update: true,
@@ -128,6 +221,24 @@ func (r *talosClusterKubeConfigResource) Schema(ctx context.Context, _ resource.
 	}
 }
 
+func (r *talosClusterKubeConfigResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*talosProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"failed to get provider data",
+			fmt.Sprintf("Expected *talosProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.providerData = providerData
+}
+
 // Create implements the resource.Resource interface.
 //
 //nolint:dupl
@@ -152,12 +263,7 @@ func (r *talosClusterKubeConfigResource) Create(ctx context.Context, req resourc
 		return
 	}
 
-	talosConfig, err := talosClientTFConfigToTalosClientConfig(
-		"dynamic",
-		state.ClientConfiguration.CA.ValueString(),
-		state.ClientConfiguration.Cert.ValueString(),
-		state.ClientConfiguration.Key.ValueString(),
-	)
+	talosConfig, err := r.providerData.talosClientConfig("dynamic", state.ClientConfiguration)
 	if err != nil {
 		resp.Diagnostics.AddError("failed to generate talos config", err.Error())
 
@@ -178,8 +284,8 @@ func (r *talosClusterKubeConfigResource) Create(ctx context.Context, req resourc
 	ctxDeadline, cancel := context.WithTimeout(ctx, readTimeout)
 	defer cancel()
 
-	if retryErr := retry.RetryContext(ctxDeadline, readTimeout, func() *retry.RetryError {
-		if clientOpErr := talosClientOp(ctx, state.Endpoint.ValueString(), state.Node.ValueString(), talosConfig, func(nodeCtx context.Context, c *client.Client) error {
+	if retryErr := r.providerData.retryContext(ctxDeadline, readTimeout, func() *retry.RetryError {
+		if clientOpErr := talosClientOpEndpoints(r.providerData.withClientOptions(ctx), clientEndpoints(state.Endpoint, state.Endpoints, state.Port), state.Node.ValueString(), talosConfig, func(nodeCtx context.Context, c *client.Client) error {
 			kubeConfigBytes, clientErr := c.Kubeconfig(nodeCtx)
 			if clientErr != nil {
 				return clientErr
@@ -203,7 +309,16 @@ func (r *talosClusterKubeConfigResource) Create(ctx context.Context, req resourc
 		return
 	}
 
-	kubeConfig, err := clientcmd.Load([]byte(state.KubeConfigRaw.ValueString()))
+	kubeConfigBytes, err := state.kubeConfigOptions().apply([]byte(state.KubeConfigRaw.ValueString()))
+	if err != nil {
+		resp.Diagnostics.AddError("failed to customize kubeconfig", err.Error())
+
+		return
+	}
+
+	state.KubeConfigRaw = basetypes.NewStringValue(string(kubeConfigBytes))
+
+	kubeConfig, err := clientcmd.Load(kubeConfigBytes)
 	if err != nil {
 		resp.Diagnostics.AddError("failed to parse kubeconfig", err.Error())
 
@@ -220,6 +335,14 @@ func (r *talosClusterKubeConfigResource) Create(ctx context.Context, req resourc
 		ClientKey:         basetypes.NewStringValue(bytesToBase64(kubeConfig.AuthInfos[authName].ClientKeyData)),
 	}
 
+	if state.Wait.ValueBool() {
+		if err = waitKubernetesAPIReady(r.providerData.withClientOptions(ctxDeadline), []byte(state.KubeConfigRaw.ValueString())); err != nil {
+			resp.Diagnostics.AddError("failed to wait for the Kubernetes API server", err.Error())
+
+			return
+		}
+	}
+
 	state.ID = basetypes.NewStringValue(clusterName)
 
 	diags = resp.State.Set(ctx, state)
@@ -263,8 +386,30 @@ func (r *talosClusterKubeConfigResource) ModifyPlan(ctx context.Context, req res
 		return
 	}
 
-	// if either endpoint or node is unknown return early
+	if !config.ClusterEndpoint.IsUnknown() && !config.ClusterEndpoint.IsNull() {
+		if err := validateClusterEndpoint(config.ClusterEndpoint.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("cluster_endpoint"), "invalid cluster endpoint", err.Error())
+		}
+	}
+
+	for i, endpoint := range config.ControlPlaneEndpoints {
+		if endpoint.IsUnknown() || endpoint.IsNull() {
+			continue
+		}
+
+		if err := validateClusterEndpoint(endpoint.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("control_plane_endpoints").AtListIndex(i), "invalid control plane endpoint", err.Error())
+		}
+	}
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// if either endpoint or node is unknown return early, deferring the creation if Terraform allows it
 	if config.Endpoint.IsUnknown() || config.Node.IsUnknown() {
+		deferCreate(req, resp)
+
 		return
 	}
 
@@ -443,12 +588,7 @@ func (r *talosClusterKubeConfigResource) Update(ctx context.Context, req resourc
 	if x509Cert.NotAfter.Before(OverridableTimeFunc().AddDate(0, 1, 0)) {
 		tflog.Info(ctx, "kubernetes client certificate expires in a month, regenerating")
 
-		talosConfig, err := talosClientTFConfigToTalosClientConfig(
-			"dynamic",
-			state.ClientConfiguration.CA.ValueString(),
-			state.ClientConfiguration.Cert.ValueString(),
-			state.ClientConfiguration.Key.ValueString(),
-		)
+		talosConfig, err := r.providerData.talosClientConfig("dynamic", state.ClientConfiguration)
 		if err != nil {
 			resp.Diagnostics.AddError("failed to generate talos config", err.Error())
 
@@ -469,8 +609,8 @@ func (r *talosClusterKubeConfigResource) Update(ctx context.Context, req resourc
 		ctxDeadline, cancel := context.WithTimeout(ctx, updateTimeout)
 		defer cancel()
 
-		if retryErr := retry.RetryContext(ctxDeadline, updateTimeout, func() *retry.RetryError {
-			if clientOpErr := talosClientOp(ctx, state.Endpoint.ValueString(), state.Node.ValueString(), talosConfig, func(nodeCtx context.Context, c *client.Client) error {
+		if retryErr := r.providerData.retryContext(ctxDeadline, updateTimeout, func() *retry.RetryError {
+			if clientOpErr := talosClientOpEndpoints(r.providerData.withClientOptions(ctx), clientEndpoints(state.Endpoint, state.Endpoints, state.Port), state.Node.ValueString(), talosConfig, func(nodeCtx context.Context, c *client.Client) error {
 				kubeConfigBytes, clientErr := c.Kubeconfig(nodeCtx)
 				if clientErr != nil {
 					return clientErr
@@ -494,7 +634,16 @@ func (r *talosClusterKubeConfigResource) Update(ctx context.Context, req resourc
 			return
 		}
 
-		kubeConfig, err := clientcmd.Load([]byte(state.KubeConfigRaw.ValueString()))
+		kubeConfigBytes, err := state.kubeConfigOptions().apply([]byte(state.KubeConfigRaw.ValueString()))
+		if err != nil {
+			resp.Diagnostics.AddError("failed to customize kubeconfig", err.Error())
+
+			return
+		}
+
+		state.KubeConfigRaw = basetypes.NewStringValue(string(kubeConfigBytes))
+
+		kubeConfig, err := clientcmd.Load(kubeConfigBytes)
 		if err != nil {
 			resp.Diagnostics.AddError("failed to parse kubeconfig", err.Error())
 
@@ -522,3 +671,129 @@ func (r *talosClusterKubeConfigResource) Update(ctx context.Context, req resourc
 		}
 	}
 }
+
+// kubeConfigOptions customize the kubeconfig retrieved from the node.
+type kubeConfigOptions struct {
+	clusterEndpoint       string
+	controlPlaneEndpoints []string
+	clusterName           string
+	contextName           string
+	userName              string
+	namespace             string
+}
+
+func (s *talosClusterKubeConfigResourceModelV0) kubeConfigOptions() kubeConfigOptions {
+	return kubeConfigOptions{
+		clusterEndpoint:       s.ClusterEndpoint.ValueString(),
+		controlPlaneEndpoints: stringValues(s.ControlPlaneEndpoints),
+		clusterName:           s.ClusterName.ValueString(),
+		contextName:           s.ContextName.ValueString(),
+		userName:              s.UserName.ValueString(),
+		namespace:             s.Namespace.ValueString(),
+	}
+}
+
+// apply returns the kubeconfig with the options applied.
+func (opts kubeConfigOptions) apply(kubeConfigBytes []byte) ([]byte, error) { //nolint:gocyclo,cyclop
+	// the kubeconfig is returned as is if there is nothing to customize
+	if opts.clusterEndpoint == "" && len(opts.controlPlaneEndpoints) == 0 &&
+		opts.clusterName == "" && opts.contextName == "" && opts.userName == "" && opts.namespace == "" {
+		return kubeConfigBytes, nil
+	}
+
+	kubeConfig, err := clientcmd.Load(kubeConfigBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	currentContext, ok := kubeConfig.Contexts[kubeConfig.CurrentContext]
+	if !ok {
+		return nil, fmt.Errorf("context %q not found", kubeConfig.CurrentContext)
+	}
+
+	currentCluster, ok := kubeConfig.Clusters[currentContext.Cluster]
+	if !ok {
+		return nil, fmt.Errorf("cluster %q not found", currentContext.Cluster)
+	}
+
+	currentAuthInfo, ok := kubeConfig.AuthInfos[currentContext.AuthInfo]
+	if !ok {
+		return nil, fmt.Errorf("user %q not found", currentContext.AuthInfo)
+	}
+
+	// the entries of the current context are renamed, so that kubeconfigs of several clusters can be merged without collisions
+	if opts.clusterName != "" {
+		delete(kubeConfig.Clusters, currentContext.Cluster)
+
+		currentContext.Cluster = opts.clusterName
+		kubeConfig.Clusters[opts.clusterName] = currentCluster
+	}
+
+	if opts.userName != "" {
+		delete(kubeConfig.AuthInfos, currentContext.AuthInfo)
+
+		currentContext.AuthInfo = opts.userName
+		kubeConfig.AuthInfos[opts.userName] = currentAuthInfo
+	}
+
+	if opts.contextName != "" {
+		delete(kubeConfig.Contexts, kubeConfig.CurrentContext)
+
+		kubeConfig.CurrentContext = opts.contextName
+		kubeConfig.Contexts[opts.contextName] = currentContext
+	}
+
+	if opts.namespace != "" {
+		currentContext.Namespace = opts.namespace
+	}
+
+	if opts.clusterEndpoint != "" {
+		currentCluster.Server = opts.clusterEndpoint
+	}
+
+	// each control plane endpoint gets its own cluster and context, sharing the credentials of the current context
+	for _, endpoint := range opts.controlPlaneEndpoints {
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the control plane endpoint URL: %w", err)
+		}
+
+		clusterName := currentContext.Cluster + "-" + u.Hostname()
+
+		cluster := currentCluster.DeepCopy()
+		cluster.Server = endpoint
+
+		kubeContext := currentContext.DeepCopy()
+		kubeContext.Cluster = clusterName
+
+		kubeConfig.Clusters[clusterName] = cluster
+		kubeConfig.Contexts[kubeConfig.CurrentContext+"-"+u.Hostname()] = kubeContext
+	}
+
+	return clientcmd.Write(*kubeConfig)
+}
+
+// waitKubernetesAPIReady polls the Kubernetes API server of the kubeconfig until `/readyz` succeeds,
+// the API server is dialed through the proxy dialer in the context, if any.
+func waitKubernetesAPIReady(ctx context.Context, kubeConfig []byte) error {
+	httpClient, host, err := kubernetesHTTPClient(ctx, kubeConfig)
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		err = kubernetesAPIReady(ctx, httpClient, host)
+		if err == nil {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-ticker.C:
+		}
+	}
+}
@@ -5,11 +5,14 @@
 package talos_test
 
 import (
+	"regexp"
 	"testing"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 
 	"github.com/siderolabs/terraform-provider-talos/pkg/talos"
 )
@@ -105,3 +108,197 @@ func testAccTalosClusterKubeconfigResourceConfig(rName string) string {
 
 	return config.render()
 }
+
+func TestAccTalosClusterKubeconfigResourceEndpoints(t *testing.T) {
+	rName := acctest.RandStringFromCharSet(10, acctest.CharSetAlpha)
+
+	resource.ParallelTest(t, resource.TestCase{
+		ExternalProviders: map[string]resource.ExternalProvider{
+			"libvirt": {
+				Source: "dmacvicar/libvirt",
+			},
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccTalosClusterKubeconfigResourceEndpointsConfig(rName),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("talos_cluster_kubeconfig.endpoints", "kubernetes_client_configuration.host", "https://kube.example.com:6443"),
+					resource.TestCheckResourceAttr("talos_cluster_kubeconfig.endpoints", "control_plane_endpoints.#", "1"),
+					resource.TestMatchResourceAttr("talos_cluster_kubeconfig.endpoints", "kubeconfig_raw", regexp.MustCompile(`name: example-admin`)),
+					resource.TestMatchResourceAttr("talos_cluster_kubeconfig.endpoints", "kubeconfig_raw", regexp.MustCompile(`current-context: example`)),
+					resource.TestMatchResourceAttr("talos_cluster_kubeconfig.endpoints", "kubeconfig_raw", regexp.MustCompile(`namespace: kube-system`)),
+				),
+			},
+			// make sure there are no changes
+			{
+				Config:   testAccTalosClusterKubeconfigResourceEndpointsConfig(rName),
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func testAccTalosClusterKubeconfigResourceEndpointsConfig(rName string) string {
+	config := dynamicConfig{
+		Provider:               "talos",
+		ResourceName:           rName,
+		WithApplyConfig:        true,
+		WithBootstrap:          true,
+		WithRetrieveKubeConfig: true,
+	}
+
+	return config.render() + `
+resource "talos_cluster_kubeconfig" "endpoints" {
+  depends_on = [
+    talos_machine_bootstrap.this
+  ]
+  client_configuration    = talos_machine_secrets.this.client_configuration
+  node                    = libvirt_domain.cp.network_interface[0].addresses[0]
+  cluster_endpoint        = "https://kube.example.com:6443"
+  control_plane_endpoints = ["https://${libvirt_domain.cp.network_interface[0].addresses[0]}:6443"]
+  cluster_name            = "example"
+  context_name            = "example"
+  user_name               = "example-admin"
+  namespace               = "kube-system"
+}
+`
+}
+
+func TestKubeConfigOptionsApply(t *testing.T) {
+	t.Parallel()
+
+	kubeConfig := clientcmdapi.NewConfig()
+	kubeConfig.Clusters["talos"] = &clientcmdapi.Cluster{
+		Server:                   "https://cluster.local:6443",
+		CertificateAuthorityData: []byte("ca"),
+	}
+	kubeConfig.AuthInfos["admin@talos"] = &clientcmdapi.AuthInfo{
+		ClientCertificateData: []byte("crt"),
+		ClientKeyData:         []byte("key"),
+	}
+	kubeConfig.Contexts["admin@talos"] = &clientcmdapi.Context{
+		Cluster:  "talos",
+		AuthInfo: "admin@talos",
+	}
+	kubeConfig.CurrentContext = "admin@talos"
+
+	kubeConfigBytes, err := clientcmd.Write(*kubeConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name              string
+		opts              talos.KubeConfigOptions
+		expectedServer    string
+		expectedContext   string
+		expectedUser      string
+		expectedNamespace string
+		expectedContexts  map[string]string
+	}{
+		{
+			name:            "defaults",
+			expectedServer:  "https://cluster.local:6443",
+			expectedContext: "admin@talos",
+			expectedUser:    "admin@talos",
+			expectedContexts: map[string]string{
+				"admin@talos": "https://cluster.local:6443",
+			},
+		},
+		{
+			name: "cluster endpoint",
+			opts: talos.KubeConfigOptions{
+				ClusterEndpoint: "https://lb.example.com:6443",
+			},
+			expectedServer:  "https://lb.example.com:6443",
+			expectedContext: "admin@talos",
+			expectedUser:    "admin@talos",
+			expectedContexts: map[string]string{
+				"admin@talos": "https://lb.example.com:6443",
+			},
+		},
+		{
+			name: "control plane endpoints",
+			opts: talos.KubeConfigOptions{
+				ClusterEndpoint:       "https://lb.example.com:6443",
+				ControlPlaneEndpoints: []string{"https://10.5.0.2:6443", "https://[2001:db8::2]:6443"},
+			},
+			expectedServer:  "https://lb.example.com:6443",
+			expectedContext: "admin@talos",
+			expectedUser:    "admin@talos",
+			expectedContexts: map[string]string{
+				"admin@talos":             "https://lb.example.com:6443",
+				"admin@talos-10.5.0.2":    "https://10.5.0.2:6443",
+				"admin@talos-2001:db8::2": "https://[2001:db8::2]:6443",
+			},
+		},
+		{
+			name: "names and namespace",
+			opts: talos.KubeConfigOptions{
+				ControlPlaneEndpoints: []string{"https://10.5.0.2:6443"},
+				ClusterName:           "prod",
+				ContextName:           "prod",
+				UserName:              "prod-admin",
+				Namespace:             "apps",
+			},
+			expectedServer:    "https://cluster.local:6443",
+			expectedContext:   "prod",
+			expectedUser:      "prod-admin",
+			expectedNamespace: "apps",
+			expectedContexts: map[string]string{
+				"prod":          "https://cluster.local:6443",
+				"prod-10.5.0.2": "https://10.5.0.2:6443",
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			out, err := tt.opts.Apply(kubeConfigBytes)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			cfg, err := clientcmd.Load(out)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if cfg.CurrentContext != tt.expectedContext {
+				t.Errorf("expected current context %q, got %q", tt.expectedContext, cfg.CurrentContext)
+			}
+
+			if len(cfg.Clusters) != len(tt.expectedContexts) || len(cfg.AuthInfos) != 1 {
+				t.Errorf("expected %d clusters and 1 user, got %d clusters and %d users", len(tt.expectedContexts), len(cfg.Clusters), len(cfg.AuthInfos))
+			}
+
+			if server := cfg.Clusters[cfg.Contexts[cfg.CurrentContext].Cluster].Server; server != tt.expectedServer {
+				t.Errorf("expected server %q, got %q", tt.expectedServer, server)
+			}
+
+			if len(cfg.Contexts) != len(tt.expectedContexts) {
+				t.Fatalf("expected %d contexts, got %d", len(tt.expectedContexts), len(cfg.Contexts))
+			}
+
+			for name, server := range tt.expectedContexts {
+				kubeContext, ok := cfg.Contexts[name]
+				if !ok {
+					t.Fatalf("expected context %q", name)
+				}
+
+				if kubeContext.AuthInfo != tt.expectedUser {
+					t.Errorf("expected context %q user %q, got %q", name, tt.expectedUser, kubeContext.AuthInfo)
+				}
+
+				if kubeContext.Namespace != tt.expectedNamespace {
+					t.Errorf("expected context %q namespace %q, got %q", name, tt.expectedNamespace, kubeContext.Namespace)
+				}
+
+				if cfg.Clusters[kubeContext.Cluster].Server != server {
+					t.Errorf("expected context %q server %q, got %q", name, server, cfg.Clusters[kubeContext.Cluster].Server)
+				}
+			}
+		})
+	}
+}
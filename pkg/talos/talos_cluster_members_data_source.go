@@ -0,0 +1,277 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/cosi-project/runtime/pkg/safe"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
+	"github.com/siderolabs/talos/pkg/machinery/client"
+	"github.com/siderolabs/talos/pkg/machinery/resources/cluster"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var talosVersionRegexp = regexp.MustCompile(`\((v[^)]+)\)`)
+
+type talosClusterMembersDataSource struct {
+	providerData *talosProviderData
+}
+
+type talosClusterMembersDataSourceModelV0 struct { //nolint:govet
+	ID                  types.String         `tfsdk:"id"`
+	Node                types.String         `tfsdk:"node"`
+	Endpoint            types.String         `tfsdk:"endpoint"`
+	ClientConfiguration *clientConfiguration `tfsdk:"client_configuration"`
+	MachineType         types.String         `tfsdk:"machine_type"`
+	Members             []talosClusterMember `tfsdk:"members"`
+	Timeouts            timeouts.Value       `tfsdk:"timeouts"`
+}
+
+type talosClusterMember struct {
+	ID              types.String   `tfsdk:"id"`
+	Hostname        types.String   `tfsdk:"hostname"`
+	MachineType     types.String   `tfsdk:"machine_type"`
+	Addresses       []types.String `tfsdk:"addresses"`
+	OperatingSystem types.String   `tfsdk:"operating_system"`
+	TalosVersion    types.String   `tfsdk:"talos_version"`
+}
+
+var (
+	_ datasource.DataSource              = &talosClusterMembersDataSource{}
+	_ datasource.DataSourceWithConfigure = &talosClusterMembersDataSource{}
+)
+
+// NewTalosClusterMembersDataSource implements the datasource.DataSource interface.
+func NewTalosClusterMembersDataSource() datasource.DataSource {
+	return &talosClusterMembersDataSource{}
+}
+
+func (d *talosClusterMembersDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_cluster_members"
+}
+
+func (d *talosClusterMembersDataSource) Schema(ctx context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Retrieves the members of a Talos cluster as discovered by the cluster discovery",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The generated ID of this resource",
+				Computed:    true,
+			},
+			"node": schema.StringAttribute{
+				Required:    true,
+				Description: "node to retrieve the cluster members from",
+			},
+			"endpoint": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "endpoint to use for the talosclient. If not set, the node value will be used",
+			},
+			"client_configuration": schema.SingleNestedAttribute{
+				Attributes: map[string]schema.Attribute{
+					"ca_certificate": schema.StringAttribute{
+						Required:    true,
+						Description: "The client CA certificate",
+					},
+					"client_certificate": schema.StringAttribute{
+						Required:    true,
+						Description: "The client certificate",
+					},
+					"client_key": schema.StringAttribute{
+						Required:    true,
+						Sensitive:   true,
+						Description: "The client key",
+					},
+				},
+				Optional:    true,
+				Description: "The client configuration data, defaults to the credentials of the provider `talosconfig_path`",
+			},
+			"machine_type": schema.StringAttribute{
+				Optional:    true,
+				Description: "Only return the members of the given machine type",
+				Validators: []validator.String{
+					stringvalidator.OneOf("controlplane", "worker"),
+				},
+			},
+			"members": schema.ListNestedAttribute{
+				Description: "The discovered cluster members",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							Description: "The ID of the member",
+							Computed:    true,
+						},
+						"hostname": schema.StringAttribute{
+							Description: "The hostname of the member",
+							Computed:    true,
+						},
+						"machine_type": schema.StringAttribute{
+							Description: "The machine type of the member",
+							Computed:    true,
+						},
+						"addresses": schema.ListAttribute{
+							Description: "The addresses of the member",
+							ElementType: types.StringType,
+							Computed:    true,
+						},
+						"operating_system": schema.StringAttribute{
+							Description: "The operating system of the member as reported by the member",
+							Computed:    true,
+						},
+						"talos_version": schema.StringAttribute{
+							Description: "The Talos version of the member, empty if it can't be determined",
+							Computed:    true,
+						},
+					},
+				},
+				Computed: true,
+			},
+			"timeouts": timeouts.Attributes(ctx, timeouts.Opts{
+				Read: true,
+			}),
+		},
+	}
+}
+
+func (d *talosClusterMembersDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*talosProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"failed to get provider data",
+			fmt.Sprintf("Expected *talosProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.providerData = providerData
+}
+
+func (d *talosClusterMembersDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var obj types.Object
+
+	diags := req.Config.Get(ctx, &obj)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var state talosClusterMembersDataSourceModelV0
+	diags = obj.As(ctx, &state, basetypes.ObjectAsOptions{
+		UnhandledNullAsEmpty:    true,
+		UnhandledUnknownAsEmpty: true,
+	})
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	talosConfig, err := d.providerData.talosClientConfig("dynamic", state.ClientConfiguration)
+	if err != nil {
+		resp.Diagnostics.AddError("failed to generate talos config", err.Error())
+
+		return
+	}
+
+	if state.Endpoint.IsNull() {
+		state.Endpoint = state.Node
+	}
+
+	if d.providerData.deferRead(ctx, req, resp, state.Endpoint) {
+		return
+	}
+
+	readTimeout, diags := state.Timeouts.Read(ctx, 10*time.Minute)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctxDeadline, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
+	if err := d.providerData.retryContext(ctxDeadline, readTimeout, func() *retry.RetryError {
+		if err := talosClientOp(d.providerData.withClientOptions(ctx), state.Endpoint.ValueString(), state.Node.ValueString(), talosConfig, func(nodeCtx context.Context, c *client.Client) error {
+			members, err := safe.StateListAll[*cluster.Member](nodeCtx, c.COSI)
+			if err != nil {
+				return err
+			}
+
+			state.Members = []talosClusterMember{}
+
+			for it := members.Iterator(); it.Next(); {
+				member := it.Value()
+				spec := member.TypedSpec()
+
+				if !state.MachineType.IsNull() && state.MachineType.ValueString() != spec.MachineType.String() {
+					continue
+				}
+
+				addresses := make([]types.String, 0, len(spec.Addresses))
+
+				for _, address := range spec.Addresses {
+					addresses = append(addresses, basetypes.NewStringValue(address.String()))
+				}
+
+				var talosVersion string
+
+				if matches := talosVersionRegexp.FindStringSubmatch(spec.OperatingSystem); matches != nil {
+					talosVersion = matches[1]
+				}
+
+				state.Members = append(state.Members, talosClusterMember{
+					ID:              basetypes.NewStringValue(member.Metadata().ID()),
+					Hostname:        basetypes.NewStringValue(spec.Hostname),
+					MachineType:     basetypes.NewStringValue(spec.MachineType.String()),
+					Addresses:       addresses,
+					OperatingSystem: basetypes.NewStringValue(spec.OperatingSystem),
+					TalosVersion:    basetypes.NewStringValue(talosVersion),
+				})
+			}
+
+			return nil
+		}); err != nil {
+			if s := status.Code(err); s == codes.InvalidArgument {
+				return retry.NonRetryableError(err)
+			}
+
+			return retry.RetryableError(err)
+		}
+
+		return nil
+	}); err != nil {
+		resp.Diagnostics.AddError("failed to get list of cluster members", err.Error())
+
+		return
+	}
+
+	state.ID = basetypes.NewStringValue("cluster_members")
+
+	diags = resp.State.Set(ctx, state)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
@@ -0,0 +1,58 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos_test
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/siderolabs/talos/pkg/machinery/gendata"
+)
+
+func TestAccTalosClusterMembersDataSource(t *testing.T) {
+	rName := acctest.RandStringFromCharSet(10, acctest.CharSetAlpha)
+
+	resource.ParallelTest(t, resource.TestCase{
+		ExternalProviders: map[string]resource.ExternalProvider{
+			"libvirt": {
+				Source: "dmacvicar/libvirt",
+			},
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccTalosClusterMembersDataSourceConfig("talos", rName),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.talos_cluster_members.this", "id", "cluster_members"),
+					resource.TestCheckResourceAttr("data.talos_cluster_members.this", "members.#", "1"),
+					resource.TestCheckResourceAttr("data.talos_cluster_members.this", "members.0.machine_type", "controlplane"),
+					resource.TestCheckResourceAttr("data.talos_cluster_members.this", "members.0.talos_version", gendata.VersionTag),
+					resource.TestCheckResourceAttrPair("data.talos_cluster_members.this", "members.0.addresses.0", "libvirt_domain.cp", "network_interface.0.addresses.0"),
+				),
+			},
+		},
+	})
+}
+
+func testAccTalosClusterMembersDataSourceConfig(providerName, rName string) string {
+	config := dynamicConfig{
+		Provider:        providerName,
+		ResourceName:    rName,
+		WithApplyConfig: true,
+		WithBootstrap:   true,
+	}
+
+	return config.render() + `
+data "talos_cluster_members" "this" {
+  depends_on = [
+    talos_machine_bootstrap.this
+  ]
+  client_configuration = talos_machine_secrets.this.client_configuration
+  node                 = libvirt_domain.cp.network_interface[0].addresses[0]
+  machine_type         = "controlplane"
+}
+`
+}
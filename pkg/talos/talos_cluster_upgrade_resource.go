@@ -0,0 +1,420 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/blang/semver/v4"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64default"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/siderolabs/talos/cmd/talosctl/pkg/talos/action"
+	"github.com/siderolabs/talos/pkg/machinery/client"
+	clientconfig "github.com/siderolabs/talos/pkg/machinery/client/config"
+)
+
+type talosClusterUpgradeResource struct {
+	providerData *talosProviderData
+}
+
+var (
+	_ resource.Resource              = &talosClusterUpgradeResource{}
+	_ resource.ResourceWithConfigure = &talosClusterUpgradeResource{}
+)
+
+type talosClusterUpgradeResourceModelV0 struct {
+	ID                  types.String         `tfsdk:"id"`
+	Nodes               []types.String       `tfsdk:"nodes"`
+	Endpoints           []types.String       `tfsdk:"endpoints"`
+	ClientConfiguration *clientConfiguration `tfsdk:"client_configuration"`
+	Image               types.String         `tfsdk:"image"`
+	MaxUnavailable      types.Int64          `tfsdk:"max_unavailable"`
+	Stage               types.Bool           `tfsdk:"stage"`
+	Force               types.Bool           `tfsdk:"force"`
+	HealthGate          types.Bool           `tfsdk:"health_gate"`
+	Timeouts            timeouts.Value       `tfsdk:"timeouts"`
+}
+
+// NewTalosClusterUpgradeResource implements the resource.Resource interface.
+func NewTalosClusterUpgradeResource() resource.Resource {
+	return &talosClusterUpgradeResource{}
+}
+
+func (r *talosClusterUpgradeResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_cluster_upgrade"
+}
+
+func (r *talosClusterUpgradeResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "The cluster upgrade resource upgrades a set of Talos nodes to an installer image, one batch of nodes at a time in the order of `nodes`. " +
+			"The next batch is upgraded only once the nodes of the previous batch are back and, with `health_gate`, all the nodes of the set are ready. " +
+			"All the nodes are upgraded when the resource is created or `image` changes, only the added nodes are upgraded when `nodes` changes. " +
+			"The nodes already running the Talos version of `image` are skipped, so that a failed upgrade resumes with the nodes left, " +
+			"unless `image` changes to another image of the same version (e.g. with other system extensions).",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:    true,
+				Description: "This is a unique identifier for the upgrade",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"nodes": schema.ListAttribute{
+				ElementType: types.StringType,
+				Required:    true,
+				Description: "The nodes to upgrade, in order",
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+					listvalidator.UniqueValues(),
+				},
+			},
+			"endpoints": schema.ListAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Description: "The endpoints to reach the nodes through, each node is reached directly if not set",
+			},
+			"client_configuration": schema.SingleNestedAttribute{
+				Attributes: map[string]schema.Attribute{
+					"ca_certificate": schema.StringAttribute{
+						Required:    true,
+						Description: "The client CA certificate",
+					},
+					"client_certificate": schema.StringAttribute{
+						Required:    true,
+						Description: "The client certificate",
+					},
+					"client_key": schema.StringAttribute{
+						Required:    true,
+						Sensitive:   true,
+						Description: "The client key",
+					},
+				},
+				Optional:    true,
+				Description: "The client configuration data, defaults to the credentials of the provider `talosconfig_path`",
+			},
+			"image": schema.StringAttribute{
+				Required:    true,
+				Description: "The installer image to upgrade the nodes to, e.g. `ghcr.io/siderolabs/installer:v1.8.0`",
+			},
+			"max_unavailable": schema.Int64Attribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     int64default.StaticInt64(1),
+				Description: "The number of nodes upgraded at once",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"stage": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+				Description: "Stage the upgrade to perform it after a reboot",
+			},
+			"force": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+				Description: "Force the upgrade, skipping the etcd health and member checks",
+			},
+			"health_gate": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(true),
+				Description: "Wait for all the nodes of the set to be ready between batches",
+			},
+			"timeouts": timeouts.Attributes(ctx, timeouts.Opts{
+				Create: true,
+				Update: true,
+			}),
+		},
+	}
+}
+
+func (r *talosClusterUpgradeResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*talosProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"failed to get provider data",
+			fmt.Sprintf("Expected *talosProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.providerData = providerData
+}
+
+func (r *talosClusterUpgradeResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var state talosClusterUpgradeResourceModelV0
+
+	diags := req.Plan.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+
+	if diags.HasError() {
+		return
+	}
+
+	createTimeout, diags := state.Timeouts.Create(ctx, 30*time.Minute)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.upgrade(ctx, &state, stringValues(state.Nodes), upgradeSkipVersion("", state.Image.ValueString()), createTimeout); err != nil {
+		resp.Diagnostics.AddError("Error upgrading nodes", err.Error())
+
+		return
+	}
+
+	state.ID = basetypes.NewStringValue("cluster_upgrade")
+
+	// Set state to fully populated data
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r *talosClusterUpgradeResource) Read(_ context.Context, _ resource.ReadRequest, _ *resource.ReadResponse) {
+}
+
+func (r *talosClusterUpgradeResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var state, priorState talosClusterUpgradeResourceModelV0
+
+	diags := req.Plan.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+
+	if diags.HasError() {
+		return
+	}
+
+	diags = req.State.Get(ctx, &priorState)
+	resp.Diagnostics.Append(diags...)
+
+	if diags.HasError() {
+		return
+	}
+
+	updateTimeout, diags := state.Timeouts.Update(ctx, 30*time.Minute)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	nodes := nodesToUpgrade(stringValues(priorState.Nodes), stringValues(state.Nodes), priorState.Image.ValueString() != state.Image.ValueString())
+
+	if err := r.upgrade(ctx, &state, nodes, upgradeSkipVersion(priorState.Image.ValueString(), state.Image.ValueString()), updateTimeout); err != nil {
+		resp.Diagnostics.AddError("Error upgrading nodes", err.Error())
+
+		return
+	}
+
+	// Set state to fully populated data
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r *talosClusterUpgradeResource) Delete(_ context.Context, _ resource.DeleteRequest, _ *resource.DeleteResponse) {
+}
+
+// upgrade upgrades the nodes batch by batch, stopping at the first batch with a failed node.
+// The nodes already running skipVersion aren't upgraded again, no node is skipped if it is empty.
+func (r *talosClusterUpgradeResource) upgrade(ctx context.Context, state *talosClusterUpgradeResourceModelV0, nodes []string, skipVersion string, timeout time.Duration) error {
+	if len(nodes) == 0 {
+		return nil
+	}
+
+	talosClientConfig, err := r.providerData.talosClientConfig("dynamic", state.ClientConfiguration)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	maxUnavailable := int(state.MaxUnavailable.ValueInt64())
+
+	for i, batch := range upgradeBatches(nodes, maxUnavailable) {
+		if err = runConcurrently(ctx, batch, maxUnavailable, func(ctx context.Context, node string) error {
+			return r.providerData.withSupportBundle(ctx, r.nodeEndpoints(state, node), node, talosClientConfig, r.upgradeNode(ctx, state, talosClientConfig, node, skipVersion))
+		}); err != nil {
+			return fmt.Errorf("failed to upgrade batch %d: %w", i+1, err)
+		}
+
+		if !state.HealthGate.ValueBool() {
+			continue
+		}
+
+		if err = runConcurrently(ctx, stringValues(state.Nodes), defaultHealthCheckConcurrency, func(ctx context.Context, node string) error {
+			return talosClientOpEndpoints(r.providerData.withClientOptions(ctx), r.nodeEndpoints(state, node), node, talosClientConfig, func(nodeCtx context.Context, c *client.Client) error {
+				return waitNodeReady(nodeCtx, c, node)
+			})
+		}); err != nil {
+			return fmt.Errorf("nodes are not ready after batch %d: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+// upgradeNode upgrades the node and waits for it to come back with a new boot ID, unless the node already runs skipVersion.
+func (r *talosClusterUpgradeResource) upgradeNode(ctx context.Context, state *talosClusterUpgradeResourceModelV0, tc *clientconfig.Config, node, skipVersion string) error {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return errors.New("no deadline set for the upgrade")
+	}
+
+	actionFn := func(ctx context.Context, c *client.Client) (string, error) {
+		resp, err := c.UpgradeWithOptions(ctx,
+			client.WithUpgradeImage(state.Image.ValueString()),
+			client.WithUpgradeStage(state.Stage.ValueBool()),
+			client.WithUpgradeForce(state.Force.ValueBool()),
+		)
+		if err != nil {
+			return "", err
+		}
+
+		if len(resp.GetMessages()) == 0 {
+			return "", errors.New("no messages returned from action run")
+		}
+
+		return resp.GetMessages()[0].GetActorId(), nil
+	}
+
+	return talosClientOpEndpoints(r.providerData.withClientOptions(ctx), r.nodeEndpoints(state, node), node, tc, func(nodeCtx context.Context, c *client.Client) error {
+		if skipVersion != "" {
+			version, err := nodeTalosVersion(nodeCtx, c)
+			if err != nil {
+				return err
+			}
+
+			if version == skipVersion {
+				tflog.Info(ctx, "skipping the node already running the target version", map[string]any{"node": node, "version": version})
+
+				return nil
+			}
+		}
+
+		return action.NewTracker(
+			newClientExecutor(c, []string{node}),
+			action.MachineReadyEventFn,
+			actionFn,
+			action.WithPostCheck(action.BootIDChangedPostCheckFn),
+			action.WithDebug(false),
+			action.WithTimeout(time.Until(deadline)),
+		).Run()
+	})
+}
+
+// nodeTalosVersion returns the semantic version of Talos running on the node.
+func nodeTalosVersion(ctx context.Context, c *client.Client) (string, error) {
+	resp, err := c.Version(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if len(resp.GetMessages()) == 0 {
+		return "", errors.New("no messages returned from version request")
+	}
+
+	version, err := semver.ParseTolerant(resp.GetMessages()[0].GetVersion().GetTag())
+	if err != nil {
+		return "", fmt.Errorf("failed to parse the Talos version of the node: %w", err)
+	}
+
+	return version.String(), nil
+}
+
+func (r *talosClusterUpgradeResource) nodeEndpoints(state *talosClusterUpgradeResourceModelV0, node string) []string {
+	if len(state.Endpoints) == 0 {
+		return []string{node}
+	}
+
+	return stringValues(state.Endpoints)
+}
+
+// upgradeBatches splits the nodes into batches of at most maxUnavailable nodes, keeping their order.
+func upgradeBatches(nodes []string, maxUnavailable int) [][]string {
+	return slices.Collect(slices.Chunk(nodes, maxUnavailable))
+}
+
+// installerImageVersion returns the semantic version of the installer image tag, empty if the image isn't tagged with a version.
+func installerImageVersion(image string) string {
+	image, _, _ = strings.Cut(image, "@")
+
+	i := strings.LastIndex(image, ":")
+	if i == -1 || strings.Contains(image[i+1:], "/") {
+		return ""
+	}
+
+	version, err := semver.ParseTolerant(image[i+1:])
+	if err != nil {
+		return ""
+	}
+
+	return version.String()
+}
+
+// upgradeSkipVersion returns the version of the image, the nodes already running it are skipped so that a failed upgrade resumes with the nodes left.
+// It returns an empty version if no node can be skipped: the image isn't tagged with a version, or the image changed to another image of the same version.
+func upgradeSkipVersion(priorImage, image string) string {
+	version := installerImageVersion(image)
+
+	if priorImage != "" && priorImage != image && installerImageVersion(priorImage) == version {
+		return ""
+	}
+
+	return version
+}
+
+// nodesToUpgrade returns all the planned nodes if the image changed, only the added nodes otherwise.
+func nodesToUpgrade(priorNodes, nodes []string, imageChanged bool) []string {
+	if imageChanged {
+		return nodes
+	}
+
+	return slices.DeleteFunc(slices.Clone(nodes), func(node string) bool {
+		return slices.Contains(priorNodes, node)
+	})
+}
+
+func stringValues(values []types.String) []string {
+	result := make([]string, 0, len(values))
+
+	for _, value := range values {
+		result = append(result, value.ValueString())
+	}
+
+	return result
+}
@@ -0,0 +1,167 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos_test
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/siderolabs/talos/pkg/machinery/gendata"
+
+	"github.com/siderolabs/terraform-provider-talos/pkg/talos"
+)
+
+func TestAccTalosClusterUpgradeResource(t *testing.T) {
+	rName := acctest.RandStringFromCharSet(10, acctest.CharSetAlpha)
+
+	resource.ParallelTest(t, resource.TestCase{
+		ExternalProviders: map[string]resource.ExternalProvider{
+			"libvirt": {
+				Source: "dmacvicar/libvirt",
+			},
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// the node already runs the version of the image, so it is skipped
+			{
+				Config: testAccTalosClusterUpgradeResourceConfig("talos", rName, gendata.VersionTag),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("talos_cluster_upgrade.this", "id", "cluster_upgrade"),
+					resource.TestCheckResourceAttr("talos_cluster_upgrade.this", "image", fmt.Sprintf("ghcr.io/siderolabs/installer:%s", gendata.VersionTag)),
+					resource.TestCheckResourceAttr("talos_cluster_upgrade.this", "max_unavailable", "1"),
+				),
+			},
+			// make sure there are no changes
+			{
+				Config:   testAccTalosClusterUpgradeResourceConfig("talos", rName, gendata.VersionTag),
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func testAccTalosClusterUpgradeResourceConfig(providerName, rName, version string) string {
+	config := dynamicConfig{
+		Provider:          providerName,
+		ResourceName:      rName,
+		WithApplyConfig:   true,
+		WithBootstrap:     true,
+		WithClusterHealth: true,
+	}
+
+	return config.render() + fmt.Sprintf(`
+resource "talos_cluster_upgrade" "this" {
+  depends_on = [
+    data.talos_cluster_health.this
+  ]
+  client_configuration = talos_machine_secrets.this.client_configuration
+  nodes                = libvirt_domain.cp.network_interface[0].addresses
+  image                = "ghcr.io/siderolabs/installer:%s"
+}
+`, version)
+}
+
+func TestNodesToUpgrade(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name           string
+		priorNodes     []string
+		nodes          []string
+		imageChanged   bool
+		maxUnavailable int
+		batches        [][]string
+	}{
+		{
+			name:           "image changed",
+			priorNodes:     []string{"10.5.0.2", "10.5.0.3", "10.5.0.4"},
+			nodes:          []string{"10.5.0.2", "10.5.0.3", "10.5.0.4"},
+			imageChanged:   true,
+			maxUnavailable: 2,
+			batches:        [][]string{{"10.5.0.2", "10.5.0.3"}, {"10.5.0.4"}},
+		},
+		{
+			name:           "nodes added",
+			priorNodes:     []string{"10.5.0.2", "10.5.0.3"},
+			nodes:          []string{"10.5.0.2", "10.5.0.5", "10.5.0.3", "10.5.0.4"},
+			maxUnavailable: 1,
+			batches:        [][]string{{"10.5.0.5"}, {"10.5.0.4"}},
+		},
+		{
+			name:           "nodes removed",
+			priorNodes:     []string{"10.5.0.2", "10.5.0.3"},
+			nodes:          []string{"10.5.0.2"},
+			maxUnavailable: 1,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			batches := talos.UpgradeBatches(talos.NodesToUpgrade(tt.priorNodes, tt.nodes, tt.imageChanged), tt.maxUnavailable)
+
+			if !slices.EqualFunc(batches, tt.batches, slices.Equal) {
+				t.Errorf("expected batches %v, got %v", tt.batches, batches)
+			}
+		})
+	}
+}
+
+func TestUpgradeSkipVersion(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name        string
+		priorImage  string
+		image       string
+		skipVersion string
+	}{
+		{
+			name:        "create",
+			image:       "ghcr.io/siderolabs/installer:v1.8.0",
+			skipVersion: "1.8.0",
+		},
+		{
+			name:        "version changed",
+			priorImage:  "ghcr.io/siderolabs/installer:v1.7.6",
+			image:       "ghcr.io/siderolabs/installer:v1.8.0",
+			skipVersion: "1.8.0",
+		},
+		{
+			name:        "same image",
+			priorImage:  "ghcr.io/siderolabs/installer:v1.8.0",
+			image:       "ghcr.io/siderolabs/installer:v1.8.0",
+			skipVersion: "1.8.0",
+		},
+		{
+			name:       "schematic changed",
+			priorImage: "factory.talos.dev/installer/376567988ad370138ad8b2698212367b8edcb69b5fd68c80be1f2ec7d603b4ba:v1.8.0",
+			image:      "factory.talos.dev/installer/ce4c980550dd2ab1b17bbf2b08801c7eb59418eafe8f279833297925d67c7515:v1.8.0",
+		},
+		{
+			name:        "digest",
+			image:       "ghcr.io/siderolabs/installer:v1.8.0-beta.0@sha256:4a5d1a6a9f1a3f1fbc3dbd4bf39e8f1dcf6e1c1c8ae1f1fa3b1c9f0c9b9a3e1d",
+			skipVersion: "1.8.0-beta.0",
+		},
+		{
+			name:  "registry port without tag",
+			image: "registry.local:5000/siderolabs/installer",
+		},
+		{
+			name:  "not a version",
+			image: "ghcr.io/siderolabs/installer:latest",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if skipVersion := talos.UpgradeSkipVersion(tt.priorImage, tt.image); skipVersion != tt.skipVersion {
+				t.Errorf("expected skip version %q, got %q", tt.skipVersion, skipVersion)
+			}
+		})
+	}
+}
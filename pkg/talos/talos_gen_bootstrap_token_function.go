@@ -0,0 +1,92 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+type talosGenBootstrapTokenFunction struct{}
+
+var _ function.Function = &talosGenBootstrapTokenFunction{}
+
+var bootstrapTokenAttributeTypes = map[string]attr.Type{
+	"id":     types.StringType,
+	"secret": types.StringType,
+	"token":  types.StringType,
+}
+
+// NewTalosGenBootstrapTokenFunction implements the function.Function interface.
+func NewTalosGenBootstrapTokenFunction() function.Function {
+	return &talosGenBootstrapTokenFunction{}
+}
+
+func (f *talosGenBootstrapTokenFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "gen_bootstrap_token"
+}
+
+func (f *talosGenBootstrapTokenFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Generates a Kubernetes bootstrap token",
+		Description: "Generates a Kubernetes bootstrap token in the `[a-z0-9]{6}.[a-z0-9]{16}` format, returned as an object with the `token`, its `id` and its `secret`, " +
+			"e.g. to register the token as a `bootstrap-token-<id>` secret in Kubernetes. " +
+			"Provider functions can't be random, so the token is derived from the seed, the same seed always generates the same token. " +
+			"The seed should be a secret random value (e.g. `random_password.this.result`), a distinct seed should be used for every token.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "seed",
+				Description: fmt.Sprintf("The secret value to derive the token from, at least %d characters long", minTokenSeedLength),
+			},
+		},
+		Return: function.ObjectReturn{
+			AttributeTypes: bootstrapTokenAttributeTypes,
+		},
+	}
+}
+
+func (f *talosGenBootstrapTokenFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var seed string
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &seed))
+
+	if resp.Error != nil {
+		return
+	}
+
+	if len(seed) < minTokenSeedLength {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("seed must be at least %d characters long", minTokenSeedLength))
+
+		return
+	}
+
+	token, err := deriveBootstrapToken(seed, "bootstrap token")
+	if err != nil {
+		resp.Error = function.NewFuncError(fmt.Sprintf("failed to generate bootstrap token: %s", err))
+
+		return
+	}
+
+	tokenID, tokenSecret, _ := strings.Cut(token, ".")
+
+	result, diags := types.ObjectValue(bootstrapTokenAttributeTypes, map[string]attr.Value{
+		"id":     types.StringValue(tokenID),
+		"secret": types.StringValue(tokenSecret),
+		"token":  types.StringValue(token),
+	})
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, function.FuncErrorFromDiags(ctx, diags))
+
+	if resp.Error != nil {
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, result))
+}
@@ -0,0 +1,62 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestAccTalosGenBootstrapTokenFunction(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		IsUnitTest:               true, // this is a local only function, so can be unit tested
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: testAccTalosGenBootstrapTokenFunctionConfig("0123456789abcdef"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestMatchOutput("token", regexp.MustCompile(`^[a-z0-9]{6}\.[a-z0-9]{16}$`)),
+					resource.TestMatchOutput("id", regexp.MustCompile(`^[a-z0-9]{6}$`)),
+					resource.TestMatchOutput("secret", regexp.MustCompile(`^[a-z0-9]{16}$`)),
+					resource.TestCheckOutput("consistent", "true"),
+				),
+			},
+			{
+				Config:      testAccTalosGenBootstrapTokenFunctionConfig("too short"),
+				ExpectError: regexp.MustCompile("seed must be at least 16 characters long"),
+			},
+		},
+	})
+}
+
+func testAccTalosGenBootstrapTokenFunctionConfig(seed string) string {
+	return `
+locals {
+  bootstrap_token = provider::talos::gen_bootstrap_token("` + seed + `")
+}
+
+output "token" {
+  value = local.bootstrap_token.token
+}
+
+output "id" {
+  value = local.bootstrap_token.id
+}
+
+output "secret" {
+  value = local.bootstrap_token.secret
+}
+
+output "consistent" {
+  value = local.bootstrap_token.token == "${local.bootstrap_token.id}.${local.bootstrap_token.secret}"
+}
+`
+}
@@ -0,0 +1,69 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// minTokenSeedLength is the minimum length of the seed the tokens are derived from.
+const minTokenSeedLength = 16
+
+type talosGenTokenFunction struct{}
+
+var _ function.Function = &talosGenTokenFunction{}
+
+// NewTalosGenTokenFunction implements the function.Function interface.
+func NewTalosGenTokenFunction() function.Function {
+	return &talosGenTokenFunction{}
+}
+
+func (f *talosGenTokenFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "gen_token"
+}
+
+func (f *talosGenTokenFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Generates a Talos token",
+		Description: "Generates a token in the `[a-z0-9]{6}.[a-z0-9]{16}` format of the trustd token (`machine.token`) and the Kubernetes bootstrap token (`cluster.token`). " +
+			"Provider functions can't be random, so the token is derived from the seed, the same seed always generates the same token. " +
+			"The seed should be a secret random value (e.g. `random_password.this.result`), a distinct seed should be used for every token.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "seed",
+				Description: fmt.Sprintf("The secret value to derive the token from, at least %d characters long", minTokenSeedLength),
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *talosGenTokenFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var seed string
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &seed))
+
+	if resp.Error != nil {
+		return
+	}
+
+	if len(seed) < minTokenSeedLength {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("seed must be at least %d characters long", minTokenSeedLength))
+
+		return
+	}
+
+	token, err := deriveBootstrapToken(seed, "token")
+	if err != nil {
+		resp.Error = function.NewFuncError(fmt.Sprintf("failed to generate token: %s", err))
+
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, token))
+}
@@ -0,0 +1,57 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestAccTalosGenTokenFunction(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		IsUnitTest:               true, // this is a local only function, so can be unit tested
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: testAccTalosGenTokenFunctionConfig("0123456789abcdef"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckOutput("token", "508o5r.sygofr2vs9te1pqp"),
+					resource.TestCheckResourceAttr("data.talos_machine_configuration.this", "machine_secrets.trustdinfo.token", "508o5r.sygofr2vs9te1pqp"),
+				),
+			},
+			{
+				Config:      testAccTalosGenTokenFunctionConfig("too short"),
+				ExpectError: regexp.MustCompile("seed must be at least 16 characters long"),
+			},
+		},
+	})
+}
+
+func testAccTalosGenTokenFunctionConfig(seed string) string {
+	return `
+resource "talos_machine_secrets" "this" {}
+
+data "talos_machine_configuration" "this" {
+  cluster_name     = "example-cluster"
+  cluster_endpoint = "https://cluster.local:6443"
+  machine_type     = "worker"
+  machine_secrets = merge(talos_machine_secrets.this.machine_secrets, {
+    trustdinfo = {
+      token = provider::talos::gen_token("` + seed + `")
+    }
+  })
+}
+
+output "token" {
+  value = provider::talos::gen_token("` + seed + `")
+}
+`
+}
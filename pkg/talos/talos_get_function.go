@@ -0,0 +1,147 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"gopkg.in/yaml.v3"
+)
+
+type talosGetFunction struct{}
+
+var _ function.Function = &talosGetFunction{}
+
+// NewTalosGetFunction implements the function.Function interface.
+func NewTalosGetFunction() function.Function {
+	return &talosGetFunction{}
+}
+
+func (f *talosGetFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "get"
+}
+
+func (f *talosGetFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Retrieves a value from a machine configuration",
+		Description: "Retrieves the value at the given dot-separated path (e.g. `machine.network.hostname`, list elements are addressed by their index) from a machine configuration. " +
+			"For multi-document machine configurations the documents are searched in order and the first match is returned. " +
+			"Scalar values are returned as is, maps and lists are returned encoded as YAML.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "machine_configuration",
+				Description: "The machine configuration to retrieve the value from",
+			},
+			function.StringParameter{
+				Name:        "path",
+				Description: "The dot-separated path of the value to retrieve",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *talosGetFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var machineConfiguration, valuePath string
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &machineConfiguration, &valuePath))
+
+	if resp.Error != nil {
+		return
+	}
+
+	if valuePath == "" {
+		resp.Error = function.NewArgumentFuncError(1, "path must not be empty")
+
+		return
+	}
+
+	decoder := yaml.NewDecoder(strings.NewReader(machineConfiguration))
+
+	for {
+		var document any
+
+		if err := decoder.Decode(&document); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("failed to decode machine configuration: %s", err))
+
+			return
+		}
+
+		value, ok := lookupPath(document, strings.Split(valuePath, "."))
+		if !ok {
+			continue
+		}
+
+		result, err := valueToString(value)
+		if err != nil {
+			resp.Error = function.NewFuncError(fmt.Sprintf("failed to encode value: %s", err))
+
+			return
+		}
+
+		resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, result))
+
+		return
+	}
+
+	resp.Error = function.NewArgumentFuncError(1, fmt.Sprintf("path %q not found in machine configuration", valuePath))
+}
+
+// lookupPath walks the decoded YAML document following the path segments.
+func lookupPath(value any, segments []string) (any, bool) {
+	for _, segment := range segments {
+		switch v := value.(type) {
+		case map[string]any:
+			next, ok := v[segment]
+			if !ok {
+				return nil, false
+			}
+
+			value = next
+		case []any:
+			idx, err := strconv.Atoi(segment)
+			if err != nil || idx < 0 || idx >= len(v) {
+				return nil, false
+			}
+
+			value = v[idx]
+		default:
+			return nil, false
+		}
+	}
+
+	return value, true
+}
+
+func valueToString(value any) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case map[string]any, []any:
+		var buf bytes.Buffer
+
+		encoder := yaml.NewEncoder(&buf)
+		encoder.SetIndent(2)
+
+		if err := encoder.Encode(v); err != nil {
+			return "", err
+		}
+
+		return buf.String(), nil
+	default:
+		return fmt.Sprint(v), nil
+	}
+}
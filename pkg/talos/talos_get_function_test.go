@@ -0,0 +1,60 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestAccTalosGetFunction(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		IsUnitTest:               true, // this is a local only function, so can be unit tested
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: testAccTalosGetFunctionConfig("machine.install.disk"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckOutput("value", "/dev/sda"),
+				),
+			},
+			{
+				Config: testAccTalosGetFunctionConfig("cluster.clusterName"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckOutput("value", "example-cluster"),
+				),
+			},
+			{
+				Config:      testAccTalosGetFunctionConfig("machine.nonexistent"),
+				ExpectError: regexp.MustCompile(`path "machine.nonexistent" not found`),
+			},
+		},
+	})
+}
+
+func testAccTalosGetFunctionConfig(path string) string {
+	return `
+resource "talos_machine_secrets" "this" {}
+
+data "talos_machine_configuration" "this" {
+  cluster_name     = "example-cluster"
+  cluster_endpoint = "https://cluster.local:6443"
+  machine_type     = "controlplane"
+  machine_secrets  = talos_machine_secrets.this.machine_secrets
+}
+
+output "value" {
+  value = provider::talos::get(data.talos_machine_configuration.this.machine_configuration, "` + path + `")
+
+  sensitive = true
+}
+`
+}
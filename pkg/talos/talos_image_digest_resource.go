@@ -0,0 +1,219 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+)
+
+type talosImageDigestResource struct{}
+
+var (
+	_ resource.Resource                   = &talosImageDigestResource{}
+	_ resource.ResourceWithValidateConfig = &talosImageDigestResource{}
+)
+
+type talosImageDigestResourceModelV0 struct {
+	ID          types.String `tfsdk:"id"`
+	Image       types.String `tfsdk:"image"`
+	FailOnRetag types.Bool   `tfsdk:"fail_on_retag"`
+	Digest      types.String `tfsdk:"digest"`
+	PinnedImage types.String `tfsdk:"pinned_image"`
+}
+
+// NewTalosImageDigestResource implements the resource.Resource interface.
+func NewTalosImageDigestResource() resource.Resource {
+	return &talosImageDigestResource{}
+}
+
+func (r *talosImageDigestResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_image_digest"
+}
+
+func (r *talosImageDigestResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "The image digest resource resolves a tag based image reference (e.g. an installer or a system extension image) to its digest and records it, " +
+			"so that the pinned image reference can be used in the machine configuration instead of a mutable tag.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:    true,
+				Description: "The ID of this resource",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"image": schema.StringAttribute{
+				Required:    true,
+				Description: "The image reference to resolve",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"fail_on_retag": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+				Description: "Fail on refresh if the image tag now resolves to a different digest than the recorded one. If false, the recorded digest is updated instead and a warning is emitted",
+			},
+			"digest": schema.StringAttribute{
+				Computed:    true,
+				Description: "The resolved digest of the image",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"pinned_image": schema.StringAttribute{
+				Computed:    true,
+				Description: "The image reference pinned to the resolved digest",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+func (r *talosImageDigestResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan talosImageDigestResourceModelV0
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	digest, pinnedImage, err := resolveImageDigest(ctx, plan.Image.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("failed to resolve image digest", err.Error())
+
+		return
+	}
+
+	plan.ID = plan.Image
+	plan.Digest = basetypes.NewStringValue(digest)
+	plan.PinnedImage = basetypes.NewStringValue(pinnedImage)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r *talosImageDigestResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state talosImageDigestResourceModelV0
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	digest, pinnedImage, err := resolveImageDigest(ctx, state.Image.ValueString())
+	if err != nil {
+		// the registry being unreachable should not break the refresh, keep the recorded digest
+		resp.Diagnostics.AddWarning("failed to resolve image digest", err.Error())
+
+		return
+	}
+
+	if digest == state.Digest.ValueString() {
+		return
+	}
+
+	if state.FailOnRetag.ValueBool() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("digest"),
+			"image was retagged",
+			fmt.Sprintf("image %s resolved to %s, but now resolves to %s. Replace the resource to accept the new digest", state.Image.ValueString(), state.Digest.ValueString(), digest),
+		)
+
+		return
+	}
+
+	resp.Diagnostics.AddAttributeWarning(
+		path.Root("digest"),
+		"image was retagged",
+		fmt.Sprintf("image %s resolved to %s, but now resolves to %s. The recorded digest is updated", state.Image.ValueString(), state.Digest.ValueString(), digest),
+	)
+
+	state.Digest = basetypes.NewStringValue(digest)
+	state.PinnedImage = basetypes.NewStringValue(pinnedImage)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r *talosImageDigestResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan talosImageDigestResourceModelV0
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Set state to fully populated data
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r *talosImageDigestResource) Delete(_ context.Context, _ resource.DeleteRequest, _ *resource.DeleteResponse) {
+}
+
+func (r *talosImageDigestResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var config talosImageDigestResourceModelV0
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if config.Image.IsUnknown() || config.Image.IsNull() {
+		return
+	}
+
+	if _, err := name.ParseReference(config.Image.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("image"),
+			"image is invalid",
+			err.Error(),
+		)
+	}
+}
+
+// resolveImageDigest returns the digest the image reference currently resolves to and the image reference pinned to that digest.
+func resolveImageDigest(ctx context.Context, image string) (string, string, error) {
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		return "", "", err
+	}
+
+	digest, err := crane.Digest(image, crane.WithContext(ctx))
+	if err != nil {
+		return "", "", err
+	}
+
+	return digest, ref.Context().Name() + "@" + digest, nil
+}
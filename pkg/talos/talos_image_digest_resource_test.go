@@ -0,0 +1,45 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos_test
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/siderolabs/talos/pkg/machinery/gendata"
+)
+
+func TestAccTalosImageDigestResource(t *testing.T) {
+	resource.ParallelTest(t, resource.TestCase{
+		IsUnitTest:               true, // this is a local only resource, so can be unit tested
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// invalid image references are rejected at plan time
+			{
+				Config:      testAccTalosImageDigestResourceConfig("ghcr.io/siderolabs/installer:Invalid Tag"),
+				ExpectError: regexp.MustCompile("image is invalid"),
+			},
+			{
+				Config: testAccTalosImageDigestResourceConfig(fmt.Sprintf("ghcr.io/siderolabs/installer:%s", gendata.VersionTag)),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("talos_image_digest.this", "fail_on_retag", "true"),
+					resource.TestMatchResourceAttr("talos_image_digest.this", "digest", regexp.MustCompile(`^sha256:[0-9a-f]{64}$`)),
+					resource.TestMatchResourceAttr("talos_image_digest.this", "pinned_image", regexp.MustCompile(`^ghcr.io/siderolabs/installer@sha256:[0-9a-f]{64}$`)),
+				),
+			},
+		},
+	})
+}
+
+func testAccTalosImageDigestResourceConfig(image string) string {
+	return fmt.Sprintf(`
+resource "talos_image_digest" "this" {
+  image         = "%s"
+  fail_on_retag = true
+}
+`, image)
+}
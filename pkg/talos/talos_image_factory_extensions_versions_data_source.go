@@ -7,6 +7,7 @@ package talos
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/hashicorp/terraform-plugin-framework/attr"
@@ -31,6 +32,7 @@ type talosImageFactoryExtensionsVersionsDataSourceModelV0 struct {
 
 type talosImageFactoryExtensionsVersionsFilter struct {
 	Names types.List `tfsdk:"names"`
+	Exact types.Bool `tfsdk:"exact"`
 }
 
 type extensionInfo struct {
@@ -72,6 +74,10 @@ func (d *talosImageFactoryExtensionsVersionsDataSource) Schema(_ context.Context
 						Optional:    true,
 						Description: "The name of the extension to filter by.",
 					},
+					"exact": schema.BoolAttribute{
+						Optional:    true,
+						Description: "Match the extension names exactly (with or without the `siderolabs/` prefix) instead of by substring. Fails if any of the names is not available for the specified talos version. Defaults to false.",
+					},
 				},
 			},
 			"extensions_info": schema.ListAttribute{
@@ -96,17 +102,17 @@ func (d *talosImageFactoryExtensionsVersionsDataSource) Configure(_ context.Cont
 		return
 	}
 
-	imageFactoryClient, ok := req.ProviderData.(*client.Client)
+	providerData, ok := req.ProviderData.(*talosProviderData)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"failed to get image factory client",
-			fmt.Sprintf("Expected *client.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *talosProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 
 		return
 	}
 
-	d.imageFactoryClient = imageFactoryClient
+	d.imageFactoryClient = providerData.imageFactoryClient
 }
 
 func (d *talosImageFactoryExtensionsVersionsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
@@ -144,9 +150,33 @@ func (d *talosImageFactoryExtensionsVersionsDataSource) Read(ctx context.Context
 			return
 		}
 
+		if config.Filters.Exact.ValueBool() {
+			var missing []string
+
+			for _, n := range names {
+				if !slices.ContainsFunc(extensionsInfo, func(e client.ExtensionInfo) bool { return extensionNameMatches(e.Name, n) }) {
+					missing = append(missing, n)
+				}
+			}
+
+			if len(missing) > 0 {
+				resp.Diagnostics.AddAttributeError(
+					path.Root("filters").AtName("names"),
+					"extensions not found",
+					fmt.Sprintf("extensions %s are not available for talos version %s", strings.Join(missing, ", "), config.TalosVersion.ValueString()),
+				)
+
+				return
+			}
+		}
+
 		extensionsInfo = xslices.Filter(extensionsInfo, func(e client.ExtensionInfo) bool {
 			for _, n := range names {
-				if strings.Contains(e.Name, n) {
+				if config.Filters.Exact.ValueBool() && extensionNameMatches(e.Name, n) {
+					return true
+				}
+
+				if !config.Filters.Exact.ValueBool() && strings.Contains(e.Name, n) {
 					return true
 				}
 			}
@@ -177,3 +207,14 @@ func (d *talosImageFactoryExtensionsVersionsDataSource) Read(ctx context.Context
 		return
 	}
 }
+
+// extensionNameMatches checks if the extension name matches the given name, the name can be specified with or without the extension author prefix.
+func extensionNameMatches(extensionName, name string) bool {
+	if extensionName == name {
+		return true
+	}
+
+	_, shortName, ok := strings.Cut(extensionName, "/")
+
+	return ok && shortName == name
+}
@@ -5,6 +5,8 @@
 package talos_test
 
 import (
+	"fmt"
+	"regexp"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
@@ -28,6 +30,18 @@ func TestAccTalosImageFactoryExtensionsVersionsDataSource(t *testing.T) {
 					resource.TestCheckResourceAttr("data.talos_image_factory_extensions_versions.this", "extensions_info.0.name", "siderolabs/nvidia-container-toolkit"),
 				),
 			},
+			{
+				Config: testAccTalosImageFactoryExtensionsVersionsDataSourceConfigWithExactFilters("iscsi-tools"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.talos_image_factory_extensions_versions.this", "extensions_info.#", "2"),
+					resource.TestCheckResourceAttr("data.talos_image_factory_extensions_versions.this", "extensions_info.0.name", "siderolabs/intel-ucode"),
+					resource.TestCheckResourceAttr("data.talos_image_factory_extensions_versions.this", "extensions_info.1.name", "siderolabs/iscsi-tools"),
+				),
+			},
+			{
+				Config:      testAccTalosImageFactoryExtensionsVersionsDataSourceConfigWithExactFilters("iscsi-tool"),
+				ExpectError: regexp.MustCompile("extensions iscsi-tool are not available for talos version v1.7.0"),
+			},
 		},
 	})
 }
@@ -57,3 +71,20 @@ data "talos_image_factory_extensions_versions" "this" {
 }
 `
 }
+
+func testAccTalosImageFactoryExtensionsVersionsDataSourceConfigWithExactFilters(name string) string {
+	return fmt.Sprintf(`
+provider "talos" {}
+
+data "talos_image_factory_extensions_versions" "this" {
+	talos_version = "v1.7.0"
+	filters = {
+		names = [
+			"siderolabs/intel-ucode",
+			"%s"
+		]
+		exact = true
+	}
+}
+`, name)
+}
@@ -0,0 +1,215 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/siderolabs/gen/xslices"
+	"github.com/siderolabs/image-factory/pkg/client"
+	"github.com/siderolabs/image-factory/pkg/schematic"
+)
+
+type talosImageFactoryInstallerImageDataSource struct {
+	imageFactoryClient *client.Client
+}
+
+var _ datasource.DataSourceWithConfigure = &talosImageFactoryInstallerImageDataSource{}
+
+type talosImageFactoryInstallerImageDataSourceModelV0 struct {
+	ID           types.String   `tfsdk:"id"`
+	TalosVersion types.String   `tfsdk:"talos_version"`
+	SchematicID  types.String   `tfsdk:"schematic_id"`
+	Extensions   []types.String `tfsdk:"extensions"`
+	SecureBoot   types.Bool     `tfsdk:"secure_boot"`
+	Image        types.String   `tfsdk:"image"`
+}
+
+// NewTalosImageFactoryInstallerImageDataSource implements the datasource.DataSource interface.
+func NewTalosImageFactoryInstallerImageDataSource() datasource.DataSource {
+	return &talosImageFactoryInstallerImageDataSource{}
+}
+
+func (d *talosImageFactoryInstallerImageDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_image_factory_installer_image"
+}
+
+func (d *talosImageFactoryInstallerImageDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "The image factory installer image data source resolves the installer image reference to set as `machine.install.image`. " +
+			"The Talos version and the extensions are checked against the image factory, so that a typo fails the plan instead of the node install.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+			},
+			"talos_version": schema.StringAttribute{
+				Required:    true,
+				Description: "The Talos version of the installer image.",
+			},
+			"schematic_id": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "The schematic ID of the installer image. Defaults to the schematic of `extensions`, or to the vanilla Talos schematic if neither is set.",
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(regexp.MustCompile(`^[0-9a-f]{64}$`), "must be a 64 characters hexadecimal schematic ID"),
+					stringvalidator.ConflictsWith(path.MatchRoot("extensions")),
+				},
+			},
+			"extensions": schema.ListAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Description: "The official system extensions to install, the schematic is registered with the image factory.",
+				Validators: []validator.List{
+					listvalidator.UniqueValues(),
+				},
+			},
+			"secure_boot": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Resolve the SecureBoot installer image.",
+			},
+			"image": schema.StringAttribute{
+				Computed:    true,
+				Description: "The installer image reference.",
+			},
+		},
+	}
+}
+
+func (d *talosImageFactoryInstallerImageDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*talosProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"failed to get image factory client",
+			fmt.Sprintf("Expected *talosProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.imageFactoryClient = providerData.imageFactoryClient
+}
+
+func (d *talosImageFactoryInstallerImageDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	if d.imageFactoryClient == nil {
+		resp.Diagnostics.AddError("image factory client is not configured", "Please report this issue to the provider developers.")
+
+		return
+	}
+
+	var config talosImageFactoryInstallerImageDataSourceModelV0
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	talosVersion := config.TalosVersion.ValueString()
+
+	versions, err := d.imageFactoryClient.Versions(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError("failed to get talos versions", err.Error())
+
+		return
+	}
+
+	if !slices.Contains(versions, talosVersion) {
+		resp.Diagnostics.AddAttributeError(path.Root("talos_version"), "unknown talos version", fmt.Sprintf("the image factory has no %q Talos version", talosVersion))
+
+		return
+	}
+
+	schematicID := config.SchematicID.ValueString()
+
+	if config.SchematicID.IsNull() {
+		extensions := stringValues(config.Extensions)
+
+		if len(extensions) > 0 {
+			extensionsVersions, err := d.imageFactoryClient.ExtensionsVersions(ctx, talosVersion)
+			if err != nil {
+				resp.Diagnostics.AddError("failed to get talos extensions versions", err.Error())
+
+				return
+			}
+
+			available := xslices.Map(extensionsVersions, func(e client.ExtensionInfo) string { return e.Name })
+
+			for _, extension := range extensions {
+				if !slices.Contains(available, extension) {
+					resp.Diagnostics.AddAttributeError(
+						path.Root("extensions"),
+						"unknown extension",
+						fmt.Sprintf("the image factory has no %q extension for Talos %s", extension, talosVersion),
+					)
+				}
+			}
+
+			if resp.Diagnostics.HasError() {
+				return
+			}
+		}
+
+		schematicID, err = d.imageFactoryClient.SchematicCreate(ctx, schematic.Schematic{
+			Customization: schematic.Customization{
+				SystemExtensions: schematic.SystemExtensions{
+					OfficialExtensions: extensions,
+				},
+			},
+		})
+		if err != nil {
+			resp.Diagnostics.AddError("failed to create schematic", err.Error())
+
+			return
+		}
+	}
+
+	image, err := installerImage(d.imageFactoryClient.BaseURL(), schematicID, talosVersion, config.SecureBoot.ValueBool())
+	if err != nil {
+		resp.Diagnostics.AddError("failed to parse image factory base URL", err.Error())
+
+		return
+	}
+
+	config.ID = basetypes.NewStringValue(image)
+	config.SchematicID = basetypes.NewStringValue(schematicID)
+	config.Image = basetypes.NewStringValue(image)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &config)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// installerImage returns the installer image reference of the schematic served by the image factory.
+func installerImage(baseURL, schematicID, talosVersion string, secureBoot bool) (string, error) {
+	uri, err := url.Parse(baseURL)
+	if err != nil {
+		return "", err
+	}
+
+	installer := "installer"
+	if secureBoot {
+		installer = "installer-secureboot"
+	}
+
+	return fmt.Sprintf("%s/%s/%s:%s", uri.Host, installer, schematicID, talosVersion), nil
+}
@@ -0,0 +1,129 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+
+	"github.com/siderolabs/terraform-provider-talos/pkg/talos"
+)
+
+func TestAccTalosImageFactoryInstallerImageDataSource(t *testing.T) {
+	resource.ParallelTest(t, resource.TestCase{
+		IsUnitTest:               true, // this is a local only resource, so can be unit tested
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccTalosImageFactoryInstallerImageDataSourceUnknownVersionConfig(),
+				ExpectError: regexp.MustCompile("unknown talos version"),
+			},
+			{
+				Config:      testAccTalosImageFactoryInstallerImageDataSourceUnknownExtensionConfig(),
+				ExpectError: regexp.MustCompile("unknown extension"),
+			},
+			{
+				Config: testAccTalosImageFactoryInstallerImageDataSourceConfig(),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.talos_image_factory_installer_image.this", "schematic_id", "376567988ad370138ad8b2698212367b8edcb69b5fd68c80be1f2ec7d603b4ba"),
+					resource.TestCheckResourceAttr("data.talos_image_factory_installer_image.this", "image", "factory.talos.dev/installer/376567988ad370138ad8b2698212367b8edcb69b5fd68c80be1f2ec7d603b4ba:v1.7.5"),
+				),
+			},
+			{
+				Config: testAccTalosImageFactoryInstallerImageDataSourceExtensionsConfig(),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.talos_image_factory_installer_image.this", "schematic_id", "d9ff89777e246792e7642abd3220a616afb4e49822382e4213a2e528ab826fe5"),
+					resource.TestCheckResourceAttr("data.talos_image_factory_installer_image.this", "image", "factory.talos.dev/installer-secureboot/d9ff89777e246792e7642abd3220a616afb4e49822382e4213a2e528ab826fe5:v1.7.5"),
+				),
+			},
+		},
+	})
+}
+
+func testAccTalosImageFactoryInstallerImageDataSourceUnknownVersionConfig() string {
+	return `
+provider "talos" {}
+
+data "talos_image_factory_installer_image" "this" {
+	talos_version = "v1.7.55"
+}
+`
+}
+
+func testAccTalosImageFactoryInstallerImageDataSourceUnknownExtensionConfig() string {
+	return `
+provider "talos" {}
+
+data "talos_image_factory_installer_image" "this" {
+	talos_version = "v1.7.5"
+	extensions    = ["siderolabs/gvisr"]
+}
+`
+}
+
+func testAccTalosImageFactoryInstallerImageDataSourceConfig() string {
+	return `
+provider "talos" {}
+
+data "talos_image_factory_installer_image" "this" {
+	talos_version = "v1.7.5"
+}
+`
+}
+
+func testAccTalosImageFactoryInstallerImageDataSourceExtensionsConfig() string {
+	return `
+provider "talos" {}
+
+data "talos_image_factory_installer_image" "this" {
+	talos_version = "v1.7.5"
+	extensions    = ["siderolabs/gvisor"]
+	secure_boot   = true
+}
+`
+}
+
+func TestInstallerImage(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name       string
+		baseURL    string
+		secureBoot bool
+		expected   string
+	}{
+		{
+			name:     "installer",
+			baseURL:  "https://factory.talos.dev",
+			expected: "factory.talos.dev/installer/376567988ad370138ad8b2698212367b8edcb69b5fd68c80be1f2ec7d603b4ba:v1.7.5",
+		},
+		{
+			name:       "secureboot installer",
+			baseURL:    "https://factory.talos.dev",
+			secureBoot: true,
+			expected:   "factory.talos.dev/installer-secureboot/376567988ad370138ad8b2698212367b8edcb69b5fd68c80be1f2ec7d603b4ba:v1.7.5",
+		},
+		{
+			name:     "port",
+			baseURL:  "https://factory.example.com:8443",
+			expected: "factory.example.com:8443/installer/376567988ad370138ad8b2698212367b8edcb69b5fd68c80be1f2ec7d603b4ba:v1.7.5",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			image, err := talos.InstallerImage(tt.baseURL, "376567988ad370138ad8b2698212367b8edcb69b5fd68c80be1f2ec7d603b4ba", "v1.7.5", tt.secureBoot)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if image != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, image)
+			}
+		})
+	}
+}
@@ -30,7 +30,8 @@ type talosImageFactoryOverlaysVersionsDataSourceModelV0 struct {
 }
 
 type talosImageFactoryOverlaysVersionsFilter struct {
-	Name types.String `tfsdk:"name"`
+	Name  types.String `tfsdk:"name"`
+	Exact types.Bool   `tfsdk:"exact"`
 }
 
 type overlayInfo struct {
@@ -70,6 +71,10 @@ func (d *talosImageFactoryOverlaysVersionsDataSource) Schema(_ context.Context,
 						Optional:    true,
 						Description: "The name of the overlay to filter by.",
 					},
+					"exact": schema.BoolAttribute{
+						Optional:    true,
+						Description: "Match the overlay name exactly instead of by substring. Fails if the overlay is not available for the specified talos version. Defaults to false.",
+					},
 				},
 			},
 			"overlays_info": schema.ListAttribute{
@@ -82,7 +87,7 @@ func (d *talosImageFactoryOverlaysVersionsDataSource) Schema(_ context.Context,
 					},
 				},
 				Computed:    true,
-				Description: "The list of available extensions for the specified talos version.",
+				Description: "The list of available overlays for the specified talos version.",
 			},
 		},
 	}
@@ -93,17 +98,17 @@ func (d *talosImageFactoryOverlaysVersionsDataSource) Configure(_ context.Contex
 		return
 	}
 
-	imageFactoryClient, ok := req.ProviderData.(*client.Client)
+	providerData, ok := req.ProviderData.(*talosProviderData)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"failed to get image factory client",
-			fmt.Sprintf("Expected *client.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *talosProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 
 		return
 	}
 
-	d.imageFactoryClient = imageFactoryClient
+	d.imageFactoryClient = providerData.imageFactoryClient
 }
 
 func (d *talosImageFactoryOverlaysVersionsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
@@ -134,8 +139,22 @@ func (d *talosImageFactoryOverlaysVersionsDataSource) Read(ctx context.Context,
 
 	if config.Filters != nil && !config.Filters.Name.IsNull() && !config.Filters.Name.IsUnknown() {
 		overlaysInfo = xslices.Filter(overlaysInfo, func(e client.OverlayInfo) bool {
+			if config.Filters.Exact.ValueBool() {
+				return e.Name == config.Filters.Name.ValueString()
+			}
+
 			return strings.Contains(e.Name, config.Filters.Name.ValueString())
 		})
+
+		if config.Filters.Exact.ValueBool() && len(overlaysInfo) == 0 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("filters").AtName("name"),
+				"overlay not found",
+				fmt.Sprintf("overlay %s is not available for talos version %s", config.Filters.Name.ValueString(), config.TalosVersion.ValueString()),
+			)
+
+			return
+		}
 	}
 
 	tfOverlaysInfo := xslices.Map(overlaysInfo, func(e client.OverlayInfo) overlayInfo {
@@ -5,6 +5,8 @@
 package talos_test
 
 import (
+	"fmt"
+	"regexp"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
@@ -28,6 +30,17 @@ func TestAccTalosImageFactoryOverlaysVersionsDataSource(t *testing.T) {
 					resource.TestCheckResourceAttr("data.talos_image_factory_overlays_versions.this", "overlays_info.0.name", "rock4cplus"),
 				),
 			},
+			{
+				Config: testAccTalosImageFactoryOverlaysVersionsDataSourceConfigWithExactFilters("rpi_generic"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.talos_image_factory_overlays_versions.this", "overlays_info.#", "1"),
+					resource.TestCheckResourceAttr("data.talos_image_factory_overlays_versions.this", "overlays_info.0.name", "rpi_generic"),
+				),
+			},
+			{
+				Config:      testAccTalosImageFactoryOverlaysVersionsDataSourceConfigWithExactFilters("rock4c"),
+				ExpectError: regexp.MustCompile("overlay rock4c is not available for talos version v1.7.0"),
+			},
 		},
 	})
 }
@@ -54,3 +67,17 @@ data "talos_image_factory_overlays_versions" "this" {
 }
 `
 }
+
+func testAccTalosImageFactoryOverlaysVersionsDataSourceConfigWithExactFilters(name string) string {
+	return fmt.Sprintf(`
+provider "talos" {}
+
+data "talos_image_factory_overlays_versions" "this" {
+	talos_version = "v1.7.0"
+	filters = {
+		name  = "%s"
+		exact = true
+	}
+}
+`, name)
+}
@@ -8,6 +8,7 @@ package talos
 import (
 	"context"
 
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/types"
@@ -22,8 +23,9 @@ type talosImageFactorySchematicResource struct {
 }
 
 var (
-	_ resource.Resource              = &talosImageFactorySchematicResource{}
-	_ resource.ResourceWithConfigure = &talosImageFactorySchematicResource{}
+	_ resource.Resource                   = &talosImageFactorySchematicResource{}
+	_ resource.ResourceWithConfigure      = &talosImageFactorySchematicResource{}
+	_ resource.ResourceWithValidateConfig = &talosImageFactorySchematicResource{}
 )
 
 var schematicAttributeMarkdownDescription = `
@@ -69,17 +71,17 @@ func (r *talosImageFactorySchematicResource) Configure(_ context.Context, req re
 		return
 	}
 
-	imageFactoryClient, ok := req.ProviderData.(*client.Client)
+	providerData, ok := req.ProviderData.(*talosProviderData)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"failed to get image factory client",
-			"Expected *client.Client, got: %T. Please report this issue to the provider developers.",
+			"Expected *talosProviderData, got: %T. Please report this issue to the provider developers.",
 		)
 
 		return
 	}
 
-	r.imageFactoryClient = imageFactoryClient
+	r.imageFactoryClient = providerData.imageFactoryClient
 }
 
 func (r *talosImageFactorySchematicResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
@@ -91,15 +93,14 @@ func (r *talosImageFactorySchematicResource) Create(ctx context.Context, req res
 		return
 	}
 
-	var schematic schematic.Schematic
-
-	if err := yaml.Unmarshal([]byte(config.Schematic.ValueString()), &schematic); err != nil {
+	schematic, err := parseSchematic(config.Schematic.ValueString())
+	if err != nil {
 		resp.Diagnostics.AddError("failed to unmarshal schematic", err.Error())
 
 		return
 	}
 
-	schematicID, err := r.imageFactoryClient.SchematicCreate(ctx, schematic)
+	schematicID, err := r.imageFactoryClient.SchematicCreate(ctx, *schematic)
 	if err != nil {
 		resp.Diagnostics.AddError("failed to create schematic", err.Error())
 
@@ -131,15 +132,14 @@ func (r *talosImageFactorySchematicResource) Update(ctx context.Context, req res
 		return
 	}
 
-	var schematic schematic.Schematic
-
-	if err := yaml.Unmarshal([]byte(plan.Schematic.ValueString()), &schematic); err != nil {
+	schematic, err := parseSchematic(plan.Schematic.ValueString())
+	if err != nil {
 		resp.Diagnostics.AddError("failed to unmarshal schematic", err.Error())
 
 		return
 	}
 
-	schematicID, err := r.imageFactoryClient.SchematicCreate(ctx, schematic)
+	schematicID, err := r.imageFactoryClient.SchematicCreate(ctx, *schematic)
 	if err != nil {
 		resp.Diagnostics.AddError("failed to update schematic", err.Error())
 
@@ -155,3 +155,38 @@ func (r *talosImageFactorySchematicResource) Update(ctx context.Context, req res
 		return
 	}
 }
+
+func (r *talosImageFactorySchematicResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var config talosImageFactorySchematicResourceModelV0
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if config.Schematic.IsUnknown() {
+		return
+	}
+
+	if _, err := parseSchematic(config.Schematic.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("schematic"),
+			"schematic is invalid",
+			err.Error(),
+		)
+	}
+}
+
+// parseSchematic decodes the schematic, an empty schematic results in a vanilla Talos image schematic.
+//
+// Unknown fields are ignored, so that schematics for newer Image Factory versions are still accepted.
+func parseSchematic(data string) (*schematic.Schematic, error) {
+	var s schematic.Schematic
+
+	if err := yaml.Unmarshal([]byte(data), &s); err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
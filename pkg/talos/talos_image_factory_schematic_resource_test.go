@@ -5,12 +5,25 @@
 package talos_test
 
 import (
+	"regexp"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 )
 
 func TestAccTalosImageFactorySchematicResource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		IsUnitTest:               true, // this is a local only resource, so can be unit tested
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// malformed schematics are rejected at plan time
+			{
+				Config:      testAccTalosTalosImageFactorySchematicInvalidConfig(),
+				ExpectError: regexp.MustCompile("schematic is invalid"),
+			},
+		},
+	})
+
 	resource.ParallelTest(t, resource.TestCase{
 		IsUnitTest:               true, // this is a local only resource, so can be unit tested
 		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
@@ -62,6 +75,16 @@ resource "talos_image_factory_schematic" "this" {}
 `
 }
 
+func testAccTalosTalosImageFactorySchematicInvalidConfig() string {
+	return `
+provider "talos" {}
+
+resource "talos_image_factory_schematic" "this" {
+	schematic = "customization: ["
+}
+`
+}
+
 func testAccTalosTalosImageFactorySchematicEmptySchematicConfig() string {
 	return `
 provider "talos" {}
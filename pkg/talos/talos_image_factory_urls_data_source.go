@@ -30,6 +30,14 @@ type talosImageFactoryURLSDataSource struct {
 
 var _ datasource.DataSource = &talosImageFactoryURLSDataSource{}
 
+const (
+	// secureBootSignedPCR is the PCR the UKI sections are measured to, the disk encryption keys are sealed to its signed policy.
+	secureBootSignedPCR = 11
+
+	// secureBootStatePCR is the PCR the SecureBoot state and the signing certificates are measured to.
+	secureBootStatePCR = 7
+)
+
 var (
 	metalPlatforms                      = []string{"metal"}
 	cloudPlatforms                      = xslices.Map(metadata.Platforms(), func(platform metadata.Platform) string { return platform.Name })
@@ -65,13 +73,21 @@ var (
 )
 
 type talosImageFactoryURLSDataSourceModelV0 struct {
-	ID           types.String `tfsdk:"id"`
-	Architecture types.String `tfsdk:"architecture"`
-	TalosVersion types.String `tfsdk:"talos_version"`
-	SchematicID  types.String `tfsdk:"schematic_id"`
-	Platform     types.String `tfsdk:"platform"`
-	SBC          types.String `tfsdk:"sbc"`
-	URLs         urls         `tfsdk:"urls"`
+	ID             types.String    `tfsdk:"id"`
+	Architecture   types.String    `tfsdk:"architecture"`
+	TalosVersion   types.String    `tfsdk:"talos_version"`
+	SchematicID    types.String    `tfsdk:"schematic_id"`
+	Platform       types.String    `tfsdk:"platform"`
+	SBC            types.String    `tfsdk:"sbc"`
+	SecureBoot     types.Bool      `tfsdk:"secure_boot"`
+	URLs           urls            `tfsdk:"urls"`
+	SecureBootInfo *secureBootInfo `tfsdk:"secure_boot_info"`
+}
+
+type secureBootInfo struct {
+	SigningCertURL     types.String `tfsdk:"signing_cert_url"`
+	SignedPCR          types.Int64  `tfsdk:"signed_pcr"`
+	SecureBootStatePCR types.Int64  `tfsdk:"secure_boot_state_pcr"`
 }
 
 type urls struct {
@@ -160,6 +176,11 @@ func (d *talosImageFactoryURLSDataSource) Schema(_ context.Context, _ datasource
 					),
 				},
 			},
+			"secure_boot": schema.BoolAttribute{
+				Optional: true,
+				Description: "Generate the URLs of the signed SecureBoot assets: `installer`, `iso`, `disk_image` and `pxe` are the SecureBoot variants " +
+					"and the kernel and initramfs URLs are not set, as only the UKI can be booted with SecureBoot. Only supported for the metal platform.",
+			},
 			"urls": schema.SingleNestedAttribute{
 				Computed:    true,
 				Description: "The URLs for different assets supported by the Talos image factory. If the URL is not available for a specific asset, it will be an empty string.",
@@ -210,6 +231,24 @@ func (d *talosImageFactoryURLSDataSource) Schema(_ context.Context, _ datasource
 					},
 				},
 			},
+			"secure_boot_info": schema.SingleNestedAttribute{
+				Computed:    true,
+				Description: "The SecureBoot signing metadata of the assets, only set when `secure_boot` is set.",
+				Attributes: map[string]schema.Attribute{
+					"signing_cert_url": schema.StringAttribute{
+						Computed:    true,
+						Description: "The URL of the certificate the image factory signs the SecureBoot assets with, to be enrolled in the UEFI db.",
+					},
+					"signed_pcr": schema.Int64Attribute{
+						Computed:    true,
+						Description: "The PCR the UKI is measured to, the disk encryption keys are sealed to its policy signed by the image factory.",
+					},
+					"secure_boot_state_pcr": schema.Int64Attribute{
+						Computed:    true,
+						Description: "The PCR the SecureBoot state is measured to.",
+					},
+				},
+			},
 		},
 	}
 }
@@ -219,17 +258,17 @@ func (d *talosImageFactoryURLSDataSource) Configure(_ context.Context, req datas
 		return
 	}
 
-	imageFactoryClient, ok := req.ProviderData.(*client.Client)
+	providerData, ok := req.ProviderData.(*talosProviderData)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"failed to get image factory client",
-			fmt.Sprintf("Expected *client.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *talosProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 
 		return
 	}
 
-	d.imageFactoryClient = imageFactoryClient
+	d.imageFactoryClient = providerData.imageFactoryClient
 }
 
 func (d *talosImageFactoryURLSDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
@@ -274,6 +313,14 @@ func (d *talosImageFactoryURLSDataSource) Read(ctx context.Context, req datasour
 		return
 	}
 
+	secureBoot := config.SecureBoot.ValueBool()
+
+	if secureBoot && platform != "metal" {
+		resp.Diagnostics.AddAttributeError(path.Root("secure_boot"), "secure boot is not supported", "the SecureBoot assets are only available for the metal platform")
+
+		return
+	}
+
 	urlsData := urls{
 		Installer: basetypes.NewStringValue(fmt.Sprintf("%s/installer/%s:%s", uri.Host, schematicID, talosVersion)),
 	}
@@ -301,12 +348,34 @@ func (d *talosImageFactoryURLSDataSource) Read(ctx context.Context, req datasour
 				urlsData.DiskImage = basetypes.NewStringValue(fmt.Sprintf("%s/image/%s/%s/%s-%s.%s", d.imageFactoryClient.BaseURL(), schematicID, talosVersion, platform, architecture, platformData[0].DiskImageSuffix)) //nolint:lll
 			case "pxe":
 				urlsData.PXE = basetypes.NewStringValue(fmt.Sprintf("%s://pxe.%s/pxe/%s/%s/%s-%s", uri.Scheme, uri.Host, schematicID, talosVersion, platform, architecture))
+				// PXE booting can also be done with the kernel, initramfs and the platform specific kernel command line directly
+				urlsData.Kernel = basetypes.NewStringValue(fmt.Sprintf("%s/image/%s/%s/kernel-%s", d.imageFactoryClient.BaseURL(), schematicID, talosVersion, architecture))
+				urlsData.KernelCommandLine = basetypes.NewStringValue(fmt.Sprintf("%s/image/%s/%s/cmdline-%s-%s", d.imageFactoryClient.BaseURL(), schematicID, talosVersion, platform, architecture))
+				urlsData.Initramfs = basetypes.NewStringValue(fmt.Sprintf("%s/image/%s/%s/initramfs-%s.xz", d.imageFactoryClient.BaseURL(), schematicID, talosVersion, architecture))
 			case "iso":
 				urlsData.ISO = basetypes.NewStringValue(fmt.Sprintf("%s/image/%s/%s/%s-%s.iso", d.imageFactoryClient.BaseURL(), schematicID, talosVersion, platform, architecture))
 			}
 		}
 	}
 
+	config.SecureBootInfo = nil
+
+	if secureBoot {
+		urlsData.Installer = urlsData.InstallerSecureboot
+		urlsData.ISO = urlsData.ISOSecureboot
+		urlsData.DiskImage = urlsData.DiskImageSecureboot
+		urlsData.PXE = basetypes.NewStringValue(fmt.Sprintf("%s://pxe.%s/pxe/%s/%s/metal-%s-secureboot", uri.Scheme, uri.Host, schematicID, talosVersion, architecture))
+		urlsData.Kernel = types.StringNull()
+		urlsData.KernelCommandLine = types.StringNull()
+		urlsData.Initramfs = types.StringNull()
+
+		config.SecureBootInfo = &secureBootInfo{
+			SigningCertURL:     basetypes.NewStringValue(d.imageFactoryClient.BaseURL() + "/secureboot/signing-cert.pem"),
+			SignedPCR:          basetypes.NewInt64Value(secureBootSignedPCR),
+			SecureBootStatePCR: basetypes.NewInt64Value(secureBootStatePCR),
+		}
+	}
+
 	config.ID = basetypes.NewStringValue(config.ID.ValueString())
 	config.URLs = urlsData
 
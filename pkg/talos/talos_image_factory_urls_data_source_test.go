@@ -24,6 +24,10 @@ func TestAccTalosImageFactoryURLsDataSource(t *testing.T) {
 				Config:      testAccTalosImageFactoryURLsBothSBCAndPlatformSetConfig(),
 				ExpectError: regexp.MustCompile("Invalid Attribute Combination"),
 			},
+			{
+				Config:      testAccTalosImageFactoryURLsSecureBootAWSPlatformConfig(),
+				ExpectError: regexp.MustCompile("secure boot is not supported"),
+			},
 		},
 	})
 
@@ -66,6 +70,23 @@ func TestAccTalosImageFactoryURLsDataSource(t *testing.T) {
 					resource.TestCheckResourceAttr("data.talos_image_factory_urls.this", "urls.uki", "https://factory.talos.dev/image/376567988ad370138ad8b2698212367b8edcb69b5fd68c80be1f2ec7d603b4ba/v1.7.5/metal-arm64-secureboot-uki.efi"),
 				),
 			},
+			// metal platform with secure boot
+			{
+				Config: testAccTalosImageFactoryURLsSecureBootMetalPlatformConfig(),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.talos_image_factory_urls.this", "urls.installer", "factory.talos.dev/installer-secureboot/376567988ad370138ad8b2698212367b8edcb69b5fd68c80be1f2ec7d603b4ba:v1.7.5"),
+					resource.TestCheckResourceAttr("data.talos_image_factory_urls.this", "urls.iso", "https://factory.talos.dev/image/376567988ad370138ad8b2698212367b8edcb69b5fd68c80be1f2ec7d603b4ba/v1.7.5/metal-amd64-secureboot.iso"),
+					resource.TestCheckResourceAttr("data.talos_image_factory_urls.this", "urls.disk_image", "https://factory.talos.dev/image/376567988ad370138ad8b2698212367b8edcb69b5fd68c80be1f2ec7d603b4ba/v1.7.5/metal-amd64-secureboot.raw.zst"),
+					resource.TestCheckResourceAttr("data.talos_image_factory_urls.this", "urls.pxe", "https://pxe.factory.talos.dev/pxe/376567988ad370138ad8b2698212367b8edcb69b5fd68c80be1f2ec7d603b4ba/v1.7.5/metal-amd64-secureboot"),
+					resource.TestCheckNoResourceAttr("data.talos_image_factory_urls.this", "urls.kernel"),
+					resource.TestCheckNoResourceAttr("data.talos_image_factory_urls.this", "urls.kernel_command_line"),
+					resource.TestCheckNoResourceAttr("data.talos_image_factory_urls.this", "urls.initramfs"),
+					resource.TestCheckResourceAttr("data.talos_image_factory_urls.this", "urls.uki", "https://factory.talos.dev/image/376567988ad370138ad8b2698212367b8edcb69b5fd68c80be1f2ec7d603b4ba/v1.7.5/metal-amd64-secureboot-uki.efi"),
+					resource.TestCheckResourceAttr("data.talos_image_factory_urls.this", "secure_boot_info.signing_cert_url", "https://factory.talos.dev/secureboot/signing-cert.pem"),
+					resource.TestCheckResourceAttr("data.talos_image_factory_urls.this", "secure_boot_info.signed_pcr", "11"),
+					resource.TestCheckResourceAttr("data.talos_image_factory_urls.this", "secure_boot_info.secure_boot_state_pcr", "7"),
+				),
+			},
 			// aws platform
 			{
 				Config: testAccTalosImageFactoryURLsAWSPlatformConfig(),
@@ -94,9 +115,9 @@ func TestAccTalosImageFactoryURLsDataSource(t *testing.T) {
 					resource.TestCheckNoResourceAttr("data.talos_image_factory_urls.this", "urls.disk_image"),
 					resource.TestCheckNoResourceAttr("data.talos_image_factory_urls.this", "urls.disk_image_secureboot"),
 					resource.TestCheckResourceAttr("data.talos_image_factory_urls.this", "urls.pxe", "https://pxe.factory.talos.dev/pxe/376567988ad370138ad8b2698212367b8edcb69b5fd68c80be1f2ec7d603b4ba/v1.7.5/equinixMetal-amd64"),
-					resource.TestCheckNoResourceAttr("data.talos_image_factory_urls.this", "urls.kernel"),
-					resource.TestCheckNoResourceAttr("data.talos_image_factory_urls.this", "urls.kernel_command_line"),
-					resource.TestCheckNoResourceAttr("data.talos_image_factory_urls.this", "urls.initramfs"),
+					resource.TestCheckResourceAttr("data.talos_image_factory_urls.this", "urls.kernel", "https://factory.talos.dev/image/376567988ad370138ad8b2698212367b8edcb69b5fd68c80be1f2ec7d603b4ba/v1.7.5/kernel-amd64"),
+					resource.TestCheckResourceAttr("data.talos_image_factory_urls.this", "urls.kernel_command_line", "https://factory.talos.dev/image/376567988ad370138ad8b2698212367b8edcb69b5fd68c80be1f2ec7d603b4ba/v1.7.5/cmdline-equinixMetal-amd64"),
+					resource.TestCheckResourceAttr("data.talos_image_factory_urls.this", "urls.initramfs", "https://factory.talos.dev/image/376567988ad370138ad8b2698212367b8edcb69b5fd68c80be1f2ec7d603b4ba/v1.7.5/initramfs-amd64.xz"),
 					resource.TestCheckNoResourceAttr("data.talos_image_factory_urls.this", "urls.uki"),
 				),
 			},
@@ -111,9 +132,9 @@ func TestAccTalosImageFactoryURLsDataSource(t *testing.T) {
 					resource.TestCheckResourceAttr("data.talos_image_factory_urls.this", "urls.disk_image", "https://factory.talos.dev/image/376567988ad370138ad8b2698212367b8edcb69b5fd68c80be1f2ec7d603b4ba/v1.7.5/nocloud-amd64.raw.xz"),
 					resource.TestCheckNoResourceAttr("data.talos_image_factory_urls.this", "urls.disk_image_secureboot"),
 					resource.TestCheckResourceAttr("data.talos_image_factory_urls.this", "urls.pxe", "https://pxe.factory.talos.dev/pxe/376567988ad370138ad8b2698212367b8edcb69b5fd68c80be1f2ec7d603b4ba/v1.7.5/nocloud-amd64"),
-					resource.TestCheckNoResourceAttr("data.talos_image_factory_urls.this", "urls.kernel"),
-					resource.TestCheckNoResourceAttr("data.talos_image_factory_urls.this", "urls.kernel_command_line"),
-					resource.TestCheckNoResourceAttr("data.talos_image_factory_urls.this", "urls.initramfs"),
+					resource.TestCheckResourceAttr("data.talos_image_factory_urls.this", "urls.kernel", "https://factory.talos.dev/image/376567988ad370138ad8b2698212367b8edcb69b5fd68c80be1f2ec7d603b4ba/v1.7.5/kernel-amd64"),
+					resource.TestCheckResourceAttr("data.talos_image_factory_urls.this", "urls.kernel_command_line", "https://factory.talos.dev/image/376567988ad370138ad8b2698212367b8edcb69b5fd68c80be1f2ec7d603b4ba/v1.7.5/cmdline-nocloud-amd64"),
+					resource.TestCheckResourceAttr("data.talos_image_factory_urls.this", "urls.initramfs", "https://factory.talos.dev/image/376567988ad370138ad8b2698212367b8edcb69b5fd68c80be1f2ec7d603b4ba/v1.7.5/initramfs-amd64.xz"),
 					resource.TestCheckNoResourceAttr("data.talos_image_factory_urls.this", "urls.uki"),
 				),
 			},
@@ -187,6 +208,32 @@ data "talos_image_factory_urls" "this" {
 `
 }
 
+func testAccTalosImageFactoryURLsSecureBootMetalPlatformConfig() string {
+	return `
+provider "talos" {}
+
+data "talos_image_factory_urls" "this" {
+	talos_version = "v1.7.5"
+	schematic_id = "376567988ad370138ad8b2698212367b8edcb69b5fd68c80be1f2ec7d603b4ba"
+	platform = "metal"
+	secure_boot = true
+}
+`
+}
+
+func testAccTalosImageFactoryURLsSecureBootAWSPlatformConfig() string {
+	return `
+provider "talos" {}
+
+data "talos_image_factory_urls" "this" {
+	talos_version = "v1.7.5"
+	schematic_id = "376567988ad370138ad8b2698212367b8edcb69b5fd68c80be1f2ec7d603b4ba"
+	platform = "aws"
+	secure_boot = true
+}
+`
+}
+
 func testAccTalosImageFactoryURLsAWSPlatformConfig() string {
 	return `
 provider "talos" {}
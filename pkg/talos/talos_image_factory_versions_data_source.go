@@ -73,17 +73,17 @@ func (d *talosImageFactoryVersionsDataSource) Configure(_ context.Context, req d
 		return
 	}
 
-	imageFactoryClient, ok := req.ProviderData.(*client.Client)
+	providerData, ok := req.ProviderData.(*talosProviderData)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"failed to get image factory client",
-			fmt.Sprintf("Expected *client.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *talosProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 
 		return
 	}
 
-	d.imageFactoryClient = imageFactoryClient
+	d.imageFactoryClient = providerData.imageFactoryClient
 }
 
 func (d *talosImageFactoryVersionsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
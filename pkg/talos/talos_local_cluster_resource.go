@@ -0,0 +1,789 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"net/netip"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64default"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/objectplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	sideronet "github.com/siderolabs/net"
+	"github.com/siderolabs/talos/pkg/cluster/check"
+	"github.com/siderolabs/talos/pkg/images"
+	clientconfig "github.com/siderolabs/talos/pkg/machinery/client/config"
+	"github.com/siderolabs/talos/pkg/machinery/config/bundle"
+	"github.com/siderolabs/talos/pkg/machinery/config/configpatcher"
+	"github.com/siderolabs/talos/pkg/machinery/config/generate"
+	"github.com/siderolabs/talos/pkg/machinery/config/machine"
+	"github.com/siderolabs/talos/pkg/machinery/constants"
+	"github.com/siderolabs/talos/pkg/machinery/gendata"
+	"github.com/siderolabs/talos/pkg/provision"
+	"github.com/siderolabs/talos/pkg/provision/access"
+	"github.com/siderolabs/talos/pkg/provision/providers"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+type talosLocalClusterResource struct{}
+
+var (
+	_ resource.Resource                   = &talosLocalClusterResource{}
+	_ resource.ResourceWithValidateConfig = &talosLocalClusterResource{}
+)
+
+type talosLocalClusterResourceModelV0 struct { //nolint:govet
+	ID                  types.String        `tfsdk:"id"`
+	Name                types.String        `tfsdk:"name"`
+	Provisioner         types.String        `tfsdk:"provisioner"`
+	Image               types.String        `tfsdk:"image"`
+	TalosctlPath        types.String        `tfsdk:"talosctl_path"`
+	KernelPath          types.String        `tfsdk:"kernel_path"`
+	InitramfsPath       types.String        `tfsdk:"initramfs_path"`
+	StateDirectory      types.String        `tfsdk:"state_directory"`
+	KubernetesVersion   types.String        `tfsdk:"kubernetes_version"`
+	ControlPlanes       types.Int64         `tfsdk:"controlplanes"`
+	Workers             types.Int64         `tfsdk:"workers"`
+	CIDR                types.String        `tfsdk:"cidr"`
+	Nameservers         []types.String      `tfsdk:"nameservers"`
+	ConfigPatches       []types.String      `tfsdk:"config_patches"`
+	Endpoint            types.String        `tfsdk:"endpoint"`
+	KubernetesEndpoint  types.String        `tfsdk:"kubernetes_endpoint"`
+	Nodes               []localClusterNode  `tfsdk:"nodes"`
+	ClientConfiguration clientConfiguration `tfsdk:"client_configuration"`
+	KubeConfigRaw       types.String        `tfsdk:"kubeconfig_raw"`
+	Timeouts            timeouts.Value      `tfsdk:"timeouts"`
+}
+
+type localClusterNode struct {
+	Name    types.String `tfsdk:"name"`
+	Type    types.String `tfsdk:"type"`
+	Address types.String `tfsdk:"address"`
+}
+
+// NewTalosLocalClusterResource implements the resource.Resource interface.
+func NewTalosLocalClusterResource() resource.Resource {
+	return &talosLocalClusterResource{}
+}
+
+func (r *talosLocalClusterResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_local_cluster"
+}
+
+func (r *talosLocalClusterResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "The local cluster resource provisions a throwaway Talos cluster on the local machine (like `talosctl cluster create`), useful for tests and demos. Any change to the arguments re-creates the cluster.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:    true,
+				Description: "The ID of the local cluster",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"name": schema.StringAttribute{
+				Required:    true,
+				Description: "The name of the cluster, also used as the name of the cluster network and as the prefix of the node names",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"provisioner": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "The provisioner to use, either `docker` or `qemu`. The QEMU provisioner runs the nodes as VMs, it only works on Linux and requires root privileges, QEMU and the `talosctl` executable",
+				Default:     stringdefault.StaticString("docker"),
+				Validators: []validator.String{
+					stringvalidator.OneOf("docker", "qemu"),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"image": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "The Talos container image to use for the nodes, only used by the docker provisioner",
+				Default:     stringdefault.StaticString(images.DefaultTalosImageRepository + ":" + gendata.VersionTag),
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"talosctl_path": schema.StringAttribute{
+				Optional:    true,
+				Description: "The path to the `talosctl` executable the QEMU provisioner runs to launch the VMs, the load balancer and the DHCP server, defaults to `talosctl` from the `PATH`",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"kernel_path": schema.StringAttribute{
+				Optional:    true,
+				Description: "The path to the Talos kernel image the QEMU nodes boot from, required by the QEMU provisioner. `${ARCH}` is replaced with the architecture of the host",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"initramfs_path": schema.StringAttribute{
+				Optional:    true,
+				Description: "The path to the Talos initramfs the QEMU nodes boot from, required by the QEMU provisioner. `${ARCH}` is replaced with the architecture of the host",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"state_directory": schema.StringAttribute{
+				Optional:    true,
+				Description: "The directory the QEMU provisioner stores the state of the cluster in, defaults to the `talosctl` one (`~/.talos/clusters`)",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"kubernetes_version": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "The version of kubernetes to use",
+				Default:     stringdefault.StaticString(constants.DefaultKubernetesVersion),
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"controlplanes": schema.Int64Attribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "The number of controlplane nodes",
+				Default:     int64default.StaticInt64(1),
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.RequiresReplace(),
+				},
+			},
+			"workers": schema.Int64Attribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "The number of worker nodes",
+				Default:     int64default.StaticInt64(1),
+				Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+				},
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.RequiresReplace(),
+				},
+			},
+			"cidr": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "The IPv4 CIDR of the cluster network",
+				Default:     stringdefault.StaticString("10.5.0.0/24"),
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"nameservers": schema.ListAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Computed:    true,
+				Description: "The nameservers to use for the cluster network, defaults to the `talosctl cluster create` defaults",
+				Default: listdefault.StaticValue(types.ListValueMust(types.StringType, []attr.Value{
+					types.StringValue("8.8.8.8"),
+					types.StringValue("1.1.1.1"),
+				})),
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+				},
+				PlanModifiers: []planmodifier.List{
+					listplanmodifier.RequiresReplace(),
+				},
+			},
+			"config_patches": schema.ListAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Description: "The list of config patches to apply to all the nodes",
+				PlanModifiers: []planmodifier.List{
+					listplanmodifier.RequiresReplace(),
+				},
+			},
+			"endpoint": schema.StringAttribute{
+				Computed:    true,
+				Description: "The Talos API endpoint of the cluster",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"kubernetes_endpoint": schema.StringAttribute{
+				Computed:    true,
+				Description: "The Kubernetes API endpoint of the cluster",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"nodes": schema.ListNestedAttribute{
+				Computed:    true,
+				Description: "The nodes of the cluster",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							Computed:    true,
+							Description: "The name of the node",
+						},
+						"type": schema.StringAttribute{
+							Computed:    true,
+							Description: "The machine type of the node",
+						},
+						"address": schema.StringAttribute{
+							Computed:    true,
+							Description: "The address of the node in the cluster network",
+						},
+					},
+				},
+				PlanModifiers: []planmodifier.List{
+					listplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"client_configuration": schema.SingleNestedAttribute{
+				Attributes: map[string]schema.Attribute{
+					"ca_certificate": schema.StringAttribute{
+						Computed:    true,
+						Description: "The client CA certificate",
+					},
+					"client_certificate": schema.StringAttribute{
+						Computed:    true,
+						Description: "The client certificate",
+					},
+					"client_key": schema.StringAttribute{
+						Computed:    true,
+						Sensitive:   true,
+						Description: "The client key",
+					},
+				},
+				Computed:    true,
+				Description: "The client configuration data",
+				PlanModifiers: []planmodifier.Object{
+					objectplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"kubeconfig_raw": schema.StringAttribute{
+				Computed:    true,
+				Sensitive:   true,
+				Description: "The raw kubeconfig pointing to the kubernetes endpoint of the cluster",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"timeouts": timeouts.Attributes(ctx, timeouts.Opts{
+				Create: true,
+				Delete: true,
+			}),
+		},
+	}
+}
+
+func (r *talosLocalClusterResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) { //nolint:gocyclo,cyclop
+	var planObj types.Object
+
+	diags := req.Plan.Get(ctx, &planObj)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var state talosLocalClusterResourceModelV0
+
+	diags = planObj.As(ctx, &state, basetypes.ObjectAsOptions{
+		UnhandledNullAsEmpty:    true,
+		UnhandledUnknownAsEmpty: true,
+	})
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	createTimeout, diags := state.Timeouts.Create(ctx, 10*time.Minute)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctxDeadline, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
+	clusterName := state.Name.ValueString()
+	controlPlanes := int(state.ControlPlanes.ValueInt64())
+	workers := int(state.Workers.ValueInt64())
+
+	cidr, err := netip.ParsePrefix(state.CIDR.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("failed to parse cidr", err.Error())
+
+		return
+	}
+
+	if !cidr.Addr().Is4() {
+		resp.Diagnostics.AddError("failed to parse cidr", "cidr is expected to be an IPv4 CIDR")
+
+		return
+	}
+
+	// gateway is the first IP in the network, nodes start at the second IP
+	gatewayIP, err := sideronet.NthIPInNetwork(cidr, 1)
+	if err != nil {
+		resp.Diagnostics.AddError("failed to allocate gateway IP", err.Error())
+
+		return
+	}
+
+	nameservers := make([]netip.Addr, len(state.Nameservers))
+
+	for i, nameserver := range state.Nameservers {
+		nameservers[i], err = netip.ParseAddr(nameserver.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("nameservers").AtListIndex(i), "failed to parse nameserver", err.Error())
+
+			return
+		}
+	}
+
+	nodeIPs := make([]netip.Addr, controlPlanes+workers)
+
+	for i := range nodeIPs {
+		nodeIPs[i], err = sideronet.NthIPInNetwork(cidr, i+2)
+		if err != nil {
+			resp.Diagnostics.AddError("failed to allocate node IPs", err.Error())
+
+			return
+		}
+	}
+
+	stateDirectory, err := localClusterStateDirectory(state.StateDirectory)
+	if err != nil {
+		resp.Diagnostics.AddError("failed to determine the state directory", err.Error())
+
+		return
+	}
+
+	provisioner, err := providers.Factory(ctxDeadline, state.Provisioner.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("failed to create provisioner", err.Error())
+
+		return
+	}
+
+	defer provisioner.Close() //nolint:errcheck
+
+	request := provision.ClusterRequest{
+		Name: clusterName,
+		Network: provision.NetworkRequest{
+			Name:              clusterName,
+			CIDRs:             []netip.Prefix{cidr},
+			GatewayAddrs:      []netip.Addr{gatewayIP},
+			MTU:               1500,
+			Nameservers:       nameservers,
+			LoadBalancerPorts: []int{constants.DefaultControlPlanePort},
+		},
+		Image:          state.Image.ValueString(),
+		StateDirectory: stateDirectory,
+	}
+
+	if state.Provisioner.ValueString() == "qemu" {
+		talosDir, talosDirErr := clientconfig.GetTalosDirectory()
+		if talosDirErr != nil {
+			resp.Diagnostics.AddError("failed to determine the talos directory", talosDirErr.Error())
+
+			return
+		}
+
+		// the QEMU provisioner re-executes talosctl to launch the VMs and the helper services, like `talosctl cluster create` does
+		talosctlPath := state.TalosctlPath.ValueString()
+
+		if talosctlPath == "" {
+			talosctlPath, err = exec.LookPath("talosctl")
+			if err != nil {
+				resp.Diagnostics.AddAttributeError(path.Root("talosctl_path"), "failed to find talosctl", err.Error())
+
+				return
+			}
+		}
+
+		request.SelfExecutable = talosctlPath
+		request.KernelPath = state.KernelPath.ValueString()
+		request.InitramfsPath = state.InitramfsPath.ValueString()
+		request.Network.CNI = provision.CNIConfig{
+			BinPath:   []string{filepath.Join(talosDir, "cni", "bin")},
+			ConfDir:   filepath.Join(talosDir, "cni", "conf.d"),
+			CacheDir:  filepath.Join(talosDir, "cni", "cache"),
+			BundleURL: fmt.Sprintf("https://github.com/%s/talos/releases/download/%s/talosctl-cni-bundle-%s.tar.gz", images.Username, gendata.VersionTag, constants.ArchVariable),
+		}
+	}
+
+	endpoints := provisioner.GetTalosAPIEndpoints(request.Network)
+	kubernetesEndpoint := provisioner.GetExternalKubernetesControlPlaneEndpoint(request.Network, constants.DefaultControlPlanePort)
+
+	genOptions := provisioner.GenOptions(request.Network)
+
+	for _, endpoint := range endpoints {
+		host, _, splitErr := net.SplitHostPort(endpoint)
+		if splitErr != nil {
+			host = endpoint
+		}
+
+		genOptions = append(genOptions, generate.WithAdditionalSubjectAltNames([]string{host}))
+	}
+
+	genOptions = append(genOptions, generate.WithEndpointList(endpoints))
+
+	configPatches := make([]string, len(state.ConfigPatches))
+
+	for i, patch := range state.ConfigPatches {
+		configPatches[i] = patch.ValueString()
+	}
+
+	patches, err := configpatcher.LoadPatches(configPatches)
+	if err != nil {
+		resp.Diagnostics.AddError("failed to load config patches", err.Error())
+
+		return
+	}
+
+	configBundle, err := bundle.NewBundle(
+		bundle.WithInputOptions(
+			&bundle.InputOptions{
+				ClusterName: clusterName,
+				Endpoint:    provisioner.GetInClusterKubernetesControlPlaneEndpoint(request.Network, constants.DefaultControlPlanePort),
+				KubeVersion: strings.TrimPrefix(state.KubernetesVersion.ValueString(), "v"),
+				GenOptions:  genOptions,
+			},
+		),
+		bundle.WithPatch(patches),
+		bundle.WithVerbose(false),
+	)
+	if err != nil {
+		resp.Diagnostics.AddError("failed to generate machine configuration", err.Error())
+
+		return
+	}
+
+	for i := range controlPlanes {
+		request.Nodes = append(request.Nodes, provision.NodeRequest{
+			Name:     fmt.Sprintf("%s-controlplane-%d", clusterName, i+1),
+			Type:     machine.TypeControlPlane,
+			IPs:      []netip.Addr{nodeIPs[i]},
+			Memory:   2048 * 1024 * 1024,
+			NanoCPUs: 2 * 1000 * 1000 * 1000,
+			Disks:    localClusterDisks(),
+			Config:   configBundle.ControlPlane(),
+		})
+	}
+
+	for i := range workers {
+		request.Nodes = append(request.Nodes, provision.NodeRequest{
+			Name:     fmt.Sprintf("%s-worker-%d", clusterName, i+1),
+			Type:     machine.TypeWorker,
+			IPs:      []netip.Addr{nodeIPs[controlPlanes+i]},
+			Memory:   2048 * 1024 * 1024,
+			NanoCPUs: 2 * 1000 * 1000 * 1000,
+			Disks:    localClusterDisks(),
+			Config:   configBundle.Worker(),
+		})
+	}
+
+	talosConfig := configBundle.TalosConfig()
+
+	provisionOptions := []provision.Option{
+		provision.WithLogWriter(io.Discard),
+		provision.WithKubernetesEndpoint(kubernetesEndpoint),
+		provision.WithTalosConfig(talosConfig),
+	}
+
+	cluster, err := provisioner.Create(ctxDeadline, request, provisionOptions...)
+	if err != nil {
+		resp.Diagnostics.AddError("failed to create cluster", err.Error())
+
+		// the cluster might be partially created, so clean up the nodes and the network which were created
+		if partialCluster, reflectErr := provisioner.Reflect(ctx, clusterName, stateDirectory); reflectErr == nil {
+			if destroyErr := provisioner.Destroy(ctx, partialCluster, provision.WithLogWriter(io.Discard)); destroyErr != nil {
+				resp.Diagnostics.AddError("failed to destroy partially created cluster", destroyErr.Error())
+			}
+		}
+
+		return
+	}
+
+	talosContext := talosConfig.Contexts[talosConfig.Context]
+
+	state.ID = basetypes.NewStringValue(clusterName)
+	state.Endpoint = basetypes.NewStringValue(endpoints[0])
+	state.KubernetesEndpoint = basetypes.NewStringValue(kubernetesEndpoint)
+	state.ClientConfiguration = clientConfiguration{
+		CA:   basetypes.NewStringValue(talosContext.CA),
+		Cert: basetypes.NewStringValue(talosContext.Crt),
+		Key:  basetypes.NewStringValue(talosContext.Key),
+	}
+	state.KubeConfigRaw = basetypes.NewStringNull()
+	state.Nodes = make([]localClusterNode, 0, len(cluster.Info().Nodes))
+
+	for _, node := range cluster.Info().Nodes {
+		var address string
+
+		if len(node.IPs) > 0 {
+			address = node.IPs[0].String()
+		}
+
+		state.Nodes = append(state.Nodes, localClusterNode{
+			Name:    basetypes.NewStringValue(node.Name),
+			Type:    basetypes.NewStringValue(node.Type.String()),
+			Address: basetypes.NewStringValue(address),
+		})
+	}
+
+	// the cluster is created at this point, so the subsequent failures should taint the resource instead of leaking the nodes
+	defer func() {
+		resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+	}()
+
+	clusterAccess := access.NewAdapter(cluster, provisionOptions...)
+	defer clusterAccess.Close() //nolint:errcheck
+
+	if err = clusterAccess.Bootstrap(ctxDeadline, io.Discard); err != nil {
+		resp.Diagnostics.AddError("failed to bootstrap cluster", err.Error())
+
+		return
+	}
+
+	reporter := newReporter()
+
+	if err = check.Wait(ctxDeadline, clusterAccess, check.DefaultClusterChecks(), reporter); err != nil {
+		resp.Diagnostics.AddWarning("failed checks", reporter.String())
+		resp.Diagnostics.AddError("cluster health check failed", err.Error())
+
+		return
+	}
+
+	kubeConfigBytes, err := clusterAccess.Kubeconfig(ctxDeadline)
+	if err != nil {
+		resp.Diagnostics.AddError("failed to retrieve kubeconfig", err.Error())
+
+		return
+	}
+
+	kubeConfig, err := clientcmd.Load(kubeConfigBytes)
+	if err != nil {
+		resp.Diagnostics.AddError("failed to parse kubeconfig", err.Error())
+
+		return
+	}
+
+	// the kubeconfig points to the in-cluster endpoint, which might not be reachable from the host
+	for _, kubeCluster := range kubeConfig.Clusters {
+		kubeCluster.Server = kubernetesEndpoint
+	}
+
+	kubeConfigBytes, err = clientcmd.Write(*kubeConfig)
+	if err != nil {
+		resp.Diagnostics.AddError("failed to marshal kubeconfig", err.Error())
+
+		return
+	}
+
+	state.KubeConfigRaw = basetypes.NewStringValue(string(kubeConfigBytes))
+}
+
+func (r *talosLocalClusterResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state talosLocalClusterResourceModelV0
+
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	stateDirectory, err := localClusterStateDirectory(state.StateDirectory)
+	if err != nil {
+		resp.Diagnostics.AddError("failed to determine the state directory", err.Error())
+
+		return
+	}
+
+	provisioner, err := providers.Factory(ctx, state.Provisioner.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("failed to create provisioner", err.Error())
+
+		return
+	}
+
+	defer provisioner.Close() //nolint:errcheck
+
+	cluster, err := provisioner.Reflect(ctx, state.Name.ValueString(), stateDirectory)
+	if err != nil {
+		// the QEMU provisioner fails to reflect a cluster whose state was removed
+		if errors.Is(err, fs.ErrNotExist) {
+			resp.State.RemoveResource(ctx)
+
+			return
+		}
+
+		resp.Diagnostics.AddError("failed to read cluster", err.Error())
+
+		return
+	}
+
+	// the cluster was destroyed outside of terraform
+	if len(cluster.Info().Nodes) == 0 {
+		resp.State.RemoveResource(ctx)
+	}
+}
+
+func (r *talosLocalClusterResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var state talosLocalClusterResourceModelV0
+
+	// all the arguments require replacement, so only timeouts can be updated in place
+	diags := req.Plan.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *talosLocalClusterResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state talosLocalClusterResourceModelV0
+
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	deleteTimeout, diags := state.Timeouts.Delete(ctx, 10*time.Minute)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctxDeadline, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
+
+	stateDirectory, err := localClusterStateDirectory(state.StateDirectory)
+	if err != nil {
+		resp.Diagnostics.AddError("failed to determine the state directory", err.Error())
+
+		return
+	}
+
+	provisioner, err := providers.Factory(ctxDeadline, state.Provisioner.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("failed to create provisioner", err.Error())
+
+		return
+	}
+
+	defer provisioner.Close() //nolint:errcheck
+
+	cluster, err := provisioner.Reflect(ctxDeadline, state.Name.ValueString(), stateDirectory)
+	if err != nil {
+		// the cluster was already destroyed outside of terraform
+		if errors.Is(err, fs.ErrNotExist) {
+			return
+		}
+
+		resp.Diagnostics.AddError("failed to read cluster", err.Error())
+
+		return
+	}
+
+	if err = provisioner.Destroy(ctxDeadline, cluster, provision.WithLogWriter(io.Discard)); err != nil {
+		resp.Diagnostics.AddError("failed to destroy cluster", err.Error())
+	}
+}
+
+func (r *talosLocalClusterResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var provisioner types.String
+
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("provisioner"), &provisioner)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if provisioner.ValueString() != "qemu" {
+		return
+	}
+
+	// the QEMU nodes boot from the kernel and the initramfs before installing Talos to their disk
+	for _, attribute := range []string{"kernel_path", "initramfs_path"} {
+		var value types.String
+
+		resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root(attribute), &value)...)
+
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		if value.IsNull() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root(attribute),
+				"missing "+attribute,
+				attribute+" is required by the qemu provisioner",
+			)
+		}
+	}
+}
+
+// localClusterStateDirectory returns the directory the QEMU provisioner stores the state of the clusters in, defaulting to the talosctl one.
+func localClusterStateDirectory(stateDirectory types.String) (string, error) {
+	if stateDirectory.ValueString() != "" {
+		return stateDirectory.ValueString(), nil
+	}
+
+	talosDir, err := clientconfig.GetTalosDirectory()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(talosDir, "clusters"), nil
+}
+
+// localClusterDisks returns the disks of a local cluster node, the docker provisioner ignores them.
+func localClusterDisks() []*provision.Disk {
+	return []*provision.Disk{
+		{
+			Size:   6 * 1024 * 1024 * 1024,
+			Driver: "virtio",
+		},
+	}
+}
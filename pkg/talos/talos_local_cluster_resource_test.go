@@ -0,0 +1,91 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos_test
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccTalosLocalClusterResource(t *testing.T) {
+	rName := acctest.RandStringFromCharSet(10, acctest.CharSetAlpha)
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccTalosLocalClusterResourceConfig(rName),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("talos_local_cluster.this", "id", rName),
+					resource.TestCheckResourceAttr("talos_local_cluster.this", "provisioner", "docker"),
+					resource.TestCheckResourceAttr("talos_local_cluster.this", "controlplanes", "1"),
+					resource.TestCheckResourceAttr("talos_local_cluster.this", "workers", "1"),
+					resource.TestCheckResourceAttr("talos_local_cluster.this", "nameservers.#", "2"),
+					resource.TestCheckResourceAttr("talos_local_cluster.this", "nameservers.0", "8.8.8.8"),
+					resource.TestCheckResourceAttr("talos_local_cluster.this", "nodes.#", "2"),
+					resource.TestCheckResourceAttr("talos_local_cluster.this", "nodes.0.type", "controlplane"),
+					resource.TestCheckResourceAttr("talos_local_cluster.this", "nodes.0.address", "10.5.0.2"),
+					resource.TestCheckResourceAttr("talos_local_cluster.this", "nodes.1.type", "worker"),
+					resource.TestCheckResourceAttr("talos_local_cluster.this", "nodes.1.address", "10.5.0.3"),
+					resource.TestCheckResourceAttrSet("talos_local_cluster.this", "endpoint"),
+					resource.TestCheckResourceAttrSet("talos_local_cluster.this", "kubernetes_endpoint"),
+					resource.TestCheckResourceAttrSet("talos_local_cluster.this", "client_configuration.ca_certificate"),
+					resource.TestCheckResourceAttrSet("talos_local_cluster.this", "client_configuration.client_certificate"),
+					resource.TestCheckResourceAttrSet("talos_local_cluster.this", "client_configuration.client_key"),
+					resource.TestCheckResourceAttrSet("talos_local_cluster.this", "kubeconfig_raw"),
+				),
+			},
+			// ensure there is no diff
+			{
+				Config:   testAccTalosLocalClusterResourceConfig(rName),
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestAccTalosLocalClusterResourceQEMUValidation(t *testing.T) {
+	rName := acctest.RandStringFromCharSet(10, acctest.CharSetAlpha)
+
+	resource.ParallelTest(t, resource.TestCase{
+		IsUnitTest:               true, // the validation runs before provisioning
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccTalosLocalClusterResourceQEMUConfig(rName, ""),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("kernel_path is required by the qemu provisioner"),
+			},
+			{
+				Config:             testAccTalosLocalClusterResourceQEMUConfig(rName, `kernel_path = "_out/vmlinuz-$${ARCH}"`),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func testAccTalosLocalClusterResourceConfig(rName string) string {
+	return fmt.Sprintf(`
+resource "talos_local_cluster" "this" {
+  name = "%s"
+}
+`, rName)
+}
+
+func testAccTalosLocalClusterResourceQEMUConfig(rName, kernelPath string) string {
+	return fmt.Sprintf(`
+resource "talos_local_cluster" "this" {
+  name           = "%s"
+  provisioner    = "qemu"
+  initramfs_path = "_out/initramfs-$${ARCH}.xz"
+  %s
+}
+`, rName, kernelPath)
+}
@@ -6,17 +6,23 @@ package talos
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
 	machineapi "github.com/siderolabs/talos/pkg/machinery/api/machine"
 	"github.com/siderolabs/talos/pkg/machinery/client"
@@ -24,13 +30,16 @@ import (
 	"google.golang.org/grpc/status"
 )
 
-type talosMachineBootstrapResource struct{}
+type talosMachineBootstrapResource struct {
+	providerData *talosProviderData
+}
 
 var (
 	_ resource.Resource                 = &talosMachineBootstrapResource{}
 	_ resource.ResourceWithModifyPlan   = &talosMachineBootstrapResource{}
 	_ resource.ResourceWithUpgradeState = &talosMachineBootstrapResource{}
 	_ resource.ResourceWithImportState  = &talosMachineBootstrapResource{}
+	_ resource.ResourceWithConfigure    = &talosMachineBootstrapResource{}
 )
 
 type talosMachineBootstrapResourceModelV0 struct {
@@ -41,11 +50,15 @@ type talosMachineBootstrapResourceModelV0 struct {
 }
 
 type talosMachineBootstrapResourceModelV1 struct {
-	ID                  types.String        `tfsdk:"id"`
-	Endpoint            types.String        `tfsdk:"endpoint"`
-	Node                types.String        `tfsdk:"node"`
-	ClientConfiguration clientConfiguration `tfsdk:"client_configuration"`
-	Timeouts            timeouts.Value      `tfsdk:"timeouts"`
+	ID                  types.String         `tfsdk:"id"`
+	Endpoint            types.String         `tfsdk:"endpoint"`
+	Endpoints           []types.String       `tfsdk:"endpoints"`
+	Port                types.Int64          `tfsdk:"port"`
+	Node                types.String         `tfsdk:"node"`
+	ClientConfiguration *clientConfiguration `tfsdk:"client_configuration"`
+	Wait                types.Bool           `tfsdk:"wait"`
+	AlreadyBootstrapped types.Bool           `tfsdk:"already_bootstrapped"`
+	Timeouts            timeouts.Value       `tfsdk:"timeouts"`
 }
 
 // NewTalosMachineBootstrapResource implements the resource.Resource interface.
@@ -74,6 +87,18 @@ func (r *talosMachineBootstrapResource) Schema(ctx context.Context, _ resource.S
 				Computed:    true,
 				Description: "The endpoint of the machine to bootstrap",
 			},
+			"endpoints": schema.ListAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Description: "The additional endpoints to fail over to if `endpoint` is unreachable, the Talos API client load balances the requests over the reachable endpoints",
+			},
+			"port": schema.Int64Attribute{
+				Optional:    true,
+				Description: "The port of the Talos API, overrides the port of `endpoint` and `endpoints` (e.g. when apid is exposed on a different port through NAT), defaults to `50000`",
+				Validators: []validator.Int64{
+					int64validator.Between(1, 65535),
+				},
+			},
 			"node": schema.StringAttribute{
 				Required:    true,
 				Description: "The name of the node to bootstrap",
@@ -94,8 +119,19 @@ func (r *talosMachineBootstrapResource) Schema(ctx context.Context, _ resource.S
 						Description: "The client key",
 					},
 				},
-				Required:    true,
-				Description: "The client configuration data",
+				Optional:    true,
+				Description: "The client configuration data, defaults to the credentials of the provider `talosconfig_path`",
+			},
+			"wait": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Wait for etcd to be a healthy single-member cluster and the Kubernetes API server to be ready after the bootstrap, within the create timeout",
+			},
+			"already_bootstrapped": schema.BoolAttribute{
+				Computed:    true,
+				Description: "Whether etcd was already bootstrapped on the node (e.g. out-of-band or by a resource which state was lost), the bootstrap is then considered successful",
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.UseStateForUnknown(),
+				},
 			},
 			"timeouts": timeouts.Attributes(ctx, timeouts.Opts{
 				Create: true,
@@ -104,6 +140,24 @@ func (r *talosMachineBootstrapResource) Schema(ctx context.Context, _ resource.S
 	}
 }
 
+func (r *talosMachineBootstrapResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*talosProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"failed to get provider data",
+			fmt.Sprintf("Expected *talosProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.providerData = providerData
+}
+
 func (r *talosMachineBootstrapResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	var state talosMachineBootstrapResourceModelV1
 
@@ -114,12 +168,7 @@ func (r *talosMachineBootstrapResource) Create(ctx context.Context, req resource
 		return
 	}
 
-	talosClientConfig, err := talosClientTFConfigToTalosClientConfig(
-		"dynamic",
-		state.ClientConfiguration.CA.ValueString(),
-		state.ClientConfiguration.Cert.ValueString(),
-		state.ClientConfiguration.Key.ValueString(),
-	)
+	talosClientConfig, err := r.providerData.talosClientConfig("dynamic", state.ClientConfiguration)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error converting config to talos client config",
@@ -139,10 +188,20 @@ func (r *talosMachineBootstrapResource) Create(ctx context.Context, req resource
 	ctxDeadline, cancel := context.WithTimeout(ctx, createTimeout)
 	defer cancel()
 
-	if err := retry.RetryContext(ctxDeadline, createTimeout, func() *retry.RetryError {
-		if err := talosClientOp(ctx, state.Endpoint.ValueString(), state.Node.ValueString(), talosClientConfig, func(nodeCtx context.Context, c *client.Client) error {
+	alreadyBootstrapped := false
+
+	if err := r.providerData.retryContext(ctxDeadline, createTimeout, func() *retry.RetryError {
+		if err := talosClientOpEndpoints(r.providerData.withClientOptions(ctx), clientEndpoints(state.Endpoint, state.Endpoints, state.Port), state.Node.ValueString(), talosClientConfig, func(nodeCtx context.Context, c *client.Client) error {
 			return c.Bootstrap(nodeCtx, &machineapi.BootstrapRequest{})
 		}); err != nil {
+			if etcdAlreadyBootstrapped(err) {
+				tflog.Info(ctx, "etcd is already bootstrapped, adopting the cluster", map[string]any{"node": state.Node.ValueString(), "error": err.Error()})
+
+				alreadyBootstrapped = true
+
+				return nil
+			}
+
 			if s := status.Code(err); s == codes.InvalidArgument {
 				return retry.NonRetryableError(err)
 			}
@@ -152,6 +211,8 @@ func (r *talosMachineBootstrapResource) Create(ctx context.Context, req resource
 
 		return nil
 	}); err != nil {
+		err = r.providerData.withSupportBundle(ctx, clientEndpoints(state.Endpoint, state.Endpoints, state.Port), state.Node.ValueString(), talosClientConfig, err)
+
 		resp.Diagnostics.AddError(
 			"Error bootstrapping node",
 			err.Error(),
@@ -160,7 +221,21 @@ func (r *talosMachineBootstrapResource) Create(ctx context.Context, req resource
 		return
 	}
 
+	if state.Wait.ValueBool() {
+		if err := talosClientOpEndpoints(r.providerData.withClientOptions(ctxDeadline), clientEndpoints(state.Endpoint, state.Endpoints, state.Port), state.Node.ValueString(), talosClientConfig, waitBootstrapConverged); err != nil {
+			err = r.providerData.withSupportBundle(ctx, clientEndpoints(state.Endpoint, state.Endpoints, state.Port), state.Node.ValueString(), talosClientConfig, err)
+
+			resp.Diagnostics.AddError(
+				"Error waiting for the bootstrap to converge",
+				err.Error(),
+			)
+
+			return
+		}
+	}
+
 	state.ID = basetypes.NewStringValue("machine_bootstrap")
+	state.AlreadyBootstrapped = basetypes.NewBoolValue(alreadyBootstrapped)
 
 	// Set state to fully populated data
 	diags = resp.State.Set(ctx, &state)
@@ -184,6 +259,11 @@ func (r *talosMachineBootstrapResource) Update(ctx context.Context, req resource
 		return
 	}
 
+	// the resources created before already_bootstrapped was introduced bootstrapped the node
+	if state.AlreadyBootstrapped.IsUnknown() {
+		state.AlreadyBootstrapped = basetypes.NewBoolValue(false)
+	}
+
 	// Set state to fully populated data
 	diags = resp.State.Set(ctx, &state)
 	resp.Diagnostics.Append(diags...)
@@ -223,8 +303,10 @@ func (r talosMachineBootstrapResource) ModifyPlan(ctx context.Context, req resou
 		return
 	}
 
-	// if either endpoint or node is unknown return early
+	// if either endpoint or node is unknown return early, deferring the creation if Terraform allows it
 	if config.Endpoint.IsUnknown() || config.Node.IsUnknown() {
+		deferCreate(req, resp)
+
 		return
 	}
 
@@ -298,9 +380,10 @@ func (r *talosMachineBootstrapResource) UpgradeState(_ context.Context) map[int6
 				}
 
 				state := talosMachineBootstrapResourceModelV1{
-					ID:       basetypes.NewStringValue("machine_bootstrap"),
-					Endpoint: priorStateData.Endpoint,
-					Node:     priorStateData.Node,
+					ID:                  basetypes.NewStringValue("machine_bootstrap"),
+					Endpoint:            priorStateData.Endpoint,
+					Node:                priorStateData.Node,
+					AlreadyBootstrapped: basetypes.NewBoolValue(false),
 					Timeouts: timeouts.Value{
 						Object: timeout,
 					},
@@ -329,8 +412,10 @@ func (r *talosMachineBootstrapResource) ImportState(ctx context.Context, _ resou
 		return
 	}
 
+	// the imported clusters were bootstrapped outside of the resource
 	state := talosMachineBootstrapResourceModelV1{
-		ID: basetypes.NewStringValue("machine_bootstrap"),
+		ID:                  basetypes.NewStringValue("machine_bootstrap"),
+		AlreadyBootstrapped: basetypes.NewBoolValue(true),
 		Timeouts: timeouts.Value{
 			Object: timeout,
 		},
@@ -344,3 +429,84 @@ func (r *talosMachineBootstrapResource) ImportState(ctx context.Context, _ resou
 		return
 	}
 }
+
+// etcdAlreadyBootstrapped returns true if the bootstrap failed because the etcd data directory of the node is not empty,
+// i.e. the cluster was bootstrapped before (out-of-band or by a resource which state was lost).
+// FailedPrecondition isn't matched, as it is returned when the node isn't ready to be bootstrapped (e.g. the time isn't in sync yet).
+func etcdAlreadyBootstrapped(err error) bool {
+	return status.Code(err) == codes.AlreadyExists
+}
+
+// waitBootstrapConverged polls the bootstrapped node until etcd is a healthy single-member cluster and the Kubernetes API server is ready.
+func waitBootstrapConverged(ctx context.Context, c *client.Client) error {
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		err := bootstrapConverged(ctx, c)
+		if err == nil {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-ticker.C:
+		}
+	}
+}
+
+func bootstrapConverged(ctx context.Context, c *client.Client) error {
+	services, err := c.ServiceInfo(ctx, "etcd")
+	if err != nil {
+		return err
+	}
+
+	if len(services) == 0 {
+		return errors.New("etcd is not running yet")
+	}
+
+	if !serviceReady(services[0].Service) {
+		return fmt.Errorf("etcd is in state %q, health message: %q", services[0].Service.GetState(), services[0].Service.GetHealth().GetLastMessage())
+	}
+
+	members, err := c.EtcdMemberList(ctx, &machineapi.EtcdMemberListRequest{})
+	if err != nil {
+		return err
+	}
+
+	if err = etcdSingleMember(members); err != nil {
+		return err
+	}
+
+	kubeConfig, err := c.Kubeconfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	httpClient, host, err := kubernetesHTTPClient(ctx, kubeConfig)
+	if err != nil {
+		return err
+	}
+
+	return kubernetesAPIReady(ctx, httpClient, host)
+}
+
+// etcdSingleMember checks that the etcd cluster formed by the bootstrap has converged to a single started member.
+func etcdSingleMember(resp *machineapi.EtcdMemberListResponse) error {
+	for _, msg := range resp.GetMessages() {
+		members := msg.GetMembers()
+
+		if len(members) != 1 {
+			return fmt.Errorf("expected a single etcd member, got %d", len(members))
+		}
+
+		if members[0].GetIsLearner() {
+			return fmt.Errorf("etcd member %q is still a learner", members[0].GetHostname())
+		}
+
+		return nil
+	}
+
+	return errors.New("no etcd members returned")
+}
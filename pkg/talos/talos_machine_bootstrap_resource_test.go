@@ -6,10 +6,16 @@ package talos_test
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	machineapi "github.com/siderolabs/talos/pkg/machinery/api/machine"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/siderolabs/terraform-provider-talos/pkg/talos"
 )
 
 func TestAccTalosMachineBootstrapResource(t *testing.T) {
@@ -32,6 +38,7 @@ func TestAccTalosMachineBootstrapResource(t *testing.T) {
 					resource.TestCheckResourceAttr("talos_machine_bootstrap.this", "id", "machine_bootstrap"),
 					resource.TestCheckResourceAttr("talos_machine_bootstrap.this", "node", "10.5.0.2"),
 					resource.TestCheckResourceAttr("talos_machine_bootstrap.this", "endpoint", "10.5.0.2"),
+					resource.TestCheckResourceAttr("talos_machine_bootstrap.this", "already_bootstrapped", "true"),
 					resource.TestCheckResourceAttrSet("talos_machine_bootstrap.this", "client_configuration.ca_certificate"),
 					resource.TestCheckResourceAttrSet("talos_machine_bootstrap.this", "client_configuration.client_certificate"),
 					resource.TestCheckResourceAttrSet("talos_machine_bootstrap.this", "client_configuration.client_key"),
@@ -80,6 +87,7 @@ func TestAccTalosMachineBootstrapResourceUpgrade(t *testing.T) {
 					resource.TestCheckResourceAttr("talos_machine_bootstrap.this", "id", "machine_bootstrap"),
 					resource.TestCheckResourceAttrSet("talos_machine_bootstrap.this", "node"),
 					resource.TestCheckResourceAttrSet("talos_machine_bootstrap.this", "endpoint"),
+					resource.TestCheckResourceAttr("talos_machine_bootstrap.this", "already_bootstrapped", "false"),
 					resource.TestCheckResourceAttrSet("talos_machine_bootstrap.this", "client_configuration.ca_certificate"),
 					resource.TestCheckResourceAttrSet("talos_machine_bootstrap.this", "client_configuration.client_certificate"),
 					resource.TestCheckResourceAttrSet("talos_machine_bootstrap.this", "client_configuration.client_key"),
@@ -132,3 +140,88 @@ resource "talos_machine_bootstrap" "this" {
 }
 `, node)
 }
+
+func TestEtcdSingleMember(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name          string
+		members       []*machineapi.EtcdMember
+		expectedError string
+	}{
+		{
+			name:    "single member",
+			members: []*machineapi.EtcdMember{{Hostname: "controlplane-1"}},
+		},
+		{
+			name:          "learner",
+			members:       []*machineapi.EtcdMember{{Hostname: "controlplane-1", IsLearner: true}},
+			expectedError: `etcd member "controlplane-1" is still a learner`,
+		},
+		{
+			name:          "several members",
+			members:       []*machineapi.EtcdMember{{Hostname: "controlplane-1"}, {Hostname: "controlplane-2"}},
+			expectedError: "expected a single etcd member, got 2",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := talos.EtcdSingleMember(&machineapi.EtcdMemberListResponse{
+				Messages: []*machineapi.EtcdMembers{{Members: tt.members}},
+			})
+
+			if tt.expectedError == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+				t.Errorf("expected error containing %q, got %v", tt.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestEtcdAlreadyBootstrapped(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{
+			name:     "already exists",
+			err:      status.Error(codes.AlreadyExists, "etcd data directory is not empty"),
+			expected: true,
+		},
+		{
+			name:     "wrapped",
+			err:      fmt.Errorf("error bootstrapping: %w", status.Error(codes.AlreadyExists, "etcd data directory is not empty")),
+			expected: true,
+		},
+		{
+			name: "time not in sync",
+			err:  status.Error(codes.FailedPrecondition, "time is not in sync yet"),
+		},
+		{
+			name: "unavailable",
+			err:  status.Error(codes.Unavailable, "connection refused"),
+		},
+		{
+			name: "no error",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if actual := talos.EtcdAlreadyBootstrapped(tt.err); actual != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, actual)
+			}
+		})
+	}
+}
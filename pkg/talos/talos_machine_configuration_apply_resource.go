@@ -5,15 +5,24 @@
 package talos
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
 	"errors"
+	"fmt"
+	"slices"
 	"strings"
+	"sync/atomic"
 	"time"
 
+	cosiresource "github.com/cosi-project/runtime/pkg/resource"
+	"github.com/cosi-project/runtime/pkg/safe"
+	"github.com/cosi-project/runtime/pkg/state"
 	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
@@ -24,22 +33,30 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
 	"github.com/siderolabs/talos/cmd/talosctl/pkg/talos/action"
 	machineapi "github.com/siderolabs/talos/pkg/machinery/api/machine"
 	"github.com/siderolabs/talos/pkg/machinery/client"
+	clientconfig "github.com/siderolabs/talos/pkg/machinery/client/config"
+	"github.com/siderolabs/talos/pkg/machinery/config/configloader"
 	"github.com/siderolabs/talos/pkg/machinery/config/configpatcher"
+	"github.com/siderolabs/talos/pkg/machinery/config/encoder"
+	"github.com/siderolabs/talos/pkg/machinery/resources/cluster"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
-type talosMachineConfigurationApplyResource struct{}
+type talosMachineConfigurationApplyResource struct {
+	providerData *talosProviderData
+}
 
 var (
 	_ resource.Resource                 = &talosMachineConfigurationApplyResource{}
 	_ resource.ResourceWithModifyPlan   = &talosMachineConfigurationApplyResource{}
 	_ resource.ResourceWithUpgradeState = &talosMachineConfigurationApplyResource{}
+	_ resource.ResourceWithConfigure    = &talosMachineConfigurationApplyResource{}
 )
 
 var onDestroyMarkDownDescription = `Actions to be taken on destroy, if *reset* is not set this is a no-op.
@@ -58,16 +75,31 @@ type talosMachineConfigurationApplyResourceModelV0 struct {
 }
 
 type talosMachineConfigurationApplyResourceModelV1 struct { //nolint:govet
-	ID                        types.String        `tfsdk:"id"`
-	ApplyMode                 types.String        `tfsdk:"apply_mode"`
-	Node                      types.String        `tfsdk:"node"`
-	Endpoint                  types.String        `tfsdk:"endpoint"`
-	ClientConfiguration       clientConfiguration `tfsdk:"client_configuration"`
-	MachineConfigurationInput types.String        `tfsdk:"machine_configuration_input"`
-	OnDestroy                 *onDestroyOptions   `tfsdk:"on_destroy"`
-	MachineConfiguration      types.String        `tfsdk:"machine_configuration"`
-	ConfigPatches             []types.String      `tfsdk:"config_patches"`
-	Timeouts                  timeouts.Value      `tfsdk:"timeouts"`
+	ID                         types.String         `tfsdk:"id"`
+	ApplyMode                  types.String         `tfsdk:"apply_mode"`
+	Node                       types.String         `tfsdk:"node"`
+	Endpoint                   types.String         `tfsdk:"endpoint"`
+	Endpoints                  []types.String       `tfsdk:"endpoints"`
+	Port                       types.Int64          `tfsdk:"port"`
+	ClientConfiguration        *clientConfiguration `tfsdk:"client_configuration"`
+	MachineConfigurationInput  types.String         `tfsdk:"machine_configuration_input"`
+	OnDestroy                  *onDestroyOptions    `tfsdk:"on_destroy"`
+	MachineConfiguration       types.String         `tfsdk:"machine_configuration"`
+	MachineConfigurationSHA256 types.String         `tfsdk:"machine_configuration_sha256"`
+	ConfigPatches              []types.String       `tfsdk:"config_patches"`
+	ValidationMode             types.String         `tfsdk:"validation_mode"`
+	OutputFormat               types.String         `tfsdk:"output_format"`
+	PostApplyChecks            *postApplyChecks     `tfsdk:"post_apply_checks"`
+	DryRun                     types.Bool           `tfsdk:"dry_run"`
+	DiffSummary                types.Bool           `tfsdk:"diff_summary"`
+	ConfigurationDiffSummary   types.List           `tfsdk:"configuration_diff_summary"`
+	OperationStats             types.Object         `tfsdk:"operation_stats"`
+	AppliedMode                types.String         `tfsdk:"applied_mode"`
+	Rebooted                   types.Bool           `tfsdk:"rebooted"`
+	RebootRequired             types.Bool           `tfsdk:"reboot_required"`
+	Messages                   types.List           `tfsdk:"messages"`
+	NodeIdentity               types.String         `tfsdk:"node_identity"`
+	Timeouts                   timeouts.Value       `tfsdk:"timeouts"`
 }
 
 type onDestroyOptions struct {
@@ -76,6 +108,17 @@ type onDestroyOptions struct {
 	Reboot   bool `tfsdk:"reboot"`
 }
 
+type postApplyChecks struct {
+	Services  []types.String           `tfsdk:"services"`
+	Resources []postApplyResourceCheck `tfsdk:"resources"`
+}
+
+type postApplyResourceCheck struct {
+	Namespace types.String `tfsdk:"namespace"`
+	Type      types.String `tfsdk:"type"`
+	ID        types.String `tfsdk:"id"`
+}
+
 // NewTalosMachineConfigurationApplyResource implements the resource.Resource interface.
 func NewTalosMachineConfigurationApplyResource() resource.Resource {
 	return &talosMachineConfigurationApplyResource{}
@@ -87,12 +130,12 @@ func (p *talosMachineConfigurationApplyResource) Metadata(_ context.Context, req
 
 func (p *talosMachineConfigurationApplyResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
-		Version:     1,
+		Version:     2,
 		Description: "The machine configuration apply resource allows to apply machine configuration to a node",
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
 				Computed:    true,
-				Description: "This is a unique identifier for the machine ",
+				Description: "This is a unique identifier for the machine, derived from the endpoint and the node",
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.UseStateForUnknown(),
 				},
@@ -115,6 +158,18 @@ func (p *talosMachineConfigurationApplyResource) Schema(ctx context.Context, _ r
 				Computed:    true,
 				Description: "The endpoint of the machine to bootstrap",
 			},
+			"endpoints": schema.ListAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Description: "The additional endpoints to fail over to if `endpoint` is unreachable, the Talos API client load balances the requests over the reachable endpoints",
+			},
+			"port": schema.Int64Attribute{
+				Optional:    true,
+				Description: "The port of the Talos API, overrides the port of `endpoint` and `endpoints` (e.g. when apid is exposed on a different port through NAT), defaults to `50000`",
+				Validators: []validator.Int64{
+					int64validator.Between(1, 65535),
+				},
+			},
 			"client_configuration": schema.SingleNestedAttribute{
 				Attributes: map[string]schema.Attribute{
 					"ca_certificate": schema.StringAttribute{
@@ -131,8 +186,8 @@ func (p *talosMachineConfigurationApplyResource) Schema(ctx context.Context, _ r
 						Description: "The client key",
 					},
 				},
-				Required:    true,
-				Description: "The client configuration data",
+				Optional:    true,
+				Description: "The client configuration data, defaults to the credentials of the provider `talosconfig_path`",
 			},
 			"machine_configuration_input": schema.StringAttribute{
 				Description: "The machine configuration to apply",
@@ -169,13 +224,130 @@ func (p *talosMachineConfigurationApplyResource) Schema(ctx context.Context, _ r
 				Computed:    true,
 				Sensitive:   true,
 			},
+			"machine_configuration_sha256": schema.StringAttribute{
+				Description: "The SHA-256 checksum of `machine_configuration`, to depend on the machine configuration changes without referencing the sensitive machine configuration",
+				Computed:    true,
+			},
 			"config_patches": schema.ListAttribute{
 				ElementType: types.StringType,
 				Optional:    true,
 				Description: "The list of config patches to apply",
 			},
+			"validation_mode": schema.StringAttribute{
+				Description: "How the machine configuration is validated at plan time: `strict` fails on unknown fields, validation errors and warnings, " +
+					"`permissive` fails on unknown fields and validation errors and reports the warnings, `off` skips the validation. Defaults to `permissive`",
+				Optional: true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(validationModeNames...),
+				},
+			},
+			"output_format": schema.StringAttribute{
+				Description: "The format of `machine_configuration`, `yaml` or `json`. In `json` the documents of a multi-document configuration are separated by `---`. Defaults to `yaml`",
+				Optional:    true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(outputFormatNames...),
+				},
+			},
+			"dry_run": schema.BoolAttribute{
+				Description: "Apply the planned machine configuration to the node in dry-run mode during the plan, when the node is reachable, " +
+					"and report the configuration diff computed by the node and whether a reboot would be required as a warning",
+				Optional: true,
+			},
+			"diff_summary": schema.BoolAttribute{
+				Description: "Plan `configuration_diff_summary`, the paths of the machine configuration values changed by the plan",
+				Optional:    true,
+			},
+			"configuration_diff_summary": schema.ListAttribute{
+				ElementType: types.StringType,
+				Description: "The dot-separated paths of the machine configuration values changed by the last planned change, set if `diff_summary` is enabled. " +
+					"Only the paths are listed, so that the secrets in the machine configuration are not disclosed",
+				Computed: true,
+			},
+			"post_apply_checks": schema.SingleNestedAttribute{
+				Description: "Conditions to be satisfied after the configuration is applied, the operation fails with a diagnostic for each unsatisfied condition if they are not met within the timeout",
+				Optional:    true,
+				Attributes: map[string]schema.Attribute{
+					"services": schema.ListAttribute{
+						ElementType: types.StringType,
+						Optional:    true,
+						Description: "The list of services which should be running and healthy",
+					},
+					"resources": schema.ListNestedAttribute{
+						Optional:    true,
+						Description: "The list of resources which should exist",
+						NestedObject: schema.NestedAttributeObject{
+							Attributes: map[string]schema.Attribute{
+								"namespace": schema.StringAttribute{
+									Optional:    true,
+									Description: "The namespace of the resource, defaults to the resource type default namespace",
+								},
+								"type": schema.StringAttribute{
+									Required:    true,
+									Description: "The type of the resource, aliases are supported (e.g. `members`, `nodename`)",
+								},
+								"id": schema.StringAttribute{
+									Required:    true,
+									Description: "The ID of the resource",
+								},
+							},
+						},
+					},
+				},
+			},
+			"operation_stats": schema.SingleNestedAttribute{
+				Description: "Statistics of the Talos API calls done by the last create or update operation",
+				Computed:    true,
+				Attributes: map[string]schema.Attribute{
+					"rpc_count": schema.Int64Attribute{
+						Computed:    true,
+						Description: "The number of Talos API calls",
+					},
+					"bytes_sent": schema.Int64Attribute{
+						Computed:    true,
+						Description: "The number of bytes sent to the node",
+					},
+					"bytes_received": schema.Int64Attribute{
+						Computed:    true,
+						Description: "The number of bytes received from the node",
+					},
+					"retries": schema.Int64Attribute{
+						Computed:    true,
+						Description: "The number of retries performed while applying the configuration and waiting for the post apply checks",
+					},
+					"duration": schema.StringAttribute{
+						Computed:    true,
+						Description: "The total duration of the operation",
+					},
+				},
+			},
+			"applied_mode": schema.StringAttribute{
+				Description: "The apply mode actually used by the node, e.g. `reboot` if the `auto` mode decided that a reboot is required",
+				Computed:    true,
+			},
+			"rebooted": schema.BoolAttribute{
+				Description: "Whether the node rebooted to apply the machine configuration of the last apply operation",
+				Computed:    true,
+			},
+			"reboot_required": schema.BoolAttribute{
+				Description: "Whether the machine configuration of the last apply operation is only applied on the next reboot of the node (`staged` mode)",
+				Computed:    true,
+			},
+			"messages": schema.ListAttribute{
+				ElementType: types.StringType,
+				Description: "The messages and warnings returned by the node for the last apply operation",
+				Computed:    true,
+			},
+			"node_identity": schema.StringAttribute{
+				Description: "The identity of the node, generated again when the node is wiped and reinstalled. " +
+					"If the identity of the node changes or the node is back in maintenance mode, the resource is removed from the state so that the machine configuration is applied again.",
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
 			"timeouts": timeouts.Attributes(ctx, timeouts.Opts{
 				Create: true,
+				Read:   true,
 				Update: true,
 				Delete: true,
 			}),
@@ -183,6 +355,24 @@ func (p *talosMachineConfigurationApplyResource) Schema(ctx context.Context, _ r
 	}
 }
 
+func (p *talosMachineConfigurationApplyResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*talosProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"failed to get provider data",
+			fmt.Sprintf("Expected *talosProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	p.providerData = providerData
+}
+
 func (p *talosMachineConfigurationApplyResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) { //nolint:dupl
 	var state talosMachineConfigurationApplyResourceModelV1
 
@@ -193,12 +383,7 @@ func (p *talosMachineConfigurationApplyResource) Create(ctx context.Context, req
 		return
 	}
 
-	talosClientConfig, err := talosClientTFConfigToTalosClientConfig(
-		"dynamic",
-		state.ClientConfiguration.CA.ValueString(),
-		state.ClientConfiguration.Cert.ValueString(),
-		state.ClientConfiguration.Key.ValueString(),
-	)
+	talosClientConfig, err := p.providerData.talosClientConfig("dynamic", state.ClientConfiguration)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error converting config to talos client config",
@@ -215,30 +400,37 @@ func (p *talosMachineConfigurationApplyResource) Create(ctx context.Context, req
 		return
 	}
 
+	ctx, opStats := withOperationStats(ctx)
+
+	var applyResp *machineapi.ApplyConfigurationResponse
+
 	ctxDeadline, cancel := context.WithTimeout(ctx, createTimeout)
 	defer cancel()
 
-	if err := retry.RetryContext(ctxDeadline, createTimeout, func() *retry.RetryError {
-		if err := talosClientOp(ctx, state.Endpoint.ValueString(), state.Node.ValueString(), talosClientConfig, func(nodeCtx context.Context, c *client.Client) error {
-			_, err := c.ApplyConfiguration(nodeCtx, &machineapi.ApplyConfigurationRequest{
+	if err := p.providerData.retryContext(ctxDeadline, createTimeout, func() *retry.RetryError {
+		if err := talosClientOpEndpoints(p.providerData.withClientOptions(ctx), clientEndpoints(state.Endpoint, state.Endpoints, state.Port), state.Node.ValueString(), talosClientConfig, func(nodeCtx context.Context, c *client.Client) error {
+			var err error
+
+			applyResp, err = c.ApplyConfiguration(nodeCtx, &machineapi.ApplyConfigurationRequest{
 				Mode: machineapi.ApplyConfigurationRequest_Mode(machineapi.ApplyConfigurationRequest_Mode_value[strings.ToUpper(state.ApplyMode.ValueString())]),
 				Data: []byte(state.MachineConfiguration.ValueString()),
 			})
-			if err != nil {
-				return err
-			}
 
-			return nil
+			return err
 		}); err != nil {
 			if s := status.Code(err); s == codes.InvalidArgument {
 				return retry.NonRetryableError(err)
 			}
 
+			opStats.retries.Add(1)
+
 			return retry.RetryableError(err)
 		}
 
 		return nil
 	}); err != nil {
+		err = p.providerData.withSupportBundle(ctx, clientEndpoints(state.Endpoint, state.Endpoints, state.Port), state.Node.ValueString(), talosClientConfig, err)
+
 		resp.Diagnostics.AddError(
 			"Error applying configuration",
 			err.Error(),
@@ -247,7 +439,36 @@ func (p *talosMachineConfigurationApplyResource) Create(ctx context.Context, req
 		return
 	}
 
-	state.ID = basetypes.NewStringValue("machine_configuration_apply")
+	resp.Diagnostics.Append(state.setApplyResult(applyResp)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if state.PostApplyChecks != nil {
+		resp.Diagnostics.Append(state.PostApplyChecks.wait(p.providerData.withClientOptions(ctxDeadline), clientEndpoints(state.Endpoint, state.Endpoints, state.Port), state.Node.ValueString(), talosClientConfig, createTimeout)...)
+
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	resp.Diagnostics.Append(state.setConfigurationDiffSummary(ctx, types.StringNull())...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	state.ID = basetypes.NewStringValue(machineConfigurationApplyID(state.Endpoint.ValueString(), state.Node.ValueString()))
+	state.MachineConfigurationSHA256 = machineConfigurationSHA256(state.MachineConfiguration)
+	state.NodeIdentity = p.nodeIdentity(ctx, &state, talosClientConfig, types.StringNull())
+
+	state.OperationStats, diags = opStats.value()
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
 	// Set state to fully populated data
 	diags = resp.State.Set(ctx, &state)
@@ -258,7 +479,88 @@ func (p *talosMachineConfigurationApplyResource) Create(ctx context.Context, req
 	}
 }
 
-func (p *talosMachineConfigurationApplyResource) Read(_ context.Context, _ resource.ReadRequest, _ *resource.ReadResponse) {
+func (p *talosMachineConfigurationApplyResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state talosMachineConfigurationApplyResourceModelV1
+
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	talosClientConfig, err := p.providerData.talosClientConfig("dynamic", state.ClientConfiguration)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error converting config to talos client config",
+			err.Error(),
+		)
+
+		return
+	}
+
+	// the states created before the checksum was added don't have it
+	state.MachineConfigurationSHA256 = machineConfigurationSHA256(state.MachineConfiguration)
+
+	identity, err := p.readNodeIdentity(ctx, &state, talosClientConfig)
+
+	switch {
+	case err == nil && state.NodeIdentity.IsNull():
+		// the node identity wasn't known yet, e.g. the node was still installing when the configuration was applied
+		state.NodeIdentity = types.StringValue(identity)
+	case nodeReinstalled(state.NodeIdentity, identity, err):
+		resp.Diagnostics.AddWarning(
+			"node was reinstalled",
+			fmt.Sprintf("the identity of node %s changed, the machine configuration will be applied again", state.Node.ValueString()),
+		)
+
+		resp.State.RemoveResource(ctx)
+
+		return
+	case err != nil:
+		// the node might be unreachable or rebooting, the state is kept as is
+		tflog.Debug(ctx, "failed to read the node identity", map[string]any{"node": state.Node.ValueString(), "error": err.Error()})
+
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// nodeIdentity returns the identity of the node if it can be read, the fallback value otherwise.
+func (p *talosMachineConfigurationApplyResource) nodeIdentity(ctx context.Context, state *talosMachineConfigurationApplyResourceModelV1, tc *clientconfig.Config, fallback types.String) types.String {
+	identity, err := p.readNodeIdentity(ctx, state, tc)
+	if err != nil {
+		return fallback
+	}
+
+	return types.StringValue(identity)
+}
+
+func (p *talosMachineConfigurationApplyResource) readNodeIdentity(ctx context.Context, state *talosMachineConfigurationApplyResourceModelV1, tc *clientconfig.Config) (string, error) {
+	readTimeout, diags := state.Timeouts.Read(ctx, nodeIdentityTimeout)
+	if diags.HasError() {
+		return "", fmt.Errorf("failed to get the read timeout: %v", diags)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
+	var identity string
+
+	err := talosClientOpEndpoints(p.providerData.withClientOptions(ctx), clientEndpoints(state.Endpoint, state.Endpoints, state.Port), state.Node.ValueString(), tc, func(nodeCtx context.Context, c *client.Client) error {
+		if maintenanceMode(nodeCtx) {
+			return errMaintenanceMode
+		}
+
+		var err error
+
+		identity, err = nodeIdentity(nodeCtx, c)
+
+		return err
+	})
+
+	return identity, err
 }
 
 func (p *talosMachineConfigurationApplyResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) { //nolint:dupl
@@ -271,12 +573,7 @@ func (p *talosMachineConfigurationApplyResource) Update(ctx context.Context, req
 		return
 	}
 
-	talosClientConfig, err := talosClientTFConfigToTalosClientConfig(
-		"dynamic",
-		state.ClientConfiguration.CA.ValueString(),
-		state.ClientConfiguration.Cert.ValueString(),
-		state.ClientConfiguration.Key.ValueString(),
-	)
+	talosClientConfig, err := p.providerData.talosClientConfig("dynamic", state.ClientConfiguration)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error converting config to talos client config",
@@ -293,47 +590,291 @@ func (p *talosMachineConfigurationApplyResource) Update(ctx context.Context, req
 		return
 	}
 
+	var priorState talosMachineConfigurationApplyResourceModelV1
+
+	diags = req.State.Get(ctx, &priorState)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// the machine configuration is unchanged (e.g. only cosmetic changes to the input), there is nothing to apply
+	skipApply := !machineConfigurationApplyRequired(priorState.MachineConfiguration, state.MachineConfiguration)
+
+	ctx, opStats := withOperationStats(ctx)
+
+	var applyResp *machineapi.ApplyConfigurationResponse
+
 	ctxDeadline, cancel := context.WithTimeout(ctx, updateTimeout)
 	defer cancel()
 
-	if err := retry.RetryContext(ctxDeadline, updateTimeout, func() *retry.RetryError {
-		if err := talosClientOp(ctx, state.Endpoint.ValueString(), state.Node.ValueString(), talosClientConfig, func(nodeCtx context.Context, c *client.Client) error {
-			_, err := c.ApplyConfiguration(nodeCtx, &machineapi.ApplyConfigurationRequest{
+	if err := p.providerData.retryContext(ctxDeadline, updateTimeout, func() *retry.RetryError {
+		if skipApply {
+			return nil
+		}
+
+		if err := talosClientOpEndpoints(p.providerData.withClientOptions(ctx), clientEndpoints(state.Endpoint, state.Endpoints, state.Port), state.Node.ValueString(), talosClientConfig, func(nodeCtx context.Context, c *client.Client) error {
+			var err error
+
+			applyResp, err = c.ApplyConfiguration(nodeCtx, &machineapi.ApplyConfigurationRequest{
 				Mode: machineapi.ApplyConfigurationRequest_Mode(machineapi.ApplyConfigurationRequest_Mode_value[strings.ToUpper(state.ApplyMode.ValueString())]),
 				Data: []byte(state.MachineConfiguration.ValueString()),
 			})
-			if err != nil {
-				return err
-			}
 
-			return nil
+			return err
 		}); err != nil {
 			if s := status.Code(err); s == codes.InvalidArgument {
 				return retry.NonRetryableError(err)
 			}
 
+			opStats.retries.Add(1)
+
 			return retry.RetryableError(err)
 		}
 
 		return nil
 	}); err != nil {
+		err = p.providerData.withSupportBundle(ctx, clientEndpoints(state.Endpoint, state.Endpoints, state.Port), state.Node.ValueString(), talosClientConfig, err)
+
 		resp.Diagnostics.AddError(
 			"Error applying configuration",
 			err.Error(),
 		)
 
-		return
+		return
+	}
+
+	if skipApply {
+		state.AppliedMode = priorState.AppliedMode
+		state.Rebooted = priorState.Rebooted
+		state.RebootRequired = priorState.RebootRequired
+		state.Messages = priorState.Messages
+	} else {
+		resp.Diagnostics.Append(state.setApplyResult(applyResp)...)
+	}
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if state.PostApplyChecks != nil {
+		resp.Diagnostics.Append(state.PostApplyChecks.wait(p.providerData.withClientOptions(ctxDeadline), clientEndpoints(state.Endpoint, state.Endpoints, state.Port), state.Node.ValueString(), talosClientConfig, updateTimeout)...)
+
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	resp.Diagnostics.Append(state.setConfigurationDiffSummary(ctx, priorState.MachineConfiguration)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	state.ID = basetypes.NewStringValue(machineConfigurationApplyID(state.Endpoint.ValueString(), state.Node.ValueString()))
+	state.MachineConfigurationSHA256 = machineConfigurationSHA256(state.MachineConfiguration)
+	state.NodeIdentity = p.nodeIdentity(ctx, &state, talosClientConfig, priorState.NodeIdentity)
+
+	state.OperationStats, diags = opStats.value()
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Set state to fully populated data
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// setConfigurationDiffSummary sets the diff summary if it was unknown at plan time, e.g. when the machine configuration input was unknown.
+func (s *talosMachineConfigurationApplyResourceModelV1) setConfigurationDiffSummary(ctx context.Context, applied types.String) diag.Diagnostics {
+	if !s.ConfigurationDiffSummary.IsUnknown() {
+		return nil
+	}
+
+	if !s.DiffSummary.ValueBool() {
+		s.ConfigurationDiffSummary = types.ListNull(types.StringType)
+
+		return nil
+	}
+
+	var diags diag.Diagnostics
+
+	s.ConfigurationDiffSummary, diags = configurationDiffSummary(ctx, applied, s.MachineConfiguration.ValueString())
+
+	return diags
+}
+
+// configurationDiffSummary returns the paths of the values changed from the applied to the planned machine configuration,
+// the summary is empty if no machine configuration was applied yet as the whole configuration is new.
+func configurationDiffSummary(ctx context.Context, applied types.String, planned string) (types.List, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	if applied.IsNull() || applied.IsUnknown() {
+		return types.ListValueMust(types.StringType, []attr.Value{}), diags
+	}
+
+	changes, err := machineConfigurationChanges(applied.ValueString(), planned)
+	if err != nil {
+		diags.AddError("Error comparing machine configurations", err.Error())
+
+		return types.ListNull(types.StringType), diags
+	}
+
+	if changes == nil {
+		changes = []string{}
+	}
+
+	return types.ListValueFrom(ctx, types.StringType, changes)
+}
+
+// installTimeChangesWarnings warns about the changes of the machine configuration which don't take effect on the running node.
+func installTimeChangesWarnings(applied, planned string) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	changes, err := machineConfigurationChanges(applied, planned)
+	if err != nil {
+		diags.AddError("Error comparing machine configurations", err.Error())
+
+		return diags
+	}
+
+	for _, warning := range installTimeChanges(changes) {
+		diags.AddAttributeWarning(
+			path.Root("machine_configuration_input"),
+			"machine configuration change doesn't take effect on the running node",
+			warning,
+		)
+	}
+
+	return diags
+}
+
+// dryRunTimeout bounds the dry run during the plan, so that an unreachable node doesn't block the plan.
+const dryRunTimeout = 30 * time.Second
+
+// dryRun applies the machine configuration in dry-run mode and reports the summary of the node as a warning,
+// the dry run is skipped with a warning if the node can't be reached.
+func (p *talosMachineConfigurationApplyResource) dryRun(ctx context.Context, state *talosMachineConfigurationApplyResourceModelV1, machineConfiguration string) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if state.ClientConfiguration != nil && (state.ClientConfiguration.CA.IsUnknown() || state.ClientConfiguration.Cert.IsUnknown() || state.ClientConfiguration.Key.IsUnknown()) {
+		return diags
+	}
+
+	talosClientConfig, err := p.providerData.talosClientConfig("dynamic", state.ClientConfiguration)
+	if err != nil {
+		diags.AddAttributeWarning(path.Root("dry_run"), "machine configuration dry run skipped", err.Error())
+
+		return diags
+	}
+
+	// the plan is read from the configuration, so the apply mode default is not set yet
+	applyMode := state.ApplyMode.ValueString()
+	if state.ApplyMode.IsNull() || state.ApplyMode.IsUnknown() {
+		applyMode = "auto"
+	}
+
+	endpoint := state.Endpoint
+	if endpoint.IsNull() || endpoint.IsUnknown() {
+		endpoint = state.Node
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, dryRunTimeout)
+	defer cancel()
+
+	var applyResp *machineapi.ApplyConfigurationResponse
+
+	if err = talosClientOpEndpoints(p.providerData.withClientOptions(ctx), clientEndpoints(endpoint, state.Endpoints, state.Port), state.Node.ValueString(), talosClientConfig, func(nodeCtx context.Context, c *client.Client) error {
+		applyResp, err = c.ApplyConfiguration(nodeCtx, &machineapi.ApplyConfigurationRequest{
+			Mode:   machineapi.ApplyConfigurationRequest_Mode(machineapi.ApplyConfigurationRequest_Mode_value[strings.ToUpper(applyMode)]),
+			Data:   []byte(machineConfiguration),
+			DryRun: true,
+		})
+
+		return err
+	}); err != nil {
+		diags.AddAttributeWarning(path.Root("dry_run"), "machine configuration dry run skipped", err.Error())
+
+		return diags
+	}
+
+	diags.AddAttributeWarning(path.Root("dry_run"), "machine configuration dry run", dryRunSummary(applyResp))
+
+	return diags
+}
+
+// dryRunSummary returns the summary of the dry run returned by the node, i.e. the mode the configuration would be applied with and the configuration diff.
+func dryRunSummary(applyResp *machineapi.ApplyConfigurationResponse) string {
+	summary := make([]string, 0, len(applyResp.GetMessages()))
+
+	for _, msg := range applyResp.GetMessages() {
+		if msg.GetModeDetails() != "" {
+			summary = append(summary, msg.GetModeDetails())
+		}
+
+		for _, warning := range msg.GetWarnings() {
+			summary = append(summary, "warning: "+warning)
+		}
+	}
+
+	if len(summary) == 0 {
+		return "the node returned no dry run summary"
+	}
+
+	return strings.Join(summary, "\n")
+}
+
+// setApplyResult records the apply mode chosen by the node and the messages it returned,
+// the configuration validation warnings are surfaced as diagnostics.
+func (s *talosMachineConfigurationApplyResourceModelV1) setApplyResult(applyResp *machineapi.ApplyConfigurationResponse) diag.Diagnostics {
+	var (
+		diags       diag.Diagnostics
+		appliedMode string
+		messages    []attr.Value
+	)
+
+	requestedMode := strings.ToLower(s.ApplyMode.ValueString())
+
+	for _, msg := range applyResp.GetMessages() {
+		appliedMode = strings.ToLower(msg.GetMode().String())
+
+		if msg.GetModeDetails() != "" {
+			messages = append(messages, types.StringValue(msg.GetModeDetails()))
+
+			// surface the cases where the node decided differently than requested, e.g. `auto` requiring a reboot
+			if appliedMode != requestedMode && appliedMode != strings.ToLower(machineapi.ApplyConfigurationRequest_NO_REBOOT.String()) {
+				diags.AddWarning(fmt.Sprintf("machine configuration applied with mode %q", appliedMode), msg.GetModeDetails())
+			}
+		}
+
+		for _, warning := range msg.GetWarnings() {
+			messages = append(messages, types.StringValue(warning))
+
+			diags.AddWarning("machine configuration validation warning", warning)
+		}
 	}
 
-	state.ID = basetypes.NewStringValue("machine_configuration_apply")
+	if appliedMode == "" {
+		appliedMode = requestedMode
+	}
 
-	// Set state to fully populated data
-	diags = resp.State.Set(ctx, &state)
-	resp.Diagnostics.Append(diags...)
+	s.AppliedMode = types.StringValue(appliedMode)
+	s.Rebooted = types.BoolValue(appliedMode == strings.ToLower(machineapi.ApplyConfigurationRequest_REBOOT.String()))
+	s.RebootRequired = types.BoolValue(appliedMode == strings.ToLower(machineapi.ApplyConfigurationRequest_STAGED.String()))
 
-	if resp.Diagnostics.HasError() {
-		return
-	}
+	var listDiags diag.Diagnostics
+
+	s.Messages, listDiags = types.ListValue(types.StringType, messages)
+	diags.Append(listDiags...)
+
+	return diags
 }
 
 func (p *talosMachineConfigurationApplyResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
@@ -347,12 +888,7 @@ func (p *talosMachineConfigurationApplyResource) Delete(ctx context.Context, req
 	}
 
 	if state.OnDestroy != nil && state.OnDestroy.Reset {
-		talosClientConfig, err := talosClientTFConfigToTalosClientConfig(
-			"dynamic",
-			state.ClientConfiguration.CA.ValueString(),
-			state.ClientConfiguration.Cert.ValueString(),
-			state.ClientConfiguration.Key.ValueString(),
-		)
+		talosClientConfig, err := p.providerData.talosClientConfig("dynamic", state.ClientConfiguration)
 		if err != nil {
 			resp.Diagnostics.AddError(
 				"Error converting config to talos client config",
@@ -382,8 +918,15 @@ func (p *talosMachineConfigurationApplyResource) Delete(ctx context.Context, req
 			},
 		}
 
+		var resetAccepted atomic.Bool
+
 		actionFn := func(ctx context.Context, c *client.Client) (string, error) {
-			return resetGetActorID(ctx, c, resetRequest)
+			actorID, err := resetGetActorID(ctx, c, resetRequest)
+			if err == nil {
+				resetAccepted.Store(true)
+			}
+
+			return actorID, err
 		}
 
 		var postCheckFn func(context.Context, *client.Client, string) error
@@ -395,13 +938,17 @@ func (p *talosMachineConfigurationApplyResource) Delete(ctx context.Context, req
 					client.WithTLSConfig(&tls.Config{
 						InsecureSkipVerify: true,
 					}),
-					client.WithEndpoints(state.Endpoint.ValueString()),
+					client.WithEndpoints(normalizeEndpoints(clientEndpoints(state.Endpoint, state.Endpoints, state.Port))...),
+					client.WithGRPCDialOptions(proxyDialOptionsFromContext(p.providerData.withClientOptions(ctx))...),
+					client.WithGRPCDialOptions(traceDialOptions()...),
 				)
 				if err != nil {
 					return err
 				}
 
-				_, err = insecureClient.Disks(client.WithNode(ctx, state.Node.ValueString()))
+				defer insecureClient.Close() //nolint:errcheck
+
+				_, err = insecureClient.Disks(client.WithNode(ctx, normalizeNode(state.Node.ValueString())))
 
 				// if we can get into maintenance mode, reset has succeeded
 				if err == nil {
@@ -413,18 +960,37 @@ func (p *talosMachineConfigurationApplyResource) Delete(ctx context.Context, req
 			}
 		}
 
-		if err := talosClientOp(ctx, state.Endpoint.ValueString(), state.Node.ValueString(), talosClientConfig, func(_ context.Context, c *client.Client) error {
-			executor := newClientExecutor(c, []string{state.Node.ValueString()})
+		ctxDeadline, cancel := context.WithTimeout(ctx, deleteTimeout)
+		defer cancel()
+
+		// the reset is retried until the node accepts it, the tracker then tracks its progress until the delete timeout
+		if err := p.providerData.retryContext(ctxDeadline, deleteTimeout, func() *retry.RetryError {
+			if err := talosClientOpEndpoints(p.providerData.withClientOptions(ctx), clientEndpoints(state.Endpoint, state.Endpoints, state.Port), state.Node.ValueString(), talosClientConfig, func(_ context.Context, c *client.Client) error {
+				executor := newClientExecutor(c, []string{state.Node.ValueString()})
+
+				return action.NewTracker(
+					executor,
+					action.StopAllServicesEventFn,
+					actionFn,
+					action.WithPostCheck(postCheckFn),
+					action.WithDebug(false),
+					action.WithTimeout(deleteTimeout),
+				).Run()
+			}); err != nil {
+				if resetAccepted.Load() || status.Code(err) == codes.Unimplemented {
+					return retry.NonRetryableError(err)
+				}
+
+				return retry.RetryableError(err)
+			}
 
-			return action.NewTracker(
-				executor,
-				action.StopAllServicesEventFn,
-				actionFn,
-				action.WithPostCheck(postCheckFn),
-				action.WithDebug(false),
-				action.WithTimeout(deleteTimeout),
-			).Run()
+			return nil
 		}); err != nil {
+			// the node is already in maintenance mode, i.e. it has been reset before
+			if status.Code(err) == codes.Unimplemented {
+				return
+			}
+
 			resp.Diagnostics.AddError("Error resetting machine", err.Error())
 
 			return
@@ -459,8 +1025,27 @@ func (p *talosMachineConfigurationApplyResource) ModifyPlan(ctx context.Context,
 		return
 	}
 
-	// if either endpoint or node is unknown return early
-	if config.Endpoint.IsUnknown() || config.Node.IsUnknown() || config.MachineConfiguration.IsUnknown() {
+	// if either endpoint or node is unknown return early, deferring the creation if Terraform allows it
+	if config.Endpoint.IsUnknown() || config.Node.IsUnknown() {
+		deferCreate(req, resp)
+
+		return
+	}
+
+	// the ID is planned as it changes with the endpoint or the node
+	endpoint := config.Endpoint.ValueString()
+	if config.Endpoint.IsNull() {
+		endpoint = config.Node.ValueString()
+	}
+
+	diags = resp.Plan.SetAttribute(ctx, path.Root("id"), machineConfigurationApplyID(endpoint, config.Node.ValueString()))
+	resp.Diagnostics.Append(diags...)
+
+	if diags.HasError() {
+		return
+	}
+
+	if config.MachineConfiguration.IsUnknown() {
 		return
 	}
 
@@ -508,37 +1093,144 @@ func (p *talosMachineConfigurationApplyResource) ModifyPlan(ctx context.Context,
 			}
 		}
 
-		patches, err := configpatcher.LoadPatches(configPatches)
-		if err != nil {
-			resp.Diagnostics.AddError(
-				"Error loading config patches",
-				err.Error(),
-			)
+		var cfg configpatcher.Output
+
+		cfg, diags = applyConfigPatches(path.Root("config_patches"), configpatcher.WithBytes([]byte(planState.MachineConfigurationInput.ValueString())), configPatches)
+		resp.Diagnostics.Append(diags...)
 
+		if diags.HasError() {
 			return
 		}
 
-		cfg, err := configpatcher.Apply(configpatcher.WithBytes([]byte(planState.MachineConfigurationInput.ValueString())), patches)
+		cfgBytes, err := cfg.Bytes()
 		if err != nil {
 			resp.Diagnostics.AddError(
-				"Error applying config patches",
+				"Error converting config to bytes",
 				err.Error(),
 			)
 
 			return
 		}
 
-		cfgBytes, err := cfg.Bytes()
+		machineConfiguration := string(cfgBytes)
+
+		validationMode := planState.ValidationMode.ValueString()
+		if planState.ValidationMode.IsNull() {
+			validationMode = "permissive"
+		}
+
+		// the validation mode is unknown until apply, so the validation is skipped
+		if !planState.ValidationMode.IsUnknown() {
+			warnings, err := validateMachineConfiguration(machineConfiguration, validationMode)
+			if err != nil {
+				resp.Diagnostics.AddAttributeError(
+					path.Root("machine_configuration_input"),
+					"machine configuration is invalid",
+					err.Error(),
+				)
+
+				return
+			}
+
+			for _, warning := range warnings {
+				resp.Diagnostics.AddAttributeWarning(
+					path.Root("machine_configuration_input"),
+					"machine configuration validation warning",
+					warning,
+				)
+			}
+		}
+
+		// the output format is unknown until apply, so the machine configuration is unknown as well
+		if planState.OutputFormat.IsUnknown() {
+			return
+		}
+
+		machineConfiguration, err = formatMachineConfiguration(machineConfiguration, planState.OutputFormat.ValueString())
 		if err != nil {
 			resp.Diagnostics.AddError(
-				"Error converting config to bytes",
+				"Error formatting machine configuration",
 				err.Error(),
 			)
 
 			return
 		}
 
-		diags = resp.Plan.SetAttribute(ctx, path.Root("machine_configuration"), string(cfgBytes))
+		// keep the applied machine configuration if the change is cosmetic only (key order, comments, whitespace),
+		// so that the configuration is not re-applied to the node
+		stateMachineConfiguration := types.StringNull()
+
+		if !req.State.Raw.IsNull() {
+			diags = req.State.GetAttribute(ctx, path.Root("machine_configuration"), &stateMachineConfiguration)
+			resp.Diagnostics.Append(diags...)
+
+			if diags.HasError() {
+				return
+			}
+
+			// the node runs the machine configuration from the state, so the client configuration must still be accepted by it
+			if !stateMachineConfiguration.IsNull() && planState.ClientConfiguration != nil && !planState.ClientConfiguration.CA.IsUnknown() && !planState.ClientConfiguration.CA.IsNull() {
+				divergence, err := secretsDivergence(stateMachineConfiguration.ValueString(), planState.ClientConfiguration.CA.ValueString(), p.providerData.additionalCAs()...)
+				if err != nil {
+					resp.Diagnostics.AddError(
+						"Error checking machine secrets",
+						err.Error(),
+					)
+
+					return
+				}
+
+				if divergence != "" {
+					resp.Diagnostics.AddAttributeWarning(
+						path.Root("client_configuration"),
+						"machine configuration and client configuration secrets diverge",
+						divergence,
+					)
+				}
+			}
+
+			machineConfiguration = plannedMachineConfiguration(stateMachineConfiguration, machineConfiguration)
+
+			if machineConfigurationApplyRequired(stateMachineConfiguration, types.StringValue(machineConfiguration)) {
+				resp.Diagnostics.Append(installTimeChangesWarnings(stateMachineConfiguration.ValueString(), machineConfiguration)...)
+			}
+
+			if planState.DryRun.ValueBool() && machineConfigurationApplyRequired(stateMachineConfiguration, types.StringValue(machineConfiguration)) {
+				resp.Diagnostics.Append(p.dryRun(ctx, &planState, machineConfiguration)...)
+			}
+		}
+
+		diags = resp.Plan.SetAttribute(ctx, path.Root("machine_configuration"), machineConfiguration)
+		resp.Diagnostics.Append(diags...)
+
+		if diags.HasError() {
+			return
+		}
+
+		diags = resp.Plan.SetAttribute(ctx, path.Root("machine_configuration_sha256"), machineConfigurationSHA256(types.StringValue(machineConfiguration)))
+		resp.Diagnostics.Append(diags...)
+
+		if diags.HasError() {
+			return
+		}
+
+		diffSummary := types.ListNull(types.StringType)
+
+		if planState.DiffSummary.ValueBool() {
+			// the summary of the last change is kept as long as the machine configuration is unchanged
+			resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("configuration_diff_summary"), &diffSummary)...)
+
+			if machineConfigurationApplyRequired(stateMachineConfiguration, types.StringValue(machineConfiguration)) || diffSummary.IsNull() {
+				diffSummary, diags = configurationDiffSummary(ctx, stateMachineConfiguration, machineConfiguration)
+				resp.Diagnostics.Append(diags...)
+			}
+
+			if resp.Diagnostics.HasError() {
+				return
+			}
+		}
+
+		diags = resp.Plan.SetAttribute(ctx, path.Root("configuration_diff_summary"), diffSummary)
 		resp.Diagnostics.Append(diags...)
 
 		if diags.HasError() {
@@ -547,7 +1239,12 @@ func (p *talosMachineConfigurationApplyResource) ModifyPlan(ctx context.Context,
 	}
 }
 
-func (p *talosMachineConfigurationApplyResource) UpgradeState(_ context.Context) map[int64]resource.StateUpgrader {
+func (p *talosMachineConfigurationApplyResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	// version 1 only differs from the current schema by the ID value
+	var schemaV1 resource.SchemaResponse
+
+	p.Schema(ctx, resource.SchemaRequest{}, &schemaV1)
+
 	return map[int64]resource.StateUpgrader{
 		0: {
 			PriorSchema: &schema.Schema{
@@ -607,12 +1304,19 @@ func (p *talosMachineConfigurationApplyResource) UpgradeState(_ context.Context)
 				}
 
 				state := talosMachineConfigurationApplyResourceModelV1{
-					ID:                        basetypes.NewStringValue("machine_configuration_apply"),
-					ApplyMode:                 priorStateData.Mode,
-					Node:                      priorStateData.Node,
-					Endpoint:                  priorStateData.Endpoint,
-					MachineConfigurationInput: priorStateData.MachineConfiguration,
-					ConfigPatches:             configPatches,
+					ID:                         basetypes.NewStringValue(machineConfigurationApplyID(priorStateData.Endpoint.ValueString(), priorStateData.Node.ValueString())),
+					ApplyMode:                  priorStateData.Mode,
+					Node:                       priorStateData.Node,
+					Endpoint:                   priorStateData.Endpoint,
+					MachineConfigurationInput:  priorStateData.MachineConfiguration,
+					ConfigPatches:              configPatches,
+					OperationStats:             types.ObjectNull(operationStatsAttrTypes),
+					MachineConfigurationSHA256: machineConfigurationSHA256(priorStateData.MachineConfiguration),
+					AppliedMode:                types.StringNull(),
+					Rebooted:                   types.BoolNull(),
+					RebootRequired:             types.BoolNull(),
+					Messages:                   types.ListNull(types.StringType),
+					ConfigurationDiffSummary:   types.ListNull(types.StringType),
 					Timeouts: timeouts.Value{
 						Object: timeout,
 					},
@@ -626,7 +1330,129 @@ func (p *talosMachineConfigurationApplyResource) UpgradeState(_ context.Context)
 				}
 			},
 		},
+		1: {
+			PriorSchema: &schemaV1.Schema,
+			StateUpgrader: func(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+				var state talosMachineConfigurationApplyResourceModelV1
+
+				diags := req.State.Get(ctx, &state)
+				resp.Diagnostics.Append(diags...)
+				if diags.HasError() {
+					return
+				}
+
+				state.ID = basetypes.NewStringValue(machineConfigurationApplyID(state.Endpoint.ValueString(), state.Node.ValueString()))
+				state.MachineConfigurationSHA256 = machineConfigurationSHA256(state.MachineConfiguration)
+
+				diags = resp.State.Set(ctx, state)
+				resp.Diagnostics.Append(diags...)
+				if resp.Diagnostics.HasError() {
+					return
+				}
+			},
+		},
+	}
+}
+
+// machineConfigurationApplyID returns the ID of the machine configuration apply resource of the node reached through the endpoint.
+func machineConfigurationApplyID(endpoint, node string) string {
+	return endpoint + "/" + node
+}
+
+// wait waits for the post apply checks to be satisfied, returning a diagnostic for each unsatisfied check on timeout.
+func (checks *postApplyChecks) wait(ctx context.Context, endpoints []string, node string, tc *clientconfig.Config, timeout time.Duration) diag.Diagnostics {
+	var checkDiags diag.Diagnostics
+
+	opStats := operationStatsFromContext(ctx)
+
+	retryable := func(err error) *retry.RetryError {
+		if opStats != nil {
+			opStats.retries.Add(1)
+		}
+
+		return retry.RetryableError(err)
+	}
+
+	if err := retryContext(ctx, timeout, func() *retry.RetryError {
+		if err := talosClientOpEndpoints(ctx, endpoints, node, tc, func(nodeCtx context.Context, c *client.Client) error {
+			checkDiags = checks.evaluate(nodeCtx, c, path.Root("post_apply_checks"))
+
+			return nil
+		}); err != nil {
+			return retryable(err)
+		}
+
+		if checkDiags.HasError() {
+			return retryable(errors.New("post apply checks are not satisfied"))
+		}
+
+		return nil
+	}); err != nil {
+		checkDiags.AddError("Error waiting for post apply checks", err.Error())
+
+		return checkDiags
+	}
+
+	return nil
+}
+
+// evaluate checks the conditions against the node, the diagnostics are scoped to the attribute at attrRoot.
+func (checks *postApplyChecks) evaluate(ctx context.Context, c *client.Client, attrRoot path.Path) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	for i, svc := range checks.Services {
+		attrPath := attrRoot.AtName("services").AtListIndex(i)
+
+		services, err := c.ServiceInfo(ctx, svc.ValueString())
+		if err != nil {
+			diags.AddAttributeError(attrPath, "failed to get service info", err.Error())
+
+			continue
+		}
+
+		if len(services) == 0 {
+			diags.AddAttributeError(attrPath, "service not found", fmt.Sprintf("service %q is not registered", svc.ValueString()))
+
+			continue
+		}
+
+		for _, s := range services {
+			health := s.Service.GetHealth()
+
+			if !serviceReady(s.Service) {
+				diags.AddAttributeError(
+					attrPath,
+					"service is not healthy",
+					fmt.Sprintf("service %q is in state %q, health message: %q", svc.ValueString(), s.Service.GetState(), health.GetLastMessage()),
+				)
+			}
+		}
 	}
+
+	for i, res := range checks.Resources {
+		attrPath := attrRoot.AtName("resources").AtListIndex(i)
+
+		namespace := res.Namespace.ValueString()
+
+		rd, err := c.ResolveResourceKind(ctx, &namespace, res.Type.ValueString())
+		if err != nil {
+			diags.AddAttributeError(attrPath, "failed to resolve resource type", err.Error())
+
+			continue
+		}
+
+		if _, err = c.COSI.Get(ctx, cosiresource.NewMetadata(namespace, rd.TypedSpec().Type, res.ID.ValueString(), cosiresource.VersionUndefined)); err != nil {
+			if state.IsNotFoundError(err) {
+				diags.AddAttributeError(attrPath, "resource not found", fmt.Sprintf("resource %s/%s/%s does not exist", namespace, rd.TypedSpec().Type, res.ID.ValueString()))
+
+				continue
+			}
+
+			diags.AddAttributeError(attrPath, "failed to get resource", err.Error())
+		}
+	}
+
+	return diags
 }
 
 func resetGetActorID(ctx context.Context, c *client.Client, req *machineapi.ResetRequest) (string, error) {
@@ -663,3 +1489,192 @@ func (c *clientExecutor) WithClient(action func(context.Context, *client.Client)
 func (c *clientExecutor) NodeList() []string {
 	return c.nodes
 }
+
+// applyConfigPatches applies the config patches one by one, strictly decoding the machine configuration after each of them,
+// so that a patch producing an invalid machine configuration (e.g. a JSON patch adding an unknown field) is pointed at.
+func applyConfigPatches(attrPath path.Path, in configpatcher.Input, configPatches []string) (configpatcher.Output, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	for i, configPatch := range configPatches {
+		patches, err := configpatcher.LoadPatches([]string{configPatch})
+		if err != nil {
+			diags.AddAttributeError(attrPath.AtListIndex(i), "invalid config patch", configPatchErrorDetail(i, configPatch, err))
+
+			return nil, diags
+		}
+
+		out, err := configpatcher.Apply(in, patches)
+		if err == nil {
+			_, err = out.Config()
+		}
+
+		if err != nil {
+			diags.AddAttributeError(attrPath.AtListIndex(i), "failed to apply config patch", configPatchErrorDetail(i, configPatch, err))
+
+			return nil, diags
+		}
+
+		in = out
+	}
+
+	return in, diags
+}
+
+// installTimeFields are the machine configuration fields which only take effect when Talos is installed (or upgraded for `machine.install`),
+// with the reason reported when they are changed on a running node.
+var installTimeFields = []struct {
+	path   string
+	reason string
+}{
+	{
+		path:   "machine.install",
+		reason: "the install settings are only used when Talos is installed or upgraded, the running node is not reinstalled",
+	},
+	{
+		path:   "machine.systemDiskEncryption",
+		reason: "the disk encryption settings are only used when the partitions are created, the node has to be reset to apply them",
+	},
+}
+
+// installTimeChanges returns the warnings for the changes of the install-time fields.
+func installTimeChanges(changes []string) []string {
+	var warnings []string
+
+	for _, change := range changes {
+		for _, field := range installTimeFields {
+			if change == field.path || strings.HasPrefix(change, field.path+".") {
+				warnings = append(warnings, fmt.Sprintf("%s is changed, but %s", change, field.reason))
+			}
+		}
+	}
+
+	return warnings
+}
+
+// machineConfigurationEqual returns true if both machine configurations are semantically equal,
+// i.e. they only differ in key order, comments or whitespace.
+func machineConfigurationEqual(a, b string) bool {
+	if a == b {
+		return true
+	}
+
+	canonical := func(in string) ([]byte, error) {
+		cfg, err := configloader.NewFromBytes([]byte(in))
+		if err != nil {
+			return nil, err
+		}
+
+		return cfg.EncodeBytes(encoder.WithComments(encoder.CommentsDisabled))
+	}
+
+	canonicalA, err := canonical(a)
+	if err != nil {
+		return false
+	}
+
+	canonicalB, err := canonical(b)
+	if err != nil {
+		return false
+	}
+
+	return bytes.Equal(canonicalA, canonicalB)
+}
+
+// plannedMachineConfiguration returns the machine configuration to plan,
+// the applied machine configuration is kept if the change is cosmetic only (key order, comments, whitespace).
+func plannedMachineConfiguration(applied types.String, machineConfiguration string) string {
+	if !applied.IsNull() && !applied.IsUnknown() &&
+		isJSONMachineConfiguration(applied.ValueString()) == isJSONMachineConfiguration(machineConfiguration) &&
+		machineConfigurationEqual(applied.ValueString(), machineConfiguration) {
+		return applied.ValueString()
+	}
+
+	return machineConfiguration
+}
+
+// machineConfigurationApplyRequired returns true if the planned machine configuration has to be applied to the node.
+func machineConfigurationApplyRequired(applied, planned types.String) bool {
+	return !applied.Equal(planned)
+}
+
+// secretsDivergence checks that the machine configuration and the client configuration were generated from the same machine secrets.
+//
+// It returns a description of the divergence (or an empty string), as otherwise the mismatch only surfaces as an opaque TLS failure on the next operation.
+func secretsDivergence(machineConfiguration, ca string, additionalCAs ...string) (string, error) {
+	cfg, err := configloader.NewFromBytes([]byte(machineConfiguration))
+	if err != nil {
+		return "", err
+	}
+
+	if cfg.Machine() == nil || cfg.Machine().Security().IssuingCA() == nil {
+		return "", nil
+	}
+
+	clientCA, err := base64ToBytes(ca)
+	if err != nil {
+		return "", err
+	}
+
+	clientTrusted := [][]byte{clientCA}
+
+	for _, additionalCA := range additionalCAs {
+		additionalCACert, err := base64ToBytes(additionalCA)
+		if err != nil {
+			return "", fmt.Errorf("error decoding additional CA certificate: %w", err)
+		}
+
+		clientTrusted = append(clientTrusted, additionalCACert)
+	}
+
+	machineCA := cfg.Machine().Security().IssuingCA().Crt
+	machineTrusted := [][]byte{machineCA}
+
+	for _, acceptedCA := range cfg.Machine().Security().AcceptedCAs() {
+		machineTrusted = append(machineTrusted, acceptedCA.Crt)
+	}
+
+	containsCert := func(certs [][]byte, cert []byte) bool {
+		return slices.ContainsFunc(certs, func(c []byte) bool {
+			return bytes.Equal(bytes.TrimSpace(c), bytes.TrimSpace(cert))
+		})
+	}
+
+	switch {
+	case !containsCert(machineTrusted, clientCA):
+		return "the client configuration CA certificate is not accepted by the machine configuration, " +
+			"the machine configuration and the client configuration were likely generated from different machine secrets (e.g. talos_machine_secrets was regenerated)", nil
+	case !containsCert(clientTrusted, machineCA):
+		return "the machine configuration CA certificate is not trusted by the client configuration, " +
+			"the machine configuration and the client configuration were likely generated from different machine secrets (e.g. talos_machine_secrets was regenerated)", nil
+	default:
+		return "", nil
+	}
+}
+
+// nodeIdentityTimeout is the default `read` timeout bounding the read of the node identity, so that unreachable nodes don't slow down the refresh.
+const nodeIdentityTimeout = 15 * time.Second
+
+// nodeIdentity returns the identity of the node, which is generated again when the node is wiped.
+func nodeIdentity(ctx context.Context, c *client.Client) (string, error) {
+	identity, err := safe.StateGetByID[*cluster.Identity](ctx, c.COSI, cluster.LocalIdentity)
+	if err != nil {
+		return "", err
+	}
+
+	return identity.TypedSpec().NodeID, nil
+}
+
+// nodeReinstalled returns true if the node identity read from the node shows that the node was reinstalled since the identity was recorded.
+// The other errors (e.g. the node is unreachable or the credentials are rejected) don't tell whether the node was reinstalled.
+func nodeReinstalled(recorded types.String, identity string, err error) bool {
+	if recorded.IsNull() || recorded.IsUnknown() {
+		return false
+	}
+
+	if err != nil {
+		// the reinstalled node waits for its configuration in maintenance mode, or has no identity until it is configured again
+		return errors.Is(err, errMaintenanceMode) || status.Code(err) == codes.NotFound
+	}
+
+	return identity != recorded.ValueString()
+}
@@ -5,10 +5,28 @@
 package talos_test
 
 import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	machineapi "github.com/siderolabs/talos/pkg/machinery/api/machine"
+	"github.com/siderolabs/talos/pkg/machinery/config/configpatcher"
+	"github.com/siderolabs/talos/pkg/machinery/config/generate/secrets"
+	"github.com/siderolabs/talos/pkg/machinery/config/machine"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/siderolabs/terraform-provider-talos/pkg/talos"
 )
 
 func TestAccTalosMachineConfigurationApplyResource(t *testing.T) {
@@ -25,7 +43,7 @@ func TestAccTalosMachineConfigurationApplyResource(t *testing.T) {
 			{
 				Config: testAccTalosMachineConfigurationApplyResourceConfig("talos", rName),
 				Check: resource.ComposeAggregateTestCheckFunc(
-					resource.TestCheckResourceAttr("talos_machine_configuration_apply.this", "id", "machine_configuration_apply"),
+					resource.TestMatchResourceAttr("talos_machine_configuration_apply.this", "id", regexp.MustCompile(`^[^/]+/[^/]+$`)),
 					resource.TestCheckResourceAttr("talos_machine_configuration_apply.this", "apply_mode", "auto"),
 					resource.TestCheckResourceAttrSet("talos_machine_configuration_apply.this", "node"),
 					resource.TestCheckResourceAttrSet("talos_machine_configuration_apply.this", "endpoint"),
@@ -34,8 +52,17 @@ func TestAccTalosMachineConfigurationApplyResource(t *testing.T) {
 					resource.TestCheckResourceAttrSet("talos_machine_configuration_apply.this", "client_configuration.client_key"),
 					resource.TestCheckResourceAttrSet("talos_machine_configuration_apply.this", "machine_configuration_input"),
 					resource.TestCheckResourceAttrSet("talos_machine_configuration_apply.this", "machine_configuration"),
+					resource.TestMatchResourceAttr("talos_machine_configuration_apply.this", "machine_configuration_sha256", regexp.MustCompile(`^[0-9a-f]{64}$`)),
 					resource.TestCheckResourceAttr("talos_machine_configuration_apply.this", "config_patches.#", "1"),
+					resource.TestCheckResourceAttrSet("talos_machine_configuration_apply.this", "applied_mode"),
+					resource.TestCheckResourceAttr("talos_machine_configuration_apply.this", "reboot_required", "false"),
 					resource.TestCheckResourceAttr("talos_machine_configuration_apply.this", "config_patches.0", "\"machine\":\n  \"install\":\n    \"disk\": \"/dev/vda\"\n"),
+					resource.TestCheckResourceAttrWith("talos_machine_configuration_apply.this", "operation_stats.rpc_count", testAccCheckPositiveInt),
+					resource.TestCheckResourceAttrWith("talos_machine_configuration_apply.this", "operation_stats.bytes_sent", testAccCheckPositiveInt),
+					resource.TestCheckResourceAttrWith("talos_machine_configuration_apply.this", "operation_stats.bytes_received", testAccCheckPositiveInt),
+					// the node is in maintenance mode and accepts the configuration on the first attempt
+					resource.TestCheckResourceAttr("talos_machine_configuration_apply.this", "operation_stats.retries", "0"),
+					resource.TestCheckResourceAttrSet("talos_machine_configuration_apply.this", "operation_stats.duration"),
 				),
 			},
 			// ensure there is no diff
@@ -47,6 +74,51 @@ func TestAccTalosMachineConfigurationApplyResource(t *testing.T) {
 	})
 }
 
+func TestAccTalosMachineConfigurationApplyResourcePostApplyChecks(t *testing.T) {
+	rName := acctest.RandStringFromCharSet(10, acctest.CharSetAlpha)
+
+	resource.ParallelTest(t, resource.TestCase{
+		ExternalProviders: map[string]resource.ExternalProvider{
+			"libvirt": {
+				Source: "dmacvicar/libvirt",
+			},
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccTalosMachineConfigurationApplyResourcePostApplyChecksConfig("talos", rName),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("talos_machine_configuration_apply.this", "post_apply_checks.services.#", "1"),
+					resource.TestCheckResourceAttr("talos_machine_configuration_apply.this", "post_apply_checks.resources.#", "1"),
+					resource.TestCheckResourceAttrSet("talos_machine_configuration_apply.this", "operation_stats.retries"),
+				),
+			},
+			// ensure there is no diff
+			{
+				Config:   testAccTalosMachineConfigurationApplyResourcePostApplyChecksConfig("talos", rName),
+				PlanOnly: true,
+			},
+			// unsatisfied checks fail the update with a diagnostic for the check
+			{
+				Config: testAccTalosMachineConfigurationApplyResourcePostApplyChecksConfig("talos", rName) + `
+resource "talos_machine_configuration_apply" "unsatisfied" {
+  client_configuration        = talos_machine_secrets.this.client_configuration
+  machine_configuration_input = talos_machine_configuration_apply.this.machine_configuration
+  node                        = libvirt_domain.cp.network_interface[0].addresses[0]
+  post_apply_checks = {
+    services = ["nonexistent"]
+  }
+  timeouts = {
+    create = "1m"
+  }
+}
+`,
+				ExpectError: regexp.MustCompile("service not found"),
+			},
+		},
+	})
+}
+
 func TestAccTalosMachineConfigurationApplyResourceUpgrade(t *testing.T) {
 	// ref: https://github.com/hashicorp/terraform-plugin-testing/pull/118
 	t.Skip("skipping until TF test framework has a way to remove state resource")
@@ -82,7 +154,7 @@ func TestAccTalosMachineConfigurationApplyResourceUpgrade(t *testing.T) {
 				ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
 				Config:                   testAccTalosMachineConfigurationApplyResourceConfigV1("talos", rName),
 				Check: resource.ComposeAggregateTestCheckFunc(
-					resource.TestCheckResourceAttr("talos_machine_configuration_apply.this", "id", "machine_configuration_apply"),
+					resource.TestMatchResourceAttr("talos_machine_configuration_apply.this", "id", regexp.MustCompile(`^[^/]+/[^/]+$`)),
 					resource.TestCheckResourceAttr("talos_machine_configuration_apply.this", "apply_mode", "auto"),
 					resource.TestCheckResourceAttrSet("talos_machine_configuration_apply.this", "node"),
 					resource.TestCheckResourceAttrSet("talos_machine_configuration_apply.this", "endpoint"),
@@ -110,6 +182,19 @@ func TestAccTalosMachineConfigurationApplyResourceUpgrade(t *testing.T) {
 	})
 }
 
+func testAccCheckPositiveInt(value string) error {
+	v, err := strconv.Atoi(value)
+	if err != nil {
+		return err
+	}
+
+	if v <= 0 {
+		return fmt.Errorf("expected a positive value, got %d", v)
+	}
+
+	return nil
+}
+
 func testAccTalosMachineConfigurationApplyResourceConfig(providerName, rName string) string {
 	config := dynamicConfig{
 		Provider:        providerName,
@@ -121,6 +206,18 @@ func testAccTalosMachineConfigurationApplyResourceConfig(providerName, rName str
 	return config.render()
 }
 
+func testAccTalosMachineConfigurationApplyResourcePostApplyChecksConfig(providerName, rName string) string {
+	config := dynamicConfig{
+		Provider:            providerName,
+		ResourceName:        rName,
+		WithApplyConfig:     true,
+		WithPostApplyChecks: true,
+		WithBootstrap:       false,
+	}
+
+	return config.render()
+}
+
 func testAccTalosMachineConfigurationApplyResourceConfigV0(providerName, rName string) string {
 	config := dynamicConfig{
 		Provider:        providerName,
@@ -142,3 +239,438 @@ func testAccTalosMachineConfigurationApplyResourceConfigV1(providerName, rName s
 
 	return config.render()
 }
+
+func TestSecretsDivergence(t *testing.T) {
+	t.Parallel()
+
+	newSecrets := func(t *testing.T) (*secrets.Bundle, string) {
+		t.Helper()
+
+		secretsBundle, err := secrets.NewBundle(secrets.NewFixedClock(time.Now()), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		machineConfiguration, err := talos.GenerateMachineConfiguration(machine.TypeControlPlane, secretsBundle)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return secretsBundle, machineConfiguration
+	}
+
+	secretsBundle, machineConfiguration := newSecrets(t)
+	otherSecretsBundle, _ := newSecrets(t)
+
+	ca := talos.BytesToBase64(secretsBundle.Certs.OS.Crt)
+	otherCA := talos.BytesToBase64(otherSecretsBundle.Certs.OS.Crt)
+
+	for _, tt := range []struct {
+		name          string
+		ca            string
+		additionalCAs []string
+		diverged      bool
+	}{
+		{
+			name: "consistent",
+			ca:   ca,
+		},
+		{
+			name:     "diverged",
+			ca:       otherCA,
+			diverged: true,
+		},
+		{
+			name:          "diverged with the machine CA trusted as an additional CA",
+			ca:            otherCA,
+			additionalCAs: []string{ca},
+			diverged:      true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			divergence, err := talos.SecretsDivergence(machineConfiguration, tt.ca, tt.additionalCAs...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diverged := divergence != ""; diverged != tt.diverged {
+				t.Errorf("expected diverged %v, got %q", tt.diverged, divergence)
+			}
+		})
+	}
+}
+
+func TestMachineConfigurationEqual(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name  string
+		other string
+		equal bool
+	}{
+		{
+			name:  "identical",
+			other: talos.MachineConfigurationYAML,
+			equal: true,
+		},
+		{
+			name: "comments and whitespace",
+			other: `# worker configuration
+version: v1alpha1
+machine:
+  type: worker # the machine type
+
+  token: abcdef.0123456789abcdef
+  network:
+    hostname: worker-1
+cluster:
+  controlPlane:
+    endpoint: https://cluster.local:6443
+`,
+			equal: true,
+		},
+		{
+			name: "key order",
+			other: `version: v1alpha1
+cluster:
+  controlPlane:
+    endpoint: https://cluster.local:6443
+machine:
+  network:
+    hostname: worker-1
+  token: abcdef.0123456789abcdef
+  type: worker
+`,
+			equal: true,
+		},
+		{
+			name:  "different value",
+			other: strings.ReplaceAll(talos.MachineConfigurationYAML, "worker-1", "worker-2"),
+			equal: false,
+		},
+		{
+			name: "additional field",
+			other: talos.MachineConfigurationYAML + `  clusterName: test
+`,
+			equal: false,
+		},
+		{
+			name:  "invalid",
+			other: "machine: [",
+			equal: false,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if equal := talos.MachineConfigurationEqual(talos.MachineConfigurationYAML, tt.other); equal != tt.equal {
+				t.Errorf("expected equal %v, got %v", tt.equal, equal)
+			}
+		})
+	}
+}
+
+func TestMachineConfigurationApplyRequired(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name                 string
+		applied              types.String
+		machineConfiguration string
+		applyRequired        bool
+	}{
+		{
+			name:                 "create",
+			applied:              types.StringNull(),
+			machineConfiguration: talos.MachineConfigurationYAML,
+			applyRequired:        true,
+		},
+		{
+			name:                 "unchanged",
+			applied:              types.StringValue(talos.MachineConfigurationYAML),
+			machineConfiguration: talos.MachineConfigurationYAML,
+			applyRequired:        false,
+		},
+		{
+			name:                 "cosmetic change",
+			applied:              types.StringValue(talos.MachineConfigurationYAML),
+			machineConfiguration: "# comment\n" + talos.MachineConfigurationYAML,
+			applyRequired:        false,
+		},
+		{
+			name:                 "real change",
+			applied:              types.StringValue(talos.MachineConfigurationYAML),
+			machineConfiguration: strings.ReplaceAll(talos.MachineConfigurationYAML, "worker-1", "worker-2"),
+			applyRequired:        true,
+		},
+		{
+			name:                 "output format change",
+			applied:              types.StringValue(talos.MachineConfigurationYAML),
+			machineConfiguration: talos.MachineConfigurationJSON,
+			applyRequired:        true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			planned := types.StringValue(talos.PlannedMachineConfiguration(tt.applied, tt.machineConfiguration))
+
+			if applyRequired := talos.MachineConfigurationApplyRequired(tt.applied, planned); applyRequired != tt.applyRequired {
+				t.Errorf("expected apply required %v, got %v", tt.applyRequired, applyRequired)
+			}
+		})
+	}
+}
+
+func TestNodeReinstalled(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name        string
+		recorded    types.String
+		identity    string
+		err         error
+		reinstalled bool
+	}{
+		{
+			name:     "not recorded yet",
+			recorded: types.StringNull(),
+			identity: "7x1SuC8Ege5BGXdAfTEff5iQnlWZLfv9h1LGMxA2pYkC",
+		},
+		{
+			name:     "same identity",
+			recorded: types.StringValue("7x1SuC8Ege5BGXdAfTEff5iQnlWZLfv9h1LGMxA2pYkC"),
+			identity: "7x1SuC8Ege5BGXdAfTEff5iQnlWZLfv9h1LGMxA2pYkC",
+		},
+		{
+			name:        "new identity",
+			recorded:    types.StringValue("7x1SuC8Ege5BGXdAfTEff5iQnlWZLfv9h1LGMxA2pYkC"),
+			identity:    "2Tlf3RHGrmPI63mPmv0S4omQgS6Fy3cjxtKsnLH2oH6",
+			reinstalled: true,
+		},
+		{
+			name:        "identity missing",
+			recorded:    types.StringValue("7x1SuC8Ege5BGXdAfTEff5iQnlWZLfv9h1LGMxA2pYkC"),
+			err:         status.Error(codes.NotFound, "resource doesn't exist"),
+			reinstalled: true,
+		},
+		{
+			name:     "node unreachable",
+			recorded: types.StringValue("7x1SuC8Ege5BGXdAfTEff5iQnlWZLfv9h1LGMxA2pYkC"),
+			err:      status.Error(codes.Unavailable, "connection refused"),
+		},
+		{
+			name:        "maintenance mode",
+			recorded:    types.StringValue("7x1SuC8Ege5BGXdAfTEff5iQnlWZLfv9h1LGMxA2pYkC"),
+			err:         fmt.Errorf("10.5.0.2: %w", talos.ErrMaintenanceMode),
+			reinstalled: true,
+		},
+		{
+			name:     "credentials rejected",
+			recorded: types.StringValue("7x1SuC8Ege5BGXdAfTEff5iQnlWZLfv9h1LGMxA2pYkC"),
+			err:      status.Error(codes.PermissionDenied, "not authorized"),
+		},
+		{
+			name:     "unknown authority",
+			recorded: types.StringValue("7x1SuC8Ege5BGXdAfTEff5iQnlWZLfv9h1LGMxA2pYkC"),
+			err:      status.Error(codes.Unavailable, "connection error: desc = \"transport: authentication handshake failed: tls: failed to verify certificate: x509: certificate signed by unknown authority\""),
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if reinstalled := talos.NodeReinstalled(tt.recorded, tt.identity, tt.err); reinstalled != tt.reinstalled {
+				t.Errorf("expected reinstalled %v, got %v", tt.reinstalled, reinstalled)
+			}
+		})
+	}
+}
+
+func TestDryRunSummary(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name     string
+		messages []*machineapi.ApplyConfiguration
+		expected string
+	}{
+		{
+			name: "diff",
+			messages: []*machineapi.ApplyConfiguration{
+				{
+					Mode:        machineapi.ApplyConfigurationRequest_REBOOT,
+					ModeDetails: "Dry run summary:\nApplied configuration with a reboot (skipped in dry-run).\n\nConfig diff:\n\n-    hostname: worker-1\n+    hostname: worker-2",
+					Warnings:    []string{"use \"worker\" instead of \"join\" for machine type"},
+				},
+			},
+			expected: "Dry run summary:\nApplied configuration with a reboot (skipped in dry-run).\n\nConfig diff:\n\n-    hostname: worker-1\n+    hostname: worker-2\n" +
+				"warning: use \"worker\" instead of \"join\" for machine type",
+		},
+		{
+			name:     "no summary",
+			messages: []*machineapi.ApplyConfiguration{{}},
+			expected: "the node returned no dry run summary",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if summary := talos.DryRunSummary(&machineapi.ApplyConfigurationResponse{Messages: tt.messages}); summary != tt.expected {
+				t.Errorf("expected summary %q, got %q", tt.expected, summary)
+			}
+		})
+	}
+}
+
+func TestConfigurationDiffSummary(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name     string
+		applied  types.String
+		planned  string
+		expected []string
+	}{
+		{
+			name:     "create",
+			applied:  types.StringNull(),
+			planned:  talos.MachineConfigurationYAML,
+			expected: []string{},
+		},
+		{
+			name:     "unchanged",
+			applied:  types.StringValue(talos.MachineConfigurationYAML),
+			planned:  "# comment\n" + talos.MachineConfigurationYAML,
+			expected: []string{},
+		},
+		{
+			name:     "secret and hostname changed",
+			applied:  types.StringValue(talos.MachineConfigurationYAML),
+			planned:  strings.NewReplacer("worker-1", "worker-2", "abcdef.0123456789abcdef", "ghijkl.0123456789abcdef").Replace(talos.MachineConfigurationYAML),
+			expected: []string{"machine.network.hostname", "machine.token"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			summary, diags := talos.ConfigurationDiffSummary(context.Background(), tt.applied, tt.planned)
+			if diags.HasError() {
+				t.Fatalf("unexpected error: %v", diags)
+			}
+
+			var paths []string
+
+			if diags = summary.ElementsAs(context.Background(), &paths, false); diags.HasError() {
+				t.Fatalf("unexpected error: %v", diags)
+			}
+
+			if !slices.Equal(paths, tt.expected) {
+				t.Errorf("expected paths %v, got %v", tt.expected, paths)
+			}
+
+			if strings.Contains(summary.String(), "ghijkl") {
+				t.Errorf("expected the summary not to disclose the values, got %s", summary)
+			}
+		})
+	}
+}
+
+func TestApplyConfigPatches(t *testing.T) {
+	t.Parallel()
+
+	input := configpatcher.WithBytes([]byte("version: v1alpha1\nmachine:\n  type: worker\n  install:\n    disk: /dev/sda\ncluster:\n  clusterName: test\n"))
+
+	out, diags := talos.ApplyConfigPatches(path.Root("config_patches"), input, []string{
+		"machine:\n  install:\n    wipe: true\n",
+		"- op: add\n  path: /machine/install/instal\n  value: /dev/sdb\n",
+	})
+	if out != nil || diags.ErrorsCount() != 1 {
+		t.Fatalf("expected the JSON patch to fail, got %v", diags)
+	}
+
+	withPath, ok := diags.Errors()[0].(diag.DiagnosticWithPath)
+	if !ok || !withPath.Path().Equal(path.Root("config_patches").AtListIndex(1)) {
+		t.Errorf("expected the error at the second patch, got %v", diags.Errors()[0])
+	}
+
+	if !strings.Contains(diags.Errors()[0].Detail(), "machine.install.instal") {
+		t.Errorf("expected the error to point at the unknown field, got %q", diags.Errors()[0].Detail())
+	}
+}
+
+func TestInstallTimeChanges(t *testing.T) {
+	t.Parallel()
+
+	warnings := talos.InstallTimeChanges([]string{
+		"machine.features.hostDNS",
+		"machine.install.disk",
+		"machine.installer",
+		"machine.systemDiskEncryption.ephemeral",
+	})
+
+	if len(warnings) != 2 {
+		t.Fatalf("expected two warnings, got %v", warnings)
+	}
+
+	if !strings.HasPrefix(warnings[0], "machine.install.disk is changed") {
+		t.Errorf("expected the install disk warning, got %q", warnings[0])
+	}
+
+	if !strings.Contains(warnings[1], "reset") {
+		t.Errorf("expected the disk encryption warning to mention the reset, got %q", warnings[1])
+	}
+}
+
+func TestSetApplyResult(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name                   string
+		applyMode              string
+		mode                   machineapi.ApplyConfigurationRequest_Mode
+		expectedRebooted       bool
+		expectedRebootRequired bool
+	}{
+		{
+			name:      "no reboot",
+			applyMode: "auto",
+			mode:      machineapi.ApplyConfigurationRequest_NO_REBOOT,
+		},
+		{
+			name:             "reboot",
+			applyMode:        "auto",
+			mode:             machineapi.ApplyConfigurationRequest_REBOOT,
+			expectedRebooted: true,
+		},
+		{
+			name:                   "staged",
+			applyMode:              "staged",
+			mode:                   machineapi.ApplyConfigurationRequest_STAGED,
+			expectedRebootRequired: true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			state := talos.TalosMachineConfigurationApplyResourceModelV1{
+				ApplyMode: types.StringValue(tt.applyMode),
+			}
+
+			state.SetApplyResult(&machineapi.ApplyConfigurationResponse{
+				Messages: []*machineapi.ApplyConfiguration{
+					{Mode: tt.mode},
+				},
+			})
+
+			if state.Rebooted.ValueBool() != tt.expectedRebooted {
+				t.Errorf("expected rebooted %v, got %v", tt.expectedRebooted, state.Rebooted)
+			}
+
+			if state.RebootRequired.ValueBool() != tt.expectedRebootRequired {
+				t.Errorf("expected reboot_required %v, got %v", tt.expectedRebootRequired, state.RebootRequired)
+			}
+		})
+	}
+}
@@ -6,38 +6,151 @@ package talos
 
 import (
 	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math/big"
+	"net"
+	"net/url"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
 	"github.com/siderolabs/crypto/x509"
+	"github.com/siderolabs/go-pointer"
 	machineapi "github.com/siderolabs/talos/pkg/machinery/api/machine"
+	"github.com/siderolabs/talos/pkg/machinery/cel"
+	"github.com/siderolabs/talos/pkg/machinery/cel/celenv"
 	"github.com/siderolabs/talos/pkg/machinery/compatibility"
-	"github.com/siderolabs/talos/pkg/machinery/config/configpatcher"
+	"github.com/siderolabs/talos/pkg/machinery/config"
+	"github.com/siderolabs/talos/pkg/machinery/config/configloader"
+	"github.com/siderolabs/talos/pkg/machinery/config/encoder"
+	"github.com/siderolabs/talos/pkg/machinery/config/generate"
 	"github.com/siderolabs/talos/pkg/machinery/config/generate/secrets"
 	"github.com/siderolabs/talos/pkg/machinery/config/machine"
+	"github.com/siderolabs/talos/pkg/machinery/config/types/block"
+	"github.com/siderolabs/talos/pkg/machinery/config/types/v1alpha1"
 	"github.com/siderolabs/talos/pkg/machinery/constants"
 	"github.com/siderolabs/talos/pkg/machinery/gendata"
+	"github.com/siderolabs/talos/pkg/machinery/labels"
 	"golang.org/x/mod/semver"
+	"gopkg.in/yaml.v3"
 )
 
 type talosMachineConfigurationDataSourceModelV0 struct {
-	ID                   types.String   `tfsdk:"id"`
-	ClusterName          types.String   `tfsdk:"cluster_name"`
-	ClusterEndpoint      types.String   `tfsdk:"cluster_endpoint"`
-	MachineType          types.String   `tfsdk:"machine_type"`
-	KubernetesVersion    types.String   `tfsdk:"kubernetes_version"`
-	TalosVersion         types.String   `tfsdk:"talos_version"`
-	MachineSecrets       machineSecrets `tfsdk:"machine_secrets"`
-	MachineConfiguration types.String   `tfsdk:"machine_configuration"`
-	ConfigPatches        types.List     `tfsdk:"config_patches"`
-	Docs                 types.Bool     `tfsdk:"docs"`
-	Examples             types.Bool     `tfsdk:"examples"`
+	ID                         types.String                        `tfsdk:"id"`
+	ClusterName                types.String                        `tfsdk:"cluster_name"`
+	ClusterEndpoint            types.String                        `tfsdk:"cluster_endpoint"`
+	ClusterEndpointPort        types.Int64                         `tfsdk:"cluster_endpoint_port"`
+	LocalAPIServerPort         types.Int64                         `tfsdk:"local_api_server_port"`
+	AdditionalCertSANs         types.List                          `tfsdk:"additional_cert_sans"`
+	MachineType                types.String                        `tfsdk:"machine_type"`
+	KubernetesVersion          types.String                        `tfsdk:"kubernetes_version"`
+	TalosVersion               types.String                        `tfsdk:"talos_version"`
+	ConfigContract             types.String                        `tfsdk:"config_contract"`
+	ConfigContractChanges      types.List                          `tfsdk:"config_contract_changes"`
+	MachineSecrets             machineSecrets                      `tfsdk:"machine_secrets"`
+	MachineConfiguration       types.String                        `tfsdk:"machine_configuration"`
+	MachineConfigurationSHA256 types.String                        `tfsdk:"machine_configuration_sha256"`
+	MachineConfigurationObject types.Dynamic                       `tfsdk:"machine_configuration_object"`
+	ConfigPatches              types.List                          `tfsdk:"config_patches"`
+	AdditionalDocuments        types.List                          `tfsdk:"additional_documents"`
+	Registries                 types.Map                           `tfsdk:"registries"`
+	Volumes                    types.Map                           `tfsdk:"volumes"`
+	SystemDiskEncryption       *machineConfigurationDiskEncryption `tfsdk:"system_disk_encryption"`
+	NodeLabels                 types.Map                           `tfsdk:"node_labels"`
+	NodeAnnotations            types.Map                           `tfsdk:"node_annotations"`
+	NodeTaints                 types.Map                           `tfsdk:"node_taints"`
+	InlineManifests            types.List                          `tfsdk:"inline_manifests"`
+	ExtraManifests             types.List                          `tfsdk:"extra_manifests"`
+	ExtraManifestHeaders       types.Map                           `tfsdk:"extra_manifest_headers"`
+	ExtensionServices          types.Map                           `tfsdk:"extension_services"`
+	ValidationMode             types.String                        `tfsdk:"validation_mode"`
+	OutputFormat               types.String                        `tfsdk:"output_format"`
+	Docs                       types.Bool                          `tfsdk:"docs"`
+	Examples                   types.Bool                          `tfsdk:"examples"`
+}
+
+type machineConfigurationRegistry struct {
+	Mirrors      []types.String                    `tfsdk:"mirrors"`
+	OverridePath types.Bool                        `tfsdk:"override_path"`
+	Auth         *machineConfigurationRegistryAuth `tfsdk:"auth"`
+	TLS          *machineConfigurationRegistryTLS  `tfsdk:"tls"`
+}
+
+type machineConfigurationExtensionService struct {
+	Environment          map[string]types.String `tfsdk:"environment"`
+	SensitiveEnvironment map[string]types.String `tfsdk:"sensitive_environment"`
+	ConfigFiles          map[string]types.String `tfsdk:"config_files"`
+}
+
+type machineConfigurationVolume struct {
+	DiskSelector types.String `tfsdk:"disk_selector"`
+	MinSize      types.String `tfsdk:"min_size"`
+	MaxSize      types.String `tfsdk:"max_size"`
+	Grow         types.Bool   `tfsdk:"grow"`
+}
+
+type machineConfigurationNodeTaint struct {
+	Value  types.String `tfsdk:"value"`
+	Effect types.String `tfsdk:"effect"`
+}
+
+type machineConfigurationInlineManifest struct {
+	Name     types.String `tfsdk:"name"`
+	Contents types.String `tfsdk:"contents"`
+}
+
+type machineConfigurationDiskEncryption struct {
+	State     *machineConfigurationPartitionEncryption `tfsdk:"state"`
+	Ephemeral *machineConfigurationPartitionEncryption `tfsdk:"ephemeral"`
+}
+
+type machineConfigurationPartitionEncryption struct {
+	Provider  types.String                        `tfsdk:"provider"`
+	Keys      []machineConfigurationEncryptionKey `tfsdk:"keys"`
+	Cipher    types.String                        `tfsdk:"cipher"`
+	KeySize   types.Int64                         `tfsdk:"key_size"`
+	BlockSize types.Int64                         `tfsdk:"block_size"`
+	Options   []types.String                      `tfsdk:"options"`
+}
+
+type machineConfigurationEncryptionKey struct {
+	Slot                             types.Int64  `tfsdk:"slot"`
+	StaticPassphrase                 types.String `tfsdk:"static_passphrase"`
+	NodeID                           types.Bool   `tfsdk:"node_id"`
+	KMSEndpoint                      types.String `tfsdk:"kms_endpoint"`
+	TPM                              types.Bool   `tfsdk:"tpm"`
+	TPMCheckSecurebootStatusOnEnroll types.Bool   `tfsdk:"tpm_check_secureboot_status_on_enroll"`
+}
+
+type machineConfigurationRegistryAuth struct {
+	Username      types.String `tfsdk:"username"`
+	Password      types.String `tfsdk:"password"`
+	Auth          types.String `tfsdk:"auth"`
+	IdentityToken types.String `tfsdk:"identity_token"`
+}
+
+type machineConfigurationRegistryTLS struct {
+	CACertificate      types.String `tfsdk:"ca_certificate"`
+	ClientCertificate  types.String `tfsdk:"client_certificate"`
+	ClientKey          types.String `tfsdk:"client_key"`
+	InsecureSkipVerify types.Bool   `tfsdk:"insecure_skip_verify"`
 }
 
 type talosMachineConfigurationDataSource struct{}
@@ -74,79 +187,26 @@ func (d *talosMachineConfigurationDataSource) Schema(_ context.Context, _ dataso
 				Required:    true,
 				Description: "The endpoint of the talos kubernetes cluster",
 			},
-			"machine_secrets": schema.SingleNestedAttribute{
-				Description: "The secrets for the talos cluster",
-				Attributes: map[string]schema.Attribute{
-					"cluster": schema.SingleNestedAttribute{
-						Description: "The cluster secrets",
-						Attributes: map[string]schema.Attribute{
-							"id": schema.StringAttribute{
-								Required:    true,
-								Description: "The cluster id",
-							},
-							"secret": schema.StringAttribute{
-								Required:    true,
-								Sensitive:   true,
-								Description: "The cluster secret",
-							},
-						},
-						Required: true,
-					},
-					"secrets": schema.SingleNestedAttribute{
-						Description: "The secrets for the talos kubernetes cluster",
-						Attributes: map[string]schema.Attribute{
-							"bootstrap_token": schema.StringAttribute{
-								Description: "The bootstrap token for the talos kubernetes cluster",
-								Required:    true,
-								Sensitive:   true,
-							},
-							"secretbox_encryption_secret": schema.StringAttribute{
-								Description: "The secretbox encryption secret for the talos kubernetes cluster",
-								Required:    true,
-								Sensitive:   true,
-							},
-							"aescbc_encryption_secret": schema.StringAttribute{
-								Description: "The aescbc encryption secret for the talos kubernetes cluster",
-								Optional:    true,
-								Sensitive:   true,
-							},
-						},
-						Required: true,
-					},
-					"trustdinfo": schema.SingleNestedAttribute{
-						Description: "The trustd info for the talos kubernetes cluster",
-						Attributes: map[string]schema.Attribute{
-							"token": schema.StringAttribute{
-								Description: "The trustd token for the talos kubernetes cluster",
-								Required:    true,
-								Sensitive:   true,
-							},
-						},
-						Required: true,
-					},
-					"certs": schema.SingleNestedAttribute{
-						Description: "The certs for the talos kubernetes cluster",
-						Attributes: map[string]schema.Attribute{
-							"etcd":           certSchemaInput(),
-							"k8s":            certSchemaInput(),
-							"k8s_aggregator": certSchemaInput(),
-							"k8s_serviceaccount": schema.SingleNestedAttribute{
-								Attributes: map[string]schema.Attribute{
-									"key": schema.StringAttribute{
-										Description: "The key for the k8s service account",
-										Required:    true,
-										Sensitive:   true,
-									},
-								},
-								Required: true,
-							},
-							"os": certSchemaInput(),
-						},
-						Required: true,
-					},
+			"cluster_endpoint_port": schema.Int64Attribute{
+				Optional:    true,
+				Description: "The port of the cluster endpoint, overrides the port of `cluster_endpoint` (e.g. 443 if the load balancer in front of the API server listens on it)",
+				Validators: []validator.Int64{
+					int64validator.Between(1, 65535),
 				},
-				Required: true,
 			},
+			"local_api_server_port": schema.Int64Attribute{
+				Optional:    true,
+				Description: "The port the API server listens on on the control plane nodes, defaults to 6443",
+				Validators: []validator.Int64{
+					int64validator.Between(1, 65535),
+				},
+			},
+			"additional_cert_sans": schema.ListAttribute{
+				Optional:    true,
+				Description: "The additional subject alternative names (e.g. the load balancer address) for the API server and Talos API certificates",
+				ElementType: types.StringType,
+			},
+			"machine_secrets": machineSecretsSchemaInput(),
 			"machine_type": schema.StringAttribute{
 				Required:    true,
 				Description: "The type of machine to generate the configuration for",
@@ -159,6 +219,77 @@ func (d *talosMachineConfigurationDataSource) Schema(_ context.Context, _ dataso
 				Optional:    true,
 				ElementType: types.StringType,
 			},
+			"additional_documents": schema.ListAttribute{
+				Description: "The list of additional machine configuration documents (e.g. `SideroLinkConfig`, `KmsgLogConfig`, `WatchdogTimerConfig`) to append to the generated configuration, one document per element. " +
+					"The `v1alpha1` configuration should be changed via `config_patches` instead",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"registries":             registriesSchemaInput(),
+			"volumes":                volumesSchemaInput(),
+			"system_disk_encryption": systemDiskEncryptionSchemaInput(),
+			"node_labels": schema.MapAttribute{
+				Description: "The Kubernetes labels of the node rendered as `machine.nodeLabels`. " +
+					"The NodeRestriction admission plugin only allows the worker nodes to set the labels with some prefixes",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"node_annotations": schema.MapAttribute{
+				Description: "The Kubernetes annotations of the node rendered as `machine.nodeAnnotations`",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"node_taints": nodeTaintsSchemaInput(),
+			"inline_manifests": schema.ListNestedAttribute{
+				Description: "The Kubernetes manifests (e.g. the CNI) rendered as `cluster.inlineManifests`, applied by the control plane nodes during the bootstrap. " +
+					"Only supported for the `controlplane` machine type",
+				Optional: true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							Description: "The unique name of the manifest",
+							Required:    true,
+							Validators: []validator.String{
+								stringvalidator.LengthAtLeast(1),
+							},
+						},
+						"contents": schema.StringAttribute{
+							Description: "The YAML Kubernetes objects of the manifest, multiple documents are separated by `---`",
+							Required:    true,
+						},
+					},
+				},
+			},
+			"extra_manifests": schema.ListAttribute{
+				Description: "The URLs of the Kubernetes manifests rendered as `cluster.extraManifests`, downloaded and applied by the control plane nodes during the bootstrap. " +
+					"Only supported for the `controlplane` machine type",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"extra_manifest_headers": schema.MapAttribute{
+				Description: "The HTTP headers (e.g. `Authorization`) used to download `extra_manifests`, rendered as `cluster.extraManifestHeaders`",
+				Optional:    true,
+				Sensitive:   true,
+				ElementType: types.StringType,
+			},
+			"extension_services": extensionServicesSchemaInput(
+				"The system extension services configuration rendered as `ExtensionServiceConfig` documents, keyed by extension service name (e.g. `tailscale`)",
+			),
+			"validation_mode": schema.StringAttribute{
+				Description: "How the generated machine configuration is validated: `strict` fails on unknown fields, validation errors and warnings, " +
+					"`permissive` fails on unknown fields and validation errors and reports the warnings, `off` skips the validation. Defaults to `permissive`",
+				Optional: true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(validationModeNames...),
+				},
+			},
+			"output_format": schema.StringAttribute{
+				Description: "The format of `machine_configuration`, `yaml` or `json`. In `json` the documents of a multi-document configuration are separated by `---`. Defaults to `yaml`",
+				Optional:    true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(outputFormatNames...),
+				},
+			},
 			"kubernetes_version": schema.StringAttribute{
 				Description: "The version of kubernetes to use",
 				Optional:    true,
@@ -170,12 +301,27 @@ func (d *talosMachineConfigurationDataSource) Schema(_ context.Context, _ dataso
 					talosVersionValid(),
 				},
 			},
+			"config_contract": schema.StringAttribute{
+				Description: "The config contract (e.g. `v1.7`) to pin the generated machine configuration to, defaults to the contract of `talos_version`. " +
+					"Pinning the contract allows to bump `talos_version` without changing the generated machine configuration, see `config_contract_changes` for the upgrade path",
+				Optional: true,
+				Computed: true,
+				Validators: []validator.String{
+					talosVersionValid(),
+				},
+			},
+			"config_contract_changes": schema.ListAttribute{
+				Description: "The paths of the machine configuration values which change when the pinned `config_contract` is moved to the contract of `talos_version`, " +
+					"empty if the contract isn't pinned to an older version",
+				Computed:    true,
+				ElementType: types.StringType,
+			},
 			"docs": schema.BoolAttribute{
-				Description: "Whether to generate documentation for the generated configuration. Defaults to false",
+				Description: "Whether to add the documentation comments to the generated configuration. Defaults to false, which keeps the generated configuration minimal",
 				Optional:    true,
 			},
 			"examples": schema.BoolAttribute{
-				Description: "Whether to generate examples for the generated configuration. DFaults to false",
+				Description: "Whether to add the commented out example fields to the generated configuration. Defaults to false",
 				Optional:    true,
 			},
 			"machine_configuration": schema.StringAttribute{
@@ -183,6 +329,15 @@ func (d *talosMachineConfigurationDataSource) Schema(_ context.Context, _ dataso
 				Computed:    true,
 				Sensitive:   true,
 			},
+			"machine_configuration_sha256": schema.StringAttribute{
+				Description: "The SHA-256 checksum of `machine_configuration`, to depend on the machine configuration changes without referencing the sensitive machine configuration",
+				Computed:    true,
+			},
+			"machine_configuration_object": schema.DynamicAttribute{
+				Description: "The v1alpha1 document of the generated machine configuration as an object, e.g. `machine_configuration_object.cluster.clusterName`",
+				Computed:    true,
+				Sensitive:   true,
+			},
 		},
 	}
 }
@@ -214,26 +369,17 @@ func (d *talosMachineConfigurationDataSource) Read(ctx context.Context, req data
 		machineType = machine.TypeWorker
 	}
 
-	machineSecrets := &secrets.Bundle{
-		Clock: secrets.NewFixedClock(time.Now()),
-		Cluster: &secrets.Cluster{
-			ID:     state.MachineSecrets.Cluster.ID.ValueString(),
-			Secret: state.MachineSecrets.Cluster.Secret.ValueString(),
-		},
-		Secrets: &secrets.Secrets{
-			BootstrapToken:            state.MachineSecrets.Secrets.BootstrapToken.ValueString(),
-			SecretboxEncryptionSecret: state.MachineSecrets.Secrets.SecretboxEncryptionSecret.ValueString(),
-		},
-		TrustdInfo: &secrets.TrustdInfo{
-			Token: state.MachineSecrets.TrustdInfo.Token.ValueString(),
-		},
-	}
+	if machineType == machine.TypeControlPlane && state.MachineSecrets.Certs.OS.Key.ValueString() == "" {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("machine_secrets"),
+			"machine_secrets are incomplete",
+			"controlplane machine configuration requires the full machine secrets, worker machine secrets can only be used to generate worker machine configuration",
+		)
 
-	if !state.MachineSecrets.Secrets.AESCBCEncryptionSecret.IsNull() {
-		machineSecrets.Secrets.AESCBCEncryptionSecret = state.MachineSecrets.Secrets.AESCBCEncryptionSecret.ValueString()
+		return
 	}
 
-	machineSecretsCerts, err := machineSecretsCertsToSecretsBundleCerts(state.MachineSecrets.Certs)
+	machineSecrets, err := machineSecretsInputToSecretsBundle(state.MachineSecrets)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"failed to convert machine secrets certs to secrets bundle certs",
@@ -243,138 +389,779 @@ func (d *talosMachineConfigurationDataSource) Read(ctx context.Context, req data
 		return
 	}
 
-	machineSecrets.Certs = machineSecretsCerts
-
 	var configPatches []string
 
 	resp.Diagnostics.Append(state.ConfigPatches.ElementsAs(ctx, &configPatches, true)...)
 
+	var additionalDocuments []string
+
+	resp.Diagnostics.Append(state.AdditionalDocuments.ElementsAs(ctx, &additionalDocuments, true)...)
+
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	genOptions := &machineConfigGenerateOptions{
-		machineType:       machineType,
-		clusterName:       state.ClusterName.ValueString(),
-		clusterEndpoint:   state.ClusterEndpoint.ValueString(),
-		machineSecrets:    machineSecrets,
-		configPatches:     configPatches,
-		kubernetesVersion: state.KubernetesVersion.ValueString(),
-		talosVersion:      state.TalosVersion.ValueString(),
-		docsEnabled:       state.Docs.ValueBool(),
-		examplesEnabled:   state.Examples.ValueBool(),
+	for i, document := range additionalDocuments {
+		if err := validateAdditionalDocument(document); err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("additional_documents").AtListIndex(i),
+				"invalid additional document",
+				err.Error(),
+			)
+		}
 	}
 
-	machineConfiguration, err := genOptions.generate()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// additional documents are appended as strategic merge patches, which add the documents not present in the generated configuration
+	configPatches = append(configPatches, additionalDocuments...)
+
+	var registries map[string]machineConfigurationRegistry
+
+	resp.Diagnostics.Append(state.Registries.ElementsAs(ctx, &registries, true)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	registryOptions, err := registriesGenerateOptions(registries)
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"failed to generate machine configuration",
+		resp.Diagnostics.AddAttributeError(
+			path.Root("registries"),
+			"registries are invalid",
 			err.Error(),
 		)
 
 		return
 	}
 
-	state.MachineConfiguration = basetypes.NewStringValue(machineConfiguration)
-	state.ID = state.ClusterName
+	diskEncryption, err := systemDiskEncryptionConfig(state.SystemDiskEncryption)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("system_disk_encryption"),
+			"system_disk_encryption is invalid",
+			err.Error(),
+		)
 
-	diags = resp.State.Set(ctx, state)
-	resp.Diagnostics.Append(diags...)
+		return
+	}
+
+	talosVersionContract, err := validateVersionContract(state.TalosVersion.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("talos_version"),
+			"talos_version is invalid",
+			err.Error(),
+		)
 
-	if resp.Diagnostics.HasError() {
 		return
 	}
-}
 
-func (d talosMachineConfigurationDataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
-	var obj types.Object
+	configContract := talosVersionContract
 
-	diags := req.Config.Get(ctx, &obj)
-	resp.Diagnostics.Append(diags...)
+	if !state.ConfigContract.IsNull() && !state.ConfigContract.IsUnknown() {
+		configContract, err = validateVersionContract(state.ConfigContract.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("config_contract"),
+				"config_contract is invalid",
+				err.Error(),
+			)
 
-	if resp.Diagnostics.HasError() {
-		return
+			return
+		}
+
+		if configContract.Greater(talosVersionContract) {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("config_contract"),
+				"config_contract is invalid",
+				fmt.Sprintf("config contract %s is newer than the talos version %s", configContract, state.TalosVersion.ValueString()),
+			)
+
+			return
+		}
 	}
 
-	var state talosMachineConfigurationDataSourceModelV0
+	var volumes map[string]machineConfigurationVolume
 
-	diags = obj.As(ctx, &state, basetypes.ObjectAsOptions{
-		UnhandledNullAsEmpty:    true,
-		UnhandledUnknownAsEmpty: true,
-	})
-	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(state.Volumes.ElementsAs(ctx, &volumes, true)...)
 
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	if !state.ClusterEndpoint.IsUnknown() && !state.ClusterEndpoint.IsNull() {
-		if err := validateClusterEndpoint(state.ClusterEndpoint.ValueString()); err != nil {
-			resp.Diagnostics.AddError(
-				"cluster_endpoint is invalid",
+	if len(volumes) > 0 {
+		if !configContract.Greater(config.TalosVersion1_7) {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("volumes"),
+				"volumes are not supported",
+				fmt.Sprintf("the volume configuration documents require Talos v1.8 or later, the config contract is %s", configContract),
+			)
+
+			return
+		}
+
+		volumeDocuments, err := volumeConfigDocuments(volumes)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("volumes"),
+				"volumes are invalid",
 				err.Error(),
 			)
+
+			return
 		}
+
+		configPatches = append(configPatches, volumeDocuments...)
 	}
 
-	var configPatches []string
+	var (
+		nodeLabels, nodeAnnotations map[string]string
+		nodeTaints                  map[string]machineConfigurationNodeTaint
+	)
 
-	resp.Diagnostics.Append(state.ConfigPatches.ElementsAs(ctx, &configPatches, true)...)
+	resp.Diagnostics.Append(state.NodeLabels.ElementsAs(ctx, &nodeLabels, true)...)
+	resp.Diagnostics.Append(state.NodeAnnotations.ElementsAs(ctx, &nodeAnnotations, true)...)
+	resp.Diagnostics.Append(state.NodeTaints.ElementsAs(ctx, &nodeTaints, true)...)
 
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	if _, err := configpatcher.LoadPatches(configPatches); err != nil {
+	nodeMetadata, err := nodeMetadataPatch(nodeLabels, nodeAnnotations, nodeTaints)
+	if err != nil {
 		resp.Diagnostics.AddError(
-			"config_patches are invalid",
+			"node labels, annotations or taints are invalid",
 			err.Error(),
 		)
 
 		return
 	}
 
-	if !state.KubernetesVersion.IsUnknown() && !state.KubernetesVersion.IsNull() && !state.TalosVersion.IsUnknown() {
-		k8sVersionCompatibility, err := compatibility.ParseKubernetesVersion(strings.TrimPrefix(state.KubernetesVersion.ValueString(), "v"))
-		if err != nil {
+	if nodeMetadata != "" {
+		configPatches = append(configPatches, nodeMetadata)
+	}
+
+	var (
+		inlineManifests      []machineConfigurationInlineManifest
+		extraManifests       []string
+		extraManifestHeaders map[string]string
+	)
+
+	resp.Diagnostics.Append(state.InlineManifests.ElementsAs(ctx, &inlineManifests, true)...)
+	resp.Diagnostics.Append(state.ExtraManifests.ElementsAs(ctx, &extraManifests, true)...)
+	resp.Diagnostics.Append(state.ExtraManifestHeaders.ElementsAs(ctx, &extraManifestHeaders, true)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	clusterManifests, err := clusterManifestsPatch(inlineManifests, extraManifests, extraManifestHeaders)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"inline or extra manifests are invalid",
+			err.Error(),
+		)
+
+		return
+	}
+
+	if clusterManifests != "" {
+		if machineType != machine.TypeControlPlane {
 			resp.Diagnostics.AddError(
-				"kubernetes_version is invalid",
-				err.Error(),
+				"inline or extra manifests are not supported",
+				"the manifests are only applied by the control plane nodes, they can't be set for the worker machine type",
 			)
 
 			return
 		}
 
-		talosVersionInfo := &machineapi.VersionInfo{}
+		configPatches = append(configPatches, clusterManifests)
+	}
 
-		if state.TalosVersion.IsNull() {
-			talosVersionInfo.Tag = gendata.VersionTag
-		}
+	var extensionServices map[string]machineConfigurationExtensionService
 
-		if !state.TalosVersion.IsNull() {
-			talosVersionInfo.Tag = state.TalosVersion.ValueString()
-		}
+	resp.Diagnostics.Append(state.ExtensionServices.ElementsAs(ctx, &extensionServices, true)...)
 
-		talosVersionCompatibility, err := compatibility.ParseTalosVersion(talosVersionInfo)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	extensionServiceDocuments, err := extensionServiceConfigDocuments(extensionServices)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("extension_services"),
+			"extension_services are invalid",
+			err.Error(),
+		)
+
+		return
+	}
+
+	configPatches = append(configPatches, extensionServiceDocuments...)
+
+	clusterEndpoint := state.ClusterEndpoint.ValueString()
+
+	if !state.ClusterEndpointPort.IsNull() {
+		clusterEndpoint, err = clusterEndpointWithPort(clusterEndpoint, int(state.ClusterEndpointPort.ValueInt64()))
 		if err != nil {
-			resp.Diagnostics.AddError(
-				"talos_version is invalid",
+			resp.Diagnostics.AddAttributeError(
+				path.Root("cluster_endpoint"),
+				"cluster_endpoint is invalid",
 				err.Error(),
 			)
 
 			return
 		}
+	}
 
-		if err := k8sVersionCompatibility.SupportedWith(talosVersionCompatibility); err != nil {
-			resp.Diagnostics.AddError(
-				"talos_version is not compatible with kubernetes_version",
-				err.Error(),
-			)
+	var additionalCertSANs []string
 
-			return
-		}
+	resp.Diagnostics.Append(state.AdditionalCertSANs.ElementsAs(ctx, &additionalCertSANs, true)...)
+
+	if resp.Diagnostics.HasError() {
+		return
 	}
-}
+
+	genOptions := &machineConfigGenerateOptions{
+		machineType:       machineType,
+		clusterName:       state.ClusterName.ValueString(),
+		clusterEndpoint:   clusterEndpoint,
+		localAPIPort:      int(state.LocalAPIServerPort.ValueInt64()),
+		additionalSANs:    additionalCertSANs,
+		machineSecrets:    machineSecrets,
+		configPatches:     configPatches,
+		registryOptions:   registryOptions,
+		diskEncryption:    diskEncryption,
+		kubernetesVersion: state.KubernetesVersion.ValueString(),
+		talosVersion:      state.TalosVersion.ValueString(),
+		configContract:    configContract.String(),
+		docsEnabled:       state.Docs.ValueBool(),
+		examplesEnabled:   state.Examples.ValueBool(),
+	}
+
+	machineConfiguration, err := genOptions.generate()
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"failed to generate machine configuration",
+			err.Error(),
+		)
+
+		return
+	}
+
+	contractChanges := []string{}
+
+	// show the changes driven by the contract only, by generating the machine configuration from the same input with the talos version contract
+	if talosVersionContract.Greater(configContract) {
+		genOptions.configContract = ""
+
+		var upgradedMachineConfiguration string
+
+		upgradedMachineConfiguration, err = genOptions.generate()
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"failed to generate machine configuration",
+				err.Error(),
+			)
+
+			return
+		}
+
+		contractChanges, err = machineConfigurationChanges(machineConfiguration, upgradedMachineConfiguration)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"failed to compare machine configurations",
+				err.Error(),
+			)
+
+			return
+		}
+	}
+
+	contractChangesValue, diags := types.ListValueFrom(ctx, types.StringType, contractChanges)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	state.ConfigContract = types.StringValue(configContract.String())
+	state.ConfigContractChanges = contractChangesValue
+
+	validationMode := state.ValidationMode.ValueString()
+	if state.ValidationMode.IsNull() {
+		validationMode = "permissive"
+	}
+
+	warnings, err := validateMachineConfiguration(machineConfiguration, validationMode)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"generated machine configuration is invalid",
+			err.Error(),
+		)
+
+		return
+	}
+
+	for _, warning := range warnings {
+		resp.Diagnostics.AddWarning(
+			"generated machine configuration validation warning",
+			warning,
+		)
+	}
+
+	machineConfigurationObject, err := machineConfigurationObject(ctx, machineConfiguration)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"failed to convert machine configuration",
+			err.Error(),
+		)
+
+		return
+	}
+
+	machineConfiguration, err = formatMachineConfiguration(machineConfiguration, state.OutputFormat.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"failed to format machine configuration",
+			err.Error(),
+		)
+
+		return
+	}
+
+	state.MachineConfiguration = basetypes.NewStringValue(machineConfiguration)
+	state.MachineConfigurationSHA256 = machineConfigurationSHA256(state.MachineConfiguration)
+	state.MachineConfigurationObject = machineConfigurationObject
+	state.ID = state.ClusterName
+
+	diags = resp.State.Set(ctx, state)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (d talosMachineConfigurationDataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	var obj types.Object
+
+	diags := req.Config.Get(ctx, &obj)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var state talosMachineConfigurationDataSourceModelV0
+
+	diags = obj.As(ctx, &state, basetypes.ObjectAsOptions{
+		UnhandledNullAsEmpty:    true,
+		UnhandledUnknownAsEmpty: true,
+	})
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !state.ClusterEndpoint.IsUnknown() && !state.ClusterEndpoint.IsNull() {
+		if err := validateClusterEndpoint(state.ClusterEndpoint.ValueString()); err != nil {
+			resp.Diagnostics.AddError(
+				"cluster_endpoint is invalid",
+				err.Error(),
+			)
+		}
+	}
+
+	if _, err := systemDiskEncryptionConfig(state.SystemDiskEncryption); err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("system_disk_encryption"),
+			"system_disk_encryption is invalid",
+			err.Error(),
+		)
+	}
+
+	var configPatches []string
+
+	resp.Diagnostics.Append(state.ConfigPatches.ElementsAs(ctx, &configPatches, true)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(validateConfigPatches(path.Root("config_patches"), configPatches)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !state.KubernetesVersion.IsUnknown() && !state.KubernetesVersion.IsNull() && !state.TalosVersion.IsUnknown() {
+		k8sVersionCompatibility, err := compatibility.ParseKubernetesVersion(strings.TrimPrefix(state.KubernetesVersion.ValueString(), "v"))
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"kubernetes_version is invalid",
+				err.Error(),
+			)
+
+			return
+		}
+
+		talosVersionInfo := &machineapi.VersionInfo{}
+
+		if state.TalosVersion.IsNull() {
+			talosVersionInfo.Tag = gendata.VersionTag
+		}
+
+		if !state.TalosVersion.IsNull() {
+			talosVersionInfo.Tag = state.TalosVersion.ValueString()
+		}
+
+		talosVersionCompatibility, err := compatibility.ParseTalosVersion(talosVersionInfo)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"talos_version is invalid",
+				err.Error(),
+			)
+
+			return
+		}
+
+		if err := k8sVersionCompatibility.SupportedWith(talosVersionCompatibility); err != nil {
+			resp.Diagnostics.AddError(
+				"talos_version is not compatible with kubernetes_version",
+				err.Error(),
+			)
+
+			return
+		}
+	}
+}
+
+// machineSecretsSchemaInput returns the schema of the machine secrets input attribute.
+func machineSecretsSchemaInput() schema.SingleNestedAttribute {
+	return schema.SingleNestedAttribute{
+		Description: "The secrets for the talos cluster",
+		Attributes: map[string]schema.Attribute{
+			"cluster": schema.SingleNestedAttribute{
+				Description: "The cluster secrets",
+				Attributes: map[string]schema.Attribute{
+					"id": schema.StringAttribute{
+						Required:    true,
+						Description: "The cluster id",
+					},
+					"secret": schema.StringAttribute{
+						Required:    true,
+						Sensitive:   true,
+						Description: "The cluster secret",
+					},
+				},
+				Required: true,
+			},
+			"secrets": schema.SingleNestedAttribute{
+				Description: "The secrets for the talos kubernetes cluster",
+				Attributes: map[string]schema.Attribute{
+					"bootstrap_token": schema.StringAttribute{
+						Description: "The bootstrap token for the talos kubernetes cluster",
+						Required:    true,
+						Sensitive:   true,
+					},
+					"secretbox_encryption_secret": schema.StringAttribute{
+						Description: "The secretbox encryption secret for the talos kubernetes cluster",
+						Required:    true,
+						Sensitive:   true,
+					},
+					"aescbc_encryption_secret": schema.StringAttribute{
+						Description: "The aescbc encryption secret for the talos kubernetes cluster",
+						Optional:    true,
+						Sensitive:   true,
+					},
+				},
+				Required: true,
+			},
+			"trustdinfo": schema.SingleNestedAttribute{
+				Description: "The trustd info for the talos kubernetes cluster",
+				Attributes: map[string]schema.Attribute{
+					"token": schema.StringAttribute{
+						Description: "The trustd token for the talos kubernetes cluster",
+						Required:    true,
+						Sensitive:   true,
+					},
+				},
+				Required: true,
+			},
+			"certs": schema.SingleNestedAttribute{
+				Description: "The certs for the talos kubernetes cluster",
+				Attributes: map[string]schema.Attribute{
+					"etcd":           certSchemaInput(),
+					"k8s":            certSchemaInput(),
+					"k8s_aggregator": certSchemaInput(),
+					"k8s_serviceaccount": schema.SingleNestedAttribute{
+						Attributes: map[string]schema.Attribute{
+							"key": schema.StringAttribute{
+								Description: "The key for the k8s service account",
+								Required:    true,
+								Sensitive:   true,
+							},
+						},
+						Required: true,
+					},
+					"os": certSchemaInput(),
+				},
+				Required: true,
+			},
+		},
+		Required: true,
+	}
+}
+
+// extensionServicesSchemaInput returns the schema of the extension services input attribute.
+func extensionServicesSchemaInput(description string) schema.MapNestedAttribute {
+	return schema.MapNestedAttribute{
+		Description: description,
+		Optional:    true,
+		NestedObject: schema.NestedAttributeObject{
+			Attributes: map[string]schema.Attribute{
+				"environment": schema.MapAttribute{
+					Description: "The environment variables of the extension service",
+					Optional:    true,
+					ElementType: types.StringType,
+				},
+				"sensitive_environment": schema.MapAttribute{
+					Description: "The environment variables of the extension service holding secrets (e.g. `TS_AUTHKEY`), merged with `environment`",
+					Optional:    true,
+					Sensitive:   true,
+					ElementType: types.StringType,
+				},
+				"config_files": schema.MapAttribute{
+					Description: "The contents of the configuration files of the extension service, keyed by mount path",
+					Optional:    true,
+					ElementType: types.StringType,
+				},
+			},
+		},
+	}
+}
+
+// systemDiskEncryptionSchemaInput returns the schema of the system disk encryption input attribute.
+func systemDiskEncryptionSchemaInput() schema.SingleNestedAttribute {
+	return schema.SingleNestedAttribute{
+		Description: "The encryption of the system disk partitions rendered as `machine.systemDiskEncryption`. " +
+			"The encryption settings are only used when the partitions are created, the node has to be reset to change them",
+		Optional: true,
+		Attributes: map[string]schema.Attribute{
+			"state":     partitionEncryptionSchemaInput("The encryption of the `STATE` partition"),
+			"ephemeral": partitionEncryptionSchemaInput("The encryption of the `EPHEMERAL` partition"),
+		},
+	}
+}
+
+func partitionEncryptionSchemaInput(description string) schema.SingleNestedAttribute {
+	return schema.SingleNestedAttribute{
+		Description: description,
+		Optional:    true,
+		Attributes: map[string]schema.Attribute{
+			"provider": schema.StringAttribute{
+				Description: "The encryption provider, defaults to `luks2`",
+				Optional:    true,
+				Validators: []validator.String{
+					stringvalidator.OneOf("luks2"),
+				},
+			},
+			"keys": schema.ListNestedAttribute{
+				Description: "The key slots, each key sets exactly one of `static_passphrase`, `node_id`, `kms_endpoint` or `tpm`",
+				Required:    true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"slot": schema.Int64Attribute{
+							Description: "The LUKS key slot",
+							Required:    true,
+							Validators: []validator.Int64{
+								int64validator.Between(0, 31),
+							},
+						},
+						"static_passphrase": schema.StringAttribute{
+							Description: "The static passphrase to encrypt the partition with",
+							Optional:    true,
+							Sensitive:   true,
+						},
+						"node_id": schema.BoolAttribute{
+							Description: "Derive the key from the node UUID (weak, the node UUID can be read from the node)",
+							Optional:    true,
+						},
+						"kms_endpoint": schema.StringAttribute{
+							Description: "The endpoint of the KMS server sealing the key, e.g. the SideroLink KMS `grpc://omni.example.com:8090`",
+							Optional:    true,
+						},
+						"tpm": schema.BoolAttribute{
+							Description: "Seal the key with the TPM, requires SecureBoot",
+							Optional:    true,
+						},
+						"tpm_check_secureboot_status_on_enroll": schema.BoolAttribute{
+							Description: "Whether to check that SecureBoot is enabled when the TPM key is enrolled, requires `tpm`",
+							Optional:    true,
+						},
+					},
+				},
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+				},
+			},
+			"cipher": schema.StringAttribute{
+				Description: "The cipher, e.g. `aes-xts-plain64`",
+				Optional:    true,
+			},
+			"key_size": schema.Int64Attribute{
+				Description: "The key size in bits",
+				Optional:    true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"block_size": schema.Int64Attribute{
+				Description: "The encryption sector size in bytes",
+				Optional:    true,
+				Validators: []validator.Int64{
+					int64validator.OneOf(512, 4096),
+				},
+			},
+			"options": schema.ListAttribute{
+				Description: "The performance options of the encryption (`no_read_workqueue`, `no_write_workqueue`, `same_cpu_crypt`)",
+				Optional:    true,
+				ElementType: types.StringType,
+				Validators: []validator.List{
+					listvalidator.ValueStringsAre(stringvalidator.OneOf("no_read_workqueue", "no_write_workqueue", "same_cpu_crypt")),
+				},
+			},
+		},
+	}
+}
+
+// nodeTaintsSchemaInput returns the schema of the node taints input attribute.
+func nodeTaintsSchemaInput() schema.MapNestedAttribute {
+	return schema.MapNestedAttribute{
+		Description: "The Kubernetes taints of the node rendered as `machine.nodeTaints`, keyed by taint key. " +
+			"The NodeRestriction admission plugin doesn't allow the worker nodes to set their taints",
+		Optional: true,
+		NestedObject: schema.NestedAttributeObject{
+			Attributes: map[string]schema.Attribute{
+				"value": schema.StringAttribute{
+					Description: "The value of the taint",
+					Optional:    true,
+				},
+				"effect": schema.StringAttribute{
+					Description: "The effect of the taint (`NoSchedule`, `PreferNoSchedule` or `NoExecute`)",
+					Required:    true,
+					Validators: []validator.String{
+						stringvalidator.OneOf(constants.ValidEffects...),
+					},
+				},
+			},
+		},
+	}
+}
+
+// volumesSchemaInput returns the schema of the volumes input attribute.
+func volumesSchemaInput() schema.MapNestedAttribute {
+	return schema.MapNestedAttribute{
+		Description: "The volumes provisioning constraints rendered as `VolumeConfig` documents, keyed by volume name. " +
+			"Talos v1.8 only supports the `EPHEMERAL` volume",
+		Optional: true,
+		NestedObject: schema.NestedAttributeObject{
+			Attributes: map[string]schema.Attribute{
+				"disk_selector": schema.StringAttribute{
+					Description: "The CEL expression selecting the disk to provision the volume on (e.g. `disk.transport == \"nvme\" && !system_disk`), defaults to the system disk",
+					Optional:    true,
+				},
+				"min_size": schema.StringAttribute{
+					Description: "The minimum size of the volume (e.g. `10GiB`)",
+					Optional:    true,
+				},
+				"max_size": schema.StringAttribute{
+					Description: "The maximum size of the volume (e.g. `100GiB`)",
+					Optional:    true,
+				},
+				"grow": schema.BoolAttribute{
+					Description: "Whether to grow the volume up to `max_size` or the size of the disk",
+					Optional:    true,
+				},
+			},
+		},
+	}
+}
+
+// registriesSchemaInput returns the schema of the registries input attribute.
+func registriesSchemaInput() schema.MapNestedAttribute {
+	return schema.MapNestedAttribute{
+		Description: "The container image registries configuration rendered as `machine.registries`, keyed by registry host (e.g. `docker.io`). " +
+			"The auth and TLS settings apply to the key host, so the settings of a mirror are set under the host of its endpoints",
+		Optional: true,
+		NestedObject: schema.NestedAttributeObject{
+			Attributes: map[string]schema.Attribute{
+				"mirrors": schema.ListAttribute{
+					Description: "The mirror endpoints (e.g. `https://registry.local:5000`) to pull the images of the registry from, tried in order before the registry itself",
+					Optional:    true,
+					ElementType: types.StringType,
+				},
+				"override_path": schema.BoolAttribute{
+					Description: "Use the path of the mirror endpoints as is instead of appending `/v2`, e.g. for Harbor proxy caches",
+					Optional:    true,
+				},
+				"auth": schema.SingleNestedAttribute{
+					Description: "The credentials to authenticate to the registry with",
+					Optional:    true,
+					Attributes: map[string]schema.Attribute{
+						"username": schema.StringAttribute{
+							Description: "The username, set along with `password`",
+							Optional:    true,
+						},
+						"password": schema.StringAttribute{
+							Description: "The password, set along with `username`",
+							Optional:    true,
+							Sensitive:   true,
+						},
+						"auth": schema.StringAttribute{
+							Description: "The base64 encoded `username:password` pair",
+							Optional:    true,
+							Sensitive:   true,
+						},
+						"identity_token": schema.StringAttribute{
+							Description: "The identity token",
+							Optional:    true,
+							Sensitive:   true,
+						},
+					},
+				},
+				"tls": schema.SingleNestedAttribute{
+					Description: "The TLS settings to connect to the registry with",
+					Optional:    true,
+					Attributes: map[string]schema.Attribute{
+						"ca_certificate": schema.StringAttribute{
+							Description: "The PEM encoded CA certificate to verify the registry certificate against",
+							Optional:    true,
+						},
+						"client_certificate": schema.StringAttribute{
+							Description: "The PEM encoded client certificate to authenticate to the registry with, set along with `client_key`",
+							Optional:    true,
+						},
+						"client_key": schema.StringAttribute{
+							Description: "The PEM encoded client key, set along with `client_certificate`",
+							Optional:    true,
+							Sensitive:   true,
+						},
+						"insecure_skip_verify": schema.BoolAttribute{
+							Description: "Skip the verification of the registry certificate",
+							Optional:    true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
 
 func certSchemaInput() schema.SingleNestedAttribute {
 	return schema.SingleNestedAttribute{
@@ -390,8 +1177,39 @@ func certSchemaInput() schema.SingleNestedAttribute {
 				Sensitive:   true,
 			},
 		},
-		Required: true,
+		Required: true,
+	}
+}
+
+// machineSecretsInputToSecretsBundle converts the machine secrets input attribute to a secrets bundle.
+func machineSecretsInputToSecretsBundle(ms machineSecrets) (*secrets.Bundle, error) {
+	machineSecrets := &secrets.Bundle{
+		Clock: secrets.NewFixedClock(time.Now()),
+		Cluster: &secrets.Cluster{
+			ID:     ms.Cluster.ID.ValueString(),
+			Secret: ms.Cluster.Secret.ValueString(),
+		},
+		Secrets: &secrets.Secrets{
+			BootstrapToken:            ms.Secrets.BootstrapToken.ValueString(),
+			SecretboxEncryptionSecret: ms.Secrets.SecretboxEncryptionSecret.ValueString(),
+		},
+		TrustdInfo: &secrets.TrustdInfo{
+			Token: ms.TrustdInfo.Token.ValueString(),
+		},
+	}
+
+	if !ms.Secrets.AESCBCEncryptionSecret.IsNull() {
+		machineSecrets.Secrets.AESCBCEncryptionSecret = ms.Secrets.AESCBCEncryptionSecret.ValueString()
+	}
+
+	machineSecretsCerts, err := machineSecretsCertsToSecretsBundleCerts(ms.Certs)
+	if err != nil {
+		return nil, err
 	}
+
+	machineSecrets.Certs = machineSecretsCerts
+
+	return machineSecrets, nil
 }
 
 func machineSecretsCertsToSecretsBundleCerts(machineSecretsCerts machineSecretsCerts) (*secrets.Certs, error) {
@@ -456,3 +1274,577 @@ func certDataToX509PEMEncodedKey(key string) (*x509.PEMEncodedKey, error) {
 		Key: keyBytes,
 	}, nil
 }
+
+// registriesGenerateOptions validates the registries and returns the options to render them as the machine registries configuration.
+func registriesGenerateOptions(registries map[string]machineConfigurationRegistry) ([]generate.Option, error) {
+	hosts := slices.Sorted(maps.Keys(registries))
+
+	mirrors := map[string]*v1alpha1.RegistryMirrorConfig{}
+	configs := map[string]*v1alpha1.RegistryConfig{}
+
+	for _, host := range hosts {
+		registry := registries[host]
+
+		if host == "" || strings.Contains(host, "://") {
+			return nil, fmt.Errorf("%q: the registry must be a host (e.g. docker.io), not a URL", host)
+		}
+
+		if len(registry.Mirrors) > 0 {
+			endpoints := stringValues(registry.Mirrors)
+
+			for _, endpoint := range endpoints {
+				u, err := url.Parse(endpoint)
+				if err != nil {
+					return nil, fmt.Errorf("%s: failed to parse the mirror endpoint: %w", host, err)
+				}
+
+				if (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+					return nil, fmt.Errorf("%s: the mirror endpoint %q must be an http:// or https:// URL", host, endpoint)
+				}
+			}
+
+			mirrors[host] = &v1alpha1.RegistryMirrorConfig{
+				MirrorEndpoints: endpoints,
+			}
+
+			if registry.OverridePath.ValueBool() {
+				mirrors[host].MirrorOverridePath = pointer.To(true)
+			}
+		} else if registry.OverridePath.ValueBool() {
+			return nil, fmt.Errorf("%s: override_path requires mirrors", host)
+		}
+
+		registryConfig, err := registryConfig(registry)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", host, err)
+		}
+
+		if registryConfig != nil {
+			configs[host] = registryConfig
+		}
+	}
+
+	return []generate.Option{
+		func(o *generate.Options) error {
+			if len(mirrors) > 0 {
+				o.RegistryMirrors = mirrors
+			}
+
+			if len(configs) > 0 {
+				o.RegistryConfig = configs
+			}
+
+			return nil
+		},
+	}, nil
+}
+
+// registryConfig returns the auth and TLS configuration of the registry, nil if it has none.
+func registryConfig(registry machineConfigurationRegistry) (*v1alpha1.RegistryConfig, error) {
+	if registry.Auth == nil && registry.TLS == nil {
+		return nil, nil //nolint:nilnil
+	}
+
+	cfg := &v1alpha1.RegistryConfig{}
+
+	if registry.Auth != nil {
+		if registry.Auth.Username.IsNull() != registry.Auth.Password.IsNull() {
+			return nil, errors.New("the auth username and password must be set together")
+		}
+
+		if registry.Auth.Auth.ValueString() != "" {
+			if _, err := base64.StdEncoding.DecodeString(registry.Auth.Auth.ValueString()); err != nil {
+				return nil, fmt.Errorf("failed to decode the auth: %w", err)
+			}
+		}
+
+		cfg.RegistryAuth = &v1alpha1.RegistryAuthConfig{
+			RegistryUsername:      registry.Auth.Username.ValueString(),
+			RegistryPassword:      registry.Auth.Password.ValueString(),
+			RegistryAuth:          registry.Auth.Auth.ValueString(),
+			RegistryIdentityToken: registry.Auth.IdentityToken.ValueString(),
+		}
+	}
+
+	if registry.TLS != nil {
+		cfg.RegistryTLS = &v1alpha1.RegistryTLSConfig{}
+
+		if ca := registry.TLS.CACertificate.ValueString(); ca != "" {
+			if block, _ := pem.Decode([]byte(ca)); block == nil || block.Type != "CERTIFICATE" {
+				return nil, errors.New("the TLS CA certificate is not a PEM encoded certificate")
+			}
+
+			cfg.RegistryTLS.TLSCA = v1alpha1.Base64Bytes(ca)
+		}
+
+		if registry.TLS.ClientCertificate.IsNull() != registry.TLS.ClientKey.IsNull() {
+			return nil, errors.New("the TLS client certificate and key must be set together")
+		}
+
+		if !registry.TLS.ClientCertificate.IsNull() {
+			if _, err := tls.X509KeyPair([]byte(registry.TLS.ClientCertificate.ValueString()), []byte(registry.TLS.ClientKey.ValueString())); err != nil {
+				return nil, fmt.Errorf("failed to load the TLS client identity: %w", err)
+			}
+
+			cfg.RegistryTLS.TLSClientIdentity = &x509.PEMEncodedCertificateAndKey{
+				Crt: []byte(registry.TLS.ClientCertificate.ValueString()),
+				Key: []byte(registry.TLS.ClientKey.ValueString()),
+			}
+		}
+
+		if registry.TLS.InsecureSkipVerify.ValueBool() {
+			cfg.RegistryTLS.TLSInsecureSkipVerify = pointer.To(true)
+		}
+	}
+
+	return cfg, nil
+}
+
+// systemDiskEncryptionConfig validates the system disk encryption settings and returns them as the machine system disk encryption configuration.
+//
+// The unknown values are skipped by the validation, so that it can run at plan time.
+func systemDiskEncryptionConfig(diskEncryption *machineConfigurationDiskEncryption) (*v1alpha1.SystemDiskEncryptionConfig, error) {
+	if diskEncryption == nil {
+		return nil, nil //nolint:nilnil
+	}
+
+	if diskEncryption.State == nil && diskEncryption.Ephemeral == nil {
+		return nil, errors.New("at least one of state or ephemeral must be set")
+	}
+
+	var (
+		cfg v1alpha1.SystemDiskEncryptionConfig
+		err error
+	)
+
+	if cfg.StatePartition, err = partitionEncryptionConfig(diskEncryption.State); err != nil {
+		return nil, fmt.Errorf("state: %w", err)
+	}
+
+	if cfg.EphemeralPartition, err = partitionEncryptionConfig(diskEncryption.Ephemeral); err != nil {
+		return nil, fmt.Errorf("ephemeral: %w", err)
+	}
+
+	return &cfg, nil
+}
+
+func partitionEncryptionConfig(encryption *machineConfigurationPartitionEncryption) (*v1alpha1.EncryptionConfig, error) {
+	if encryption == nil {
+		return nil, nil //nolint:nilnil
+	}
+
+	if len(encryption.Keys) == 0 {
+		return nil, errors.New("at least one key is required")
+	}
+
+	cfg := &v1alpha1.EncryptionConfig{
+		EncryptionProvider:    "luks2",
+		EncryptionCipher:      encryption.Cipher.ValueString(),
+		EncryptionKeySize:     uint(encryption.KeySize.ValueInt64()),
+		EncryptionBlockSize:   uint64(encryption.BlockSize.ValueInt64()),
+		EncryptionPerfOptions: stringValues(encryption.Options),
+	}
+
+	if !encryption.Provider.IsNull() && !encryption.Provider.IsUnknown() {
+		cfg.EncryptionProvider = encryption.Provider.ValueString()
+	}
+
+	slots := map[int64]struct{}{}
+
+	for _, key := range encryption.Keys {
+		slot := key.Slot.ValueInt64()
+
+		if !key.Slot.IsUnknown() {
+			if _, exists := slots[slot]; exists {
+				return nil, fmt.Errorf("key slot %d is used by several keys", slot)
+			}
+
+			slots[slot] = struct{}{}
+		}
+
+		encryptionKey, err := encryptionKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("key slot %d: %w", slot, err)
+		}
+
+		cfg.EncryptionKeys = append(cfg.EncryptionKeys, encryptionKey)
+	}
+
+	return cfg, nil
+}
+
+func encryptionKey(key machineConfigurationEncryptionKey) (*v1alpha1.EncryptionKey, error) {
+	encryptionKey := &v1alpha1.EncryptionKey{
+		KeySlot: int(key.Slot.ValueInt64()),
+	}
+
+	var kinds int
+
+	if !key.StaticPassphrase.IsNull() {
+		kinds++
+
+		encryptionKey.KeyStatic = &v1alpha1.EncryptionKeyStatic{
+			KeyData: key.StaticPassphrase.ValueString(),
+		}
+	}
+
+	if key.NodeID.ValueBool() || key.NodeID.IsUnknown() {
+		kinds++
+
+		encryptionKey.KeyNodeID = &v1alpha1.EncryptionKeyNodeID{}
+	}
+
+	if !key.KMSEndpoint.IsNull() {
+		kinds++
+
+		if !key.KMSEndpoint.IsUnknown() {
+			u, err := url.Parse(key.KMSEndpoint.ValueString())
+			if err != nil || u.Host == "" || (u.Scheme != "grpc" && u.Scheme != "https" && u.Scheme != "http") {
+				return nil, fmt.Errorf("the KMS endpoint %q must be a grpc:// or http(s):// URL", key.KMSEndpoint.ValueString())
+			}
+		}
+
+		encryptionKey.KeyKMS = &v1alpha1.EncryptionKeyKMS{
+			KMSEndpoint: key.KMSEndpoint.ValueString(),
+		}
+	}
+
+	if key.TPM.ValueBool() || key.TPM.IsUnknown() {
+		kinds++
+
+		encryptionKey.KeyTPM = &v1alpha1.EncryptionKeyTPM{}
+
+		if !key.TPMCheckSecurebootStatusOnEnroll.IsNull() {
+			encryptionKey.KeyTPM.TPMCheckSecurebootStatusOnEnroll = pointer.To(key.TPMCheckSecurebootStatusOnEnroll.ValueBool())
+		}
+	} else if !key.TPMCheckSecurebootStatusOnEnroll.IsNull() {
+		return nil, errors.New("tpm_check_secureboot_status_on_enroll requires tpm")
+	}
+
+	if kinds != 1 {
+		return nil, errors.New("exactly one of static_passphrase, node_id, kms_endpoint or tpm must be set")
+	}
+
+	return encryptionKey, nil
+}
+
+// nodeMetadataPatch validates the node labels, annotations and taints and returns the config patch setting them,
+// it returns an empty patch if none is set.
+func nodeMetadataPatch(nodeLabels, nodeAnnotations map[string]string, nodeTaints map[string]machineConfigurationNodeTaint) (string, error) {
+	if len(nodeLabels) == 0 && len(nodeAnnotations) == 0 && len(nodeTaints) == 0 {
+		return "", nil
+	}
+
+	if err := labels.Validate(nodeLabels); err != nil {
+		return "", fmt.Errorf("node_labels: %w", err)
+	}
+
+	if err := labels.ValidateAnnotations(nodeAnnotations); err != nil {
+		return "", fmt.Errorf("node_annotations: %w", err)
+	}
+
+	taints := make(map[string]string, len(nodeTaints))
+
+	for key, taint := range nodeTaints {
+		taints[key] = taint.Effect.ValueString()
+
+		if taint.Value.ValueString() != "" {
+			taints[key] = taint.Value.ValueString() + ":" + taint.Effect.ValueString()
+		}
+	}
+
+	if err := labels.ValidateTaints(taints); err != nil {
+		return "", fmt.Errorf("node_taints: %w", err)
+	}
+
+	machineConfig := map[string]any{}
+
+	if len(nodeLabels) > 0 {
+		machineConfig["nodeLabels"] = nodeLabels
+	}
+
+	if len(nodeAnnotations) > 0 {
+		machineConfig["nodeAnnotations"] = nodeAnnotations
+	}
+
+	if len(taints) > 0 {
+		machineConfig["nodeTaints"] = taints
+	}
+
+	patch, err := yaml.Marshal(map[string]any{
+		"machine": machineConfig,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return string(patch), nil
+}
+
+// clusterManifestsPatch validates the inline manifests and the extra manifest URLs and returns the config patch setting them,
+// it returns an empty patch if none is set.
+func clusterManifestsPatch(inlineManifests []machineConfigurationInlineManifest, extraManifests []string, extraManifestHeaders map[string]string) (string, error) {
+	if len(inlineManifests) == 0 && len(extraManifests) == 0 && len(extraManifestHeaders) == 0 {
+		return "", nil
+	}
+
+	clusterConfig := map[string]any{}
+
+	if len(inlineManifests) > 0 {
+		manifests := make([]map[string]string, 0, len(inlineManifests))
+
+		for _, manifest := range inlineManifests {
+			if err := validateKubernetesManifests(manifest.Contents.ValueString()); err != nil {
+				return "", fmt.Errorf("inline_manifests: %s: %w", manifest.Name.ValueString(), err)
+			}
+
+			manifests = append(manifests, map[string]string{
+				"name":     manifest.Name.ValueString(),
+				"contents": manifest.Contents.ValueString(),
+			})
+		}
+
+		clusterConfig["inlineManifests"] = manifests
+	}
+
+	for _, manifest := range extraManifests {
+		u, err := url.Parse(manifest)
+		if err != nil {
+			return "", fmt.Errorf("extra_manifests: %w", err)
+		}
+
+		if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return "", fmt.Errorf("extra_manifests: %q is not an HTTP(S) URL", manifest)
+		}
+	}
+
+	if len(extraManifests) > 0 {
+		clusterConfig["extraManifests"] = extraManifests
+	}
+
+	if len(extraManifestHeaders) > 0 {
+		clusterConfig["extraManifestHeaders"] = extraManifestHeaders
+	}
+
+	patch, err := yaml.Marshal(map[string]any{
+		"cluster": clusterConfig,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return string(patch), nil
+}
+
+// validateKubernetesManifests checks that the multi-document YAML contains Kubernetes objects, each with an `apiVersion` and a `kind`,
+// the empty documents are ignored.
+func validateKubernetesManifests(contents string) error {
+	decoder := yaml.NewDecoder(strings.NewReader(contents))
+
+	objects := 0
+
+	for i := 0; ; i++ {
+		var document any
+
+		if err := decoder.Decode(&document); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			return fmt.Errorf("document %d is not valid YAML: %w", i, err)
+		}
+
+		if document == nil {
+			continue
+		}
+
+		object, ok := document.(map[string]any)
+		if !ok {
+			return fmt.Errorf("document %d is not a Kubernetes object", i)
+		}
+
+		for _, field := range []string{"apiVersion", "kind"} {
+			if value, ok := object[field].(string); !ok || value == "" {
+				return fmt.Errorf("document %d has no %s", i, field)
+			}
+		}
+
+		objects++
+	}
+
+	if objects == 0 {
+		return errors.New("no Kubernetes objects in the manifest")
+	}
+
+	return nil
+}
+
+// volumeConfigDocuments validates the volumes and returns their VolumeConfig documents, sorted by volume name.
+func volumeConfigDocuments(volumes map[string]machineConfigurationVolume) ([]string, error) {
+	documents := make([]string, 0, len(volumes))
+
+	for _, name := range slices.Sorted(maps.Keys(volumes)) {
+		volume := volumes[name]
+
+		volumeConfig := block.NewVolumeConfigV1Alpha1()
+		volumeConfig.MetaName = name
+
+		if volume.DiskSelector.ValueString() != "" {
+			expression, err := cel.ParseBooleanExpression(volume.DiskSelector.ValueString(), celenv.DiskLocator())
+			if err != nil {
+				return nil, fmt.Errorf("%s: disk selector is invalid: %w", name, err)
+			}
+
+			volumeConfig.ProvisioningSpec.DiskSelectorSpec.Match = expression
+		}
+
+		if err := volumeConfig.ProvisioningSpec.ProvisioningMinSize.UnmarshalText([]byte(volume.MinSize.ValueString())); err != nil {
+			return nil, fmt.Errorf("%s: min size is invalid: %w", name, err)
+		}
+
+		if err := volumeConfig.ProvisioningSpec.ProvisioningMaxSize.UnmarshalText([]byte(volume.MaxSize.ValueString())); err != nil {
+			return nil, fmt.Errorf("%s: max size is invalid: %w", name, err)
+		}
+
+		if !volume.Grow.IsNull() {
+			volumeConfig.ProvisioningSpec.ProvisioningGrow = pointer.To(volume.Grow.ValueBool())
+		}
+
+		if _, err := volumeConfig.Validate(nil); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+
+		document, err := encoder.NewEncoder(volumeConfig, encoder.WithComments(encoder.CommentsDisabled)).Encode()
+		if err != nil {
+			return nil, err
+		}
+
+		documents = append(documents, string(document))
+	}
+
+	return documents, nil
+}
+
+// clusterEndpointWithPort returns the cluster endpoint URL with the port replaced.
+func clusterEndpointWithPort(endpoint string, port int) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse the cluster endpoint URL: %w", err)
+	}
+
+	u.Host = net.JoinHostPort(u.Hostname(), strconv.Itoa(port))
+
+	return u.String(), nil
+}
+
+// validateAdditionalDocument checks that the document is a single machine configuration document other than the v1alpha1 configuration.
+func validateAdditionalDocument(document string) error {
+	cfg, err := configloader.NewFromBytes([]byte(document))
+	if err != nil {
+		return err
+	}
+
+	if cfg.RawV1Alpha1() != nil {
+		return errors.New("the v1alpha1 configuration can't be an additional document, use config_patches instead")
+	}
+
+	if len(cfg.Documents()) != 1 {
+		return fmt.Errorf("expected a single document, got %d", len(cfg.Documents()))
+	}
+
+	return nil
+}
+
+// machineConfigurationObject returns the v1alpha1 document of the machine configuration as a dynamic object.
+func machineConfigurationObject(ctx context.Context, machineConfiguration string) (types.Dynamic, error) {
+	decoder := yaml.NewDecoder(strings.NewReader(machineConfiguration))
+
+	for {
+		var document any
+
+		if err := decoder.Decode(&document); err != nil {
+			if errors.Is(err, io.EOF) {
+				return types.DynamicNull(), errors.New("no v1alpha1 document in the machine configuration")
+			}
+
+			return types.DynamicNull(), fmt.Errorf("failed to decode machine configuration: %w", err)
+		}
+
+		fields, ok := document.(map[string]any)
+		if !ok || fields["version"] != "v1alpha1" || fields["kind"] != nil {
+			continue
+		}
+
+		value, err := yamlToAttrValue(ctx, document)
+		if err != nil {
+			return types.DynamicNull(), err
+		}
+
+		return types.DynamicValue(value), nil
+	}
+}
+
+// yamlToAttrValue converts the decoded YAML value to a Terraform value, maps are converted to objects and sequences to tuples,
+// null values are left out of the objects as they can't be typed.
+func yamlToAttrValue(ctx context.Context, value any) (attr.Value, error) {
+	switch v := value.(type) {
+	case map[string]any:
+		attrTypes := make(map[string]attr.Type, len(v))
+		attrValues := make(map[string]attr.Value, len(v))
+
+		for key, field := range v {
+			if field == nil {
+				continue
+			}
+
+			fieldValue, err := yamlToAttrValue(ctx, field)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", key, err)
+			}
+
+			attrTypes[key] = fieldValue.Type(ctx)
+			attrValues[key] = fieldValue
+		}
+
+		obj, diags := types.ObjectValue(attrTypes, attrValues)
+		if diags.HasError() {
+			return nil, fmt.Errorf("failed to convert object: %v", diags)
+		}
+
+		return obj, nil
+	case []any:
+		elemTypes := make([]attr.Type, 0, len(v))
+		elemValues := make([]attr.Value, 0, len(v))
+
+		for i, elem := range v {
+			elemValue, err := yamlToAttrValue(ctx, elem)
+			if err != nil {
+				return nil, fmt.Errorf("%d: %w", i, err)
+			}
+
+			elemTypes = append(elemTypes, elemValue.Type(ctx))
+			elemValues = append(elemValues, elemValue)
+		}
+
+		tuple, diags := types.TupleValue(elemTypes, elemValues)
+		if diags.HasError() {
+			return nil, fmt.Errorf("failed to convert sequence: %v", diags)
+		}
+
+		return tuple, nil
+	case nil:
+		return types.StringNull(), nil
+	case string:
+		return types.StringValue(v), nil
+	case bool:
+		return types.BoolValue(v), nil
+	case int:
+		return types.NumberValue(new(big.Float).SetInt64(int64(v))), nil
+	case uint64:
+		return types.NumberValue(new(big.Float).SetUint64(v)), nil
+	case float64:
+		return types.NumberValue(big.NewFloat(v)), nil
+	default:
+		return nil, fmt.Errorf("unsupported value type %T", value)
+	}
+}
@@ -5,14 +5,23 @@
 package talos_test
 
 import (
+	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"net/url"
 	"regexp"
+	"slices"
 	"strings"
 	"testing"
 	"text/template"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/siderolabs/crypto/x509"
+	"github.com/siderolabs/talos/pkg/machinery/config/generate/secrets"
 	"github.com/siderolabs/talos/pkg/machinery/config/machine"
 	"github.com/siderolabs/talos/pkg/machinery/config/types/v1alpha1"
 	"github.com/siderolabs/talos/pkg/machinery/constants"
@@ -43,6 +52,9 @@ func TestAccTalosMachineConfigurationDataSource(t *testing.T) {
 					resource.TestCheckResourceAttr("data.talos_machine_configuration.this", "talos_version", semver.MajorMinor(gendata.VersionTag)),
 					resource.TestCheckResourceAttr("data.talos_machine_configuration.this", "docs", "true"),
 					resource.TestCheckResourceAttr("data.talos_machine_configuration.this", "examples", "true"),
+					resource.TestCheckResourceAttr("data.talos_machine_configuration.this", "machine_configuration_object.cluster.clusterName", "example-cluster"),
+					resource.TestCheckResourceAttr("data.talos_machine_configuration.this", "machine_configuration_object.machine.type", "controlplane"),
+					resource.TestMatchResourceAttr("data.talos_machine_configuration.this", "machine_configuration_sha256", regexp.MustCompile(`^[0-9a-f]{64}$`)),
 					resource.TestCheckResourceAttrWith("data.talos_machine_configuration.this", "machine_configuration", func(value string) error {
 						return validateGeneratedTalosMachineConfig(
 							t,
@@ -215,6 +227,421 @@ func TestAccTalosMachineConfigurationDataSource(t *testing.T) {
 	})
 }
 
+func TestAccTalosMachineConfigurationDataSourceAdditionalDocuments(t *testing.T) {
+	resource.ParallelTest(t, resource.TestCase{
+		IsUnitTest:               true, // this is a local only resource, so can be unit tested
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccTalosMachineConfigurationDataSourceAdditionalDocumentsConfig("KmsgLogConfig"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.talos_machine_configuration.this", "additional_documents.#", "1"),
+					resource.TestMatchResourceAttr("data.talos_machine_configuration.this", "machine_configuration", regexp.MustCompile("(?s)kind: KmsgLogConfig.*url: tcp://192.168.3.7:3478/")),
+					resource.TestMatchResourceAttr("data.talos_machine_configuration.this", "machine_configuration", regexp.MustCompile("kind: WatchdogTimerConfig")),
+				),
+			},
+			{
+				Config:      testAccTalosMachineConfigurationDataSourceAdditionalDocumentsConfig("UnknownConfig"),
+				ExpectError: regexp.MustCompile("invalid additional document"),
+			},
+		},
+	})
+}
+
+func testAccTalosMachineConfigurationDataSourceAdditionalDocumentsConfig(kind string) string {
+	return fmt.Sprintf(`
+resource "talos_machine_secrets" "this" {}
+
+data "talos_machine_configuration" "this" {
+  cluster_name     = "example-cluster"
+  cluster_endpoint = "https://cluster.local:6443"
+  machine_type     = "controlplane"
+  machine_secrets  = talos_machine_secrets.this.machine_secrets
+  config_patches = [
+    yamlencode({
+      apiVersion = "v1alpha1"
+      kind       = "WatchdogTimerConfig"
+      device     = "/dev/watchdog0"
+    }),
+  ]
+  additional_documents = [
+    yamlencode({
+      apiVersion = "v1alpha1"
+      kind       = "%s"
+      name       = "remote-log"
+      url        = "tcp://192.168.3.7:3478/"
+    }),
+  ]
+}
+`, kind)
+}
+
+func TestAccTalosMachineConfigurationDataSourceValidationMode(t *testing.T) {
+	resource.ParallelTest(t, resource.TestCase{
+		IsUnitTest:               true, // this is a local only resource, so can be unit tested
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccTalosMachineConfigurationDataSourceValidationModeConfig("permissive"),
+				Check:  resource.TestCheckResourceAttrSet("data.talos_machine_configuration.this", "machine_configuration"),
+			},
+			{
+				Config:      testAccTalosMachineConfigurationDataSourceValidationModeConfig("strict"),
+				ExpectError: regexp.MustCompile(`machine\.install\.extensions is deprecated`),
+			},
+			{
+				Config:      testAccTalosMachineConfigurationDataSourceValidationModeConfig("lenient"),
+				ExpectError: regexp.MustCompile("Invalid Attribute Value Match"),
+			},
+		},
+	})
+}
+
+func testAccTalosMachineConfigurationDataSourceValidationModeConfig(validationMode string) string {
+	return fmt.Sprintf(`
+resource "talos_machine_secrets" "this" {}
+
+data "talos_machine_configuration" "this" {
+  cluster_name     = "example-cluster"
+  cluster_endpoint = "https://cluster.local:6443"
+  machine_type     = "controlplane"
+  machine_secrets  = talos_machine_secrets.this.machine_secrets
+  validation_mode  = "%s"
+  config_patches = [
+    yamlencode({
+      machine = {
+        install = {
+          extensions = [
+            {
+              image = "ghcr.io/siderolabs/gvisor:20231214.0-v1.6.0"
+            },
+          ]
+        }
+      }
+    }),
+  ]
+}
+`, validationMode)
+}
+
+func TestAccTalosMachineConfigurationDataSourceConfigContract(t *testing.T) {
+	resource.ParallelTest(t, resource.TestCase{
+		IsUnitTest:               true, // this is a local only resource, so can be unit tested
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccTalosMachineConfigurationDataSourceConfigContractConfig(""),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.talos_machine_configuration.this", "config_contract", "v1.8"),
+					resource.TestCheckResourceAttr("data.talos_machine_configuration.this", "config_contract_changes.#", "0"),
+				),
+			},
+			{
+				Config: testAccTalosMachineConfigurationDataSourceConfigContractConfig("v1.6"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.talos_machine_configuration.this", "config_contract", "v1.6"),
+					resource.TestCheckTypeSetElemAttr("data.talos_machine_configuration.this", "config_contract_changes.*", "machine.features.hostDNS"),
+					resource.TestCheckResourceAttrWith("data.talos_machine_configuration.this", "machine_configuration", func(value string) error {
+						if strings.Contains(value, "hostDNS") {
+							return errors.New("expected the machine configuration to be generated with the v1.6 contract")
+						}
+
+						return nil
+					}),
+				),
+			},
+			{
+				Config:      testAccTalosMachineConfigurationDataSourceConfigContractConfig("v1.9"),
+				ExpectError: regexp.MustCompile("config contract v1.9 is newer than the talos version v1.8"),
+			},
+		},
+	})
+}
+
+func testAccTalosMachineConfigurationDataSourceConfigContractConfig(configContract string) string {
+	if configContract != "" {
+		configContract = fmt.Sprintf("config_contract  = %q", configContract)
+	}
+
+	return fmt.Sprintf(`
+resource "talos_machine_secrets" "this" {}
+
+data "talos_machine_configuration" "this" {
+  cluster_name     = "example-cluster"
+  cluster_endpoint = "https://cluster.local:6443"
+  machine_type     = "controlplane"
+  machine_secrets  = talos_machine_secrets.this.machine_secrets
+  talos_version    = "v1.8"
+  %s
+}
+`, configContract)
+}
+
+func TestAccTalosMachineConfigurationDataSourceAPIServerPorts(t *testing.T) {
+	resource.ParallelTest(t, resource.TestCase{
+		IsUnitTest:               true, // this is a local only resource, so can be unit tested
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "talos_machine_secrets" "this" {}
+
+data "talos_machine_configuration" "this" {
+  cluster_name          = "example-cluster"
+  cluster_endpoint      = "https://lb.cluster.local:6443"
+  cluster_endpoint_port = 443
+  local_api_server_port = 7443
+  additional_cert_sans  = ["10.5.0.100"]
+  machine_type          = "controlplane"
+  machine_secrets       = talos_machine_secrets.this.machine_secrets
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestMatchResourceAttr("data.talos_machine_configuration.this", "machine_configuration", regexp.MustCompile(`endpoint: https://lb\.cluster\.local:443\n`)),
+					resource.TestMatchResourceAttr("data.talos_machine_configuration.this", "machine_configuration", regexp.MustCompile(`localAPIServerPort: 7443`)),
+					resource.TestMatchResourceAttr("data.talos_machine_configuration.this", "machine_configuration", regexp.MustCompile(`certSANs:\n\s+- 10\.5\.0\.100`)),
+				),
+			},
+		},
+	})
+}
+
+func TestAccTalosMachineConfigurationDataSourceRegistries(t *testing.T) {
+	resource.ParallelTest(t, resource.TestCase{
+		IsUnitTest:               true, // this is a local only resource, so can be unit tested
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccTalosMachineConfigurationDataSourceRegistriesConfig("https://harbor.local/v2/proxy-docker"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestMatchResourceAttr("data.talos_machine_configuration.this", "machine_configuration", regexp.MustCompile(`(?s)mirrors:\n\s+docker\.io:\n\s+endpoints:\n\s+- https://harbor\.local/v2/proxy-docker\n\s+overridePath: true`)),
+					resource.TestMatchResourceAttr("data.talos_machine_configuration.this", "machine_configuration", regexp.MustCompile(`(?s)config:\n\s+harbor\.local:\n.*username: robot`)),
+					resource.TestMatchResourceAttr("data.talos_machine_configuration.this", "machine_configuration", regexp.MustCompile(`insecureSkipVerify: true`)),
+				),
+			},
+			{
+				Config:      testAccTalosMachineConfigurationDataSourceRegistriesConfig("harbor.local"),
+				ExpectError: regexp.MustCompile("registries are invalid"),
+			},
+		},
+	})
+}
+
+func testAccTalosMachineConfigurationDataSourceRegistriesConfig(mirror string) string {
+	return fmt.Sprintf(`
+resource "talos_machine_secrets" "this" {}
+
+data "talos_machine_configuration" "this" {
+  cluster_name     = "example-cluster"
+  cluster_endpoint = "https://cluster.local:6443"
+  machine_type     = "worker"
+  machine_secrets  = talos_machine_secrets.this.machine_secrets
+  registries = {
+    "docker.io" = {
+      mirrors       = ["%s"]
+      override_path = true
+    }
+    "harbor.local" = {
+      auth = {
+        username = "robot"
+        password = "secret"
+      }
+      tls = {
+        insecure_skip_verify = true
+      }
+    }
+  }
+}
+`, mirror)
+}
+
+func TestAccTalosMachineConfigurationDataSourceNodeMetadata(t *testing.T) {
+	resource.ParallelTest(t, resource.TestCase{
+		IsUnitTest:               true, // this is a local only resource, so can be unit tested
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccTalosMachineConfigurationDataSourceNodeMetadataConfig("zone-a"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestMatchResourceAttr("data.talos_machine_configuration.this", "machine_configuration", regexp.MustCompile(`(?s)nodeLabels:\n\s+topology\.kubernetes\.io/zone: zone-a`)),
+					resource.TestMatchResourceAttr("data.talos_machine_configuration.this", "machine_configuration", regexp.MustCompile(`(?s)nodeAnnotations:\n\s+customer\.io/rack: r13a25`)),
+					resource.TestMatchResourceAttr("data.talos_machine_configuration.this", "machine_configuration", regexp.MustCompile(`(?s)nodeTaints:\n\s+dedicated: gpu:NoSchedule`)),
+				),
+			},
+			{
+				Config:      testAccTalosMachineConfigurationDataSourceNodeMetadataConfig("not a valid value"),
+				ExpectError: regexp.MustCompile("node labels, annotations or taints are invalid"),
+			},
+		},
+	})
+}
+
+func testAccTalosMachineConfigurationDataSourceNodeMetadataConfig(zone string) string {
+	return fmt.Sprintf(`
+resource "talos_machine_secrets" "this" {}
+
+data "talos_machine_configuration" "this" {
+  cluster_name     = "example-cluster"
+  cluster_endpoint = "https://cluster.local:6443"
+  machine_type     = "controlplane"
+  machine_secrets  = talos_machine_secrets.this.machine_secrets
+  node_labels = {
+    "topology.kubernetes.io/zone" = "%s"
+  }
+  node_annotations = {
+    "customer.io/rack" = "r13a25"
+  }
+  node_taints = {
+    dedicated = {
+      value  = "gpu"
+      effect = "NoSchedule"
+    }
+  }
+}
+`, zone)
+}
+
+func TestAccTalosMachineConfigurationDataSourceManifests(t *testing.T) {
+	resource.ParallelTest(t, resource.TestCase{
+		IsUnitTest:               true, // this is a local only resource, so can be unit tested
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccTalosMachineConfigurationDataSourceManifestsConfig("controlplane", "Namespace"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestMatchResourceAttr("data.talos_machine_configuration.this", "machine_configuration", regexp.MustCompile(`(?s)inlineManifests:\n\s+- name: flux-namespace\n\s+contents: \|-?\n\s+apiVersion: v1\n\s+kind: Namespace`)),
+					resource.TestMatchResourceAttr("data.talos_machine_configuration.this", "machine_configuration", regexp.MustCompile(`(?s)extraManifests:\n\s+- https://example\.com/flux\.yaml`)),
+					resource.TestMatchResourceAttr("data.talos_machine_configuration.this", "machine_configuration", regexp.MustCompile(`(?s)extraManifestHeaders:\n\s+Authorization: Bearer token`)),
+				),
+			},
+			{
+				Config:      testAccTalosMachineConfigurationDataSourceManifestsConfig("controlplane", ""),
+				ExpectError: regexp.MustCompile("inline or extra manifests are invalid"),
+			},
+			{
+				Config:      testAccTalosMachineConfigurationDataSourceManifestsConfig("worker", "Namespace"),
+				ExpectError: regexp.MustCompile("inline or extra manifests are not supported"),
+			},
+		},
+	})
+}
+
+func testAccTalosMachineConfigurationDataSourceManifestsConfig(machineType, kind string) string {
+	return fmt.Sprintf(`
+resource "talos_machine_secrets" "this" {}
+
+data "talos_machine_configuration" "this" {
+  cluster_name     = "example-cluster"
+  cluster_endpoint = "https://cluster.local:6443"
+  machine_type     = "%s"
+  machine_secrets  = talos_machine_secrets.this.machine_secrets
+  inline_manifests = [
+    {
+      name = "flux-namespace"
+      contents = <<-EOT
+        apiVersion: v1
+        kind: %s
+        metadata:
+          name: flux-system
+      EOT
+    },
+  ]
+  extra_manifests = ["https://example.com/flux.yaml"]
+  extra_manifest_headers = {
+    Authorization = "Bearer token"
+  }
+}
+`, machineType, kind)
+}
+
+func TestAccTalosMachineConfigurationDataSourceVolumes(t *testing.T) {
+	resource.ParallelTest(t, resource.TestCase{
+		IsUnitTest:               true, // this is a local only resource, so can be unit tested
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccTalosMachineConfigurationDataSourceVolumesConfig("v1.8", "EPHEMERAL"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestMatchResourceAttr("data.talos_machine_configuration.this", "machine_configuration", regexp.MustCompile(`(?s)kind: VolumeConfig\nname: EPHEMERAL\nprovisioning:\n\s+diskSelector:\n\s+match: disk\.transport == "nvme"\n\s+maxSize: 50GiB`)),
+				),
+			},
+			{
+				Config:      testAccTalosMachineConfigurationDataSourceVolumesConfig("v1.8", "DATA"),
+				ExpectError: regexp.MustCompile("only EPHEMERAL volumes are supported"),
+			},
+			{
+				Config:      testAccTalosMachineConfigurationDataSourceVolumesConfig("v1.7", "EPHEMERAL"),
+				ExpectError: regexp.MustCompile("volumes are not supported"),
+			},
+		},
+	})
+}
+
+func testAccTalosMachineConfigurationDataSourceVolumesConfig(talosVersion, volume string) string {
+	return fmt.Sprintf(`
+resource "talos_machine_secrets" "this" {}
+
+data "talos_machine_configuration" "this" {
+  cluster_name     = "example-cluster"
+  cluster_endpoint = "https://cluster.local:6443"
+  machine_type     = "controlplane"
+  machine_secrets  = talos_machine_secrets.this.machine_secrets
+  talos_version    = "%s"
+  volumes = {
+    "%s" = {
+      disk_selector = "disk.transport == \"nvme\""
+      max_size      = "50GiB"
+    }
+  }
+}
+`, talosVersion, volume)
+}
+
+func TestAccTalosMachineConfigurationDataSourceExtensionServices(t *testing.T) {
+	resource.ParallelTest(t, resource.TestCase{
+		IsUnitTest:               true, // this is a local only resource, so can be unit tested
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccTalosMachineConfigurationDataSourceExtensionServicesConfig("UPS_USER"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestMatchResourceAttr("data.talos_machine_configuration.this", "machine_configuration", regexp.MustCompile(`(?s)kind: ExtensionServiceConfig\nname: nut-client\nconfigFiles:\n\s+- content: MONITOR \$\{upsmonHost\} 1 remote username password\n\s+mountPath: /usr/local/etc/nut/upsmon.conf\nenvironment:\n\s+- UPS_PASSWORD=secret\n\s+- UPS_USER=plain`)),
+				),
+			},
+			{
+				Config:      testAccTalosMachineConfigurationDataSourceExtensionServicesConfig("UPS_PASSWORD"),
+				ExpectError: regexp.MustCompile("extension_services are invalid"),
+			},
+		},
+	})
+}
+
+func testAccTalosMachineConfigurationDataSourceExtensionServicesConfig(environment string) string {
+	return fmt.Sprintf(`
+resource "talos_machine_secrets" "this" {}
+
+data "talos_machine_configuration" "this" {
+  cluster_name     = "example-cluster"
+  cluster_endpoint = "https://cluster.local:6443"
+  machine_type     = "worker"
+  machine_secrets  = talos_machine_secrets.this.machine_secrets
+  extension_services = {
+    "nut-client" = {
+      environment = {
+        %s = "plain"
+      }
+      sensitive_environment = {
+        UPS_PASSWORD = "secret"
+      }
+      config_files = {
+        "/usr/local/etc/nut/upsmon.conf" = "MONITOR $${upsmonHost} 1 remote username password"
+      }
+    }
+  }
+}
+`, environment)
+}
+
 func testAccTalosMachineConfigurationDataSourceConfig(
 	talosConfigVersion,
 	clusterName,
@@ -372,3 +799,734 @@ func validateGeneratedTalosMachineConfig(
 
 	return nil
 }
+
+func TestValidateAdditionalDocument(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name     string
+		document string
+		valid    bool
+	}{
+		{
+			name:     "kmsg log config",
+			document: talos.KmsgLogConfig,
+			valid:    true,
+		},
+		{
+			name:     "v1alpha1 config",
+			document: talos.MachineConfigurationYAML,
+			valid:    false,
+		},
+		{
+			name: "multiple documents",
+			document: talos.KmsgLogConfig + `---
+apiVersion: v1alpha1
+kind: WatchdogTimerConfig
+device: /dev/watchdog0
+`,
+			valid: false,
+		},
+		{
+			name: "unknown kind",
+			document: `apiVersion: v1alpha1
+kind: UnknownConfig
+`,
+			valid: false,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if err := talos.ValidateAdditionalDocument(tt.document); (err == nil) != tt.valid {
+				t.Errorf("expected valid %v, got error %v", tt.valid, err)
+			}
+		})
+	}
+}
+
+func TestClusterEndpointWithPort(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		endpoint string
+		port     int
+		expected string
+	}{
+		{
+			endpoint: "https://cluster.local:6443",
+			port:     443,
+			expected: "https://cluster.local:443",
+		},
+		{
+			endpoint: "https://cluster.local",
+			port:     8443,
+			expected: "https://cluster.local:8443",
+		},
+		{
+			endpoint: "https://[2001:db8::1]:6443",
+			port:     443,
+			expected: "https://[2001:db8::1]:443",
+		},
+	} {
+		t.Run(tt.endpoint, func(t *testing.T) {
+			t.Parallel()
+
+			endpoint, err := talos.ClusterEndpointWithPort(tt.endpoint, tt.port)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if endpoint != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, endpoint)
+			}
+		})
+	}
+}
+
+func TestMachineConfigurationObject(t *testing.T) {
+	t.Parallel()
+
+	obj, err := talos.MachineConfigurationObject(context.Background(), `version: v1alpha1
+machine:
+  type: worker
+  install:
+    disk: /dev/sda
+    extraKernelArgs: null
+  kubelet:
+    extraArgs:
+      max-pods: "250"
+cluster:
+  clusterName: example-cluster
+  network:
+    podSubnets:
+      - 10.244.0.0/16
+  controlPlane:
+    endpoint: https://cluster.local:6443
+  apiServer:
+    admissionControl:
+      - name: PodSecurity
+        configuration:
+          apiVersion: pod-security.admission.config.k8s.io/v1alpha1
+---
+apiVersion: v1alpha1
+kind: ExtensionServiceConfig
+name: nut-client
+`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cluster, ok := obj.UnderlyingValue().(types.Object).Attributes()["cluster"].(types.Object)
+	if !ok {
+		t.Fatalf("expected cluster to be an object, got %v", obj)
+	}
+
+	if clusterName := cluster.Attributes()["clusterName"]; !clusterName.Equal(types.StringValue("example-cluster")) {
+		t.Errorf("expected cluster name %q, got %v", "example-cluster", clusterName)
+	}
+
+	podSubnets := cluster.Attributes()["network"].(types.Object).Attributes()["podSubnets"] //nolint:forcetypeassert
+	if expected, _ := types.TupleValue([]attr.Type{types.StringType}, []attr.Value{types.StringValue("10.244.0.0/16")}); !podSubnets.Equal(expected) {
+		t.Errorf("expected pod subnets %v, got %v", expected, podSubnets)
+	}
+
+	install := obj.UnderlyingValue().(types.Object).Attributes()["machine"].(types.Object).Attributes()["install"].(types.Object) //nolint:forcetypeassert
+	if _, ok = install.Attributes()["extraKernelArgs"]; ok {
+		t.Errorf("expected null fields to be left out, got %v", install)
+	}
+
+	if _, err = talos.MachineConfigurationObject(context.Background(), "apiVersion: v1alpha1\nkind: ExtensionServiceConfig\nname: nut-client\n"); err == nil || !strings.Contains(err.Error(), "no v1alpha1 document") {
+		t.Errorf("expected missing v1alpha1 document error, got %v", err)
+	}
+}
+
+func TestRegistriesGenerateOptions(t *testing.T) {
+	t.Parallel()
+
+	secretsBundle, err := secrets.NewBundle(secrets.NewFixedClock(time.Now()), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ca, err := x509.NewSelfSignedCertificateAuthority(x509.ECDSA(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name        string
+		registries  map[string]talos.MachineConfigurationRegistry
+		expected    []string
+		expectedErr string
+	}{
+		{
+			name: "mirror with auth and tls",
+			registries: map[string]talos.MachineConfigurationRegistry{
+				"docker.io": {
+					Mirrors:      []types.String{types.StringValue("https://harbor.local/v2/proxy")},
+					OverridePath: types.BoolValue(true),
+				},
+				"harbor.local": {
+					Auth: &talos.MachineConfigurationRegistryAuth{
+						Username: types.StringValue("robot"),
+						Password: types.StringValue("secret"),
+					},
+					TLS: &talos.MachineConfigurationRegistryTLS{
+						CACertificate: types.StringValue(string(ca.CrtPEM)),
+					},
+				},
+			},
+			expected: []string{
+				"docker.io:\n                endpoints:\n                    - https://harbor.local/v2/proxy\n                overridePath: true",
+				"harbor.local:",
+				"username: robot",
+				"password: secret",
+				"ca: " + base64.StdEncoding.EncodeToString(ca.CrtPEM),
+			},
+		},
+		{
+			name: "url host",
+			registries: map[string]talos.MachineConfigurationRegistry{
+				"https://docker.io": {
+					Mirrors: []types.String{types.StringValue("https://harbor.local")},
+				},
+			},
+			expectedErr: "the registry must be a host",
+		},
+		{
+			name: "invalid mirror",
+			registries: map[string]talos.MachineConfigurationRegistry{
+				"docker.io": {
+					Mirrors: []types.String{types.StringValue("harbor.local")},
+				},
+			},
+			expectedErr: `docker.io: the mirror endpoint "harbor.local" must be an http:// or https:// URL`,
+		},
+		{
+			name: "username without password",
+			registries: map[string]talos.MachineConfigurationRegistry{
+				"harbor.local": {
+					Auth: &talos.MachineConfigurationRegistryAuth{
+						Username: types.StringValue("robot"),
+					},
+				},
+			},
+			expectedErr: "harbor.local: the auth username and password must be set together",
+		},
+		{
+			name: "invalid ca",
+			registries: map[string]talos.MachineConfigurationRegistry{
+				"harbor.local": {
+					TLS: &talos.MachineConfigurationRegistryTLS{
+						CACertificate: types.StringValue("invalid"),
+					},
+				},
+			},
+			expectedErr: "harbor.local: the TLS CA certificate is not a PEM encoded certificate",
+		},
+		{
+			name: "client certificate without key",
+			registries: map[string]talos.MachineConfigurationRegistry{
+				"harbor.local": {
+					TLS: &talos.MachineConfigurationRegistryTLS{
+						ClientCertificate: types.StringValue(string(ca.CrtPEM)),
+					},
+				},
+			},
+			expectedErr: "harbor.local: the TLS client certificate and key must be set together",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			registryOptions, err := talos.RegistriesGenerateOptions(tt.registries)
+			if tt.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectedErr, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			machineConfiguration, err := talos.GenerateMachineConfiguration(machine.TypeWorker, secretsBundle, talos.WithRegistryOptions(registryOptions...))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, expected := range tt.expected {
+				if !strings.Contains(machineConfiguration, expected) {
+					t.Errorf("expected the machine configuration to contain %q:\n%s", expected, machineConfiguration)
+				}
+			}
+		})
+	}
+}
+
+func TestVolumeConfigDocuments(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name        string
+		volumes     map[string]talos.MachineConfigurationVolume
+		expected    []string
+		expectedErr string
+	}{
+		{
+			name: "ephemeral",
+			volumes: map[string]talos.MachineConfigurationVolume{
+				"EPHEMERAL": {
+					DiskSelector: types.StringValue(`disk.transport == "nvme" && !system_disk`),
+					MinSize:      types.StringValue("10GiB"),
+					MaxSize:      types.StringValue("100GiB"),
+					Grow:         types.BoolValue(false),
+				},
+			},
+			expected: []string{`apiVersion: v1alpha1
+kind: VolumeConfig
+name: EPHEMERAL
+provisioning:
+    diskSelector:
+        match: disk.transport == "nvme" && !system_disk
+    grow: false
+    minSize: 10GiB
+    maxSize: 100GiB
+`},
+		},
+		{
+			name: "unsupported volume",
+			volumes: map[string]talos.MachineConfigurationVolume{
+				"DATA": {},
+			},
+			expectedErr: "DATA: only EPHEMERAL volumes are supported",
+		},
+		{
+			name: "invalid disk selector",
+			volumes: map[string]talos.MachineConfigurationVolume{
+				"EPHEMERAL": {
+					DiskSelector: types.StringValue("disk.transprt == 'nvme'"),
+				},
+			},
+			expectedErr: "EPHEMERAL: disk selector is invalid",
+		},
+		{
+			name: "invalid size",
+			volumes: map[string]talos.MachineConfigurationVolume{
+				"EPHEMERAL": {
+					MaxSize: types.StringValue("10 potatoes"),
+				},
+			},
+			expectedErr: "EPHEMERAL: max size is invalid",
+		},
+		{
+			name: "min size greater than max size",
+			volumes: map[string]talos.MachineConfigurationVolume{
+				"EPHEMERAL": {
+					MinSize: types.StringValue("100GiB"),
+					MaxSize: types.StringValue("10GiB"),
+				},
+			},
+			expectedErr: "EPHEMERAL: min size is greater than max size",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			documents, err := talos.VolumeConfigDocuments(tt.volumes)
+			if tt.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectedErr, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !slices.Equal(documents, tt.expected) {
+				t.Errorf("expected %q, got %q", tt.expected, documents)
+			}
+		})
+	}
+}
+
+func TestSystemDiskEncryptionConfig(t *testing.T) {
+	t.Parallel()
+
+	secretsBundle, err := secrets.NewBundle(secrets.NewFixedClock(time.Now()), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name           string
+		diskEncryption *talos.MachineConfigurationDiskEncryption
+		expected       []string
+		expectedErr    string
+	}{
+		{
+			name: "tpm and kms",
+			diskEncryption: &talos.MachineConfigurationDiskEncryption{
+				State: &talos.MachineConfigurationPartitionEncryption{
+					Keys: []talos.MachineConfigurationEncryptionKey{
+						{
+							Slot:        types.Int64Value(0),
+							KMSEndpoint: types.StringValue("grpc://omni.example.com:8090"),
+						},
+					},
+				},
+				Ephemeral: &talos.MachineConfigurationPartitionEncryption{
+					Keys: []talos.MachineConfigurationEncryptionKey{
+						{
+							Slot:                             types.Int64Value(0),
+							TPM:                              types.BoolValue(true),
+							TPMCheckSecurebootStatusOnEnroll: types.BoolValue(true),
+						},
+						{
+							Slot:             types.Int64Value(1),
+							StaticPassphrase: types.StringValue("recovery"),
+						},
+					},
+					Options: []types.String{types.StringValue("no_read_workqueue")},
+				},
+			},
+			expected: []string{
+				"systemDiskEncryption:",
+				"endpoint: grpc://omni.example.com:8090",
+				"checkSecurebootStatusOnEnroll: true",
+				"passphrase: recovery",
+				"- no_read_workqueue",
+			},
+		},
+		{
+			name:           "no partition",
+			diskEncryption: &talos.MachineConfigurationDiskEncryption{},
+			expectedErr:    "at least one of state or ephemeral must be set",
+		},
+		{
+			name: "several key kinds",
+			diskEncryption: &talos.MachineConfigurationDiskEncryption{
+				State: &talos.MachineConfigurationPartitionEncryption{
+					Keys: []talos.MachineConfigurationEncryptionKey{
+						{
+							Slot:   types.Int64Value(0),
+							NodeID: types.BoolValue(true),
+							TPM:    types.BoolValue(true),
+						},
+					},
+				},
+			},
+			expectedErr: "state: key slot 0: exactly one of static_passphrase, node_id, kms_endpoint or tpm must be set",
+		},
+		{
+			name: "duplicate slot",
+			diskEncryption: &talos.MachineConfigurationDiskEncryption{
+				Ephemeral: &talos.MachineConfigurationPartitionEncryption{
+					Keys: []talos.MachineConfigurationEncryptionKey{
+						{
+							Slot:   types.Int64Value(0),
+							NodeID: types.BoolValue(true),
+						},
+						{
+							Slot: types.Int64Value(0),
+							TPM:  types.BoolValue(true),
+						},
+					},
+				},
+			},
+			expectedErr: "ephemeral: key slot 0 is used by several keys",
+		},
+		{
+			name: "invalid kms endpoint",
+			diskEncryption: &talos.MachineConfigurationDiskEncryption{
+				State: &talos.MachineConfigurationPartitionEncryption{
+					Keys: []talos.MachineConfigurationEncryptionKey{
+						{
+							Slot:        types.Int64Value(0),
+							KMSEndpoint: types.StringValue("omni.example.com"),
+						},
+					},
+				},
+			},
+			expectedErr: "must be a grpc:// or http(s):// URL",
+		},
+		{
+			name: "unknown kms endpoint",
+			diskEncryption: &talos.MachineConfigurationDiskEncryption{
+				State: &talos.MachineConfigurationPartitionEncryption{
+					Keys: []talos.MachineConfigurationEncryptionKey{
+						{
+							Slot:        types.Int64Value(0),
+							KMSEndpoint: types.StringUnknown(),
+						},
+					},
+				},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			diskEncryption, err := talos.SystemDiskEncryptionConfig(tt.diskEncryption)
+			if tt.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectedErr, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(tt.expected) == 0 {
+				return
+			}
+
+			machineConfiguration, err := talos.GenerateMachineConfiguration(machine.TypeWorker, secretsBundle, talos.WithDiskEncryption(diskEncryption))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err = talos.ValidateMachineConfiguration(machineConfiguration, "strict"); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, expected := range tt.expected {
+				if !strings.Contains(machineConfiguration, expected) {
+					t.Errorf("expected the machine configuration to contain %q:\n%s", expected, machineConfiguration)
+				}
+			}
+		})
+	}
+}
+
+func TestNodeMetadataPatch(t *testing.T) {
+	t.Parallel()
+
+	secretsBundle, err := secrets.NewBundle(secrets.NewFixedClock(time.Now()), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name            string
+		nodeLabels      map[string]string
+		nodeAnnotations map[string]string
+		nodeTaints      map[string]talos.MachineConfigurationNodeTaint
+		expected        []string
+		expectedErr     string
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name: "labels, annotations and taints",
+			nodeLabels: map[string]string{
+				"topology.kubernetes.io/zone": "zone-a",
+			},
+			nodeAnnotations: map[string]string{
+				"customer.io/rack": "r13a25",
+			},
+			nodeTaints: map[string]talos.MachineConfigurationNodeTaint{
+				"dedicated": {
+					Value:  types.StringValue("gpu"),
+					Effect: types.StringValue("NoSchedule"),
+				},
+				"maintenance": {
+					Value:  types.StringNull(),
+					Effect: types.StringValue("NoExecute"),
+				},
+			},
+			expected: []string{
+				"topology.kubernetes.io/zone: zone-a",
+				"customer.io/rack: r13a25",
+				"dedicated: gpu:NoSchedule",
+				"maintenance: NoExecute",
+			},
+		},
+		{
+			name: "invalid label",
+			nodeLabels: map[string]string{
+				"-invalid": "value",
+			},
+			expectedErr: "node_labels:",
+		},
+		{
+			name: "invalid taint value",
+			nodeTaints: map[string]talos.MachineConfigurationNodeTaint{
+				"dedicated": {
+					Value:  types.StringValue("not a valid value"),
+					Effect: types.StringValue("NoSchedule"),
+				},
+			},
+			expectedErr: "node_taints:",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			patch, err := talos.NodeMetadataPatch(tt.nodeLabels, tt.nodeAnnotations, tt.nodeTaints)
+			if tt.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectedErr, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(tt.expected) == 0 {
+				if patch != "" {
+					t.Fatalf("expected an empty patch, got %q", patch)
+				}
+
+				return
+			}
+
+			machineConfiguration, err := talos.GenerateMachineConfiguration(machine.TypeControlPlane, secretsBundle, talos.WithConfigPatches(patch))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err = talos.ValidateMachineConfiguration(machineConfiguration, "strict"); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, expected := range tt.expected {
+				if !strings.Contains(machineConfiguration, expected) {
+					t.Errorf("expected the machine configuration to contain %q:\n%s", expected, machineConfiguration)
+				}
+			}
+		})
+	}
+}
+
+func TestClusterManifestsPatch(t *testing.T) {
+	t.Parallel()
+
+	secretsBundle, err := secrets.NewBundle(secrets.NewFixedClock(time.Now()), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inlineManifest := func(name, contents string) talos.MachineConfigurationInlineManifest {
+		return talos.MachineConfigurationInlineManifest{
+			Name:     types.StringValue(name),
+			Contents: types.StringValue(contents),
+		}
+	}
+
+	for _, tt := range []struct {
+		name                 string
+		inlineManifests      []talos.MachineConfigurationInlineManifest
+		extraManifests       []string
+		extraManifestHeaders map[string]string
+		expected             []string
+		expectedErr          string
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name: "inline and extra manifests",
+			inlineManifests: []talos.MachineConfigurationInlineManifest{
+				inlineManifest("namespaces", "---\napiVersion: v1\nkind: Namespace\nmetadata:\n  name: flux-system\n---\napiVersion: v1\nkind: Namespace\nmetadata:\n  name: cilium\n"),
+			},
+			extraManifests: []string{"https://github.com/fluxcd/flux2/releases/latest/download/install.yaml"},
+			extraManifestHeaders: map[string]string{
+				"Authorization": "Bearer token",
+			},
+			expected: []string{
+				"name: namespaces",
+				"kind: Namespace",
+				"name: flux-system",
+				"- https://github.com/fluxcd/flux2/releases/latest/download/install.yaml",
+				"Authorization: Bearer token",
+			},
+		},
+		{
+			name: "invalid yaml",
+			inlineManifests: []talos.MachineConfigurationInlineManifest{
+				inlineManifest("broken", "apiVersion: v1\nkind: [Namespace\n"),
+			},
+			expectedErr: "inline_manifests: broken: document 0 is not valid YAML",
+		},
+		{
+			name: "missing kind",
+			inlineManifests: []talos.MachineConfigurationInlineManifest{
+				inlineManifest("config", "apiVersion: v1\n---\napiVersion: v1\nmetadata:\n  name: config\n"),
+			},
+			expectedErr: "inline_manifests: config: document 0 has no kind",
+		},
+		{
+			name: "not an object",
+			inlineManifests: []talos.MachineConfigurationInlineManifest{
+				inlineManifest("list", "- apiVersion: v1\n  kind: Namespace\n"),
+			},
+			expectedErr: "document 0 is not a Kubernetes object",
+		},
+		{
+			name: "no objects",
+			inlineManifests: []talos.MachineConfigurationInlineManifest{
+				inlineManifest("empty", "---\n# nothing\n---\n"),
+			},
+			expectedErr: "no Kubernetes objects in the manifest",
+		},
+		{
+			name:           "not an http url",
+			extraManifests: []string{"ftp://example.com/manifest.yaml"},
+			expectedErr:    `extra_manifests: "ftp://example.com/manifest.yaml" is not an HTTP(S) URL`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			patch, err := talos.ClusterManifestsPatch(tt.inlineManifests, tt.extraManifests, tt.extraManifestHeaders)
+			if tt.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectedErr, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(tt.expected) == 0 {
+				if patch != "" {
+					t.Fatalf("expected an empty patch, got %q", patch)
+				}
+
+				return
+			}
+
+			machineConfiguration, err := talos.GenerateMachineConfiguration(machine.TypeControlPlane, secretsBundle, talos.WithConfigPatches(patch))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err = talos.ValidateMachineConfiguration(machineConfiguration, "strict"); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, expected := range tt.expected {
+				if !strings.Contains(machineConfiguration, expected) {
+					t.Errorf("expected the machine configuration to contain %q:\n%s", expected, machineConfiguration)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,226 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cosi-project/runtime/pkg/safe"
+	cosistate "github.com/cosi-project/runtime/pkg/state"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
+	"github.com/siderolabs/talos/pkg/machinery/client"
+	"github.com/siderolabs/talos/pkg/machinery/config/encoder"
+	"github.com/siderolabs/talos/pkg/machinery/resources/config"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type talosMachineConfigurationLiveDataSource struct {
+	providerData *talosProviderData
+}
+
+type talosMachineConfigurationLiveDataSourceModelV0 struct { //nolint:govet
+	ID                   types.String         `tfsdk:"id"`
+	Node                 types.String         `tfsdk:"node"`
+	Endpoint             types.String         `tfsdk:"endpoint"`
+	ClientConfiguration  *clientConfiguration `tfsdk:"client_configuration"`
+	RedactSecrets        types.Bool           `tfsdk:"redact_secrets"`
+	MachineConfiguration types.String         `tfsdk:"machine_configuration"`
+	Timeouts             timeouts.Value       `tfsdk:"timeouts"`
+}
+
+var (
+	_ datasource.DataSource              = &talosMachineConfigurationLiveDataSource{}
+	_ datasource.DataSourceWithConfigure = &talosMachineConfigurationLiveDataSource{}
+)
+
+// NewTalosMachineConfigurationLiveDataSource implements the datasource.DataSource interface.
+func NewTalosMachineConfigurationLiveDataSource() datasource.DataSource {
+	return &talosMachineConfigurationLiveDataSource{}
+}
+
+func (d *talosMachineConfigurationLiveDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_machine_configuration_live"
+}
+
+func (d *talosMachineConfigurationLiveDataSource) Schema(ctx context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Retrieves the machine configuration currently applied to a node",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The generated ID of this resource",
+				Computed:    true,
+			},
+			"node": schema.StringAttribute{
+				Required:    true,
+				Description: "node to retrieve the machine configuration from",
+			},
+			"endpoint": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "endpoint to use for the talosclient. If not set, the node value will be used",
+			},
+			"client_configuration": schema.SingleNestedAttribute{
+				Attributes: map[string]schema.Attribute{
+					"ca_certificate": schema.StringAttribute{
+						Required:    true,
+						Description: "The client CA certificate",
+					},
+					"client_certificate": schema.StringAttribute{
+						Required:    true,
+						Description: "The client certificate",
+					},
+					"client_key": schema.StringAttribute{
+						Required:    true,
+						Sensitive:   true,
+						Description: "The client key",
+					},
+				},
+				Optional:    true,
+				Description: "The client configuration data, defaults to the credentials of the provider `talosconfig_path`",
+			},
+			"redact_secrets": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Whether to redact the secrets (keys, tokens) in the retrieved machine configuration",
+			},
+			"machine_configuration": schema.StringAttribute{
+				Computed:    true,
+				Sensitive:   true,
+				Description: "The machine configuration documents currently applied to the node",
+			},
+			"timeouts": timeouts.Attributes(ctx, timeouts.Opts{
+				Read: true,
+			}),
+		},
+	}
+}
+
+func (d *talosMachineConfigurationLiveDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*talosProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"failed to get provider data",
+			fmt.Sprintf("Expected *talosProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.providerData = providerData
+}
+
+func (d *talosMachineConfigurationLiveDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var obj types.Object
+
+	diags := req.Config.Get(ctx, &obj)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var state talosMachineConfigurationLiveDataSourceModelV0
+	diags = obj.As(ctx, &state, basetypes.ObjectAsOptions{
+		UnhandledNullAsEmpty:    true,
+		UnhandledUnknownAsEmpty: true,
+	})
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	talosConfig, err := d.providerData.talosClientConfig("dynamic", state.ClientConfiguration)
+	if err != nil {
+		resp.Diagnostics.AddError("failed to generate talos config", err.Error())
+
+		return
+	}
+
+	if state.Endpoint.IsNull() {
+		state.Endpoint = state.Node
+	}
+
+	if d.providerData.deferRead(ctx, req, resp, state.Endpoint) {
+		return
+	}
+
+	readTimeout, diags := state.Timeouts.Read(ctx, 10*time.Minute)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctxDeadline, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
+	errNoMachineConfig := errors.New("the node has no machine configuration applied (it is likely in maintenance mode)")
+
+	if err := d.providerData.retryContext(ctxDeadline, readTimeout, func() *retry.RetryError {
+		if err := talosClientOp(d.providerData.withClientOptions(ctx), state.Endpoint.ValueString(), state.Node.ValueString(), talosConfig, func(nodeCtx context.Context, c *client.Client) error {
+			machineConfig, err := safe.StateGetByID[*config.MachineConfig](nodeCtx, c.COSI, config.V1Alpha1ID)
+			if err != nil {
+				if cosistate.IsNotFoundError(err) {
+					return errNoMachineConfig
+				}
+
+				return err
+			}
+
+			cfg := machineConfig.Provider()
+
+			if state.RedactSecrets.ValueBool() {
+				cfg = cfg.RedactSecrets("REDACTED")
+			}
+
+			cfgBytes, err := cfg.EncodeBytes(encoder.WithComments(encoder.CommentsDisabled))
+			if err != nil {
+				return err
+			}
+
+			state.MachineConfiguration = basetypes.NewStringValue(string(cfgBytes))
+
+			return nil
+		}); err != nil {
+			if s := status.Code(err); s == codes.InvalidArgument {
+				return retry.NonRetryableError(err)
+			}
+
+			if errors.Is(err, errNoMachineConfig) {
+				return retry.NonRetryableError(err)
+			}
+
+			return retry.RetryableError(err)
+		}
+
+		return nil
+	}); err != nil {
+		resp.Diagnostics.AddError("failed to get machine configuration", err.Error())
+
+		return
+	}
+
+	state.ID = basetypes.NewStringValue("machine_configuration_live")
+
+	diags = resp.State.Set(ctx, state)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
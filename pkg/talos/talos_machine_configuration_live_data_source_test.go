@@ -0,0 +1,56 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccTalosMachineConfigurationLiveDataSource(t *testing.T) {
+	rName := acctest.RandStringFromCharSet(10, acctest.CharSetAlpha)
+
+	resource.ParallelTest(t, resource.TestCase{
+		ExternalProviders: map[string]resource.ExternalProvider{
+			"libvirt": {
+				Source: "dmacvicar/libvirt",
+			},
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccTalosMachineConfigurationLiveDataSourceConfig("talos", rName),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.talos_machine_configuration_live.this", "id", "machine_configuration_live"),
+					resource.TestMatchResourceAttr("data.talos_machine_configuration_live.this", "machine_configuration", regexp.MustCompile(`clusterName: example-cluster`)),
+					resource.TestMatchResourceAttr("data.talos_machine_configuration_live.this", "machine_configuration", regexp.MustCompile(`token: REDACTED`)),
+				),
+			},
+		},
+	})
+}
+
+func testAccTalosMachineConfigurationLiveDataSourceConfig(providerName, rName string) string {
+	config := dynamicConfig{
+		Provider:        providerName,
+		ResourceName:    rName,
+		WithApplyConfig: true,
+		WithBootstrap:   false,
+	}
+
+	return config.render() + `
+data "talos_machine_configuration_live" "this" {
+  depends_on = [
+    talos_machine_configuration_apply.this
+  ]
+  client_configuration = talos_machine_secrets.this.client_configuration
+  node                 = libvirt_domain.cp.network_interface[0].addresses[0]
+  redact_secrets       = true
+}
+`
+}
@@ -0,0 +1,392 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cosi-project/runtime/pkg/safe"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
+	machineapi "github.com/siderolabs/talos/pkg/machinery/api/machine"
+	"github.com/siderolabs/talos/pkg/machinery/client"
+	talosconfig "github.com/siderolabs/talos/pkg/machinery/config"
+	"github.com/siderolabs/talos/pkg/machinery/config/configpatcher"
+	"github.com/siderolabs/talos/pkg/machinery/config/encoder"
+	"github.com/siderolabs/talos/pkg/machinery/resources/config"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type talosMachineConfigurationPatchResource struct {
+	providerData *talosProviderData
+}
+
+var (
+	_ resource.Resource               = &talosMachineConfigurationPatchResource{}
+	_ resource.ResourceWithModifyPlan = &talosMachineConfigurationPatchResource{}
+	_ resource.ResourceWithConfigure  = &talosMachineConfigurationPatchResource{}
+)
+
+type talosMachineConfigurationPatchResourceModelV0 struct {
+	ID                  types.String         `tfsdk:"id"`
+	Endpoint            types.String         `tfsdk:"endpoint"`
+	Node                types.String         `tfsdk:"node"`
+	ClientConfiguration *clientConfiguration `tfsdk:"client_configuration"`
+	ConfigPatches       []types.String       `tfsdk:"config_patches"`
+	ApplyMode           types.String         `tfsdk:"apply_mode"`
+	Timeouts            timeouts.Value       `tfsdk:"timeouts"`
+}
+
+// NewTalosMachineConfigurationPatchResource implements the resource.Resource interface.
+func NewTalosMachineConfigurationPatchResource() resource.Resource {
+	return &talosMachineConfigurationPatchResource{}
+}
+
+func (r *talosMachineConfigurationPatchResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_machine_configuration_patch"
+}
+
+func (r *talosMachineConfigurationPatchResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "The machine configuration patch resource manages a slice of the machine configuration of a node, leaving the rest of it to another system. " +
+			"The current machine configuration is read from the node, the patches are applied to it and the result is applied back to the node. " +
+			"Only the patches are tracked, the settings removed from the patches and the patched settings on destroy are left as is on the node.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:    true,
+				Description: "The ID of the patched node",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"endpoint": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "The endpoint of the machine to patch",
+			},
+			"node": schema.StringAttribute{
+				Required:    true,
+				Description: "The name of the node to patch",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"client_configuration": schema.SingleNestedAttribute{
+				Attributes: map[string]schema.Attribute{
+					"ca_certificate": schema.StringAttribute{
+						Required:    true,
+						Description: "The client CA certificate",
+					},
+					"client_certificate": schema.StringAttribute{
+						Required:    true,
+						Description: "The client certificate",
+					},
+					"client_key": schema.StringAttribute{
+						Required:    true,
+						Sensitive:   true,
+						Description: "The client key",
+					},
+				},
+				Optional:    true,
+				Description: "The client configuration data, defaults to the credentials of the provider `talosconfig_path`",
+			},
+			"config_patches": schema.ListAttribute{
+				ElementType: types.StringType,
+				Required:    true,
+				Description: "The list of config patches to apply to the current machine configuration of the node",
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+				},
+			},
+			"apply_mode": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "The mode of the apply operation",
+				Validators: []validator.String{
+					stringvalidator.OneOf("auto", "reboot", "no_reboot", "staged"),
+				},
+				Default: stringdefault.StaticString("auto"),
+			},
+			"timeouts": timeouts.Attributes(ctx, timeouts.Opts{
+				Create: true,
+				Update: true,
+			}),
+		},
+	}
+}
+
+func (r *talosMachineConfigurationPatchResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*talosProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"failed to get provider data",
+			fmt.Sprintf("Expected *talosProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.providerData = providerData
+}
+
+func (r *talosMachineConfigurationPatchResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var state talosMachineConfigurationPatchResourceModelV0
+
+	diags := req.Plan.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+
+	if diags.HasError() {
+		return
+	}
+
+	createTimeout, diags := state.Timeouts.Create(ctx, 10*time.Minute)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.patch(ctx, &state, createTimeout)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Set state to fully populated data
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r *talosMachineConfigurationPatchResource) Read(_ context.Context, _ resource.ReadRequest, _ *resource.ReadResponse) {
+}
+
+func (r *talosMachineConfigurationPatchResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var state talosMachineConfigurationPatchResourceModelV0
+
+	diags := req.Plan.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+
+	if diags.HasError() {
+		return
+	}
+
+	updateTimeout, diags := state.Timeouts.Update(ctx, 10*time.Minute)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.patch(ctx, &state, updateTimeout)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Set state to fully populated data
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r *talosMachineConfigurationPatchResource) Delete(_ context.Context, _ resource.DeleteRequest, _ *resource.DeleteResponse) {
+}
+
+// patch applies the patches to the current machine configuration of the node and applies the result back to the node.
+func (r *talosMachineConfigurationPatchResource) patch(ctx context.Context, state *talosMachineConfigurationPatchResourceModelV0, timeout time.Duration) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	talosClientConfig, err := r.providerData.talosClientConfig("dynamic", state.ClientConfiguration)
+	if err != nil {
+		diags.AddError(
+			"Error converting config to talos client config",
+			err.Error(),
+		)
+
+		return diags
+	}
+
+	patches, err := configpatcher.LoadPatches(stringValues(state.ConfigPatches))
+	if err != nil {
+		diags.AddAttributeError(path.Root("config_patches"), "Error loading config patches", err.Error())
+
+		return diags
+	}
+
+	ctxDeadline, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if err := r.providerData.retryContext(ctxDeadline, timeout, func() *retry.RetryError {
+		var patchErr error
+
+		if err := talosClientOp(r.providerData.withClientOptions(ctx), state.Endpoint.ValueString(), state.Node.ValueString(), talosClientConfig, func(nodeCtx context.Context, c *client.Client) error {
+			machineConfig, err := safe.StateGetByID[*config.MachineConfig](nodeCtx, c.COSI, config.V1Alpha1ID)
+			if err != nil {
+				return err
+			}
+
+			cfgBytes, changed, err := patchMachineConfiguration(machineConfig.Provider(), patches)
+			if err != nil {
+				patchErr = err
+
+				return err
+			}
+
+			// the node already has the patched settings
+			if !changed {
+				return nil
+			}
+
+			_, err = c.ApplyConfiguration(nodeCtx, &machineapi.ApplyConfigurationRequest{
+				Data: cfgBytes,
+				Mode: machineapi.ApplyConfigurationRequest_Mode(machineapi.ApplyConfigurationRequest_Mode_value[strings.ToUpper(state.ApplyMode.ValueString())]),
+			})
+
+			return err
+		}); err != nil {
+			if patchErr != nil {
+				return retry.NonRetryableError(err)
+			}
+
+			if s := status.Code(err); s == codes.InvalidArgument {
+				return retry.NonRetryableError(err)
+			}
+
+			return retry.RetryableError(err)
+		}
+
+		return nil
+	}); err != nil {
+		diags.AddError(
+			"Error patching machine configuration",
+			err.Error(),
+		)
+
+		return diags
+	}
+
+	state.ID = state.Node
+
+	return diags
+}
+
+// patchMachineConfiguration returns the machine configuration with the patches applied,
+// and whether the patches changed the machine configuration.
+func patchMachineConfiguration(cfg talosconfig.Provider, patches []configpatcher.Patch) ([]byte, bool, error) {
+	current, err := cfg.EncodeBytes(encoder.WithComments(encoder.CommentsDisabled))
+	if err != nil {
+		return nil, false, err
+	}
+
+	patched, err := configpatcher.Apply(configpatcher.WithConfig(cfg), patches)
+	if err != nil {
+		return nil, false, err
+	}
+
+	patchedCfg, err := patched.Config()
+	if err != nil {
+		return nil, false, err
+	}
+
+	patchedBytes, err := patchedCfg.EncodeBytes(encoder.WithComments(encoder.CommentsDisabled))
+	if err != nil {
+		return nil, false, err
+	}
+
+	return patchedBytes, !bytes.Equal(current, patchedBytes), nil
+}
+
+func (r *talosMachineConfigurationPatchResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// delete is a no-op
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var configObj types.Object
+
+	diags := req.Config.Get(ctx, &configObj)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var config talosMachineConfigurationPatchResourceModelV0
+
+	diags = configObj.As(ctx, &config, basetypes.ObjectAsOptions{
+		UnhandledNullAsEmpty:    true,
+		UnhandledUnknownAsEmpty: true,
+	})
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	configPatches := make([]string, 0, len(config.ConfigPatches))
+
+	for _, patch := range config.ConfigPatches {
+		// the patches are validated once all of them are known
+		if patch.IsUnknown() {
+			configPatches = nil
+
+			break
+		}
+
+		configPatches = append(configPatches, patch.ValueString())
+	}
+
+	if configPatches != nil {
+		resp.Diagnostics.Append(validateConfigPatches(path.Root("config_patches"), configPatches)...)
+
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	// if either endpoint or node is unknown return early, deferring the creation if Terraform allows it
+	if config.Endpoint.IsUnknown() || config.Node.IsUnknown() {
+		deferCreate(req, resp)
+
+		return
+	}
+
+	if config.Endpoint.IsNull() {
+		diags = resp.Plan.SetAttribute(ctx, path.Root("endpoint"), config.Node.ValueString())
+		resp.Diagnostics.Append(diags...)
+
+		if diags.HasError() {
+			return
+		}
+	}
+}
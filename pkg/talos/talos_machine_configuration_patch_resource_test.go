@@ -0,0 +1,170 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/siderolabs/talos/pkg/machinery/config/configloader"
+	"github.com/siderolabs/talos/pkg/machinery/config/configpatcher"
+	"github.com/siderolabs/talos/pkg/machinery/config/generate/secrets"
+	"github.com/siderolabs/talos/pkg/machinery/config/machine"
+
+	"github.com/siderolabs/terraform-provider-talos/pkg/talos"
+)
+
+func TestAccTalosMachineConfigurationPatchResource(t *testing.T) {
+	rName := acctest.RandStringFromCharSet(10, acctest.CharSetAlpha)
+
+	resource.ParallelTest(t, resource.TestCase{
+		ExternalProviders: map[string]resource.ExternalProvider{
+			"libvirt": {
+				Source: "dmacvicar/libvirt",
+			},
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccTalosMachineConfigurationPatchResourceConfig("talos", rName, "150"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrPair("talos_machine_configuration_patch.this", "id", "talos_machine_configuration_patch.this", "node"),
+					resource.TestCheckResourceAttrPair("talos_machine_configuration_patch.this", "endpoint", "talos_machine_configuration_patch.this", "node"),
+					resource.TestCheckResourceAttr("talos_machine_configuration_patch.this", "apply_mode", "no_reboot"),
+					resource.TestCheckResourceAttr("talos_machine_configuration_patch.this", "config_patches.#", "1"),
+				),
+			},
+			// changing the patches patches the node in place
+			{
+				Config: testAccTalosMachineConfigurationPatchResourceConfig("talos", rName, "200"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("talos_machine_configuration_patch.this", plancheck.ResourceActionUpdate),
+					},
+				},
+			},
+		},
+	})
+}
+
+func testAccTalosMachineConfigurationPatchResourceConfig(providerName, rName, maxPods string) string {
+	config := dynamicConfig{
+		Provider:        providerName,
+		ResourceName:    rName,
+		WithApplyConfig: true,
+		WithBootstrap:   false,
+	}
+
+	return config.render() + fmt.Sprintf(`
+resource "talos_machine_configuration_patch" "this" {
+  depends_on = [
+    talos_machine_configuration_apply.this
+  ]
+  client_configuration = talos_machine_secrets.this.client_configuration
+  node                 = libvirt_domain.cp.network_interface[0].addresses[0]
+  apply_mode           = "no_reboot"
+  config_patches = [
+    yamlencode({
+      machine = {
+        kubelet = {
+          extraArgs = {
+            max-pods = "%s"
+          }
+        }
+      }
+    }),
+  ]
+}
+`, maxPods)
+}
+
+func TestPatchMachineConfiguration(t *testing.T) {
+	t.Parallel()
+
+	secretsBundle, err := secrets.NewBundle(secrets.NewFixedClock(time.Now()), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	machineConfiguration, err := talos.GenerateMachineConfiguration(machine.TypeWorker, secretsBundle)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := configloader.NewFromBytes([]byte(machineConfiguration))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name            string
+		patch           string
+		expectedChanged bool
+		expectedError   string
+	}{
+		{
+			name:            "new setting",
+			patch:           "machine:\n  kubelet:\n    extraArgs:\n      max-pods: \"150\"\n",
+			expectedChanged: true,
+		},
+		{
+			name:  "setting already set",
+			patch: fmt.Sprintf("cluster:\n  clusterName: %s\n", cfg.Cluster().Name()),
+		},
+		{
+			name:          "invalid patch",
+			patch:         `[{"op": "remove", "path": "/machine/missing"}]`,
+			expectedError: "missing",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			patches, err := configpatcher.LoadPatches([]string{tt.patch})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			cfgBytes, changed, err := talos.PatchMachineConfiguration(cfg, patches)
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectedError, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if changed != tt.expectedChanged {
+				t.Errorf("expected changed %t, got %t", tt.expectedChanged, changed)
+			}
+
+			if !changed {
+				return
+			}
+
+			patched, err := configloader.NewFromBytes(cfgBytes)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if patched.Machine().Kubelet().ExtraArgs()["max-pods"] != "150" {
+				t.Errorf("expected the patch to be applied, got %v", patched.Machine().Kubelet().ExtraArgs())
+			}
+
+			if patched.Machine().Security().Token() != cfg.Machine().Security().Token() {
+				t.Errorf("expected the rest of the machine configuration to be left unchanged")
+			}
+		})
+	}
+}
@@ -0,0 +1,406 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/siderolabs/talos/pkg/machinery/config/configpatcher"
+	"github.com/siderolabs/talos/pkg/machinery/config/machine"
+	"github.com/siderolabs/talos/pkg/machinery/constants"
+	"github.com/siderolabs/talos/pkg/machinery/gendata"
+	"golang.org/x/mod/semver"
+)
+
+type talosMachineConfigurationsDataSourceModelV0 struct {
+	ID                        types.String                                `tfsdk:"id"`
+	ClusterName               types.String                                `tfsdk:"cluster_name"`
+	ClusterEndpoint           types.String                                `tfsdk:"cluster_endpoint"`
+	KubernetesVersion         types.String                                `tfsdk:"kubernetes_version"`
+	TalosVersion              types.String                                `tfsdk:"talos_version"`
+	MachineSecrets            machineSecrets                              `tfsdk:"machine_secrets"`
+	ConfigPatches             types.List                                  `tfsdk:"config_patches"`
+	Nodes                     map[string]talosMachineConfigurationsNodeV0 `tfsdk:"nodes"`
+	ValidationMode            types.String                                `tfsdk:"validation_mode"`
+	Docs                      types.Bool                                  `tfsdk:"docs"`
+	Examples                  types.Bool                                  `tfsdk:"examples"`
+	MachineConfigurations     types.Map                                   `tfsdk:"machine_configurations"`
+	NodeMachineConfigurations types.Map                                   `tfsdk:"node_machine_configurations"`
+}
+
+type talosMachineConfigurationsNodeV0 struct {
+	MachineType       types.String `tfsdk:"machine_type"`
+	ConfigPatches     types.List   `tfsdk:"config_patches"`
+	ExtensionServices types.Map    `tfsdk:"extension_services"`
+}
+
+type talosMachineConfigurationsDataSource struct{}
+
+var (
+	_ datasource.DataSource                   = &talosMachineConfigurationsDataSource{}
+	_ datasource.DataSourceWithValidateConfig = &talosMachineConfigurationsDataSource{}
+)
+
+// NewTalosMachineConfigurationsDataSource implements the datasource.DataSource interface.
+func NewTalosMachineConfigurationsDataSource() datasource.DataSource {
+	return &talosMachineConfigurationsDataSource{}
+}
+
+func (d *talosMachineConfigurationsDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_machine_configurations"
+}
+
+func (d *talosMachineConfigurationsDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Generate the machine configurations for all node types, and optionally for each node, in a single evaluation",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+			},
+			"cluster_name": schema.StringAttribute{
+				Required:    true,
+				Description: "The name of the talos kubernetes cluster",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"cluster_endpoint": schema.StringAttribute{
+				Required:    true,
+				Description: "The endpoint of the talos kubernetes cluster",
+			},
+			"machine_secrets": machineSecretsSchemaInput(),
+			"config_patches": schema.ListAttribute{
+				Description: "The list of config patches to apply to the generated configuration of all machine types",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"nodes": schema.MapNestedAttribute{
+				Description: "The nodes to generate a machine configuration for, keyed by the node name",
+				Optional:    true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"machine_type": schema.StringAttribute{
+							Required:    true,
+							Description: "The type of the node",
+							Validators: []validator.String{
+								stringvalidator.OneOf("controlplane", "worker"),
+							},
+						},
+						"config_patches": schema.ListAttribute{
+							Description: "The list of config patches to apply to the generated configuration of the machine type for this node",
+							Optional:    true,
+							ElementType: types.StringType,
+						},
+						"extension_services": extensionServicesSchemaInput(
+							"The system extension services configuration of this node rendered as `ExtensionServiceConfig` documents, keyed by extension service name (e.g. `tailscale`)",
+						),
+					},
+				},
+			},
+			"kubernetes_version": schema.StringAttribute{
+				Description: "The version of kubernetes to use",
+				Optional:    true,
+			},
+			"talos_version": schema.StringAttribute{
+				Description: "The version of talos features to use in generated machine configuration",
+				Optional:    true,
+				Validators: []validator.String{
+					talosVersionValid(),
+				},
+			},
+			"validation_mode": schema.StringAttribute{
+				Description: "How the generated machine configurations are validated: `strict` fails on unknown fields, validation errors and warnings, " +
+					"`permissive` fails on unknown fields and validation errors and reports the warnings, `off` skips the validation. Defaults to `permissive`",
+				Optional: true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(validationModeNames...),
+				},
+			},
+			"docs": schema.BoolAttribute{
+				Description: "Whether to add the documentation comments to the generated configuration. Defaults to false, which keeps the generated configuration minimal",
+				Optional:    true,
+			},
+			"examples": schema.BoolAttribute{
+				Description: "Whether to add the commented out example fields to the generated configuration. Defaults to false",
+				Optional:    true,
+			},
+			"machine_configurations": schema.MapAttribute{
+				Description: "The generated machine configurations keyed by the machine type (`controlplane`, `worker`), " +
+					"the `controlplane` machine configuration is only generated if the machine secrets contain the OS CA key",
+				Computed:    true,
+				Sensitive:   true,
+				ElementType: types.StringType,
+			},
+			"node_machine_configurations": schema.MapAttribute{
+				Description: "The generated machine configurations keyed by the node name",
+				Computed:    true,
+				Sensitive:   true,
+				ElementType: types.StringType,
+			},
+		},
+	}
+}
+
+func (d *talosMachineConfigurationsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) { //nolint:gocyclo,cyclop
+	var state talosMachineConfigurationsDataSourceModelV0
+
+	diags := req.Config.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if state.KubernetesVersion.IsNull() {
+		state.KubernetesVersion = basetypes.NewStringValue(constants.DefaultKubernetesVersion)
+	}
+
+	if state.TalosVersion.IsNull() {
+		state.TalosVersion = basetypes.NewStringValue(semver.MajorMinor(gendata.VersionTag))
+	}
+
+	machineSecrets, err := machineSecretsInputToSecretsBundle(state.MachineSecrets)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"failed to convert machine secrets certs to secrets bundle certs",
+			err.Error(),
+		)
+
+		return
+	}
+
+	// worker machine secrets can only be used to generate worker machine configuration
+	machineTypes := []machine.Type{machine.TypeWorker}
+
+	if state.MachineSecrets.Certs.OS.Key.ValueString() != "" {
+		machineTypes = append(machineTypes, machine.TypeControlPlane)
+	}
+
+	var configPatches []string
+
+	resp.Diagnostics.Append(state.ConfigPatches.ElementsAs(ctx, &configPatches, true)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	validationMode := state.ValidationMode.ValueString()
+	if state.ValidationMode.IsNull() {
+		validationMode = "permissive"
+	}
+
+	validate := func(attributePath path.Path, machineConfiguration string) bool {
+		warnings, err := validateMachineConfiguration(machineConfiguration, validationMode)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				attributePath,
+				"generated machine configuration is invalid",
+				err.Error(),
+			)
+
+			return false
+		}
+
+		for _, warning := range warnings {
+			resp.Diagnostics.AddAttributeWarning(
+				attributePath,
+				"generated machine configuration validation warning",
+				warning,
+			)
+		}
+
+		return true
+	}
+
+	machineConfigurations := make(map[string]string, len(machineTypes))
+
+	for _, machineType := range machineTypes {
+		genOptions := &machineConfigGenerateOptions{
+			machineType:       machineType,
+			clusterName:       state.ClusterName.ValueString(),
+			clusterEndpoint:   state.ClusterEndpoint.ValueString(),
+			machineSecrets:    machineSecrets,
+			configPatches:     configPatches,
+			kubernetesVersion: state.KubernetesVersion.ValueString(),
+			talosVersion:      state.TalosVersion.ValueString(),
+			docsEnabled:       state.Docs.ValueBool(),
+			examplesEnabled:   state.Examples.ValueBool(),
+		}
+
+		machineConfiguration, err := genOptions.generate()
+		if err != nil {
+			resp.Diagnostics.AddError(
+				fmt.Sprintf("failed to generate %s machine configuration", machineType),
+				err.Error(),
+			)
+
+			return
+		}
+
+		if !validate(path.Root("machine_configurations").AtMapKey(machineType.String()), machineConfiguration) {
+			return
+		}
+
+		machineConfigurations[machineType.String()] = machineConfiguration
+	}
+
+	nodeMachineConfigurations := make(map[string]string, len(state.Nodes))
+
+	// the node machine configurations are patched from the machine type ones, so they are generated only once
+	for name, node := range state.Nodes {
+		machineConfiguration, ok := machineConfigurations[node.MachineType.ValueString()]
+		if !ok {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("nodes").AtMapKey(name).AtName("machine_type"),
+				"machine_secrets are incomplete",
+				"controlplane machine configuration requires the full machine secrets, worker machine secrets can only be used to generate worker machine configuration",
+			)
+
+			return
+		}
+
+		var nodeConfigPatches []string
+
+		resp.Diagnostics.Append(node.ConfigPatches.ElementsAs(ctx, &nodeConfigPatches, true)...)
+
+		var extensionServices map[string]machineConfigurationExtensionService
+
+		resp.Diagnostics.Append(node.ExtensionServices.ElementsAs(ctx, &extensionServices, true)...)
+
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		extensionServiceDocuments, err := extensionServiceConfigDocuments(extensionServices)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("nodes").AtMapKey(name).AtName("extension_services"),
+				"extension_services are invalid",
+				err.Error(),
+			)
+
+			return
+		}
+
+		nodeConfigPatches = append(nodeConfigPatches, extensionServiceDocuments...)
+
+		if len(nodeConfigPatches) > 0 {
+			patches, err := configpatcher.LoadPatches(nodeConfigPatches)
+			if err != nil {
+				resp.Diagnostics.AddAttributeError(
+					path.Root("nodes").AtMapKey(name).AtName("config_patches"),
+					"error parsing config patch",
+					err.Error(),
+				)
+
+				return
+			}
+
+			cfg, err := configpatcher.Apply(configpatcher.WithBytes([]byte(machineConfiguration)), patches)
+			if err != nil {
+				resp.Diagnostics.AddAttributeError(
+					path.Root("nodes").AtMapKey(name).AtName("config_patches"),
+					"error patching config",
+					err.Error(),
+				)
+
+				return
+			}
+
+			cfgBytes, err := cfg.Bytes()
+			if err != nil {
+				resp.Diagnostics.AddError(
+					"failed to get patched machine configuration",
+					err.Error(),
+				)
+
+				return
+			}
+
+			machineConfiguration = string(cfgBytes)
+
+			if !validate(path.Root("node_machine_configurations").AtMapKey(name), machineConfiguration) {
+				return
+			}
+		}
+
+		nodeMachineConfigurations[name] = machineConfiguration
+	}
+
+	machineConfigurationsValue, diags := types.MapValueFrom(ctx, types.StringType, machineConfigurations)
+	resp.Diagnostics.Append(diags...)
+
+	nodeMachineConfigurationsValue, diags := types.MapValueFrom(ctx, types.StringType, nodeMachineConfigurations)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	state.MachineConfigurations = machineConfigurationsValue
+	state.NodeMachineConfigurations = nodeMachineConfigurationsValue
+	state.ID = state.ClusterName
+
+	diags = resp.State.Set(ctx, state)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (d *talosMachineConfigurationsDataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	var clusterEndpoint types.String
+
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("cluster_endpoint"), &clusterEndpoint)...)
+
+	var configPatches types.List
+
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("config_patches"), &configPatches)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !clusterEndpoint.IsUnknown() && !clusterEndpoint.IsNull() {
+		if err := validateClusterEndpoint(clusterEndpoint.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("cluster_endpoint"),
+				"cluster_endpoint is invalid",
+				err.Error(),
+			)
+		}
+	}
+
+	if configPatches.IsUnknown() || configPatches.IsNull() {
+		return
+	}
+
+	var patches []types.String
+
+	resp.Diagnostics.Append(configPatches.ElementsAs(ctx, &patches, false)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// the patches can only be checked once all of them are known
+	if slices.ContainsFunc(patches, func(patch types.String) bool { return patch.IsUnknown() }) {
+		return
+	}
+
+	loadPatches := make([]string, len(patches))
+
+	for i, patch := range patches {
+		loadPatches[i] = patch.ValueString()
+	}
+
+	resp.Diagnostics.Append(validateConfigPatches(path.Root("config_patches"), loadPatches)...)
+}
@@ -0,0 +1,107 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccTalosMachineConfigurationsDataSource(t *testing.T) {
+	resource.ParallelTest(t, resource.TestCase{
+		IsUnitTest:               true, // this is a local only resource, so can be unit tested
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccTalosMachineConfigurationsDataSourceConfig(),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.talos_machine_configurations.this", "id", "example-cluster"),
+					resource.TestCheckResourceAttr("data.talos_machine_configurations.this", "machine_configurations.%", "2"),
+					resource.TestMatchResourceAttr("data.talos_machine_configurations.this", "machine_configurations.controlplane", regexp.MustCompile("type: controlplane")),
+					resource.TestMatchResourceAttr("data.talos_machine_configurations.this", "machine_configurations.worker", regexp.MustCompile("type: worker")),
+					resource.TestCheckResourceAttr("data.talos_machine_configurations.this", "node_machine_configurations.%", "3"),
+					resource.TestMatchResourceAttr("data.talos_machine_configurations.this", "node_machine_configurations.cp-1", regexp.MustCompile("hostname: cp-1")),
+					resource.TestMatchResourceAttr("data.talos_machine_configurations.this", "node_machine_configurations.worker-2", regexp.MustCompile(`(?s)kind: ExtensionServiceConfig\nname: tailscale\nenvironment:\n\s+- TS_AUTHKEY=tskey-auth-secret\n\s+- TS_HOSTNAME=worker-2`)),
+					resource.TestCheckResourceAttrPair(
+						"data.talos_machine_configurations.this", "node_machine_configurations.worker-1",
+						"data.talos_machine_configurations.this", "machine_configurations.worker",
+					),
+				),
+			},
+			{
+				Config: testAccTalosMachineConfigurationsDataSourceWorkerSecretsConfig("worker"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.talos_machine_configurations.this", "machine_configurations.%", "1"),
+					resource.TestMatchResourceAttr("data.talos_machine_configurations.this", "machine_configurations.worker", regexp.MustCompile("type: worker")),
+				),
+			},
+			{
+				Config:      testAccTalosMachineConfigurationsDataSourceWorkerSecretsConfig("controlplane"),
+				ExpectError: regexp.MustCompile("machine_secrets are incomplete"),
+			},
+		},
+	})
+}
+
+func testAccTalosMachineConfigurationsDataSourceConfig() string {
+	return `
+resource "talos_machine_secrets" "this" {}
+
+data "talos_machine_configurations" "this" {
+  cluster_name     = "example-cluster"
+  cluster_endpoint = "https://cluster.local:6443"
+  machine_secrets  = talos_machine_secrets.this.machine_secrets
+  nodes = {
+    "cp-1" = {
+      machine_type = "controlplane"
+      config_patches = [
+        yamlencode({
+          machine = {
+            network = {
+              hostname = "cp-1"
+            }
+          }
+        }),
+      ]
+    }
+    "worker-1" = {
+      machine_type = "worker"
+    }
+    "worker-2" = {
+      machine_type = "worker"
+      extension_services = {
+        "tailscale" = {
+          environment = {
+            TS_HOSTNAME = "worker-2"
+          }
+          sensitive_environment = {
+            TS_AUTHKEY = "tskey-auth-secret"
+          }
+        }
+      }
+    }
+  }
+}
+`
+}
+
+func testAccTalosMachineConfigurationsDataSourceWorkerSecretsConfig(machineType string) string {
+	return `
+resource "talos_machine_secrets" "this" {}
+
+data "talos_machine_configurations" "this" {
+  cluster_name     = "example-cluster"
+  cluster_endpoint = "https://cluster.local:6443"
+  machine_secrets  = talos_machine_secrets.this.worker_machine_secrets
+  nodes = {
+    "node-1" = {
+      machine_type = "` + machineType + `"
+    }
+  }
+}
+`
+}
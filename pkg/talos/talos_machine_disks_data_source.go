@@ -36,28 +36,33 @@ func (e nodiskFoundError) Error() string {
 
 type nodiskFoundError struct{}
 
-type talosMachineDisksDataSource struct{}
+type talosMachineDisksDataSource struct {
+	providerData *talosProviderData
+}
 
 type talosMachineDisksDataSourceModelV0 struct { //nolint:govet
 	ID                  types.String           `tfsdk:"id"`
 	Node                types.String           `tfsdk:"node"`
 	Endpoint            types.String           `tfsdk:"endpoint"`
-	ClientConfiguration clientConfiguration    `tfsdk:"client_configuration"`
+	ClientConfiguration *clientConfiguration   `tfsdk:"client_configuration"`
 	Filters             talosMachineDiskFilter `tfsdk:"filters"`
 	Disks               []talosMachineDisk     `tfsdk:"disks"`
 	Timeouts            timeouts.Value         `tfsdk:"timeouts"`
 }
 
 type talosMachineDisk struct {
-	Size     types.String `tfsdk:"size"`
-	Name     types.String `tfsdk:"name"`
-	Model    types.String `tfsdk:"model"`
-	Serial   types.String `tfsdk:"serial"`
-	Modalias types.String `tfsdk:"modalias"`
-	UUID     types.String `tfsdk:"uuid"`
-	WWID     types.String `tfsdk:"wwid"`
-	Type     types.String `tfsdk:"type"`
-	BusPath  types.String `tfsdk:"bus_path"`
+	Size       types.String `tfsdk:"size"`
+	Name       types.String `tfsdk:"name"`
+	Model      types.String `tfsdk:"model"`
+	Serial     types.String `tfsdk:"serial"`
+	Modalias   types.String `tfsdk:"modalias"`
+	UUID       types.String `tfsdk:"uuid"`
+	WWID       types.String `tfsdk:"wwid"`
+	Type       types.String `tfsdk:"type"`
+	BusPath    types.String `tfsdk:"bus_path"`
+	Rotational types.Bool   `tfsdk:"rotational"`
+	SystemDisk types.Bool   `tfsdk:"system_disk"`
+	Readonly   types.Bool   `tfsdk:"readonly"`
 }
 
 type talosMachineDiskFilter struct {
@@ -99,6 +104,7 @@ func (m *diskSizeMatcher) Matcher(d *disk.Disk) bool {
 var (
 	_ datasource.DataSource                   = &talosMachineDisksDataSource{}
 	_ datasource.DataSourceWithValidateConfig = &talosMachineDisksDataSource{}
+	_ datasource.DataSourceWithConfigure      = &talosMachineDisksDataSource{}
 )
 
 // NewTalosMachineDisksDataSource implements the datasource.DataSource interface.
@@ -143,8 +149,8 @@ func (d *talosMachineDisksDataSource) Schema(ctx context.Context, _ datasource.S
 						Description: "The client key",
 					},
 				},
-				Required:    true,
-				Description: "The client configuration data",
+				Optional:    true,
+				Description: "The client configuration data, defaults to the credentials of the provider `talosconfig_path`",
 			},
 			"filters": schema.SingleNestedAttribute{
 				Description: "Filters to apply to the disks",
@@ -228,6 +234,18 @@ func (d *talosMachineDisksDataSource) Schema(ctx context.Context, _ datasource.S
 							Description: "The bus path of the disk",
 							Computed:    true,
 						},
+						"rotational": schema.BoolAttribute{
+							Description: "Whether the disk is rotational (HDD)",
+							Computed:    true,
+						},
+						"system_disk": schema.BoolAttribute{
+							Description: "Whether the disk is the Talos system disk",
+							Computed:    true,
+						},
+						"readonly": schema.BoolAttribute{
+							Description: "Whether the disk is read-only",
+							Computed:    true,
+						},
 					},
 				},
 				Computed: true,
@@ -239,6 +257,24 @@ func (d *talosMachineDisksDataSource) Schema(ctx context.Context, _ datasource.S
 	}
 }
 
+func (d *talosMachineDisksDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*talosProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"failed to get provider data",
+			fmt.Sprintf("Expected *talosProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.providerData = providerData
+}
+
 func (d *talosMachineDisksDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) { //nolint:gocognit,gocyclo,cyclop
 	var obj types.Object
 
@@ -260,12 +296,7 @@ func (d *talosMachineDisksDataSource) Read(ctx context.Context, req datasource.R
 		return
 	}
 
-	talosConfig, err := talosClientTFConfigToTalosClientConfig(
-		"dynamic",
-		state.ClientConfiguration.CA.ValueString(),
-		state.ClientConfiguration.Cert.ValueString(),
-		state.ClientConfiguration.Key.ValueString(),
-	)
+	talosConfig, err := d.providerData.talosClientConfig("dynamic", state.ClientConfiguration)
 	if err != nil {
 		resp.Diagnostics.AddError("failed to generate talos config", err.Error())
 
@@ -276,6 +307,10 @@ func (d *talosMachineDisksDataSource) Read(ctx context.Context, req datasource.R
 		state.Endpoint = state.Node
 	}
 
+	if d.providerData.deferRead(ctx, req, resp, state.Endpoint) {
+		return
+	}
+
 	readTimeout, diags := state.Timeouts.Read(ctx, 10*time.Minute)
 	resp.Diagnostics.Append(diags...)
 
@@ -338,16 +373,17 @@ func (d *talosMachineDisksDataSource) Read(ctx context.Context, req datasource.R
 		matchers = append(matchers, disk.WithBusPath(state.Filters.BusPath.ValueString()))
 	}
 
-	if err := retry.RetryContext(ctxDeadline, readTimeout, func() *retry.RetryError {
-		if err := talosClientOp(ctx, state.Endpoint.ValueString(), state.Node.ValueString(), talosConfig, func(nodeCtx context.Context, c *client.Client) error {
+	if err := d.providerData.retryContext(ctxDeadline, readTimeout, func() *retry.RetryError {
+		if err := talosClientOp(d.providerData.withClientOptions(ctx), state.Endpoint.ValueString(), state.Node.ValueString(), talosConfig, func(nodeCtx context.Context, c *client.Client) error {
 			diskResp, err := c.Disks(nodeCtx)
 			if err != nil {
 				return err
 			}
 
-			foundDisks := make([]disk.Disk, len(diskResp.GetMessages()[0].GetDisks()))
+			diskResps := diskResp.GetMessages()[0].GetDisks()
+			foundDisks := make([]disk.Disk, len(diskResps))
 
-			for i, diskResp := range diskResp.GetMessages()[0].GetDisks() {
+			for i, diskResp := range diskResps {
 				foundDisks[i] = disk.Disk{
 					Name:     diskResp.GetDeviceName(),
 					Model:    diskResp.GetModel(),
@@ -361,20 +397,27 @@ func (d *talosMachineDisksDataSource) Read(ctx context.Context, req datasource.R
 				}
 			}
 
+			tfDisk := func(i int) talosMachineDisk {
+				return talosMachineDisk{
+					Size:       basetypes.NewStringValue(humanize.Bytes(foundDisks[i].Size)),
+					Name:       basetypes.NewStringValue(foundDisks[i].Name),
+					Model:      basetypes.NewStringValue(foundDisks[i].Model),
+					Serial:     basetypes.NewStringValue(foundDisks[i].Serial),
+					Modalias:   basetypes.NewStringValue(foundDisks[i].Modalias),
+					UUID:       basetypes.NewStringValue(foundDisks[i].UUID),
+					WWID:       basetypes.NewStringValue(foundDisks[i].WWID),
+					Type:       basetypes.NewStringValue(foundDisks[i].Type.String()),
+					BusPath:    basetypes.NewStringValue(foundDisks[i].BusPath),
+					Rotational: basetypes.NewBoolValue(foundDisks[i].Type == disk.TypeHDD),
+					SystemDisk: basetypes.NewBoolValue(diskResps[i].GetSystemDisk()),
+					Readonly:   basetypes.NewBoolValue(diskResps[i].GetReadonly()),
+				}
+			}
+
 			if len(matchers) > 0 {
-				for _, foundDisk := range foundDisks {
-					if disk.Match(&foundDisk, matchers...) {
-						state.Disks = append(state.Disks, talosMachineDisk{
-							Size:     basetypes.NewStringValue(humanize.Bytes(foundDisk.Size)),
-							Name:     basetypes.NewStringValue(foundDisk.Name),
-							Model:    basetypes.NewStringValue(foundDisk.Model),
-							Serial:   basetypes.NewStringValue(foundDisk.Serial),
-							Modalias: basetypes.NewStringValue(foundDisk.Modalias),
-							UUID:     basetypes.NewStringValue(foundDisk.UUID),
-							WWID:     basetypes.NewStringValue(foundDisk.WWID),
-							Type:     basetypes.NewStringValue(foundDisk.Type.String()),
-							BusPath:  basetypes.NewStringValue(foundDisk.BusPath),
-						})
+				for i := range foundDisks {
+					if disk.Match(&foundDisks[i], matchers...) {
+						state.Disks = append(state.Disks, tfDisk(i))
 
 						// if there was a filter and we found a match, we can stop looking
 						return nil
@@ -386,18 +429,8 @@ func (d *talosMachineDisksDataSource) Read(ctx context.Context, req datasource.R
 			}
 
 			// if there was no filter, we can return all disks
-			for _, foundDisk := range foundDisks {
-				state.Disks = append(state.Disks, talosMachineDisk{
-					Size:     basetypes.NewStringValue(humanize.Bytes(foundDisk.Size)),
-					Name:     basetypes.NewStringValue(foundDisk.Name),
-					Model:    basetypes.NewStringValue(foundDisk.Model),
-					Serial:   basetypes.NewStringValue(foundDisk.Serial),
-					Modalias: basetypes.NewStringValue(foundDisk.Modalias),
-					UUID:     basetypes.NewStringValue(foundDisk.UUID),
-					WWID:     basetypes.NewStringValue(foundDisk.WWID),
-					Type:     basetypes.NewStringValue(foundDisk.Type.String()),
-					BusPath:  basetypes.NewStringValue(foundDisk.BusPath),
-				})
+			for i := range foundDisks {
+				state.Disks = append(state.Disks, tfDisk(i))
 			}
 
 			return nil
@@ -35,6 +35,8 @@ func TestAccTalosMachineDisksDataSource(t *testing.T) {
 					resource.TestCheckResourceAttr("data.talos_machine_disks.this", "filters.size", "> 6GB"),
 					resource.TestCheckResourceAttr("data.talos_machine_disks.this", "disks.#", "1"),
 					resource.TestCheckResourceAttr("data.talos_machine_disks.this", "disks.0.name", "/dev/vda"),
+					resource.TestCheckResourceAttrSet("data.talos_machine_disks.this", "disks.0.rotational"),
+					resource.TestCheckResourceAttr("data.talos_machine_disks.this", "disks.0.readonly", "false"),
 				),
 			},
 			// test a filter
@@ -0,0 +1,300 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/boolvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
+	machineapi "github.com/siderolabs/talos/pkg/machinery/api/machine"
+	"github.com/siderolabs/talos/pkg/machinery/client"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errSequenceFailed is returned when the awaited sequence fails, waiting for it any longer is pointless.
+var errSequenceFailed = errors.New("sequence failed")
+
+type talosMachineEventDataSource struct {
+	providerData *talosProviderData
+}
+
+type talosMachineEventDataSourceModelV0 struct { //nolint:govet
+	ID                  types.String         `tfsdk:"id"`
+	Node                types.String         `tfsdk:"node"`
+	Endpoint            types.String         `tfsdk:"endpoint"`
+	ClientConfiguration *clientConfiguration `tfsdk:"client_configuration"`
+	MachineStage        types.String         `tfsdk:"machine_stage"`
+	MachineReady        types.Bool           `tfsdk:"machine_ready"`
+	Sequence            types.String         `tfsdk:"sequence"`
+	EventID             types.String         `tfsdk:"event_id"`
+	Timeouts            timeouts.Value       `tfsdk:"timeouts"`
+}
+
+var (
+	_ datasource.DataSource              = &talosMachineEventDataSource{}
+	_ datasource.DataSourceWithConfigure = &talosMachineEventDataSource{}
+)
+
+// NewTalosMachineEventDataSource implements the datasource.DataSource interface.
+func NewTalosMachineEventDataSource() datasource.DataSource {
+	return &talosMachineEventDataSource{}
+}
+
+func (d *talosMachineEventDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_machine_event"
+}
+
+func (d *talosMachineEventDataSource) Schema(ctx context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	machineStages := make([]string, 0, len(machineapi.MachineStatusEvent_MachineStage_name)-1)
+
+	for stage := machineapi.MachineStatusEvent_BOOTING; stage <= machineapi.MachineStatusEvent_UPGRADING; stage++ {
+		machineStages = append(machineStages, stage.String())
+	}
+
+	resp.Schema = schema.Schema{
+		Description: "Waits for an event of a node from the Talos events API. " +
+			"The events since the node booted are replayed, so an event which already happened satisfies the wait.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The generated ID of this resource",
+				Computed:    true,
+			},
+			"node": schema.StringAttribute{
+				Required:    true,
+				Description: "node to wait for the event on",
+			},
+			"endpoint": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "endpoint to use for the talosclient. If not set, the node value will be used",
+			},
+			"client_configuration": schema.SingleNestedAttribute{
+				Attributes: map[string]schema.Attribute{
+					"ca_certificate": schema.StringAttribute{
+						Required:    true,
+						Description: "The client CA certificate",
+					},
+					"client_certificate": schema.StringAttribute{
+						Required:    true,
+						Description: "The client certificate",
+					},
+					"client_key": schema.StringAttribute{
+						Required:    true,
+						Sensitive:   true,
+						Description: "The client key",
+					},
+				},
+				Optional:    true,
+				Description: "The client configuration data, defaults to the credentials of the provider `talosconfig_path`",
+			},
+			"machine_stage": schema.StringAttribute{
+				Optional:    true,
+				Description: "Wait for a machine status event reporting this stage (e.g. RUNNING)",
+				Validators: []validator.String{
+					stringvalidator.OneOf(machineStages...),
+					stringvalidator.ExactlyOneOf(path.MatchRoot("machine_stage"), path.MatchRoot("sequence")),
+				},
+			},
+			"machine_ready": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Wait for the machine status event to also report the machine as ready",
+				Validators: []validator.Bool{
+					boolvalidator.AlsoRequires(path.MatchRoot("machine_stage")),
+				},
+			},
+			"sequence": schema.StringAttribute{
+				Optional:    true,
+				Description: "Wait for a sequence event reporting that this sequence finished successfully (e.g. boot, install, upgrade), the wait fails if the sequence fails",
+			},
+			"event_id": schema.StringAttribute{
+				Computed:    true,
+				Description: "The ID of the event which satisfied the wait",
+			},
+			"timeouts": timeouts.Attributes(ctx, timeouts.Opts{
+				Read: true,
+			}),
+		},
+	}
+}
+
+func (d *talosMachineEventDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*talosProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"failed to get provider data",
+			fmt.Sprintf("Expected *talosProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.providerData = providerData
+}
+
+func (d *talosMachineEventDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var obj types.Object
+
+	diags := req.Config.Get(ctx, &obj)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var state talosMachineEventDataSourceModelV0
+	diags = obj.As(ctx, &state, basetypes.ObjectAsOptions{
+		UnhandledNullAsEmpty:    true,
+		UnhandledUnknownAsEmpty: true,
+	})
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	talosConfig, err := d.providerData.talosClientConfig("dynamic", state.ClientConfiguration)
+	if err != nil {
+		resp.Diagnostics.AddError("failed to generate talos config", err.Error())
+
+		return
+	}
+
+	if state.Endpoint.IsNull() {
+		state.Endpoint = state.Node
+	}
+
+	if d.providerData.deferRead(ctx, req, resp, state.Endpoint) {
+		return
+	}
+
+	readTimeout, diags := state.Timeouts.Read(ctx, 10*time.Minute)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctxDeadline, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
+	if err := d.providerData.retryContext(ctxDeadline, readTimeout, func() *retry.RetryError {
+		if err := talosClientOp(d.providerData.withClientOptions(ctxDeadline), state.Endpoint.ValueString(), state.Node.ValueString(), talosConfig, func(nodeCtx context.Context, c *client.Client) error {
+			eventID, err := waitForEvent(nodeCtx, c, func(event client.Event) (bool, error) {
+				return machineEventMatches(event, state.MachineStage.ValueString(), state.MachineReady.ValueBool(), state.Sequence.ValueString())
+			})
+			if err != nil {
+				return err
+			}
+
+			state.EventID = basetypes.NewStringValue(eventID)
+
+			return nil
+		}); err != nil {
+			if s := status.Code(err); s == codes.InvalidArgument || errors.Is(err, errSequenceFailed) {
+				return retry.NonRetryableError(err)
+			}
+
+			return retry.RetryableError(err)
+		}
+
+		return nil
+	}); err != nil {
+		resp.Diagnostics.AddError("failed waiting for event", err.Error())
+
+		return
+	}
+
+	state.ID = basetypes.NewStringValue("machine_event")
+
+	diags = resp.State.Set(ctx, state)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// waitForEvent watches the events of the node, including the past ones, until match returns true or an error,
+// the events the client doesn't support are skipped.
+func waitForEvent(ctx context.Context, c *client.Client, match func(client.Event) (bool, error)) (string, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := c.Events(ctx, client.WithTailEvents(-1))
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		msg, err := stream.Recv()
+		if err != nil {
+			return "", err
+		}
+
+		if msg.GetMetadata().GetError() != "" {
+			return "", errors.New(msg.GetMetadata().GetError())
+		}
+
+		event, err := client.UnmarshalEvent(msg)
+		if err != nil {
+			if errors.Is(err, client.ErrEventNotSupported) {
+				continue
+			}
+
+			return "", err
+		}
+
+		matched, err := match(*event)
+		if err != nil {
+			return "", err
+		}
+
+		if matched {
+			return event.ID, nil
+		}
+	}
+}
+
+// machineEventMatches reports whether the event is the awaited machine status or sequence event,
+// it returns errSequenceFailed if the awaited sequence failed.
+func machineEventMatches(event client.Event, machineStage string, machineReady bool, sequence string) (bool, error) {
+	switch payload := event.Payload.(type) {
+	case *machineapi.MachineStatusEvent:
+		if machineStage == "" || payload.GetStage().String() != machineStage {
+			return false, nil
+		}
+
+		return !machineReady || payload.GetStatus().GetReady(), nil
+	case *machineapi.SequenceEvent:
+		if sequence == "" || payload.GetSequence() != sequence || payload.GetAction() != machineapi.SequenceEvent_STOP {
+			return false, nil
+		}
+
+		if payload.GetError() != nil {
+			return false, fmt.Errorf("%w: %s: %s", errSequenceFailed, sequence, payload.GetError().GetMessage())
+		}
+
+		return true, nil
+	default:
+		return false, nil
+	}
+}
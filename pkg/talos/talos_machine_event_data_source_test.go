@@ -0,0 +1,177 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/siderolabs/talos/pkg/machinery/api/common"
+	machineapi "github.com/siderolabs/talos/pkg/machinery/api/machine"
+	"github.com/siderolabs/talos/pkg/machinery/client"
+	"github.com/siderolabs/talos/pkg/machinery/proto"
+
+	"github.com/siderolabs/terraform-provider-talos/pkg/talos"
+)
+
+func TestAccTalosMachineEventDataSource(t *testing.T) {
+	rName := acctest.RandStringFromCharSet(10, acctest.CharSetAlpha)
+
+	resource.ParallelTest(t, resource.TestCase{
+		ExternalProviders: map[string]resource.ExternalProvider{
+			"libvirt": {
+				Source: "dmacvicar/libvirt",
+			},
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccTalosMachineEventDataSourceConfig("talos", rName),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.talos_machine_event.running", "id", "machine_event"),
+					resource.TestCheckResourceAttrSet("data.talos_machine_event.running", "event_id"),
+					resource.TestCheckResourceAttrSet("data.talos_machine_event.boot", "event_id"),
+				),
+			},
+		},
+	})
+}
+
+func testAccTalosMachineEventDataSourceConfig(providerName, rName string) string {
+	config := dynamicConfig{
+		Provider:        providerName,
+		ResourceName:    rName,
+		WithApplyConfig: true,
+		WithBootstrap:   true,
+	}
+
+	return config.render() + `
+data "talos_machine_event" "running" {
+  depends_on = [
+    talos_machine_bootstrap.this
+  ]
+  client_configuration = talos_machine_secrets.this.client_configuration
+  node                 = libvirt_domain.cp.network_interface[0].addresses[0]
+  machine_stage        = "RUNNING"
+  machine_ready        = true
+}
+
+data "talos_machine_event" "boot" {
+  depends_on = [
+    talos_machine_bootstrap.this
+  ]
+  client_configuration = talos_machine_secrets.this.client_configuration
+  node                 = libvirt_domain.cp.network_interface[0].addresses[0]
+  sequence             = "boot"
+}
+`
+}
+
+func TestMachineEventMatches(t *testing.T) {
+	t.Parallel()
+
+	running := &machineapi.MachineStatusEvent{
+		Stage:  machineapi.MachineStatusEvent_RUNNING,
+		Status: &machineapi.MachineStatusEvent_MachineStatus{},
+	}
+
+	for _, tt := range []struct {
+		name         string
+		payload      proto.Message
+		machineStage string
+		machineReady bool
+		sequence     string
+		matched      bool
+		err          string
+	}{
+		{
+			name:         "machine stage",
+			payload:      running,
+			machineStage: "RUNNING",
+			matched:      true,
+		},
+		{
+			name:         "other machine stage",
+			payload:      &machineapi.MachineStatusEvent{Stage: machineapi.MachineStatusEvent_BOOTING},
+			machineStage: "RUNNING",
+		},
+		{
+			name:         "machine not ready",
+			payload:      running,
+			machineStage: "RUNNING",
+			machineReady: true,
+		},
+		{
+			name: "machine ready",
+			payload: &machineapi.MachineStatusEvent{
+				Stage:  machineapi.MachineStatusEvent_RUNNING,
+				Status: &machineapi.MachineStatusEvent_MachineStatus{Ready: true},
+			},
+			machineStage: "RUNNING",
+			machineReady: true,
+			matched:      true,
+		},
+		{
+			name:     "machine stage without wait",
+			payload:  running,
+			sequence: "boot",
+		},
+		{
+			name:     "sequence done",
+			payload:  &machineapi.SequenceEvent{Sequence: "boot", Action: machineapi.SequenceEvent_STOP},
+			sequence: "boot",
+			matched:  true,
+		},
+		{
+			name:     "sequence started",
+			payload:  &machineapi.SequenceEvent{Sequence: "boot", Action: machineapi.SequenceEvent_START},
+			sequence: "boot",
+		},
+		{
+			name:     "other sequence",
+			payload:  &machineapi.SequenceEvent{Sequence: "install", Action: machineapi.SequenceEvent_STOP},
+			sequence: "boot",
+		},
+		{
+			name: "sequence failed",
+			payload: &machineapi.SequenceEvent{
+				Sequence: "boot",
+				Action:   machineapi.SequenceEvent_STOP,
+				Error:    &common.Error{Message: "failed to mount EPHEMERAL"},
+			},
+			sequence: "boot",
+			err:      "sequence failed: boot: failed to mount EPHEMERAL",
+		},
+		{
+			name:         "other event",
+			payload:      &machineapi.ServiceStateEvent{Service: "etcd"},
+			machineStage: "RUNNING",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			matched, err := talos.MachineEventMatches(client.Event{Payload: tt.payload}, tt.machineStage, tt.machineReady, tt.sequence)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) || !errors.Is(err, talos.ErrSequenceFailed) {
+					t.Fatalf("expected error %q, got %v", tt.err, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if matched != tt.matched {
+				t.Errorf("expected matched %t, got %t", tt.matched, matched)
+			}
+		})
+	}
+}
@@ -0,0 +1,272 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cosi-project/runtime/pkg/safe"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
+	"github.com/siderolabs/image-factory/pkg/constants"
+	"github.com/siderolabs/talos/pkg/machinery/client"
+	"github.com/siderolabs/talos/pkg/machinery/extensions"
+	"github.com/siderolabs/talos/pkg/machinery/resources/runtime"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type talosMachineExtensionsDataSource struct {
+	providerData *talosProviderData
+}
+
+type talosMachineExtensionsDataSourceModelV0 struct { //nolint:govet
+	ID                  types.String            `tfsdk:"id"`
+	Node                types.String            `tfsdk:"node"`
+	Endpoint            types.String            `tfsdk:"endpoint"`
+	ClientConfiguration *clientConfiguration    `tfsdk:"client_configuration"`
+	SchematicID         types.String            `tfsdk:"schematic_id"`
+	Extensions          []talosMachineExtension `tfsdk:"extensions"`
+	Timeouts            timeouts.Value          `tfsdk:"timeouts"`
+}
+
+type talosMachineExtension struct {
+	Name        types.String `tfsdk:"name"`
+	Version     types.String `tfsdk:"version"`
+	Author      types.String `tfsdk:"author"`
+	Description types.String `tfsdk:"description"`
+	Image       types.String `tfsdk:"image"`
+}
+
+var (
+	_ datasource.DataSource              = &talosMachineExtensionsDataSource{}
+	_ datasource.DataSourceWithConfigure = &talosMachineExtensionsDataSource{}
+)
+
+// NewTalosMachineExtensionsDataSource implements the datasource.DataSource interface.
+func NewTalosMachineExtensionsDataSource() datasource.DataSource {
+	return &talosMachineExtensionsDataSource{}
+}
+
+func (d *talosMachineExtensionsDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_machine_extensions"
+}
+
+func (d *talosMachineExtensionsDataSource) Schema(ctx context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Lists the system extensions running on a node",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The generated ID of this resource",
+				Computed:    true,
+			},
+			"node": schema.StringAttribute{
+				Required:    true,
+				Description: "node to list the extensions of",
+			},
+			"endpoint": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "endpoint to use for the talosclient. If not set, the node value will be used",
+			},
+			"client_configuration": schema.SingleNestedAttribute{
+				Attributes: map[string]schema.Attribute{
+					"ca_certificate": schema.StringAttribute{
+						Required:    true,
+						Description: "The client CA certificate",
+					},
+					"client_certificate": schema.StringAttribute{
+						Required:    true,
+						Description: "The client certificate",
+					},
+					"client_key": schema.StringAttribute{
+						Required:    true,
+						Sensitive:   true,
+						Description: "The client key",
+					},
+				},
+				Optional:    true,
+				Description: "The client configuration data, defaults to the credentials of the provider `talosconfig_path`",
+			},
+			"schematic_id": schema.StringAttribute{
+				Description: "The image factory schematic ID the node was installed from, empty if the node wasn't installed from an image factory image",
+				Computed:    true,
+			},
+			"extensions": schema.ListNestedAttribute{
+				Description: "The system extensions running on the node sorted by name, without the image factory `schematic` extension",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							Description: "The name of the extension (e.g. `gvisor` for the `siderolabs/gvisor` image factory extension)",
+							Computed:    true,
+						},
+						"version": schema.StringAttribute{
+							Description: "The version of the extension",
+							Computed:    true,
+						},
+						"author": schema.StringAttribute{
+							Description: "The author of the extension",
+							Computed:    true,
+						},
+						"description": schema.StringAttribute{
+							Description: "The description of the extension",
+							Computed:    true,
+						},
+						"image": schema.StringAttribute{
+							Description: "The squashfs image of the extension on the node",
+							Computed:    true,
+						},
+					},
+				},
+				Computed: true,
+			},
+			"timeouts": timeouts.Attributes(ctx, timeouts.Opts{
+				Read: true,
+			}),
+		},
+	}
+}
+
+func (d *talosMachineExtensionsDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*talosProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"failed to get provider data",
+			fmt.Sprintf("Expected *talosProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.providerData = providerData
+}
+
+func (d *talosMachineExtensionsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var obj types.Object
+
+	diags := req.Config.Get(ctx, &obj)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var state talosMachineExtensionsDataSourceModelV0
+	diags = obj.As(ctx, &state, basetypes.ObjectAsOptions{
+		UnhandledNullAsEmpty:    true,
+		UnhandledUnknownAsEmpty: true,
+	})
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	talosConfig, err := d.providerData.talosClientConfig("dynamic", state.ClientConfiguration)
+	if err != nil {
+		resp.Diagnostics.AddError("failed to generate talos config", err.Error())
+
+		return
+	}
+
+	if state.Endpoint.IsNull() {
+		state.Endpoint = state.Node
+	}
+
+	if d.providerData.deferRead(ctx, req, resp, state.Endpoint) {
+		return
+	}
+
+	readTimeout, diags := state.Timeouts.Read(ctx, 10*time.Minute)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctxDeadline, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
+	if err := d.providerData.retryContext(ctxDeadline, readTimeout, func() *retry.RetryError {
+		if err := talosClientOp(d.providerData.withClientOptions(ctx), state.Endpoint.ValueString(), state.Node.ValueString(), talosConfig, func(nodeCtx context.Context, c *client.Client) error {
+			extensionStatuses, err := safe.StateListAll[*runtime.ExtensionStatus](nodeCtx, c.COSI)
+			if err != nil {
+				return err
+			}
+
+			layers := make([]extensions.Layer, 0, extensionStatuses.Len())
+
+			for it := extensionStatuses.Iterator(); it.Next(); {
+				layers = append(layers, *it.Value().TypedSpec())
+			}
+
+			state.SchematicID, state.Extensions = machineExtensions(layers)
+
+			return nil
+		}); err != nil {
+			if s := status.Code(err); s == codes.InvalidArgument {
+				return retry.NonRetryableError(err)
+			}
+
+			return retry.RetryableError(err)
+		}
+
+		return nil
+	}); err != nil {
+		resp.Diagnostics.AddError("failed to get list of extensions", err.Error())
+
+		return
+	}
+
+	state.ID = basetypes.NewStringValue("machine_extensions")
+
+	diags = resp.State.Set(ctx, state)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// machineExtensions returns the schematic ID and the other extensions of the extension layers, sorted by name.
+func machineExtensions(layers []extensions.Layer) (types.String, []talosMachineExtension) {
+	schematicID := ""
+	machineExtensions := []talosMachineExtension{}
+
+	for _, layer := range layers {
+		// the image factory records the schematic ID as the version of a pseudo extension
+		if layer.Metadata.Name == constants.SchematicIDExtensionName {
+			schematicID = layer.Metadata.Version
+
+			continue
+		}
+
+		machineExtensions = append(machineExtensions, talosMachineExtension{
+			Name:        basetypes.NewStringValue(layer.Metadata.Name),
+			Version:     basetypes.NewStringValue(layer.Metadata.Version),
+			Author:      basetypes.NewStringValue(layer.Metadata.Author),
+			Description: basetypes.NewStringValue(strings.TrimSpace(layer.Metadata.Description)),
+			Image:       basetypes.NewStringValue(layer.Image),
+		})
+	}
+
+	slices.SortFunc(machineExtensions, func(a, b talosMachineExtension) int {
+		return strings.Compare(a.Name.ValueString(), b.Name.ValueString())
+	})
+
+	return basetypes.NewStringValue(schematicID), machineExtensions
+}
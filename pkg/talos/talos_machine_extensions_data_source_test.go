@@ -0,0 +1,103 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/siderolabs/gen/xslices"
+	"github.com/siderolabs/talos/pkg/machinery/extensions"
+
+	"github.com/siderolabs/terraform-provider-talos/pkg/talos"
+)
+
+func TestAccTalosMachineExtensionsDataSource(t *testing.T) {
+	rName := acctest.RandStringFromCharSet(10, acctest.CharSetAlpha)
+
+	resource.ParallelTest(t, resource.TestCase{
+		ExternalProviders: map[string]resource.ExternalProvider{
+			"libvirt": {
+				Source: "dmacvicar/libvirt",
+			},
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccTalosMachineExtensionsDataSourceConfig("talos", rName),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.talos_machine_extensions.this", "id", "machine_extensions"),
+					resource.TestCheckResourceAttrSet("data.talos_machine_extensions.this", "extensions.#"),
+				),
+			},
+		},
+	})
+}
+
+func testAccTalosMachineExtensionsDataSourceConfig(providerName, rName string) string {
+	config := dynamicConfig{
+		Provider:        providerName,
+		ResourceName:    rName,
+		WithApplyConfig: false,
+		WithBootstrap:   false,
+	}
+
+	return config.render() + `
+data "talos_machine_extensions" "this" {
+  client_configuration = talos_machine_secrets.this.client_configuration
+  node                 = libvirt_domain.cp.network_interface[0].addresses[0]
+}
+`
+}
+
+func TestMachineExtensions(t *testing.T) {
+	t.Parallel()
+
+	schematicID, machineExtensions := talos.MachineExtensions([]extensions.Layer{
+		{
+			Image: "0.sqsh",
+			Metadata: extensions.Metadata{
+				Name:        "schematic",
+				Version:     "376567988ad370138ad8b2698212367b8edcb69b5fd68c80be1f2ec7d603b4ba",
+				Description: "Describes the image factory schematic used to build the image.\n",
+			},
+		},
+		{
+			Image: "2.sqsh",
+			Metadata: extensions.Metadata{
+				Name:        "iscsi-tools",
+				Version:     "v0.1.4",
+				Author:      "Sidero Labs",
+				Description: "This system extension provides iscsi-tools.\n",
+			},
+		},
+		{
+			Image: "1.sqsh",
+			Metadata: extensions.Metadata{
+				Name:    "gvisor",
+				Version: "20240212.0",
+				Author:  "Sidero Labs",
+			},
+		},
+	})
+
+	if schematicID.ValueString() != "376567988ad370138ad8b2698212367b8edcb69b5fd68c80be1f2ec7d603b4ba" {
+		t.Errorf("unexpected schematic ID %q", schematicID.ValueString())
+	}
+
+	names := xslices.Map(machineExtensions, func(e talos.TalosMachineExtension) string {
+		return e.Name.ValueString() + "@" + e.Version.ValueString()
+	})
+
+	if !slices.Equal(names, []string{"gvisor@20240212.0", "iscsi-tools@v0.1.4"}) {
+		t.Errorf("unexpected extensions %v", names)
+	}
+
+	if machineExtensions[1].Description.ValueString() != "This system extension provides iscsi-tools." {
+		t.Errorf("unexpected description %q", machineExtensions[1].Description.ValueString())
+	}
+}
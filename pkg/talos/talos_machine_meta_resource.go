@@ -0,0 +1,456 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cosi-project/runtime/pkg/safe"
+	cosistate "github.com/cosi-project/runtime/pkg/state"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
+	"github.com/siderolabs/talos/pkg/machinery/client"
+	"github.com/siderolabs/talos/pkg/machinery/resources/runtime"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type talosMachineMetaResource struct {
+	providerData *talosProviderData
+}
+
+var (
+	_ resource.Resource               = &talosMachineMetaResource{}
+	_ resource.ResourceWithModifyPlan = &talosMachineMetaResource{}
+	_ resource.ResourceWithConfigure  = &talosMachineMetaResource{}
+)
+
+type talosMachineMetaResourceModelV0 struct {
+	ID                  types.String         `tfsdk:"id"`
+	Endpoint            types.String         `tfsdk:"endpoint"`
+	Node                types.String         `tfsdk:"node"`
+	ClientConfiguration *clientConfiguration `tfsdk:"client_configuration"`
+	Key                 types.Int64          `tfsdk:"key"`
+	Value               types.String         `tfsdk:"value"`
+	Timeouts            timeouts.Value       `tfsdk:"timeouts"`
+}
+
+// NewTalosMachineMetaResource implements the resource.Resource interface.
+func NewTalosMachineMetaResource() resource.Resource {
+	return &talosMachineMetaResource{}
+}
+
+func (r *talosMachineMetaResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_machine_meta"
+}
+
+func (r *talosMachineMetaResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "The machine meta resource allows you to manage a key in the META partition of a Talos node (e.g. the platform network configuration for bare-metal boots). " +
+			"The key is deleted from the META partition when the resource is destroyed.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:    true,
+				Description: "The ID of the META key",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"endpoint": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "The endpoint of the machine to manage the META key on",
+			},
+			"node": schema.StringAttribute{
+				Required:    true,
+				Description: "The name of the node to manage the META key on",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"client_configuration": schema.SingleNestedAttribute{
+				Attributes: map[string]schema.Attribute{
+					"ca_certificate": schema.StringAttribute{
+						Required:    true,
+						Description: "The client CA certificate",
+					},
+					"client_certificate": schema.StringAttribute{
+						Required:    true,
+						Description: "The client certificate",
+					},
+					"client_key": schema.StringAttribute{
+						Required:    true,
+						Sensitive:   true,
+						Description: "The client key",
+					},
+				},
+				Optional:    true,
+				Description: "The client configuration data, defaults to the credentials of the provider `talosconfig_path`",
+			},
+			"key": schema.Int64Attribute{
+				Required:    true,
+				Description: "The META key to manage (e.g. 10 (0x0a) for the platform network configuration of the `metal` platform)",
+				Validators: []validator.Int64{
+					int64validator.Between(1, 255),
+				},
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.RequiresReplace(),
+				},
+			},
+			"value": schema.StringAttribute{
+				Required:    true,
+				Description: "The value of the META key",
+			},
+			"timeouts": timeouts.Attributes(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
+		},
+	}
+}
+
+func (r *talosMachineMetaResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*talosProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"failed to get provider data",
+			fmt.Sprintf("Expected *talosProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.providerData = providerData
+}
+
+func (r *talosMachineMetaResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var state talosMachineMetaResourceModelV0
+
+	diags := req.Plan.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+
+	if diags.HasError() {
+		return
+	}
+
+	createTimeout, diags := state.Timeouts.Create(ctx, 10*time.Minute)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.write(ctx, &state, createTimeout)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Set state to fully populated data
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r *talosMachineMetaResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state talosMachineMetaResourceModelV0
+
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+
+	if diags.HasError() {
+		return
+	}
+
+	talosClientConfig, err := r.providerData.talosClientConfig("dynamic", state.ClientConfiguration)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error converting config to talos client config",
+			err.Error(),
+		)
+
+		return
+	}
+
+	// reads happen on every refresh, so transient errors are only retried for a short time by default
+	readTimeout, diags := state.Timeouts.Read(ctx, time.Minute)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var (
+		value    string
+		notFound bool
+	)
+
+	ctxDeadline, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
+	if err := r.providerData.retryContext(ctxDeadline, readTimeout, func() *retry.RetryError {
+		if err := talosClientOp(r.providerData.withClientOptions(ctx), state.Endpoint.ValueString(), state.Node.ValueString(), talosClientConfig, func(nodeCtx context.Context, c *client.Client) error {
+			metaKey, err := safe.StateGetByID[*runtime.MetaKey](nodeCtx, c.COSI, runtime.MetaKeyTagToID(uint8(state.Key.ValueInt64())))
+			if err != nil {
+				if cosistate.IsNotFoundError(err) {
+					notFound = true
+
+					return nil
+				}
+
+				return err
+			}
+
+			value = metaKey.TypedSpec().Value
+
+			return nil
+		}); err != nil {
+			if isTransientError(err) {
+				return retry.RetryableError(err)
+			}
+
+			return retry.NonRetryableError(err)
+		}
+
+		return nil
+	}); err != nil {
+		if !isTransientError(err) {
+			resp.Diagnostics.AddError("failed to read META key", err.Error())
+
+			return
+		}
+
+		// the node being unreachable (e.g. while it is being reinstalled) should not break the refresh
+		resp.Diagnostics.AddWarning("failed to read META key", err.Error())
+
+		return
+	}
+
+	if notFound {
+		resp.State.RemoveResource(ctx)
+
+		return
+	}
+
+	state.Value = basetypes.NewStringValue(value)
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r *talosMachineMetaResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var state talosMachineMetaResourceModelV0
+
+	diags := req.Plan.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+
+	if diags.HasError() {
+		return
+	}
+
+	updateTimeout, diags := state.Timeouts.Update(ctx, 10*time.Minute)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.write(ctx, &state, updateTimeout)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Set state to fully populated data
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r *talosMachineMetaResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state talosMachineMetaResourceModelV0
+
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+
+	if diags.HasError() {
+		return
+	}
+
+	talosClientConfig, err := r.providerData.talosClientConfig("dynamic", state.ClientConfiguration)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error converting config to talos client config",
+			err.Error(),
+		)
+
+		return
+	}
+
+	deleteTimeout, diags := state.Timeouts.Delete(ctx, 10*time.Minute)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctxDeadline, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
+
+	if err := r.providerData.retryContext(ctxDeadline, deleteTimeout, func() *retry.RetryError {
+		if err := talosClientOp(r.providerData.withClientOptions(ctx), state.Endpoint.ValueString(), state.Node.ValueString(), talosClientConfig, func(nodeCtx context.Context, c *client.Client) error {
+			return c.MetaDelete(nodeCtx, uint8(state.Key.ValueInt64()))
+		}); err != nil {
+			switch status.Code(err) { //nolint:exhaustive
+			case codes.NotFound:
+				return nil
+			case codes.InvalidArgument:
+				return retry.NonRetryableError(err)
+			default:
+				return retry.RetryableError(err)
+			}
+		}
+
+		return nil
+	}); err != nil {
+		resp.Diagnostics.AddError(
+			"Error deleting META key",
+			err.Error(),
+		)
+
+		return
+	}
+}
+
+// write writes the META key to the node.
+func (r *talosMachineMetaResource) write(ctx context.Context, state *talosMachineMetaResourceModelV0, timeout time.Duration) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	talosClientConfig, err := r.providerData.talosClientConfig("dynamic", state.ClientConfiguration)
+	if err != nil {
+		diags.AddError(
+			"Error converting config to talos client config",
+			err.Error(),
+		)
+
+		return diags
+	}
+
+	ctxDeadline, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if err := r.providerData.retryContext(ctxDeadline, timeout, func() *retry.RetryError {
+		if err := talosClientOp(r.providerData.withClientOptions(ctx), state.Endpoint.ValueString(), state.Node.ValueString(), talosClientConfig, func(nodeCtx context.Context, c *client.Client) error {
+			return c.MetaWrite(nodeCtx, uint8(state.Key.ValueInt64()), []byte(state.Value.ValueString()))
+		}); err != nil {
+			if s := status.Code(err); s == codes.InvalidArgument {
+				return retry.NonRetryableError(err)
+			}
+
+			return retry.RetryableError(err)
+		}
+
+		return nil
+	}); err != nil {
+		diags.AddError(
+			"Error writing META key",
+			err.Error(),
+		)
+
+		return diags
+	}
+
+	state.ID = basetypes.NewStringValue(runtime.MetaKeyTagToID(uint8(state.Key.ValueInt64())))
+
+	return diags
+}
+
+func (r *talosMachineMetaResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// delete is a no-op
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var configObj types.Object
+
+	diags := req.Config.Get(ctx, &configObj)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var config talosMachineMetaResourceModelV0
+
+	diags = configObj.As(ctx, &config, basetypes.ObjectAsOptions{
+		UnhandledNullAsEmpty:    true,
+		UnhandledUnknownAsEmpty: true,
+	})
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// if either endpoint or node is unknown return early, deferring the creation if Terraform allows it
+	if config.Endpoint.IsUnknown() || config.Node.IsUnknown() {
+		deferCreate(req, resp)
+
+		return
+	}
+
+	if config.Endpoint.IsNull() {
+		diags = resp.Plan.SetAttribute(ctx, path.Root("endpoint"), config.Node.ValueString())
+		resp.Diagnostics.Append(diags...)
+
+		if diags.HasError() {
+			return
+		}
+	}
+}
+
+// isTransientError returns true if the error is likely transient (e.g. the node is unreachable or rebooting), so the operation is worth retrying.
+func isTransientError(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	switch status.Code(err) { //nolint:exhaustive
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	default:
+		return false
+	}
+}
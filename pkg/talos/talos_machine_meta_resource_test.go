@@ -0,0 +1,134 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/siderolabs/terraform-provider-talos/pkg/talos"
+)
+
+func TestAccTalosMachineMetaResource(t *testing.T) {
+	rName := acctest.RandStringFromCharSet(10, acctest.CharSetAlpha)
+
+	resource.ParallelTest(t, resource.TestCase{
+		ExternalProviders: map[string]resource.ExternalProvider{
+			"libvirt": {
+				Source: "dmacvicar/libvirt",
+			},
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// write a user reserved META key on a node in maintenance mode
+			{
+				Config: testAccTalosMachineMetaResourceConfig("talos", rName, "foo"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("talos_machine_meta.this", "id", "0x0c"),
+					resource.TestCheckResourceAttr("talos_machine_meta.this", "key", "12"),
+					resource.TestCheckResourceAttr("talos_machine_meta.this", "value", "foo"),
+				),
+			},
+			// update the value
+			{
+				Config: testAccTalosMachineMetaResourceConfig("talos", rName, "bar"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("talos_machine_meta.this", "value", "bar"),
+					resource.TestCheckResourceAttr("talos_machine_meta.this", "timeouts.read", "30s"),
+				),
+			},
+			// refresh reads the value back from the node
+			{
+				RefreshState: true,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("talos_machine_meta.this", "value", "bar"),
+				),
+			},
+		},
+	})
+}
+
+func testAccTalosMachineMetaResourceConfig(providerName, rName, value string) string {
+	config := dynamicConfig{
+		Provider:        providerName,
+		ResourceName:    rName,
+		WithApplyConfig: false,
+		WithBootstrap:   false,
+	}
+
+	return config.render() + fmt.Sprintf(`
+resource "talos_machine_meta" "this" {
+  client_configuration = talos_machine_secrets.this.client_configuration
+  node                 = libvirt_domain.cp.network_interface[0].addresses[0]
+  key                  = 12
+  value                = "%s"
+
+  timeouts = {
+    read = "30s"
+  }
+}
+`, value)
+}
+
+func TestIsTransientError(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name      string
+		err       error
+		transient bool
+	}{
+		{
+			name:      "unavailable",
+			err:       status.Error(codes.Unavailable, "connection refused"),
+			transient: true,
+		},
+		{
+			name:      "deadline exceeded",
+			err:       status.Error(codes.DeadlineExceeded, "deadline exceeded"),
+			transient: true,
+		},
+		{
+			name:      "wrapped unavailable",
+			err:       fmt.Errorf("error reading META key: %w", status.Error(codes.Unavailable, "connection refused")),
+			transient: true,
+		},
+		{
+			name:      "context deadline exceeded",
+			err:       context.DeadlineExceeded,
+			transient: true,
+		},
+		{
+			name:      "permission denied",
+			err:       status.Error(codes.PermissionDenied, "not authorized"),
+			transient: false,
+		},
+		{
+			name:      "invalid argument",
+			err:       status.Error(codes.InvalidArgument, "invalid key"),
+			transient: false,
+		},
+		{
+			name:      "other",
+			err:       errors.New("boom"),
+			transient: false,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if transient := talos.IsTransientError(tt.err); transient != tt.transient {
+				t.Errorf("expected transient %v, got %v", tt.transient, transient)
+			}
+		})
+	}
+}
@@ -0,0 +1,354 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/cosi-project/runtime/pkg/safe"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
+	"github.com/siderolabs/talos/pkg/machinery/client"
+	"github.com/siderolabs/talos/pkg/machinery/resources/network"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type talosMachineNetworkInterfacesDataSource struct {
+	providerData *talosProviderData
+}
+
+type talosMachineNetworkInterfacesDataSourceModelV0 struct { //nolint:govet
+	ID                  types.String                       `tfsdk:"id"`
+	Node                types.String                       `tfsdk:"node"`
+	Endpoint            types.String                       `tfsdk:"endpoint"`
+	ClientConfiguration *clientConfiguration               `tfsdk:"client_configuration"`
+	Filters             talosMachineNetworkInterfaceFilter `tfsdk:"filters"`
+	Interfaces          []talosMachineNetworkInterface     `tfsdk:"interfaces"`
+	Timeouts            timeouts.Value                     `tfsdk:"timeouts"`
+}
+
+type talosMachineNetworkInterface struct {
+	Name             types.String   `tfsdk:"name"`
+	Type             types.String   `tfsdk:"type"`
+	Kind             types.String   `tfsdk:"kind"`
+	HardwareAddr     types.String   `tfsdk:"hardware_addr"`
+	PermanentAddr    types.String   `tfsdk:"permanent_addr"`
+	OperationalState types.String   `tfsdk:"operational_state"`
+	LinkState        types.Bool     `tfsdk:"link_state"`
+	Physical         types.Bool     `tfsdk:"physical"`
+	MTU              types.Int64    `tfsdk:"mtu"`
+	Driver           types.String   `tfsdk:"driver"`
+	BusPath          types.String   `tfsdk:"bus_path"`
+	Addresses        []types.String `tfsdk:"addresses"`
+}
+
+type talosMachineNetworkInterfaceFilter struct {
+	Name         types.String `tfsdk:"name"`
+	HardwareAddr types.String `tfsdk:"hardware_addr"`
+	Physical     types.Bool   `tfsdk:"physical"`
+}
+
+var (
+	_ datasource.DataSource              = &talosMachineNetworkInterfacesDataSource{}
+	_ datasource.DataSourceWithConfigure = &talosMachineNetworkInterfacesDataSource{}
+)
+
+// NewTalosMachineNetworkInterfacesDataSource implements the datasource.DataSource interface.
+func NewTalosMachineNetworkInterfacesDataSource() datasource.DataSource {
+	return &talosMachineNetworkInterfacesDataSource{}
+}
+
+func (d *talosMachineNetworkInterfacesDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_machine_network_interfaces"
+}
+
+func (d *talosMachineNetworkInterfacesDataSource) Schema(ctx context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Retrieves the network interfaces of a node (including a node in maintenance mode)",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The generated ID of this resource",
+				Computed:    true,
+			},
+			"node": schema.StringAttribute{
+				Required:    true,
+				Description: "node to retrieve the network interfaces from",
+			},
+			"endpoint": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "endpoint to use for the talosclient. If not set, the node value will be used",
+			},
+			"client_configuration": schema.SingleNestedAttribute{
+				Attributes: map[string]schema.Attribute{
+					"ca_certificate": schema.StringAttribute{
+						Required:    true,
+						Description: "The client CA certificate",
+					},
+					"client_certificate": schema.StringAttribute{
+						Required:    true,
+						Description: "The client certificate",
+					},
+					"client_key": schema.StringAttribute{
+						Required:    true,
+						Sensitive:   true,
+						Description: "The client key",
+					},
+				},
+				Optional:    true,
+				Description: "The client configuration data, defaults to the credentials of the provider `talosconfig_path`",
+			},
+			"filters": schema.SingleNestedAttribute{
+				Description: "Filters to apply to the network interfaces",
+				Attributes: map[string]schema.Attribute{
+					"name": schema.StringAttribute{
+						Description: "Filter network interfaces by name",
+						Optional:    true,
+					},
+					"hardware_addr": schema.StringAttribute{
+						Description: "Filter network interfaces by hardware (MAC) address, compared case-insensitively",
+						Optional:    true,
+					},
+					"physical": schema.BoolAttribute{
+						Description: "Filter network interfaces by whether they are physical",
+						Optional:    true,
+					},
+				},
+				Optional: true,
+			},
+			"interfaces": schema.ListNestedAttribute{
+				Description: "The network interfaces that match the filters",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							Description: "The name of the network interface",
+							Computed:    true,
+						},
+						"type": schema.StringAttribute{
+							Description: "The link type of the network interface",
+							Computed:    true,
+						},
+						"kind": schema.StringAttribute{
+							Description: "The kind of the network interface (e.g. bond, vlan), empty for physical interfaces",
+							Computed:    true,
+						},
+						"hardware_addr": schema.StringAttribute{
+							Description: "The current hardware (MAC) address of the network interface",
+							Computed:    true,
+						},
+						"permanent_addr": schema.StringAttribute{
+							Description: "The permanent hardware (MAC) address of the network interface",
+							Computed:    true,
+						},
+						"operational_state": schema.StringAttribute{
+							Description: "The operational state of the network interface",
+							Computed:    true,
+						},
+						"link_state": schema.BoolAttribute{
+							Description: "Whether the network interface has a carrier",
+							Computed:    true,
+						},
+						"physical": schema.BoolAttribute{
+							Description: "Whether the network interface is physical",
+							Computed:    true,
+						},
+						"mtu": schema.Int64Attribute{
+							Description: "The MTU of the network interface",
+							Computed:    true,
+						},
+						"driver": schema.StringAttribute{
+							Description: "The driver of the network interface",
+							Computed:    true,
+						},
+						"bus_path": schema.StringAttribute{
+							Description: "The bus path of the network interface",
+							Computed:    true,
+						},
+						"addresses": schema.ListAttribute{
+							Description: "The current addresses of the network interface in CIDR notation",
+							ElementType: types.StringType,
+							Computed:    true,
+						},
+					},
+				},
+				Computed: true,
+			},
+			"timeouts": timeouts.Attributes(ctx, timeouts.Opts{
+				Read: true,
+			}),
+		},
+	}
+}
+
+func (d *talosMachineNetworkInterfacesDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*talosProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"failed to get provider data",
+			fmt.Sprintf("Expected *talosProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.providerData = providerData
+}
+
+func (d *talosMachineNetworkInterfacesDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var obj types.Object
+
+	diags := req.Config.Get(ctx, &obj)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var state talosMachineNetworkInterfacesDataSourceModelV0
+	diags = obj.As(ctx, &state, basetypes.ObjectAsOptions{
+		UnhandledNullAsEmpty:    true,
+		UnhandledUnknownAsEmpty: true,
+	})
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	talosConfig, err := d.providerData.talosClientConfig("dynamic", state.ClientConfiguration)
+	if err != nil {
+		resp.Diagnostics.AddError("failed to generate talos config", err.Error())
+
+		return
+	}
+
+	if state.Endpoint.IsNull() {
+		state.Endpoint = state.Node
+	}
+
+	if d.providerData.deferRead(ctx, req, resp, state.Endpoint) {
+		return
+	}
+
+	var hardwareAddrFilter net.HardwareAddr
+
+	if !state.Filters.HardwareAddr.IsNull() {
+		// MAC addresses are compared parsed, so that the filter is case-insensitive
+		hardwareAddrFilter, err = net.ParseMAC(state.Filters.HardwareAddr.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("filters").AtName("hardware_addr"), "invalid hardware address filter", err.Error())
+
+			return
+		}
+	}
+
+	readTimeout, diags := state.Timeouts.Read(ctx, 10*time.Minute)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctxDeadline, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
+	if err := d.providerData.retryContext(ctxDeadline, readTimeout, func() *retry.RetryError {
+		if err := talosClientOp(d.providerData.withClientOptions(ctx), state.Endpoint.ValueString(), state.Node.ValueString(), talosConfig, func(nodeCtx context.Context, c *client.Client) error {
+			links, err := safe.StateListAll[*network.LinkStatus](nodeCtx, c.COSI)
+			if err != nil {
+				return err
+			}
+
+			addresses, err := safe.StateListAll[*network.AddressStatus](nodeCtx, c.COSI)
+			if err != nil {
+				return err
+			}
+
+			linkAddresses := map[string][]types.String{}
+
+			for it := addresses.Iterator(); it.Next(); {
+				spec := it.Value().TypedSpec()
+
+				linkAddresses[spec.LinkName] = append(linkAddresses[spec.LinkName], basetypes.NewStringValue(spec.Address.String()))
+			}
+
+			state.Interfaces = nil
+
+			for it := links.Iterator(); it.Next(); {
+				link := it.Value()
+				spec := link.TypedSpec()
+
+				if !state.Filters.Name.IsNull() && state.Filters.Name.ValueString() != link.Metadata().ID() {
+					continue
+				}
+
+				if hardwareAddrFilter != nil && !bytes.Equal(hardwareAddrFilter, spec.HardwareAddr) {
+					continue
+				}
+
+				if !state.Filters.Physical.IsNull() && state.Filters.Physical.ValueBool() != spec.Physical() {
+					continue
+				}
+
+				ifaceAddresses := linkAddresses[link.Metadata().ID()]
+				if ifaceAddresses == nil {
+					ifaceAddresses = []types.String{}
+				}
+
+				state.Interfaces = append(state.Interfaces, talosMachineNetworkInterface{
+					Name:             basetypes.NewStringValue(link.Metadata().ID()),
+					Type:             basetypes.NewStringValue(spec.Type.String()),
+					Kind:             basetypes.NewStringValue(spec.Kind),
+					HardwareAddr:     basetypes.NewStringValue(spec.HardwareAddr.String()),
+					PermanentAddr:    basetypes.NewStringValue(spec.PermanentAddr.String()),
+					OperationalState: basetypes.NewStringValue(spec.OperationalState.String()),
+					LinkState:        basetypes.NewBoolValue(spec.LinkState),
+					Physical:         basetypes.NewBoolValue(spec.Physical()),
+					MTU:              basetypes.NewInt64Value(int64(spec.MTU)),
+					Driver:           basetypes.NewStringValue(spec.Driver),
+					BusPath:          basetypes.NewStringValue(spec.BusPath),
+					Addresses:        ifaceAddresses,
+				})
+			}
+
+			return nil
+		}); err != nil {
+			if s := status.Code(err); s == codes.InvalidArgument {
+				return retry.NonRetryableError(err)
+			}
+
+			return retry.RetryableError(err)
+		}
+
+		return nil
+	}); err != nil {
+		resp.Diagnostics.AddError("failed to get list of network interfaces", err.Error())
+
+		return
+	}
+
+	state.ID = basetypes.NewStringValue("machine_network_interfaces")
+
+	diags = resp.State.Set(ctx, state)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
@@ -0,0 +1,98 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+)
+
+func TestAccTalosMachineNetworkInterfacesDataSource(t *testing.T) {
+	rName := acctest.RandStringFromCharSet(10, acctest.CharSetAlpha)
+
+	resource.ParallelTest(t, resource.TestCase{
+		ExternalProviders: map[string]resource.ExternalProvider{
+			"libvirt": {
+				Source: "dmacvicar/libvirt",
+			},
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// test against a node in maintenance mode
+			{
+				Config: testAccTalosMachineNetworkInterfacesDataSourceConfigV0("talos", rName),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.talos_machine_network_interfaces.this", "id", "machine_network_interfaces"),
+					resource.TestCheckResourceAttrSet("data.talos_machine_network_interfaces.this", "node"),
+					resource.TestCheckResourceAttrSet("data.talos_machine_network_interfaces.this", "endpoint"),
+					resource.TestCheckResourceAttr("data.talos_machine_network_interfaces.this", "filters.physical", "true"),
+					resource.TestCheckResourceAttr("data.talos_machine_network_interfaces.this", "interfaces.#", "1"),
+					testAccCheckResourceAttrPairEqualFold("data.talos_machine_network_interfaces.this", "interfaces.0.hardware_addr", "libvirt_domain.cp", "network_interface.0.mac"),
+					resource.TestCheckResourceAttr("data.talos_machine_network_interfaces.this", "interfaces.0.physical", "true"),
+					resource.TestCheckResourceAttr("data.talos_machine_network_interfaces.this", "interfaces.0.link_state", "true"),
+					resource.TestCheckResourceAttr("data.talos_machine_network_interfaces.this", "interfaces.0.operational_state", "up"),
+					resource.TestCheckResourceAttrSet("data.talos_machine_network_interfaces.this", "interfaces.0.addresses.0"),
+					resource.TestCheckResourceAttr("data.talos_machine_network_interfaces.mac", "interfaces.#", "1"),
+					testAccCheckResourceAttrPairEqualFold("data.talos_machine_network_interfaces.mac", "interfaces.0.hardware_addr", "libvirt_domain.cp", "network_interface.0.mac"),
+				),
+			},
+		},
+	})
+}
+
+func testAccTalosMachineNetworkInterfacesDataSourceConfigV0(providerName, rName string) string {
+	config := dynamicConfig{
+		Provider:        providerName,
+		ResourceName:    rName,
+		WithApplyConfig: false,
+		WithBootstrap:   false,
+	}
+
+	return config.render() + `
+data "talos_machine_network_interfaces" "this" {
+  client_configuration = talos_machine_secrets.this.client_configuration
+  node                 = libvirt_domain.cp.network_interface[0].addresses[0]
+  filters = {
+    physical = true
+  }
+}
+
+data "talos_machine_network_interfaces" "mac" {
+  client_configuration = talos_machine_secrets.this.client_configuration
+  node                 = libvirt_domain.cp.network_interface[0].addresses[0]
+  filters = {
+    hardware_addr = upper(libvirt_domain.cp.network_interface[0].mac)
+  }
+}
+`
+}
+
+// testAccCheckResourceAttrPairEqualFold checks that the two attributes are equal ignoring case, as MAC addresses might be reported in different cases.
+func testAccCheckResourceAttrPairEqualFold(nameFirst, keyFirst, nameSecond, keySecond string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		first, ok := s.RootModule().Resources[nameFirst]
+		if !ok {
+			return fmt.Errorf("resource %s not found", nameFirst)
+		}
+
+		second, ok := s.RootModule().Resources[nameSecond]
+		if !ok {
+			return fmt.Errorf("resource %s not found", nameSecond)
+		}
+
+		valueFirst, valueSecond := first.Primary.Attributes[keyFirst], second.Primary.Attributes[keySecond]
+
+		if !strings.EqualFold(valueFirst, valueSecond) {
+			return fmt.Errorf("%s.%s (%q) does not match %s.%s (%q)", nameFirst, keyFirst, valueFirst, nameSecond, keySecond, valueSecond)
+		}
+
+		return nil
+	}
+}
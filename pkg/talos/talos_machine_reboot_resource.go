@@ -0,0 +1,387 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/mapplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/siderolabs/talos/cmd/talosctl/pkg/talos/action"
+	"github.com/siderolabs/talos/pkg/machinery/client"
+)
+
+type talosMachineRebootResource struct {
+	providerData *talosProviderData
+}
+
+var (
+	_ resource.Resource                 = &talosMachineRebootResource{}
+	_ resource.ResourceWithModifyPlan   = &talosMachineRebootResource{}
+	_ resource.ResourceWithConfigure    = &talosMachineRebootResource{}
+	_ resource.ResourceWithUpgradeState = &talosMachineRebootResource{}
+)
+
+type talosMachineRebootResourceModelV0 struct {
+	ID                  types.String         `tfsdk:"id"`
+	Endpoint            types.String         `tfsdk:"endpoint"`
+	Endpoints           []types.String       `tfsdk:"endpoints"`
+	Port                types.Int64          `tfsdk:"port"`
+	Node                types.String         `tfsdk:"node"`
+	ClientConfiguration *clientConfiguration `tfsdk:"client_configuration"`
+	Mode                types.String         `tfsdk:"mode"`
+	Wait                types.Bool           `tfsdk:"wait"`
+	Triggers            types.Map            `tfsdk:"triggers"`
+	Timeouts            timeouts.Value       `tfsdk:"timeouts"`
+}
+
+// NewTalosMachineRebootResource implements the resource.Resource interface.
+func NewTalosMachineRebootResource() resource.Resource {
+	return &talosMachineRebootResource{}
+}
+
+func (r *talosMachineRebootResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_machine_reboot"
+}
+
+func (r *talosMachineRebootResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Version: 1,
+		Description: "The machine reboot resource allows you to reboot or shut down a Talos node, e.g. to activate a configuration applied in staged mode. " +
+			"The node is rebooted when the resource is created or replaced, use `triggers` to control when it happens.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:    true,
+				Description: "This is a unique identifier for the machine, derived from the endpoint and the node",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"endpoint": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "The endpoint of the machine to reboot",
+			},
+			"endpoints": schema.ListAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Description: "The additional endpoints to fail over to if `endpoint` is unreachable, the Talos API client load balances the requests over the reachable endpoints",
+			},
+			"port": schema.Int64Attribute{
+				Optional:    true,
+				Description: "The port of the Talos API, overrides the port of `endpoint` and `endpoints` (e.g. when apid is exposed on a different port through NAT), defaults to `50000`",
+				Validators: []validator.Int64{
+					int64validator.Between(1, 65535),
+				},
+			},
+			"node": schema.StringAttribute{
+				Required:    true,
+				Description: "The name of the node to reboot",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"client_configuration": schema.SingleNestedAttribute{
+				Attributes: map[string]schema.Attribute{
+					"ca_certificate": schema.StringAttribute{
+						Required:    true,
+						Description: "The client CA certificate",
+					},
+					"client_certificate": schema.StringAttribute{
+						Required:    true,
+						Description: "The client certificate",
+					},
+					"client_key": schema.StringAttribute{
+						Required:    true,
+						Sensitive:   true,
+						Description: "The client key",
+					},
+				},
+				Optional:    true,
+				Description: "The client configuration data, defaults to the credentials of the provider `talosconfig_path`",
+			},
+			"mode": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString("reboot"),
+				Description: "The mode of the operation, one of `reboot`, `powercycle` or `shutdown`",
+				Validators: []validator.String{
+					stringvalidator.OneOf("reboot", "powercycle", "shutdown"),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"wait": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(true),
+				Description: "Wait for the node to come back after the reboot. Ignored for `shutdown`",
+			},
+			"triggers": schema.MapAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Description: "A map of arbitrary values that, when changed, will reboot the node again",
+				PlanModifiers: []planmodifier.Map{
+					mapplanmodifier.RequiresReplace(),
+				},
+			},
+			"timeouts": timeouts.Attributes(ctx, timeouts.Opts{
+				Create: true,
+			}),
+		},
+	}
+}
+
+func (r *talosMachineRebootResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*talosProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"failed to get provider data",
+			fmt.Sprintf("Expected *talosProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.providerData = providerData
+}
+
+func (r *talosMachineRebootResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var state talosMachineRebootResourceModelV0
+
+	diags := req.Plan.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+
+	if diags.HasError() {
+		return
+	}
+
+	talosClientConfig, err := r.providerData.talosClientConfig("dynamic", state.ClientConfiguration)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error converting config to talos client config",
+			err.Error(),
+		)
+
+		return
+	}
+
+	createTimeout, diags := state.Timeouts.Create(ctx, 10*time.Minute)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	actionFn := func(ctx context.Context, c *client.Client) (string, error) {
+		return rebootGetActorID(ctx, c, state.Mode.ValueString())
+	}
+
+	// the reboot is sent only once, the tracker then waits for the node to come back with a new boot ID
+	if err := talosClientOpEndpoints(r.providerData.withClientOptions(ctx), clientEndpoints(state.Endpoint, state.Endpoints, state.Port), state.Node.ValueString(), talosClientConfig, func(nodeCtx context.Context, c *client.Client) error {
+		if !state.Wait.ValueBool() || state.Mode.ValueString() == "shutdown" {
+			_, err := actionFn(nodeCtx, c)
+
+			return err
+		}
+
+		executor := newClientExecutor(c, []string{state.Node.ValueString()})
+
+		return action.NewTracker(
+			executor,
+			action.MachineReadyEventFn,
+			actionFn,
+			action.WithPostCheck(action.BootIDChangedPostCheckFn),
+			action.WithDebug(false),
+			action.WithTimeout(createTimeout),
+		).Run()
+	}); err != nil {
+		resp.Diagnostics.AddError(
+			"Error rebooting node",
+			err.Error(),
+		)
+
+		return
+	}
+
+	state.ID = basetypes.NewStringValue(machineRebootID(state.Endpoint.ValueString(), state.Node.ValueString()))
+
+	// Set state to fully populated data
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r *talosMachineRebootResource) Read(_ context.Context, _ resource.ReadRequest, _ *resource.ReadResponse) {
+}
+
+func (r *talosMachineRebootResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var state talosMachineRebootResourceModelV0
+
+	diags := req.Plan.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+
+	if diags.HasError() {
+		return
+	}
+
+	// Set state to fully populated data
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r *talosMachineRebootResource) Delete(_ context.Context, _ resource.DeleteRequest, _ *resource.DeleteResponse) {
+}
+
+func (r *talosMachineRebootResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// delete is a no-op
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var configObj types.Object
+
+	diags := req.Config.Get(ctx, &configObj)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var config talosMachineRebootResourceModelV0
+
+	diags = configObj.As(ctx, &config, basetypes.ObjectAsOptions{
+		UnhandledNullAsEmpty:    true,
+		UnhandledUnknownAsEmpty: true,
+	})
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// if either endpoint or node is unknown return early, deferring the creation if Terraform allows it
+	if config.Endpoint.IsUnknown() || config.Node.IsUnknown() {
+		deferCreate(req, resp)
+
+		return
+	}
+
+	endpoint := config.Endpoint.ValueString()
+
+	if config.Endpoint.IsNull() {
+		endpoint = config.Node.ValueString()
+
+		diags = resp.Plan.SetAttribute(ctx, path.Root("endpoint"), endpoint)
+		resp.Diagnostics.Append(diags...)
+
+		if diags.HasError() {
+			return
+		}
+	}
+
+	// the ID is planned as it changes with the endpoint or the node
+	diags = resp.Plan.SetAttribute(ctx, path.Root("id"), machineRebootID(endpoint, config.Node.ValueString()))
+	resp.Diagnostics.Append(diags...)
+
+	if diags.HasError() {
+		return
+	}
+}
+
+func (r *talosMachineRebootResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	// version 0 only differs from the current schema by the ID value and the lack of `endpoints` and `port`, which are read as null
+	var schemaV0 resource.SchemaResponse
+
+	r.Schema(ctx, resource.SchemaRequest{}, &schemaV0)
+
+	return map[int64]resource.StateUpgrader{
+		0: {
+			PriorSchema: &schemaV0.Schema,
+			StateUpgrader: func(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+				var state talosMachineRebootResourceModelV0
+
+				diags := req.State.Get(ctx, &state)
+				resp.Diagnostics.Append(diags...)
+				if diags.HasError() {
+					return
+				}
+
+				state.ID = basetypes.NewStringValue(machineRebootID(state.Endpoint.ValueString(), state.Node.ValueString()))
+
+				diags = resp.State.Set(ctx, state)
+				resp.Diagnostics.Append(diags...)
+				if resp.Diagnostics.HasError() {
+					return
+				}
+			},
+		},
+	}
+}
+
+// machineRebootID returns the ID of the machine reboot resource of the node reached through the endpoint.
+func machineRebootID(endpoint, node string) string {
+	return endpoint + "/" + node
+}
+
+// rebootGetActorID reboots (or shuts down) the node and returns the actor ID of the operation.
+func rebootGetActorID(ctx context.Context, c *client.Client, mode string) (string, error) {
+	if mode == "shutdown" {
+		resp, err := c.ShutdownWithResponse(ctx)
+		if err != nil {
+			return "", err
+		}
+
+		if len(resp.GetMessages()) == 0 {
+			return "", errors.New("no messages returned from action run")
+		}
+
+		return resp.GetMessages()[0].GetActorId(), nil
+	}
+
+	var opts []client.RebootMode
+
+	if mode == "powercycle" {
+		opts = append(opts, client.WithPowerCycle)
+	}
+
+	resp, err := c.RebootWithResponse(ctx, opts...)
+	if err != nil {
+		return "", err
+	}
+
+	if len(resp.GetMessages()) == 0 {
+		return "", errors.New("no messages returned from action run")
+	}
+
+	return resp.GetMessages()[0].GetActorId(), nil
+}
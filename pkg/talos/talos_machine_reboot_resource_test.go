@@ -0,0 +1,70 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos_test
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+)
+
+func TestAccTalosMachineRebootResource(t *testing.T) {
+	rName := acctest.RandStringFromCharSet(10, acctest.CharSetAlpha)
+
+	resource.ParallelTest(t, resource.TestCase{
+		ExternalProviders: map[string]resource.ExternalProvider{
+			"libvirt": {
+				Source: "dmacvicar/libvirt",
+			},
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccTalosMachineRebootResourceConfig("talos", rName, "1"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestMatchResourceAttr("talos_machine_reboot.this", "id", regexp.MustCompile(`^[^/]+/[^/]+$`)),
+					resource.TestCheckResourceAttr("talos_machine_reboot.this", "mode", "reboot"),
+					resource.TestCheckResourceAttr("talos_machine_reboot.this", "wait", "true"),
+					resource.TestCheckResourceAttrPair("talos_machine_reboot.this", "endpoint", "talos_machine_reboot.this", "node"),
+				),
+			},
+			// changing the triggers reboots the node again
+			{
+				Config: testAccTalosMachineRebootResourceConfig("talos", rName, "2"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("talos_machine_reboot.this", plancheck.ResourceActionReplace),
+					},
+				},
+			},
+		},
+	})
+}
+
+func testAccTalosMachineRebootResourceConfig(providerName, rName, trigger string) string {
+	config := dynamicConfig{
+		Provider:        providerName,
+		ResourceName:    rName,
+		WithApplyConfig: true,
+		WithBootstrap:   true,
+	}
+
+	return config.render() + fmt.Sprintf(`
+resource "talos_machine_reboot" "this" {
+  depends_on = [
+    talos_machine_bootstrap.this
+  ]
+  client_configuration = talos_machine_secrets.this.client_configuration
+  node                 = libvirt_domain.cp.network_interface[0].addresses[0]
+  triggers = {
+    revision = "%s"
+  }
+}
+`, trigger)
+}
@@ -6,8 +6,11 @@ package talos
 
 import (
 	"context"
+	"crypto"
 	"crypto/x509"
 	"encoding/pem"
+	"errors"
+	"fmt"
 	"os"
 	"strings"
 	"time"
@@ -15,12 +18,15 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/mapplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/objectplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
+	talosx509 "github.com/siderolabs/crypto/x509"
 	"github.com/siderolabs/talos/pkg/machinery/config/generate/secrets"
 	"github.com/siderolabs/talos/pkg/machinery/gendata"
 	"golang.org/x/mod/semver"
@@ -50,10 +56,16 @@ type talosMachineSecretsResourceModelV0 struct {
 }
 
 type talosMachineSecretsResourceModelV1 struct {
-	ID                  types.String        `tfsdk:"id"`
-	TalosVersion        types.String        `tfsdk:"talos_version"`
-	MachineSecrets      machineSecrets      `tfsdk:"machine_secrets"`
-	ClientConfiguration clientConfiguration `tfsdk:"client_configuration"`
+	ID                    types.String                  `tfsdk:"id"`
+	TalosVersion          types.String                  `tfsdk:"talos_version"`
+	MachineSecrets        machineSecrets                `tfsdk:"machine_secrets"`
+	WorkerMachineSecrets  machineSecrets                `tfsdk:"worker_machine_secrets"`
+	WorkerJoinTTL         types.String                  `tfsdk:"worker_join_ttl"`
+	WorkerJoinExpiresAt   types.String                  `tfsdk:"worker_join_expires_at"`
+	WorkerJoinConfigPatch types.String                  `tfsdk:"worker_join_config_patch"`
+	CACertificates        *machineSecretsCACertificates `tfsdk:"ca_certificates"`
+	Triggers              types.Map                     `tfsdk:"triggers"`
+	ClientConfiguration   clientConfiguration           `tfsdk:"client_configuration"`
 }
 
 type clientConfiguration struct {
@@ -101,6 +113,13 @@ type machineSecretsCertKeyPair struct {
 	Key  types.String `tfsdk:"key"`
 }
 
+type machineSecretsCACertificates struct {
+	Etcd          *machineSecretsCertKeyPair `tfsdk:"etcd"`
+	K8s           *machineSecretsCertKeyPair `tfsdk:"k8s"`
+	K8sAggregator *machineSecretsCertKeyPair `tfsdk:"k8s_aggregator"`
+	OS            *machineSecretsCertKeyPair `tfsdk:"os"`
+}
+
 // NewTalosMachineSecretsResource implements the resource.Resource interface.
 func NewTalosMachineSecretsResource() resource.Resource {
 	return &talosMachineSecretsResource{}
@@ -133,78 +152,43 @@ func (r *talosMachineSecretsResource) Schema(_ context.Context, _ resource.Schem
 					talosMachineFeaturesVersionDefaults(),
 				},
 			},
-			"machine_secrets": schema.SingleNestedAttribute{
-				Description: "The secrets for the talos cluster",
+			"machine_secrets":        machineSecretsSchema("The secrets for the talos cluster"),
+			"worker_machine_secrets": machineSecretsSchema("The subset of the secrets required to generate worker machine configuration, without the CA keys and the control plane only secrets. It can be passed to worker machine configuration generation, so that worker provisioning never receives the control plane secrets"),
+			"worker_join_ttl": schema.StringAttribute{
+				Optional: true,
+				Description: "When set, the worker machine secrets get a dedicated Kubernetes bootstrap token valid for the given duration (e.g. `24h`) instead of the cluster bootstrap token. " +
+					"The token is only accepted once `worker_join_config_patch` is applied to the control plane nodes, and it is regenerated on the next apply after it expires",
+			},
+			"worker_join_expires_at": schema.StringAttribute{
+				Computed:    true,
+				Description: "The time (RFC3339) the worker bootstrap token expires at, only set when `worker_join_ttl` is set",
+			},
+			"worker_join_config_patch": schema.StringAttribute{
+				Computed:    true,
+				Sensitive:   true,
+				Description: "The control plane config patch registering the worker bootstrap token in Kubernetes, only set when `worker_join_ttl` is set",
+			},
+			"ca_certificates": schema.SingleNestedAttribute{
 				Attributes: map[string]schema.Attribute{
-					"cluster": schema.SingleNestedAttribute{
-						Attributes: map[string]schema.Attribute{
-							"id": schema.StringAttribute{
-								Description: "The cluster ID",
-								Computed:    true,
-							},
-							"secret": schema.StringAttribute{
-								Description: "The cluster secret",
-								Computed:    true,
-								Sensitive:   true,
-							},
-						},
-						Description: "The cluster secrets",
-						Computed:    true,
-					},
-					"secrets": schema.SingleNestedAttribute{
-						Attributes: map[string]schema.Attribute{
-							"bootstrap_token": schema.StringAttribute{
-								Description: "The bootstrap token",
-								Computed:    true,
-								Sensitive:   true,
-							},
-							"secretbox_encryption_secret": schema.StringAttribute{
-								Description: "The secretbox encryption secret",
-								Computed:    true,
-								Sensitive:   true,
-							},
-							"aescbc_encryption_secret": schema.StringAttribute{
-								Description: "The AES-CBC encryption secret",
-								Computed:    true,
-								Sensitive:   true,
-							},
-						},
-						Description: "kubernetes cluster secrets",
-						Computed:    true,
-					},
-					"trustdinfo": schema.SingleNestedAttribute{
-						Attributes: map[string]schema.Attribute{
-							"token": schema.StringAttribute{
-								Description: "The trustd token",
-								Computed:    true,
-								Sensitive:   true,
-							},
-						},
-						Description: "trustd secrets",
-						Computed:    true,
-					},
-					"certs": schema.SingleNestedAttribute{
-						Attributes: map[string]schema.Attribute{
-							"etcd":           certSchema(),
-							"k8s":            certSchema(),
-							"k8s_aggregator": certSchema(),
-							"k8s_serviceaccount": schema.SingleNestedAttribute{
-								Attributes: map[string]schema.Attribute{
-									"key": schema.StringAttribute{
-										Description: "The service account key",
-										Computed:    true,
-										Sensitive:   true,
-									},
-								},
-								Description: "The service account secrets",
-								Computed:    true,
-							},
-							"os": certSchema(),
-						},
-						Computed: true,
-					},
+					"etcd":           caCertificateSchema("etcd"),
+					"k8s":            caCertificateSchema("Kubernetes API"),
+					"k8s_aggregator": caCertificateSchema("Kubernetes aggregation layer"),
+					"os":             caCertificateSchema("Talos API"),
+				},
+				Optional: true,
+				Description: "Existing CAs, e.g. intermediate CAs issued by a corporate PKI, to use instead of generating them. " +
+					"The CAs which are not set are generated, changing them generates new machine secrets",
+				PlanModifiers: []planmodifier.Object{
+					objectplanmodifier.RequiresReplace(),
+				},
+			},
+			"triggers": schema.MapAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Description: "A map of arbitrary values that, when changed, will generate new machine secrets (e.g. a yearly rotation date)",
+				PlanModifiers: []planmodifier.Map{
+					mapplanmodifier.RequiresReplace(),
 				},
-				Computed: true,
 			},
 			"client_configuration": schema.SingleNestedAttribute{
 				Attributes: map[string]schema.Attribute{
@@ -229,6 +213,82 @@ func (r *talosMachineSecretsResource) Schema(_ context.Context, _ resource.Schem
 	}
 }
 
+func machineSecretsSchema(description string) schema.SingleNestedAttribute {
+	return schema.SingleNestedAttribute{
+		Description: description,
+		Attributes: map[string]schema.Attribute{
+			"cluster": schema.SingleNestedAttribute{
+				Attributes: map[string]schema.Attribute{
+					"id": schema.StringAttribute{
+						Description: "The cluster ID",
+						Computed:    true,
+					},
+					"secret": schema.StringAttribute{
+						Description: "The cluster secret",
+						Computed:    true,
+						Sensitive:   true,
+					},
+				},
+				Description: "The cluster secrets",
+				Computed:    true,
+			},
+			"secrets": schema.SingleNestedAttribute{
+				Attributes: map[string]schema.Attribute{
+					"bootstrap_token": schema.StringAttribute{
+						Description: "The bootstrap token",
+						Computed:    true,
+						Sensitive:   true,
+					},
+					"secretbox_encryption_secret": schema.StringAttribute{
+						Description: "The secretbox encryption secret",
+						Computed:    true,
+						Sensitive:   true,
+					},
+					"aescbc_encryption_secret": schema.StringAttribute{
+						Description: "The AES-CBC encryption secret",
+						Computed:    true,
+						Sensitive:   true,
+					},
+				},
+				Description: "kubernetes cluster secrets",
+				Computed:    true,
+			},
+			"trustdinfo": schema.SingleNestedAttribute{
+				Attributes: map[string]schema.Attribute{
+					"token": schema.StringAttribute{
+						Description: "The trustd token",
+						Computed:    true,
+						Sensitive:   true,
+					},
+				},
+				Description: "trustd secrets",
+				Computed:    true,
+			},
+			"certs": schema.SingleNestedAttribute{
+				Attributes: map[string]schema.Attribute{
+					"etcd":           certSchema(),
+					"k8s":            certSchema(),
+					"k8s_aggregator": certSchema(),
+					"k8s_serviceaccount": schema.SingleNestedAttribute{
+						Attributes: map[string]schema.Attribute{
+							"key": schema.StringAttribute{
+								Description: "The service account key",
+								Computed:    true,
+								Sensitive:   true,
+							},
+						},
+						Description: "The service account secrets",
+						Computed:    true,
+					},
+					"os": certSchema(),
+				},
+				Computed: true,
+			},
+		},
+		Computed: true,
+	}
+}
+
 func certSchema() schema.SingleNestedAttribute {
 	return schema.SingleNestedAttribute{
 		Description: "The certificate and key pair",
@@ -247,6 +307,24 @@ func certSchema() schema.SingleNestedAttribute {
 	}
 }
 
+func caCertificateSchema(name string) schema.SingleNestedAttribute {
+	return schema.SingleNestedAttribute{
+		Description: fmt.Sprintf("The %s CA", name),
+		Attributes: map[string]schema.Attribute{
+			"cert": schema.StringAttribute{
+				Description: "The PEM encoded CA certificate",
+				Required:    true,
+			},
+			"key": schema.StringAttribute{
+				Description: "The PEM encoded CA key (RSA, ECDSA or Ed25519)",
+				Required:    true,
+				Sensitive:   true,
+			},
+		},
+		Optional: true,
+	}
+}
+
 func (r *talosMachineSecretsResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	var obj types.Object
 
@@ -289,6 +367,12 @@ func (r *talosMachineSecretsResource) Create(ctx context.Context, req resource.C
 		return
 	}
 
+	if err = setCACertificates(secretsBundle, plan.CACertificates); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("ca_certificates"), "invalid CA certificates", err.Error())
+
+		return
+	}
+
 	state, err := secretsBundleTomachineSecrets(secretsBundle)
 	if err != nil {
 		resp.Diagnostics.AddError("failed to convert secrets bundle to machine secrets", err.Error())
@@ -297,6 +381,15 @@ func (r *talosMachineSecretsResource) Create(ctx context.Context, req resource.C
 	}
 
 	state.TalosVersion = plan.TalosVersion
+	state.WorkerJoinTTL = plan.WorkerJoinTTL
+	state.CACertificates = plan.CACertificates
+	state.Triggers = plan.Triggers
+
+	if err = state.setWorkerJoinToken(OverridableTimeFunc()); err != nil {
+		resp.Diagnostics.AddError("failed to generate worker bootstrap token", err.Error())
+
+		return
+	}
 
 	// Set state to fully populated data
 	diags = resp.State.Set(ctx, &state)
@@ -316,6 +409,62 @@ func (r *talosMachineSecretsResource) ModifyPlan(ctx context.Context, req resour
 		return
 	}
 
+	var workerJoinTTL types.String
+
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("worker_join_ttl"), &workerJoinTTL)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !workerJoinTTL.IsNull() && !workerJoinTTL.IsUnknown() {
+		if ttl, err := time.ParseDuration(workerJoinTTL.ValueString()); err != nil || ttl <= 0 {
+			resp.Diagnostics.AddAttributeError(path.Root("worker_join_ttl"), "invalid worker_join_ttl", "worker_join_ttl must be a positive duration, e.g. 24h")
+
+			return
+		}
+	}
+
+	// populate the worker machine secrets of the resources created before they were introduced
+	if !req.State.Raw.IsNull() {
+		var workerMachineSecretsObj types.Object
+
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("worker_machine_secrets"), &workerMachineSecretsObj)...)
+
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		if workerMachineSecretsObj.IsNull() {
+			resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("worker_machine_secrets"), types.ObjectUnknown(workerMachineSecretsObj.AttributeTypes(ctx)))...)
+
+			if resp.Diagnostics.HasError() {
+				return
+			}
+		}
+
+		var priorWorkerJoinTTL, workerJoinExpiresAt types.String
+
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("worker_join_ttl"), &priorWorkerJoinTTL)...)
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("worker_join_expires_at"), &workerJoinExpiresAt)...)
+
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		if !workerJoinTTL.Equal(priorWorkerJoinTTL) || (!workerJoinTTL.IsNull() && workerJoinTokenExpired(workerJoinExpiresAt, OverridableTimeFunc())) {
+			tflog.Info(ctx, "worker bootstrap token changed or expired, needs regeneration")
+
+			resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("worker_machine_secrets").AtName("secrets").AtName("bootstrap_token"), types.StringUnknown())...)
+			resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("worker_join_expires_at"), types.StringUnknown())...)
+			resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("worker_join_config_patch"), types.StringUnknown())...)
+
+			if resp.Diagnostics.HasError() {
+				return
+			}
+		}
+	}
+
 	clientConfigurationPath := path.Root("client_configuration")
 
 	var obj types.Object
@@ -397,6 +546,70 @@ func (r *talosMachineSecretsResource) Update(ctx context.Context, req resource.U
 		return
 	}
 
+	var machineSecretsObj types.Object
+
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("machine_secrets"), &machineSecretsObj)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var machineSecretsData machineSecrets
+
+	diags = machineSecretsObj.As(ctx, &machineSecretsData, basetypes.ObjectAsOptions{
+		UnhandledNullAsEmpty:    true,
+		UnhandledUnknownAsEmpty: true,
+	})
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var (
+		prior                   talosMachineSecretsResourceModelV1
+		workerMachineSecretsObj types.Object
+	)
+
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("worker_machine_secrets"), &workerMachineSecretsObj)...)
+	resp.Diagnostics.Append(workerMachineSecretsObj.As(ctx, &prior.WorkerMachineSecrets, basetypes.ObjectAsOptions{
+		UnhandledNullAsEmpty:    true,
+		UnhandledUnknownAsEmpty: true,
+	})...)
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("worker_join_ttl"), &prior.WorkerJoinTTL)...)
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("worker_join_expires_at"), &prior.WorkerJoinExpiresAt)...)
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("worker_join_config_patch"), &prior.WorkerJoinConfigPatch)...)
+
+	workerJoin := talosMachineSecretsResourceModelV1{
+		WorkerMachineSecrets: workerMachineSecrets(machineSecretsData),
+	}
+
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("worker_join_ttl"), &workerJoin.WorkerJoinTTL)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// keep the worker bootstrap token until the TTL changes or the token expires
+	if !workerJoin.WorkerJoinTTL.IsNull() && workerJoin.WorkerJoinTTL.Equal(prior.WorkerJoinTTL) && !workerJoinTokenExpired(prior.WorkerJoinExpiresAt, OverridableTimeFunc()) {
+		workerJoin.WorkerMachineSecrets.Secrets.BootstrapToken = prior.WorkerMachineSecrets.Secrets.BootstrapToken
+		workerJoin.WorkerJoinExpiresAt = prior.WorkerJoinExpiresAt
+		workerJoin.WorkerJoinConfigPatch = prior.WorkerJoinConfigPatch
+	} else if err := workerJoin.setWorkerJoinToken(OverridableTimeFunc()); err != nil {
+		resp.Diagnostics.AddError("failed to generate worker bootstrap token", err.Error())
+
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("worker_machine_secrets"), workerJoin.WorkerMachineSecrets)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("worker_join_ttl"), workerJoin.WorkerJoinTTL)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("worker_join_expires_at"), workerJoin.WorkerJoinExpiresAt)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("worker_join_config_patch"), workerJoin.WorkerJoinConfigPatch)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	clientConfigurationPath := path.Root("client_configuration")
 
 	var obj types.Object
@@ -499,6 +712,49 @@ func (r *talosMachineSecretsResource) Update(ctx context.Context, req resource.U
 	}
 }
 
+// setWorkerJoinToken replaces the bootstrap token of the worker machine secrets with a dedicated one expiring after the worker join TTL.
+func (m *talosMachineSecretsResourceModelV1) setWorkerJoinToken(now time.Time) error {
+	if m.WorkerJoinTTL.IsNull() || m.WorkerJoinTTL.IsUnknown() {
+		m.WorkerJoinExpiresAt = types.StringNull()
+		m.WorkerJoinConfigPatch = types.StringNull()
+
+		return nil
+	}
+
+	ttl, err := time.ParseDuration(m.WorkerJoinTTL.ValueString())
+	if err != nil {
+		return err
+	}
+
+	token, err := genBootstrapToken()
+	if err != nil {
+		return err
+	}
+
+	expiresAt := now.Add(ttl).UTC()
+
+	configPatch, err := workerJoinConfigPatch(token, expiresAt)
+	if err != nil {
+		return err
+	}
+
+	m.WorkerMachineSecrets.Secrets.BootstrapToken = types.StringValue(token)
+	m.WorkerJoinExpiresAt = types.StringValue(expiresAt.Format(time.RFC3339))
+	m.WorkerJoinConfigPatch = types.StringValue(configPatch)
+
+	return nil
+}
+
+// workerJoinTokenExpired returns true if the worker bootstrap token expiring at expiresAt is expired (or the expiration is unknown).
+func workerJoinTokenExpired(expiresAt types.String, now time.Time) bool {
+	t, err := time.Parse(time.RFC3339, expiresAt.ValueString())
+	if err != nil {
+		return true
+	}
+
+	return !now.Before(t)
+}
+
 func (r *talosMachineSecretsResource) Delete(_ context.Context, _ resource.DeleteRequest, _ *resource.DeleteResponse) {
 }
 
@@ -639,3 +895,144 @@ func (r *talosMachineSecretsResource) ImportState(ctx context.Context, req resou
 		return
 	}
 }
+
+// workerMachineSecrets returns the subset of the machine secrets required to generate worker machine configuration.
+//
+// Worker machine configuration only embeds the OS and Kubernetes CA certificates, so the CA keys and the control plane only secrets are left empty.
+func workerMachineSecrets(in machineSecrets) machineSecrets {
+	empty := types.StringValue("")
+
+	return machineSecrets{
+		Cluster: in.Cluster,
+		Secrets: machineSecretsSecrets{
+			BootstrapToken:            in.Secrets.BootstrapToken,
+			SecretboxEncryptionSecret: empty,
+		},
+		TrustdInfo: in.TrustdInfo,
+		Certs: machineSecretsCerts{
+			Etcd: machineSecretsCertKeyPair{
+				Cert: empty,
+				Key:  empty,
+			},
+			K8s: machineSecretsCertKeyPair{
+				Cert: in.Certs.K8s.Cert,
+				Key:  empty,
+			},
+			K8sAggregator: machineSecretsCertKeyPair{
+				Cert: empty,
+				Key:  empty,
+			},
+			K8sServiceAccount: machineSecretsCertsK8sServiceAccount{
+				Key: empty,
+			},
+			OS: machineSecretsCertKeyPair{
+				Cert: in.Certs.OS.Cert,
+				Key:  empty,
+			},
+		},
+	}
+}
+
+// workerJoinConfigPatch returns the control plane config patch registering the bootstrap token as a Kubernetes bootstrap token expiring at expiresAt.
+func workerJoinConfigPatch(token string, expiresAt time.Time) (string, error) {
+	tokenID, tokenSecret, ok := strings.Cut(token, ".")
+	if !ok {
+		return "", fmt.Errorf("invalid bootstrap token format")
+	}
+
+	manifest, err := yaml.Marshal(map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata": map[string]any{
+			"name":      "bootstrap-token-" + tokenID,
+			"namespace": "kube-system",
+		},
+		"type": "bootstrap.kubernetes.io/token",
+		"stringData": map[string]any{
+			"token-id":                       tokenID,
+			"token-secret":                   tokenSecret,
+			"expiration":                     expiresAt.UTC().Format(time.RFC3339),
+			"usage-bootstrap-authentication": "true",
+			"auth-extra-groups":              "system:bootstrappers:nodes",
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	patch, err := yaml.Marshal(map[string]any{
+		"cluster": map[string]any{
+			"inlineManifests": []any{
+				map[string]any{
+					"name":     "worker-join-token",
+					"contents": string(manifest),
+				},
+			},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return string(patch), nil
+}
+
+// setCACertificates replaces the generated CAs of the secrets bundle with the existing CAs which are set.
+func setCACertificates(secretsBundle *secrets.Bundle, caCertificates *machineSecretsCACertificates) error {
+	if caCertificates == nil {
+		return nil
+	}
+
+	for _, ca := range []struct {
+		name   string
+		pair   *machineSecretsCertKeyPair
+		target **talosx509.PEMEncodedCertificateAndKey
+	}{
+		{"etcd", caCertificates.Etcd, &secretsBundle.Certs.Etcd},
+		{"k8s", caCertificates.K8s, &secretsBundle.Certs.K8s},
+		{"k8s_aggregator", caCertificates.K8sAggregator, &secretsBundle.Certs.K8sAggregator},
+		{"os", caCertificates.OS, &secretsBundle.Certs.OS},
+	} {
+		if ca.pair == nil {
+			continue
+		}
+
+		certificateAuthority, err := certificateAuthorityFromPEM(ca.pair.Cert.ValueString(), ca.pair.Key.ValueString())
+		if err != nil {
+			return fmt.Errorf("%s: %w", ca.name, err)
+		}
+
+		*ca.target = certificateAuthority
+	}
+
+	return nil
+}
+
+// certificateAuthorityFromPEM returns the CA of the PEM encoded certificate and key, checking the certificate is a CA and the key matches it.
+func certificateAuthorityFromPEM(cert, key string) (*talosx509.PEMEncodedCertificateAndKey, error) {
+	pair := &talosx509.PEMEncodedCertificateAndKey{
+		Crt: []byte(cert),
+		Key: []byte(key),
+	}
+
+	ca, err := talosx509.NewCertificateAuthorityFromCertificateAndKey(pair)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ca.Crt.IsCA {
+		return nil, errors.New("the certificate is not a CA certificate")
+	}
+
+	signer, ok := ca.Key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported key type %T", ca.Key)
+	}
+
+	publicKey, ok := signer.Public().(interface{ Equal(crypto.PublicKey) bool })
+	if !ok || !publicKey.Equal(ca.Crt.PublicKey) {
+		return nil, errors.New("the key doesn't match the certificate")
+	}
+
+	return pair, nil
+}
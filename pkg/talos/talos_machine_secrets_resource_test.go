@@ -6,12 +6,20 @@ package talos_test
 
 import (
 	"fmt"
+	"regexp"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/siderolabs/crypto/x509"
+	"github.com/siderolabs/talos/pkg/machinery/config/configpatcher"
+	"github.com/siderolabs/talos/pkg/machinery/config/generate/secrets"
 	"github.com/siderolabs/talos/pkg/machinery/gendata"
+	"github.com/siderolabs/talos/pkg/machinery/role"
 	"golang.org/x/mod/semver"
 
 	"github.com/siderolabs/terraform-provider-talos/pkg/talos"
@@ -434,6 +442,162 @@ func TestAccTalosMachineSecretsResourceUpgrade3(t *testing.T) { //nolint:dupl
 	})
 }
 
+func TestAccTalosMachineSecretsResourceWorkerMachineSecrets(t *testing.T) {
+	resource.ParallelTest(t, resource.TestCase{
+		IsUnitTest:               true, // this is a local only resource, so can be unit tested
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccTalosMachineSecretsResourceWorkerMachineSecretsConfig("worker"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrPair("talos_machine_secrets.this", "worker_machine_secrets.cluster.id", "talos_machine_secrets.this", "machine_secrets.cluster.id"),
+					resource.TestCheckResourceAttrPair("talos_machine_secrets.this", "worker_machine_secrets.secrets.bootstrap_token", "talos_machine_secrets.this", "machine_secrets.secrets.bootstrap_token"),
+					resource.TestCheckResourceAttrPair("talos_machine_secrets.this", "worker_machine_secrets.trustdinfo.token", "talos_machine_secrets.this", "machine_secrets.trustdinfo.token"),
+					resource.TestCheckResourceAttrPair("talos_machine_secrets.this", "worker_machine_secrets.certs.os.cert", "talos_machine_secrets.this", "machine_secrets.certs.os.cert"),
+					resource.TestCheckResourceAttrPair("talos_machine_secrets.this", "worker_machine_secrets.certs.k8s.cert", "talos_machine_secrets.this", "machine_secrets.certs.k8s.cert"),
+					resource.TestCheckResourceAttr("talos_machine_secrets.this", "worker_machine_secrets.secrets.secretbox_encryption_secret", ""),
+					resource.TestCheckResourceAttr("talos_machine_secrets.this", "worker_machine_secrets.certs.etcd.key", ""),
+					resource.TestCheckResourceAttr("talos_machine_secrets.this", "worker_machine_secrets.certs.k8s.key", ""),
+					resource.TestCheckResourceAttr("talos_machine_secrets.this", "worker_machine_secrets.certs.k8s_aggregator.key", ""),
+					resource.TestCheckResourceAttr("talos_machine_secrets.this", "worker_machine_secrets.certs.k8s_serviceaccount.key", ""),
+					resource.TestCheckResourceAttr("talos_machine_secrets.this", "worker_machine_secrets.certs.os.key", ""),
+					resource.TestCheckResourceAttrSet("data.talos_machine_configuration.this", "machine_configuration"),
+				),
+			},
+			{
+				Config:      testAccTalosMachineSecretsResourceWorkerMachineSecretsConfig("controlplane"),
+				ExpectError: regexp.MustCompile("machine_secrets are incomplete"),
+			},
+		},
+	})
+}
+
+func TestAccTalosMachineSecretsResourceWorkerJoinTTL(t *testing.T) {
+	resource.ParallelTest(t, resource.TestCase{
+		IsUnitTest:               true, // this is a local only resource, so can be unit tested
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccTalosMachineSecretsResourceWorkerJoinTTLConfig("1h"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("talos_machine_secrets.this", "worker_join_ttl", "1h"),
+					resource.TestMatchResourceAttr("talos_machine_secrets.this", "worker_machine_secrets.secrets.bootstrap_token", regexp.MustCompile(`^[a-z0-9]{6}\.[a-z0-9]{16}$`)),
+					resource.TestCheckResourceAttrSet("talos_machine_secrets.this", "worker_join_expires_at"),
+					resource.TestMatchResourceAttr("talos_machine_secrets.this", "worker_join_config_patch", regexp.MustCompile("bootstrap.kubernetes.io/token")),
+					func(s *terraform.State) error {
+						attrs := s.RootModule().Resources["talos_machine_secrets.this"].Primary.Attributes
+
+						if attrs["worker_machine_secrets.secrets.bootstrap_token"] == attrs["machine_secrets.secrets.bootstrap_token"] {
+							return fmt.Errorf("expected the worker bootstrap token to differ from the cluster bootstrap token")
+						}
+
+						return nil
+					},
+				),
+			},
+			{
+				Config:   testAccTalosMachineSecretsResourceWorkerJoinTTLConfig("1h"),
+				PlanOnly: true,
+			},
+			{
+				Config: testAccTalosMachineSecretsResourceWorkerMachineSecretsConfig("worker"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckNoResourceAttr("talos_machine_secrets.this", "worker_join_ttl"),
+					resource.TestCheckNoResourceAttr("talos_machine_secrets.this", "worker_join_expires_at"),
+					resource.TestCheckNoResourceAttr("talos_machine_secrets.this", "worker_join_config_patch"),
+					resource.TestCheckResourceAttrPair("talos_machine_secrets.this", "worker_machine_secrets.secrets.bootstrap_token", "talos_machine_secrets.this", "machine_secrets.secrets.bootstrap_token"),
+				),
+			},
+			{
+				Config:      testAccTalosMachineSecretsResourceWorkerJoinTTLConfig("-1h"),
+				ExpectError: regexp.MustCompile("invalid worker_join_ttl"),
+			},
+		},
+	})
+}
+
+func TestAccTalosMachineSecretsResourceTriggers(t *testing.T) {
+	var clusterSecret string
+
+	resource.ParallelTest(t, resource.TestCase{
+		IsUnitTest:               true, // this is a local only resource, so can be unit tested
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccTalosMachineSecretsResourceTriggersConfig("2024"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("talos_machine_secrets.this", "triggers.rotation", "2024"),
+					func(s *terraform.State) error {
+						clusterSecret = s.RootModule().Resources["talos_machine_secrets.this"].Primary.Attributes["machine_secrets.cluster.secret"]
+
+						return nil
+					},
+				),
+			},
+			{
+				Config:   testAccTalosMachineSecretsResourceTriggersConfig("2024"),
+				PlanOnly: true,
+			},
+			{
+				Config: testAccTalosMachineSecretsResourceTriggersConfig("2025"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("talos_machine_secrets.this", plancheck.ResourceActionReplace),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("talos_machine_secrets.this", "triggers.rotation", "2025"),
+					func(s *terraform.State) error {
+						if s.RootModule().Resources["talos_machine_secrets.this"].Primary.Attributes["machine_secrets.cluster.secret"] == clusterSecret {
+							return fmt.Errorf("expected the machine secrets to be regenerated")
+						}
+
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func testAccTalosMachineSecretsResourceTriggersConfig(rotation string) string {
+	return fmt.Sprintf(`
+resource "talos_machine_secrets" "this" {
+	triggers = {
+		rotation = "%s"
+	}
+}
+`, rotation)
+}
+
+func testAccTalosMachineSecretsResourceWorkerJoinTTLConfig(ttl string) string {
+	return fmt.Sprintf(`
+resource "talos_machine_secrets" "this" {
+	worker_join_ttl = "%s"
+}
+
+data "talos_machine_configuration" "this" {
+	cluster_name     = "example-cluster"
+	machine_type     = "worker"
+	cluster_endpoint = "https://cluster.local:6443"
+	machine_secrets  = talos_machine_secrets.this.worker_machine_secrets
+}
+`, ttl)
+}
+
+func testAccTalosMachineSecretsResourceWorkerMachineSecretsConfig(machineType string) string {
+	return fmt.Sprintf(`
+resource "talos_machine_secrets" "this" {}
+
+data "talos_machine_configuration" "this" {
+	cluster_name     = "example-cluster"
+	machine_type     = "%s"
+	cluster_endpoint = "https://cluster.local:6443"
+	machine_secrets  = talos_machine_secrets.this.worker_machine_secrets
+}
+`, machineType)
+}
+
 func testAccTalosMachineSecretsResourceConfig(talosConfigVersion string) string {
 	if talosConfigVersion != "" {
 		return fmt.Sprintf(`
@@ -447,3 +611,175 @@ resource "talos_machine_secrets" "this" {
 resource "talos_machine_secrets" "this" {}
 `
 }
+
+func TestWorkerJoinConfigPatch(t *testing.T) {
+	t.Parallel()
+
+	token, err := talos.GenBootstrapToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !regexp.MustCompile(`^[a-z0-9]{6}\.[a-z0-9]{16}$`).MatchString(token) {
+		t.Fatalf("invalid bootstrap token %q", token)
+	}
+
+	expiresAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	patch, err := talos.WorkerJoinConfigPatch(token, expiresAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = configpatcher.LoadPatch([]byte(patch)); err != nil {
+		t.Fatalf("failed to load config patch: %s", err)
+	}
+
+	tokenID, tokenSecret, _ := strings.Cut(token, ".")
+
+	for _, expected := range []string{
+		"name: bootstrap-token-" + tokenID,
+		"token-secret: " + tokenSecret,
+		"expiration: \"2024-01-02T03:04:05Z\"",
+	} {
+		if !strings.Contains(patch, expected) {
+			t.Errorf("expected config patch to contain %q, got:\n%s", expected, patch)
+		}
+	}
+
+	if !talos.WorkerJoinTokenExpired(types.StringValue(expiresAt.Format(time.RFC3339)), expiresAt) {
+		t.Error("expected token to be expired at its expiration time")
+	}
+
+	if talos.WorkerJoinTokenExpired(types.StringValue(expiresAt.Format(time.RFC3339)), expiresAt.Add(-time.Minute)) {
+		t.Error("expected token to be valid before its expiration time")
+	}
+
+	if !talos.WorkerJoinTokenExpired(types.StringNull(), expiresAt) {
+		t.Error("expected token without expiration to be expired")
+	}
+}
+
+func TestCertificateAuthorityFromPEM(t *testing.T) {
+	t.Parallel()
+
+	secretsBundle, err := secrets.NewBundle(secrets.NewFixedClock(time.Now()), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ecdsaCA, err := x509.NewSelfSignedCertificateAuthority(x509.ECDSA(true), x509.Organization("corp"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clientCert, err := secretsBundle.GenerateTalosAPIClientCertificate(role.MakeSet(role.Admin))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name          string
+		cert          []byte
+		key           []byte
+		expectedError string
+	}{
+		{
+			name: "ed25519 CA",
+			cert: secretsBundle.Certs.OS.Crt,
+			key:  secretsBundle.Certs.OS.Key,
+		},
+		{
+			name: "ecdsa CA",
+			cert: ecdsaCA.CrtPEM,
+			key:  ecdsaCA.KeyPEM,
+		},
+		{
+			name:          "mismatched key",
+			cert:          secretsBundle.Certs.OS.Crt,
+			key:           ecdsaCA.KeyPEM,
+			expectedError: "the key doesn't match the certificate",
+		},
+		{
+			name:          "not a CA",
+			cert:          clientCert.Crt,
+			key:           clientCert.Key,
+			expectedError: "the certificate is not a CA certificate",
+		},
+		{
+			name:          "invalid PEM",
+			cert:          []byte("not a certificate"),
+			key:           secretsBundle.Certs.OS.Key,
+			expectedError: "failed to parse PEM block",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ca, err := talos.CertificateAuthorityFromPEM(string(tt.cert), string(tt.key))
+
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Fatalf("expected error %q, got %v", tt.expectedError, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(ca.Crt) != string(tt.cert) || string(ca.Key) != string(tt.key) {
+				t.Errorf("expected the CA to be the given certificate and key")
+			}
+		})
+	}
+}
+
+func TestSetCACertificates(t *testing.T) {
+	t.Parallel()
+
+	secretsBundle, err := secrets.NewBundle(secrets.NewFixedClock(time.Now()), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ca, err := x509.NewSelfSignedCertificateAuthority(x509.ECDSA(true), x509.Organization("corp"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	generatedEtcd := secretsBundle.Certs.Etcd
+
+	if err = talos.SetCACertificates(secretsBundle, &talos.MachineSecretsCACertificates{
+		OS: &talos.MachineSecretsCertKeyPair{
+			Cert: types.StringValue(string(ca.CrtPEM)),
+			Key:  types.StringValue(string(ca.KeyPEM)),
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if string(secretsBundle.Certs.OS.Crt) != string(ca.CrtPEM) {
+		t.Errorf("expected the Talos API CA to be replaced")
+	}
+
+	if secretsBundle.Certs.Etcd != generatedEtcd {
+		t.Errorf("expected the etcd CA to be kept")
+	}
+
+	if _, err = talos.SecretsBundleToMachineSecrets(secretsBundle); err != nil {
+		t.Fatalf("failed to generate the client configuration from the existing CA: %s", err)
+	}
+
+	err = talos.SetCACertificates(secretsBundle, &talos.MachineSecretsCACertificates{
+		K8s: &talos.MachineSecretsCertKeyPair{
+			Cert: types.StringValue(string(ca.CrtPEM)),
+			Key:  types.StringValue(string(secretsBundle.Certs.K8s.Key)),
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "k8s: the key doesn't match the certificate") {
+		t.Errorf("expected a mismatched k8s key error, got %v", err)
+	}
+}
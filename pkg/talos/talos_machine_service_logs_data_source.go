@@ -0,0 +1,234 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
+	"github.com/siderolabs/talos/pkg/machinery/api/common"
+	"github.com/siderolabs/talos/pkg/machinery/client"
+	"github.com/siderolabs/talos/pkg/machinery/constants"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// defaultServiceLogsTailLines is the default number of log lines fetched by the service logs data source.
+const defaultServiceLogsTailLines = 100
+
+type talosMachineServiceLogsDataSource struct {
+	providerData *talosProviderData
+}
+
+type talosMachineServiceLogsDataSourceModelV0 struct { //nolint:govet
+	ID                  types.String         `tfsdk:"id"`
+	Node                types.String         `tfsdk:"node"`
+	Endpoint            types.String         `tfsdk:"endpoint"`
+	ClientConfiguration *clientConfiguration `tfsdk:"client_configuration"`
+	Service             types.String         `tfsdk:"service"`
+	TailLines           types.Int64          `tfsdk:"tail_lines"`
+	Logs                types.String         `tfsdk:"logs"`
+	Timeouts            timeouts.Value       `tfsdk:"timeouts"`
+}
+
+var (
+	_ datasource.DataSource              = &talosMachineServiceLogsDataSource{}
+	_ datasource.DataSourceWithConfigure = &talosMachineServiceLogsDataSource{}
+)
+
+// NewTalosMachineServiceLogsDataSource implements the datasource.DataSource interface.
+func NewTalosMachineServiceLogsDataSource() datasource.DataSource {
+	return &talosMachineServiceLogsDataSource{}
+}
+
+func (d *talosMachineServiceLogsDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_machine_service_logs"
+}
+
+func (d *talosMachineServiceLogsDataSource) Schema(ctx context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Fetches the last lines of the logs of a Talos service of a node, e.g. to investigate a crash-looping kubelet or etcd without leaving Terraform",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The generated ID of this resource",
+				Computed:    true,
+			},
+			"node": schema.StringAttribute{
+				Required:    true,
+				Description: "node to fetch the service logs from",
+			},
+			"endpoint": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "endpoint to use for the talosclient. If not set, the node value will be used",
+			},
+			"client_configuration": schema.SingleNestedAttribute{
+				Attributes: map[string]schema.Attribute{
+					"ca_certificate": schema.StringAttribute{
+						Required:    true,
+						Description: "The client CA certificate",
+					},
+					"client_certificate": schema.StringAttribute{
+						Required:    true,
+						Description: "The client certificate",
+					},
+					"client_key": schema.StringAttribute{
+						Required:    true,
+						Sensitive:   true,
+						Description: "The client key",
+					},
+				},
+				Optional:    true,
+				Description: "The client configuration data, defaults to the credentials of the provider `talosconfig_path`",
+			},
+			"service": schema.StringAttribute{
+				Required:    true,
+				Description: "The ID of the service to fetch the logs of (e.g. etcd, kubelet, apid, trustd, ext-<name> for extension services)",
+			},
+			"tail_lines": schema.Int64Attribute{
+				Optional:    true,
+				Description: "The number of lines to fetch from the end of the logs. Default is 100.",
+				Validators: []validator.Int64{
+					int64validator.Between(1, math.MaxInt32),
+				},
+			},
+			"logs": schema.StringAttribute{
+				Computed:    true,
+				Description: "The last lines of the service logs",
+			},
+			"timeouts": timeouts.Attributes(ctx, timeouts.Opts{
+				Read: true,
+			}),
+		},
+	}
+}
+
+func (d *talosMachineServiceLogsDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*talosProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"failed to get provider data",
+			fmt.Sprintf("Expected *talosProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.providerData = providerData
+}
+
+func (d *talosMachineServiceLogsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var obj types.Object
+
+	diags := req.Config.Get(ctx, &obj)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var state talosMachineServiceLogsDataSourceModelV0
+	diags = obj.As(ctx, &state, basetypes.ObjectAsOptions{
+		UnhandledNullAsEmpty:    true,
+		UnhandledUnknownAsEmpty: true,
+	})
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	talosConfig, err := d.providerData.talosClientConfig("dynamic", state.ClientConfiguration)
+	if err != nil {
+		resp.Diagnostics.AddError("failed to generate talos config", err.Error())
+
+		return
+	}
+
+	if state.Endpoint.IsNull() {
+		state.Endpoint = state.Node
+	}
+
+	if d.providerData.deferRead(ctx, req, resp, state.Endpoint) {
+		return
+	}
+
+	tailLines := int32(defaultServiceLogsTailLines)
+
+	if !state.TailLines.IsNull() {
+		tailLines = int32(state.TailLines.ValueInt64()) //nolint:gosec // validated to fit in an int32
+	}
+
+	readTimeout, diags := state.Timeouts.Read(ctx, 10*time.Minute)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctxDeadline, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
+	if err := d.providerData.retryContext(ctxDeadline, readTimeout, func() *retry.RetryError {
+		if err := talosClientOp(d.providerData.withClientOptions(ctx), state.Endpoint.ValueString(), state.Node.ValueString(), talosConfig, func(nodeCtx context.Context, c *client.Client) error {
+			stream, err := c.Logs(nodeCtx, constants.SystemContainerdNamespace, common.ContainerDriver_CONTAINERD, state.Service.ValueString(), false, tailLines)
+			if err != nil {
+				return err
+			}
+
+			r, err := client.ReadStream(stream)
+			if err != nil {
+				return err
+			}
+
+			defer r.Close() //nolint:errcheck
+
+			logs, err := io.ReadAll(r)
+			if err != nil {
+				return err
+			}
+
+			state.Logs = basetypes.NewStringValue(string(logs))
+
+			return nil
+		}); err != nil {
+			if s := status.Code(err); s == codes.InvalidArgument {
+				return retry.NonRetryableError(err)
+			}
+
+			return retry.RetryableError(err)
+		}
+
+		return nil
+	}); err != nil {
+		resp.Diagnostics.AddError("failed to get service logs", err.Error())
+
+		return
+	}
+
+	state.ID = basetypes.NewStringValue("machine_service_logs")
+
+	diags = resp.State.Set(ctx, state)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
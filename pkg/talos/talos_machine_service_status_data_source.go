@@ -0,0 +1,259 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
+	"github.com/siderolabs/talos/pkg/machinery/client"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type talosMachineServiceStatusDataSource struct {
+	providerData *talosProviderData
+}
+
+type talosMachineServiceStatusDataSourceModelV0 struct { //nolint:govet
+	ID                  types.String         `tfsdk:"id"`
+	Node                types.String         `tfsdk:"node"`
+	Endpoint            types.String         `tfsdk:"endpoint"`
+	ClientConfiguration *clientConfiguration `tfsdk:"client_configuration"`
+	Services            []types.String       `tfsdk:"services"`
+	Statuses            []talosServiceStatus `tfsdk:"statuses"`
+	Timeouts            timeouts.Value       `tfsdk:"timeouts"`
+}
+
+type talosServiceStatus struct {
+	ID            types.String `tfsdk:"id"`
+	State         types.String `tfsdk:"state"`
+	Healthy       types.Bool   `tfsdk:"healthy"`
+	HealthMessage types.String `tfsdk:"health_message"`
+}
+
+var (
+	_ datasource.DataSource              = &talosMachineServiceStatusDataSource{}
+	_ datasource.DataSourceWithConfigure = &talosMachineServiceStatusDataSource{}
+)
+
+// NewTalosMachineServiceStatusDataSource implements the datasource.DataSource interface.
+func NewTalosMachineServiceStatusDataSource() datasource.DataSource {
+	return &talosMachineServiceStatusDataSource{}
+}
+
+func (d *talosMachineServiceStatusDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_machine_service_status"
+}
+
+func (d *talosMachineServiceStatusDataSource) Schema(ctx context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Waits for the Talos services of a node to be running and healthy",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The generated ID of this resource",
+				Computed:    true,
+			},
+			"node": schema.StringAttribute{
+				Required:    true,
+				Description: "node to wait for the services on",
+			},
+			"endpoint": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "endpoint to use for the talosclient. If not set, the node value will be used",
+			},
+			"client_configuration": schema.SingleNestedAttribute{
+				Attributes: map[string]schema.Attribute{
+					"ca_certificate": schema.StringAttribute{
+						Required:    true,
+						Description: "The client CA certificate",
+					},
+					"client_certificate": schema.StringAttribute{
+						Required:    true,
+						Description: "The client certificate",
+					},
+					"client_key": schema.StringAttribute{
+						Required:    true,
+						Sensitive:   true,
+						Description: "The client key",
+					},
+				},
+				Optional:    true,
+				Description: "The client configuration data, defaults to the credentials of the provider `talosconfig_path`",
+			},
+			"services": schema.ListAttribute{
+				ElementType: types.StringType,
+				Required:    true,
+				Description: "The IDs of the services to wait for (e.g. etcd, kubelet, apid, trustd, ext-<name> for extension services)",
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+				},
+			},
+			"statuses": schema.ListNestedAttribute{
+				Description: "The statuses of the services",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							Description: "The ID of the service",
+							Computed:    true,
+						},
+						"state": schema.StringAttribute{
+							Description: "The state of the service",
+							Computed:    true,
+						},
+						"healthy": schema.BoolAttribute{
+							Description: "Whether the service is healthy (services without health checks are considered healthy)",
+							Computed:    true,
+						},
+						"health_message": schema.StringAttribute{
+							Description: "The last health check message of the service",
+							Computed:    true,
+						},
+					},
+				},
+				Computed: true,
+			},
+			"timeouts": timeouts.Attributes(ctx, timeouts.Opts{
+				Read: true,
+			}),
+		},
+	}
+}
+
+func (d *talosMachineServiceStatusDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*talosProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"failed to get provider data",
+			fmt.Sprintf("Expected *talosProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.providerData = providerData
+}
+
+func (d *talosMachineServiceStatusDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var obj types.Object
+
+	diags := req.Config.Get(ctx, &obj)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var state talosMachineServiceStatusDataSourceModelV0
+	diags = obj.As(ctx, &state, basetypes.ObjectAsOptions{
+		UnhandledNullAsEmpty:    true,
+		UnhandledUnknownAsEmpty: true,
+	})
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	talosConfig, err := d.providerData.talosClientConfig("dynamic", state.ClientConfiguration)
+	if err != nil {
+		resp.Diagnostics.AddError("failed to generate talos config", err.Error())
+
+		return
+	}
+
+	if state.Endpoint.IsNull() {
+		state.Endpoint = state.Node
+	}
+
+	if d.providerData.deferRead(ctx, req, resp, state.Endpoint) {
+		return
+	}
+
+	readTimeout, diags := state.Timeouts.Read(ctx, 10*time.Minute)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctxDeadline, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
+	if err := d.providerData.retryContext(ctxDeadline, readTimeout, func() *retry.RetryError {
+		if err := talosClientOp(d.providerData.withClientOptions(ctx), state.Endpoint.ValueString(), state.Node.ValueString(), talosConfig, func(nodeCtx context.Context, c *client.Client) error {
+			state.Statuses = make([]talosServiceStatus, 0, len(state.Services))
+
+			var notReady []string
+
+			for _, svc := range state.Services {
+				services, err := c.ServiceInfo(nodeCtx, svc.ValueString())
+				if err != nil {
+					return err
+				}
+
+				if len(services) == 0 {
+					return fmt.Errorf("service %q is not registered", svc.ValueString())
+				}
+
+				for _, s := range services {
+					state.Statuses = append(state.Statuses, talosServiceStatus{
+						ID:            basetypes.NewStringValue(s.Service.GetId()),
+						State:         basetypes.NewStringValue(s.Service.GetState()),
+						Healthy:       basetypes.NewBoolValue(serviceHealthy(s.Service)),
+						HealthMessage: basetypes.NewStringValue(s.Service.GetHealth().GetLastMessage()),
+					})
+
+					if !serviceReady(s.Service) {
+						notReady = append(notReady, fmt.Sprintf("%s (state %q, health message: %q)", svc.ValueString(), s.Service.GetState(), s.Service.GetHealth().GetLastMessage()))
+					}
+				}
+			}
+
+			if len(notReady) > 0 {
+				return errors.New("services are not running and healthy: " + strings.Join(notReady, ", "))
+			}
+
+			return nil
+		}); err != nil {
+			if s := status.Code(err); s == codes.InvalidArgument {
+				return retry.NonRetryableError(err)
+			}
+
+			return retry.RetryableError(err)
+		}
+
+		return nil
+	}); err != nil {
+		resp.Diagnostics.AddError("failed waiting for services", err.Error())
+
+		return
+	}
+
+	state.ID = basetypes.NewStringValue("machine_service_status")
+
+	diags = resp.State.Set(ctx, state)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
@@ -0,0 +1,59 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos_test
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccTalosMachineServiceStatusDataSource(t *testing.T) {
+	rName := acctest.RandStringFromCharSet(10, acctest.CharSetAlpha)
+
+	resource.ParallelTest(t, resource.TestCase{
+		ExternalProviders: map[string]resource.ExternalProvider{
+			"libvirt": {
+				Source: "dmacvicar/libvirt",
+			},
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccTalosMachineServiceStatusDataSourceConfig("talos", rName),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.talos_machine_service_status.this", "id", "machine_service_status"),
+					resource.TestCheckResourceAttr("data.talos_machine_service_status.this", "statuses.#", "2"),
+					resource.TestCheckResourceAttr("data.talos_machine_service_status.this", "statuses.0.id", "etcd"),
+					resource.TestCheckResourceAttr("data.talos_machine_service_status.this", "statuses.0.state", "Running"),
+					resource.TestCheckResourceAttr("data.talos_machine_service_status.this", "statuses.0.healthy", "true"),
+					resource.TestCheckResourceAttr("data.talos_machine_service_status.this", "statuses.1.id", "kubelet"),
+					resource.TestCheckResourceAttr("data.talos_machine_service_status.this", "statuses.1.state", "Running"),
+				),
+			},
+		},
+	})
+}
+
+func testAccTalosMachineServiceStatusDataSourceConfig(providerName, rName string) string {
+	config := dynamicConfig{
+		Provider:        providerName,
+		ResourceName:    rName,
+		WithApplyConfig: true,
+		WithBootstrap:   true,
+	}
+
+	return config.render() + `
+data "talos_machine_service_status" "this" {
+  depends_on = [
+    talos_machine_bootstrap.this
+  ]
+  client_configuration = talos_machine_secrets.this.client_configuration
+  node                 = libvirt_domain.cp.network_interface[0].addresses[0]
+  services             = ["etcd", "kubelet"]
+}
+`
+}
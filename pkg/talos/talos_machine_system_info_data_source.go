@@ -0,0 +1,305 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cosi-project/runtime/pkg/safe"
+	"github.com/cosi-project/runtime/pkg/state"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
+	"github.com/siderolabs/talos/pkg/machinery/client"
+	"github.com/siderolabs/talos/pkg/machinery/resources/hardware"
+	"github.com/siderolabs/talos/pkg/machinery/resources/network"
+	"github.com/siderolabs/talos/pkg/machinery/resources/runtime"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type talosMachineSystemInfoDataSource struct {
+	providerData *talosProviderData
+}
+
+type talosMachineSystemInfoDataSourceModelV0 struct { //nolint:govet
+	ID                  types.String         `tfsdk:"id"`
+	Node                types.String         `tfsdk:"node"`
+	Endpoint            types.String         `tfsdk:"endpoint"`
+	ClientConfiguration *clientConfiguration `tfsdk:"client_configuration"`
+	Hostname            types.String         `tfsdk:"hostname"`
+	UUID                types.String         `tfsdk:"uuid"`
+	SerialNumber        types.String         `tfsdk:"serial_number"`
+	Manufacturer        types.String         `tfsdk:"manufacturer"`
+	ProductName         types.String         `tfsdk:"product_name"`
+	CPUCount            types.Int64          `tfsdk:"cpu_count"`
+	MemoryMiB           types.Int64          `tfsdk:"memory_mib"`
+	Platform            types.String         `tfsdk:"platform"`
+	Timeouts            timeouts.Value       `tfsdk:"timeouts"`
+}
+
+var (
+	_ datasource.DataSource              = &talosMachineSystemInfoDataSource{}
+	_ datasource.DataSourceWithConfigure = &talosMachineSystemInfoDataSource{}
+)
+
+// NewTalosMachineSystemInfoDataSource implements the datasource.DataSource interface.
+func NewTalosMachineSystemInfoDataSource() datasource.DataSource {
+	return &talosMachineSystemInfoDataSource{}
+}
+
+func (d *talosMachineSystemInfoDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_machine_system_info"
+}
+
+func (d *talosMachineSystemInfoDataSource) Schema(ctx context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Retrieves the system information of a node (including a node in maintenance mode), e.g. to assign the roles of bare-metal machines",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The generated ID of this resource",
+				Computed:    true,
+			},
+			"node": schema.StringAttribute{
+				Required:    true,
+				Description: "node to retrieve the system information from",
+			},
+			"endpoint": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "endpoint to use for the talosclient. If not set, the node value will be used",
+			},
+			"client_configuration": schema.SingleNestedAttribute{
+				Attributes: map[string]schema.Attribute{
+					"ca_certificate": schema.StringAttribute{
+						Required:    true,
+						Description: "The client CA certificate",
+					},
+					"client_certificate": schema.StringAttribute{
+						Required:    true,
+						Description: "The client certificate",
+					},
+					"client_key": schema.StringAttribute{
+						Required:    true,
+						Sensitive:   true,
+						Description: "The client key",
+					},
+				},
+				Optional:    true,
+				Description: "The client configuration data, defaults to the credentials of the provider `talosconfig_path`",
+			},
+			"hostname": schema.StringAttribute{
+				Description: "The hostname of the node",
+				Computed:    true,
+			},
+			"uuid": schema.StringAttribute{
+				Description: "The SMBIOS UUID of the machine",
+				Computed:    true,
+			},
+			"serial_number": schema.StringAttribute{
+				Description: "The SMBIOS serial number of the machine",
+				Computed:    true,
+			},
+			"manufacturer": schema.StringAttribute{
+				Description: "The SMBIOS manufacturer of the machine",
+				Computed:    true,
+			},
+			"product_name": schema.StringAttribute{
+				Description: "The SMBIOS product name of the machine",
+				Computed:    true,
+			},
+			"cpu_count": schema.Int64Attribute{
+				Description: "The number of logical CPUs of the machine, as reported by SMBIOS",
+				Computed:    true,
+			},
+			"memory_mib": schema.Int64Attribute{
+				Description: "The total size of the memory modules of the machine in MiB, as reported by SMBIOS",
+				Computed:    true,
+			},
+			"platform": schema.StringAttribute{
+				Description: "The platform Talos runs on (e.g. metal, aws, nocloud)",
+				Computed:    true,
+			},
+			"timeouts": timeouts.Attributes(ctx, timeouts.Opts{
+				Read: true,
+			}),
+		},
+	}
+}
+
+func (d *talosMachineSystemInfoDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*talosProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"failed to get provider data",
+			fmt.Sprintf("Expected *talosProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.providerData = providerData
+}
+
+func (d *talosMachineSystemInfoDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var obj types.Object
+
+	diags := req.Config.Get(ctx, &obj)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var state talosMachineSystemInfoDataSourceModelV0
+	diags = obj.As(ctx, &state, basetypes.ObjectAsOptions{
+		UnhandledNullAsEmpty:    true,
+		UnhandledUnknownAsEmpty: true,
+	})
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	talosConfig, err := d.providerData.talosClientConfig("dynamic", state.ClientConfiguration)
+	if err != nil {
+		resp.Diagnostics.AddError("failed to generate talos config", err.Error())
+
+		return
+	}
+
+	if state.Endpoint.IsNull() {
+		state.Endpoint = state.Node
+	}
+
+	if d.providerData.deferRead(ctx, req, resp, state.Endpoint) {
+		return
+	}
+
+	readTimeout, diags := state.Timeouts.Read(ctx, 10*time.Minute)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctxDeadline, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
+	if err := d.providerData.retryContext(ctxDeadline, readTimeout, func() *retry.RetryError {
+		if err := talosClientOp(d.providerData.withClientOptions(ctx), state.Endpoint.ValueString(), state.Node.ValueString(), talosConfig, func(nodeCtx context.Context, c *client.Client) error {
+			return state.readSystemInfo(nodeCtx, c)
+		}); err != nil {
+			if s := status.Code(err); s == codes.InvalidArgument {
+				return retry.NonRetryableError(err)
+			}
+
+			return retry.RetryableError(err)
+		}
+
+		return nil
+	}); err != nil {
+		resp.Diagnostics.AddError("failed to get system information", err.Error())
+
+		return
+	}
+
+	state.ID = basetypes.NewStringValue("machine_system_info")
+
+	diags = resp.State.Set(ctx, state)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// readSystemInfo sets the system information from the resources of the node.
+func (s *talosMachineSystemInfoDataSourceModelV0) readSystemInfo(ctx context.Context, c *client.Client) error {
+	systemInformation, err := safe.StateGetByID[*hardware.SystemInformation](ctx, c.COSI, hardware.SystemInformationID)
+	if err != nil {
+		return err
+	}
+
+	processors, err := safe.StateListAll[*hardware.Processor](ctx, c.COSI)
+	if err != nil {
+		return err
+	}
+
+	memoryModules, err := safe.StateListAll[*hardware.MemoryModule](ctx, c.COSI)
+	if err != nil {
+		return err
+	}
+
+	// the hostname and the platform might not be known yet, e.g. early in maintenance mode
+	hostname, platform := "", ""
+
+	hostnameStatus, err := safe.StateGetByID[*network.HostnameStatus](ctx, c.COSI, network.HostnameID)
+
+	switch {
+	case err == nil:
+		hostname = hostnameStatus.TypedSpec().Hostname
+	case !state.IsNotFoundError(err):
+		return err
+	}
+
+	platformMetadata, err := safe.StateGetByID[*runtime.PlatformMetadata](ctx, c.COSI, runtime.PlatformMetadataID)
+
+	switch {
+	case err == nil:
+		platform = platformMetadata.TypedSpec().Platform
+	case !state.IsNotFoundError(err):
+		return err
+	}
+
+	spec := systemInformation.TypedSpec()
+
+	s.Hostname = basetypes.NewStringValue(hostname)
+	s.UUID = basetypes.NewStringValue(spec.UUID)
+	s.SerialNumber = basetypes.NewStringValue(spec.SerialNumber)
+	s.Manufacturer = basetypes.NewStringValue(spec.Manufacturer)
+	s.ProductName = basetypes.NewStringValue(spec.ProductName)
+	s.CPUCount = basetypes.NewInt64Value(cpuCount(safe.ToSlice(processors, func(p *hardware.Processor) *hardware.ProcessorSpec { return p.TypedSpec() })))
+	s.MemoryMiB = basetypes.NewInt64Value(memorySize(safe.ToSlice(memoryModules, func(m *hardware.MemoryModule) *hardware.MemoryModuleSpec { return m.TypedSpec() })))
+	s.Platform = basetypes.NewStringValue(platform)
+
+	return nil
+}
+
+// cpuCount returns the number of logical CPUs of the processors, the cores are counted if the processor doesn't report its threads.
+func cpuCount(processors []*hardware.ProcessorSpec) int64 {
+	var count int64
+
+	for _, processor := range processors {
+		if processor.ThreadCount > 0 {
+			count += int64(processor.ThreadCount)
+		} else {
+			count += int64(processor.CoreCount)
+		}
+	}
+
+	return count
+}
+
+// memorySize returns the total size of the memory modules in MiB.
+func memorySize(memoryModules []*hardware.MemoryModuleSpec) int64 {
+	var size int64
+
+	for _, memoryModule := range memoryModules {
+		size += int64(memoryModule.Size)
+	}
+
+	return size
+}
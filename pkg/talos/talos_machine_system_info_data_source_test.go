@@ -0,0 +1,77 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos_test
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/siderolabs/talos/pkg/machinery/resources/hardware"
+
+	"github.com/siderolabs/terraform-provider-talos/pkg/talos"
+)
+
+func TestAccTalosMachineSystemInfoDataSource(t *testing.T) {
+	rName := acctest.RandStringFromCharSet(10, acctest.CharSetAlpha)
+
+	resource.ParallelTest(t, resource.TestCase{
+		ExternalProviders: map[string]resource.ExternalProvider{
+			"libvirt": {
+				Source: "dmacvicar/libvirt",
+			},
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccTalosMachineSystemInfoDataSourceConfig("talos", rName),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.talos_machine_system_info.this", "id", "machine_system_info"),
+					resource.TestCheckResourceAttrSet("data.talos_machine_system_info.this", "uuid"),
+					resource.TestCheckResourceAttr("data.talos_machine_system_info.this", "cpu_count", "2"),
+					resource.TestCheckResourceAttr("data.talos_machine_system_info.this", "memory_mib", "4096"),
+					resource.TestCheckResourceAttr("data.talos_machine_system_info.this", "platform", "metal"),
+				),
+			},
+		},
+	})
+}
+
+func testAccTalosMachineSystemInfoDataSourceConfig(providerName, rName string) string {
+	config := dynamicConfig{
+		Provider:     providerName,
+		ResourceName: rName,
+	}
+
+	return config.render() + `
+data "talos_machine_system_info" "this" {
+  client_configuration = talos_machine_secrets.this.client_configuration
+  node                 = libvirt_domain.cp.network_interface[0].addresses[0]
+}
+`
+}
+
+func TestCPUCountMemorySize(t *testing.T) {
+	t.Parallel()
+
+	processors := []*hardware.ProcessorSpec{
+		{CoreCount: 8, ThreadCount: 16},
+		{CoreCount: 4},
+	}
+
+	if count := talos.CPUCount(processors); count != 20 {
+		t.Errorf("expected 20 CPUs, got %d", count)
+	}
+
+	memoryModules := []*hardware.MemoryModuleSpec{
+		{Size: 16384},
+		{Size: 16384},
+		{},
+	}
+
+	if size := talos.MemorySize(memoryModules); size != 32768 {
+		t.Errorf("expected 32768 MiB of memory, got %d", size)
+	}
+}
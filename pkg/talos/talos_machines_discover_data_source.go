@@ -0,0 +1,363 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/netip"
+	"sync"
+	"time"
+
+	"github.com/cosi-project/runtime/pkg/safe"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/siderolabs/talos/pkg/machinery/client"
+	"github.com/siderolabs/talos/pkg/machinery/resources/hardware"
+	"github.com/siderolabs/talos/pkg/machinery/resources/network"
+)
+
+const (
+	// discoverProbeTimeout bounds the probe of a single address, most of the candidate addresses are expected not to answer.
+	discoverProbeTimeout = 5 * time.Second
+
+	// discoverMaxAddresses limits the number of probed addresses, so that a too large CIDR is caught early.
+	discoverMaxAddresses = 65536
+
+	defaultDiscoverConcurrency = 32
+)
+
+type talosMachinesDiscoverDataSource struct {
+	providerData *talosProviderData
+}
+
+type talosMachinesDiscoverDataSourceModelV0 struct { //nolint:govet
+	ID             types.String             `tfsdk:"id"`
+	CIDRs          []types.String           `tfsdk:"cidrs"`
+	Addresses      []types.String           `tfsdk:"addresses"`
+	MaxConcurrency types.Int64              `tfsdk:"max_concurrency"`
+	Machines       []talosDiscoveredMachine `tfsdk:"machines"`
+	Timeouts       timeouts.Value           `tfsdk:"timeouts"`
+}
+
+type talosDiscoveredMachine struct {
+	Address       types.String   `tfsdk:"address"`
+	UUID          types.String   `tfsdk:"uuid"`
+	SerialNumber  types.String   `tfsdk:"serial_number"`
+	Manufacturer  types.String   `tfsdk:"manufacturer"`
+	ProductName   types.String   `tfsdk:"product_name"`
+	HardwareAddrs []types.String `tfsdk:"hardware_addrs"`
+	NodeAddresses []types.String `tfsdk:"node_addresses"`
+}
+
+var (
+	_ datasource.DataSource              = &talosMachinesDiscoverDataSource{}
+	_ datasource.DataSourceWithConfigure = &talosMachinesDiscoverDataSource{}
+)
+
+// NewTalosMachinesDiscoverDataSource implements the datasource.DataSource interface.
+func NewTalosMachinesDiscoverDataSource() datasource.DataSource {
+	return &talosMachinesDiscoverDataSource{}
+}
+
+func (d *talosMachinesDiscoverDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_machines_discover"
+}
+
+func (d *talosMachinesDiscoverDataSource) Schema(ctx context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Discovers the Talos nodes waiting in maintenance mode by probing candidate addresses, " +
+			"the addresses which don't answer or run a configured node are left out",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The generated ID of this resource",
+				Computed:    true,
+			},
+			"cidrs": schema.ListAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Description: "The CIDRs to probe every address of, the network and broadcast addresses of IPv4 CIDRs are skipped",
+				Validators: []validator.List{
+					listvalidator.AtLeastOneOf(path.MatchRoot("addresses")),
+				},
+			},
+			"addresses": schema.ListAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Description: "The candidate addresses to probe",
+			},
+			"max_concurrency": schema.Int64Attribute{
+				Optional:    true,
+				Description: fmt.Sprintf("The maximum number of addresses probed at once, defaults to %d", defaultDiscoverConcurrency),
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"machines": schema.ListNestedAttribute{
+				Description: "The nodes in maintenance mode, in the order of the probed addresses",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"address": schema.StringAttribute{
+							Description: "The probed address the node answered on",
+							Computed:    true,
+						},
+						"uuid": schema.StringAttribute{
+							Description: "The SMBIOS UUID of the node",
+							Computed:    true,
+						},
+						"serial_number": schema.StringAttribute{
+							Description: "The SMBIOS serial number of the node",
+							Computed:    true,
+						},
+						"manufacturer": schema.StringAttribute{
+							Description: "The SMBIOS manufacturer of the node",
+							Computed:    true,
+						},
+						"product_name": schema.StringAttribute{
+							Description: "The SMBIOS product name of the node",
+							Computed:    true,
+						},
+						"hardware_addrs": schema.ListAttribute{
+							Description: "The hardware (MAC) addresses of the physical network interfaces of the node",
+							ElementType: types.StringType,
+							Computed:    true,
+						},
+						"node_addresses": schema.ListAttribute{
+							Description: "The current addresses of the node in CIDR notation",
+							ElementType: types.StringType,
+							Computed:    true,
+						},
+					},
+				},
+				Computed: true,
+			},
+			"timeouts": timeouts.Attributes(ctx, timeouts.Opts{
+				Read: true,
+			}),
+		},
+	}
+}
+
+func (d *talosMachinesDiscoverDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*talosProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"failed to get provider data",
+			fmt.Sprintf("Expected *talosProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.providerData = providerData
+}
+
+func (d *talosMachinesDiscoverDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state talosMachinesDiscoverDataSourceModelV0
+
+	diags := req.Config.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	addresses, err := discoverAddresses(stringValues(state.CIDRs), stringValues(state.Addresses), discoverMaxAddresses)
+	if err != nil {
+		resp.Diagnostics.AddError("invalid candidate addresses", err.Error())
+
+		return
+	}
+
+	readTimeout, diags := state.Timeouts.Read(ctx, 10*time.Minute)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctxDeadline, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
+	concurrency := defaultDiscoverConcurrency
+	if !state.MaxConcurrency.IsNull() {
+		concurrency = int(state.MaxConcurrency.ValueInt64())
+	}
+
+	var mu sync.Mutex
+
+	discovered := map[string]talosDiscoveredMachine{}
+
+	// the addresses which can't be probed are expected, so the probe errors are only logged
+	runConcurrently(ctxDeadline, addresses, concurrency, func(ctx context.Context, address string) error { //nolint:errcheck
+		machine, err := d.probe(ctx, address)
+		if err != nil {
+			tflog.Debug(ctx, "address is not a node in maintenance mode", map[string]any{"address": address, "error": err.Error()})
+
+			return nil
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		discovered[address] = machine
+
+		return nil
+	})
+
+	if ctxDeadline.Err() != nil {
+		resp.Diagnostics.AddError("failed to discover machines", fmt.Sprintf("the addresses weren't all probed within the timeout: %s", ctxDeadline.Err()))
+
+		return
+	}
+
+	state.Machines = []talosDiscoveredMachine{}
+
+	for _, address := range addresses {
+		if machine, ok := discovered[address]; ok {
+			state.Machines = append(state.Machines, machine)
+		}
+	}
+
+	state.ID = basetypes.NewStringValue("machines_discover")
+
+	diags = resp.State.Set(ctx, state)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// probe connects to the address without client credentials, which only succeeds for a node in maintenance mode.
+func (d *talosMachinesDiscoverDataSource) probe(ctx context.Context, address string) (talosDiscoveredMachine, error) {
+	ctx, cancel := context.WithTimeout(d.providerData.withClientOptions(ctx), discoverProbeTimeout)
+	defer cancel()
+
+	c, err := client.New(ctx,
+		client.WithGRPCDialOptions(proxyDialOptionsFromContext(ctx)...),
+		client.WithGRPCDialOptions(traceDialOptions()...),
+		client.WithTLSConfig(&tls.Config{
+			InsecureSkipVerify: true, //nolint:gosec
+		}),
+		client.WithEndpoints(normalizeEndpoint(address)),
+	)
+	if err != nil {
+		return talosDiscoveredMachine{}, err
+	}
+
+	defer c.Close() //nolint:errcheck
+
+	systemInformation, err := safe.StateGetByID[*hardware.SystemInformation](ctx, c.COSI, hardware.SystemInformationID)
+	if err != nil {
+		return talosDiscoveredMachine{}, err
+	}
+
+	links, err := safe.StateListAll[*network.LinkStatus](ctx, c.COSI)
+	if err != nil {
+		return talosDiscoveredMachine{}, err
+	}
+
+	nodeAddresses, err := safe.StateListAll[*network.AddressStatus](ctx, c.COSI)
+	if err != nil {
+		return talosDiscoveredMachine{}, err
+	}
+
+	spec := systemInformation.TypedSpec()
+
+	machine := talosDiscoveredMachine{
+		Address:       basetypes.NewStringValue(address),
+		UUID:          basetypes.NewStringValue(spec.UUID),
+		SerialNumber:  basetypes.NewStringValue(spec.SerialNumber),
+		Manufacturer:  basetypes.NewStringValue(spec.Manufacturer),
+		ProductName:   basetypes.NewStringValue(spec.ProductName),
+		HardwareAddrs: []types.String{},
+		NodeAddresses: []types.String{},
+	}
+
+	for it := links.Iterator(); it.Next(); {
+		if linkSpec := it.Value().TypedSpec(); linkSpec.Physical() {
+			machine.HardwareAddrs = append(machine.HardwareAddrs, basetypes.NewStringValue(linkSpec.HardwareAddr.String()))
+		}
+	}
+
+	for it := nodeAddresses.Iterator(); it.Next(); {
+		if addressSpec := it.Value().TypedSpec(); !addressSpec.Address.Addr().IsLoopback() {
+			machine.NodeAddresses = append(machine.NodeAddresses, basetypes.NewStringValue(addressSpec.Address.String()))
+		}
+	}
+
+	return machine, nil
+}
+
+// discoverAddresses returns the addresses of the CIDRs followed by the candidate addresses, skipping duplicates.
+func discoverAddresses(cidrs, candidates []string, limit int) ([]string, error) {
+	var addresses []string
+
+	seen := map[netip.Addr]struct{}{}
+
+	add := func(addr netip.Addr) error {
+		if _, ok := seen[addr]; ok {
+			return nil
+		}
+
+		if len(addresses) >= limit {
+			return fmt.Errorf("more than %d addresses to probe", limit)
+		}
+
+		seen[addr] = struct{}{}
+		addresses = append(addresses, addr.String())
+
+		return nil
+	}
+
+	for _, cidr := range cidrs {
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return nil, err
+		}
+
+		prefix = prefix.Masked()
+
+		// the network and broadcast addresses of IPv4 networks aren't host addresses
+		skipEdges := prefix.Addr().Is4() && prefix.Bits() < 31
+
+		for addr := prefix.Addr(); prefix.Contains(addr); addr = addr.Next() {
+			if skipEdges && (addr == prefix.Addr() || !prefix.Contains(addr.Next())) {
+				continue
+			}
+
+			if err = add(addr); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, candidate := range candidates {
+		addr, err := netip.ParseAddr(candidate)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = add(addr); err != nil {
+			return nil, err
+		}
+	}
+
+	return addresses, nil
+}
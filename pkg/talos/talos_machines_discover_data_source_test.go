@@ -0,0 +1,119 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+
+	"github.com/siderolabs/terraform-provider-talos/pkg/talos"
+)
+
+func TestAccTalosMachinesDiscoverDataSource(t *testing.T) {
+	rName := acctest.RandStringFromCharSet(10, acctest.CharSetAlpha)
+
+	resource.ParallelTest(t, resource.TestCase{
+		ExternalProviders: map[string]resource.ExternalProvider{
+			"libvirt": {
+				Source: "dmacvicar/libvirt",
+			},
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// test against a node in maintenance mode
+			{
+				Config: testAccTalosMachinesDiscoverDataSourceConfigV0("talos", rName),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.talos_machines_discover.this", "id", "machines_discover"),
+					resource.TestCheckResourceAttr("data.talos_machines_discover.this", "machines.#", "1"),
+					resource.TestCheckResourceAttrPair("data.talos_machines_discover.this", "machines.0.address", "libvirt_domain.cp", "network_interface.0.addresses.0"),
+					resource.TestCheckResourceAttrSet("data.talos_machines_discover.this", "machines.0.uuid"),
+					testAccCheckResourceAttrPairEqualFold("data.talos_machines_discover.this", "machines.0.hardware_addrs.0", "libvirt_domain.cp", "network_interface.0.mac"),
+					resource.TestCheckResourceAttrSet("data.talos_machines_discover.this", "machines.0.node_addresses.0"),
+				),
+			},
+		},
+	})
+}
+
+func testAccTalosMachinesDiscoverDataSourceConfigV0(providerName, rName string) string {
+	config := dynamicConfig{
+		Provider:        providerName,
+		ResourceName:    rName,
+		WithApplyConfig: false,
+		WithBootstrap:   false,
+	}
+
+	return config.render() + `
+data "talos_machines_discover" "this" {
+  addresses = [libvirt_domain.cp.network_interface[0].addresses[0], "127.0.0.2"]
+}
+`
+}
+
+func TestDiscoverAddresses(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name          string
+		cidrs         []string
+		candidates    []string
+		expected      []string
+		expectedError string
+	}{
+		{
+			name:       "ipv4 cidr",
+			cidrs:      []string{"10.5.0.1/30"},
+			candidates: []string{"10.5.0.2", "10.5.0.10"},
+			expected:   []string{"10.5.0.1", "10.5.0.2", "10.5.0.10"},
+		},
+		{
+			name:     "point to point cidr",
+			cidrs:    []string{"10.5.0.0/31"},
+			expected: []string{"10.5.0.0", "10.5.0.1"},
+		},
+		{
+			name:     "ipv6 cidr",
+			cidrs:    []string{"fd00::/126"},
+			expected: []string{"fd00::", "fd00::1", "fd00::2", "fd00::3"},
+		},
+		{
+			name:          "too many addresses",
+			cidrs:         []string{"fd00::/64"},
+			expectedError: "more than 16 addresses to probe",
+		},
+		{
+			name:          "invalid address",
+			candidates:    []string{"node-1"},
+			expectedError: `ParseAddr("node-1")`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			addresses, err := talos.DiscoverAddresses(tt.cidrs, tt.candidates, 16)
+
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Errorf("expected error containing %q, got %v", tt.expectedError, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !slices.Equal(addresses, tt.expected) {
+				t.Errorf("expected addresses %v, got %v", tt.expected, addresses)
+			}
+		})
+	}
+}
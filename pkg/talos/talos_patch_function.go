@@ -0,0 +1,82 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/siderolabs/talos/pkg/machinery/config/configpatcher"
+)
+
+type talosPatchFunction struct{}
+
+var _ function.Function = &talosPatchFunction{}
+
+// NewTalosPatchFunction implements the function.Function interface.
+func NewTalosPatchFunction() function.Function {
+	return &talosPatchFunction{}
+}
+
+func (f *talosPatchFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "patch"
+}
+
+func (f *talosPatchFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:     "Applies config patches to a machine configuration",
+		Description: "Applies the given config patches (strategic merge or JSON patches) to a machine configuration and returns the patched machine configuration.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "machine_configuration",
+				Description: "The machine configuration to patch",
+			},
+			function.ListParameter{
+				Name:        "patches",
+				ElementType: types.StringType,
+				Description: "The list of config patches to apply",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *talosPatchFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var (
+		machineConfiguration string
+		configPatches        []string
+	)
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &machineConfiguration, &configPatches))
+
+	if resp.Error != nil {
+		return
+	}
+
+	patches, err := configpatcher.LoadPatches(configPatches)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(1, fmt.Sprintf("failed to load config patches: %s", err))
+
+		return
+	}
+
+	cfg, err := configpatcher.Apply(configpatcher.WithBytes([]byte(machineConfiguration)), patches)
+	if err != nil {
+		resp.Error = function.NewFuncError(fmt.Sprintf("failed to apply config patches: %s", err))
+
+		return
+	}
+
+	cfgBytes, err := cfg.Bytes()
+	if err != nil {
+		resp.Error = function.NewFuncError(fmt.Sprintf("failed to get patched machine configuration: %s", err))
+
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, string(cfgBytes)))
+}
@@ -0,0 +1,54 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestAccTalosPatchFunction(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		IsUnitTest:               true, // this is a local only function, so can be unit tested
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: testAccTalosPatchFunctionConfig(`yamlencode({ machine = { network = { hostname = "worker-1" } } })`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckOutput("hostname", "worker-1"),
+				),
+			},
+			{
+				Config:      testAccTalosPatchFunctionConfig(`"not a patch"`),
+				ExpectError: regexp.MustCompile("failed to load config patches"),
+			},
+		},
+	})
+}
+
+func testAccTalosPatchFunctionConfig(patch string) string {
+	return `
+resource "talos_machine_secrets" "this" {}
+
+data "talos_machine_configuration" "this" {
+  cluster_name     = "example-cluster"
+  cluster_endpoint = "https://cluster.local:6443"
+  machine_type     = "worker"
+  machine_secrets  = talos_machine_secrets.this.machine_secrets
+}
+
+output "hostname" {
+  value = yamldecode(provider::talos::patch(data.talos_machine_configuration.this.machine_configuration, [` + patch + `])).machine.network.hostname
+
+  sensitive = true
+}
+`
+}
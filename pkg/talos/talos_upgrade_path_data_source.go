@@ -0,0 +1,262 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/blang/semver/v4"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	machineapi "github.com/siderolabs/talos/pkg/machinery/api/machine"
+	"github.com/siderolabs/talos/pkg/machinery/compatibility"
+)
+
+type talosUpgradePathDataSource struct{}
+
+var _ datasource.DataSource = &talosUpgradePathDataSource{}
+
+type talosUpgradePathDataSourceModelV0 struct {
+	ID                       types.String `tfsdk:"id"`
+	CurrentTalosVersion      types.String `tfsdk:"current_talos_version"`
+	TargetTalosVersion       types.String `tfsdk:"target_talos_version"`
+	CurrentKubernetesVersion types.String `tfsdk:"current_kubernetes_version"`
+	TargetKubernetesVersion  types.String `tfsdk:"target_kubernetes_version"`
+	KubernetesUpgradeFirst   types.Bool   `tfsdk:"kubernetes_upgrade_first"`
+}
+
+// NewTalosUpgradePathDataSource implements the datasource.DataSource interface.
+func NewTalosUpgradePathDataSource() datasource.DataSource {
+	return &talosUpgradePathDataSource{}
+}
+
+func (d *talosUpgradePathDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_upgrade_path"
+}
+
+func (d *talosUpgradePathDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Checks that Talos can be upgraded directly from the current version to the target version, and that the Kubernetes versions are supported along the way, " +
+			"using the compatibility tables of the Talos machinery. The data source fails if the upgrade isn't supported, suggesting the intermediate versions to upgrade through.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+			},
+			"current_talos_version": schema.StringAttribute{
+				Required:    true,
+				Description: "The version of Talos the nodes run, e.g. `v1.6.7`",
+			},
+			"target_talos_version": schema.StringAttribute{
+				Required:    true,
+				Description: "The version of Talos to upgrade the nodes to, e.g. `v1.8.0`",
+			},
+			"current_kubernetes_version": schema.StringAttribute{
+				Optional:    true,
+				Description: "The version of Kubernetes the cluster runs, e.g. `v1.29.3`, the Kubernetes versions aren't checked if not set",
+			},
+			"target_kubernetes_version": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "The version of Kubernetes to upgrade the cluster to, defaults to `current_kubernetes_version`",
+			},
+			"kubernetes_upgrade_first": schema.BoolAttribute{
+				Computed:    true,
+				Description: "Whether Kubernetes has to be upgraded before Talos, as the current Kubernetes version isn't supported by the target Talos version",
+			},
+		},
+	}
+}
+
+func (d *talosUpgradePathDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state talosUpgradePathDataSourceModelV0
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &state)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	currentTalos, err := parseTalosCompatibilityVersion(state.CurrentTalosVersion.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("current_talos_version"), "current_talos_version is invalid", err.Error())
+
+		return
+	}
+
+	targetTalos, err := parseTalosCompatibilityVersion(state.TargetTalosVersion.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("target_talos_version"), "target_talos_version is invalid", err.Error())
+
+		return
+	}
+
+	if err = checkTalosUpgradePath(currentTalos, targetTalos); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("target_talos_version"), "unsupported Talos upgrade path", err.Error())
+
+		return
+	}
+
+	state.ID = types.StringValue(fmt.Sprintf("%s-%s", state.CurrentTalosVersion.ValueString(), state.TargetTalosVersion.ValueString()))
+	state.KubernetesUpgradeFirst = types.BoolValue(false)
+
+	if !state.CurrentKubernetesVersion.IsNull() {
+		if state.TargetKubernetesVersion.IsNull() {
+			state.TargetKubernetesVersion = state.CurrentKubernetesVersion
+		}
+
+		kubernetesUpgradeFirst, err := checkKubernetesUpgradePath(state.CurrentKubernetesVersion.ValueString(), state.TargetKubernetesVersion.ValueString(), currentTalos, targetTalos)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("target_kubernetes_version"), "unsupported Kubernetes upgrade path", err.Error())
+
+			return
+		}
+
+		state.KubernetesUpgradeFirst = types.BoolValue(kubernetesUpgradeFirst)
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// talosCompatibilityVersion is a Talos version along with its parsed semantic version.
+type talosCompatibilityVersion struct {
+	compatibility *compatibility.TalosVersion
+	version       semver.Version
+}
+
+func parseTalosCompatibilityVersion(version string) (talosCompatibilityVersion, error) {
+	parsed, err := semver.ParseTolerant(version)
+	if err != nil {
+		return talosCompatibilityVersion{}, err
+	}
+
+	talosVersion, err := compatibility.ParseTalosVersion(&machineapi.VersionInfo{Tag: version})
+	if err != nil {
+		return talosCompatibilityVersion{}, err
+	}
+
+	return talosCompatibilityVersion{
+		compatibility: talosVersion,
+		version:       parsed,
+	}, nil
+}
+
+// checkTalosUpgradePath checks that the target version of Talos can be installed directly on the current version,
+// the error suggests the intermediate minor versions to upgrade through if the target version can be reached through them.
+func checkTalosUpgradePath(current, target talosCompatibilityVersion) error {
+	err := target.compatibility.UpgradeableFrom(current.compatibility)
+	if err == nil {
+		return nil
+	}
+
+	if intermediate := talosIntermediateVersions(current, target); len(intermediate) > 0 {
+		return fmt.Errorf("%w, upgrade through %s first", err, strings.Join(intermediate, ", "))
+	}
+
+	return err
+}
+
+// talosIntermediateVersions returns the shortest list of the minor versions to upgrade through to reach the target version,
+// it returns nil if the target version can't be reached.
+func talosIntermediateVersions(current, target talosCompatibilityVersion) []string {
+	if current.version.Major != target.version.Major || current.version.Minor >= target.version.Minor {
+		return nil
+	}
+
+	var intermediate []string
+
+	host := current
+
+	for host.version.Minor < target.version.Minor {
+		if target.compatibility.UpgradeableFrom(host.compatibility) == nil {
+			return intermediate
+		}
+
+		next, ok := talosNextUpgradeVersion(host, target)
+		if !ok {
+			return nil
+		}
+
+		intermediate = append(intermediate, fmt.Sprintf("v%d.%d", next.version.Major, next.version.Minor))
+
+		host = next
+	}
+
+	return nil
+}
+
+// talosNextUpgradeVersion returns the highest minor version below the target one which can be installed on the host version.
+func talosNextUpgradeVersion(host, target talosCompatibilityVersion) (talosCompatibilityVersion, bool) {
+	for minor := target.version.Minor - 1; minor > host.version.Minor; minor-- {
+		next, err := parseTalosCompatibilityVersion(fmt.Sprintf("v%d.%d.0", target.version.Major, minor))
+		if err != nil {
+			return talosCompatibilityVersion{}, false
+		}
+
+		if next.compatibility.UpgradeableFrom(host.compatibility) == nil {
+			return next, true
+		}
+	}
+
+	return talosCompatibilityVersion{}, false
+}
+
+// checkKubernetesUpgradePath checks that the Kubernetes upgrade skips no minor version and that either order of the Talos and Kubernetes upgrades is supported,
+// it returns true if Kubernetes has to be upgraded before Talos.
+func checkKubernetesUpgradePath(current, target string, currentTalos, targetTalos talosCompatibilityVersion) (bool, error) {
+	currentVersion, err := semver.ParseTolerant(current)
+	if err != nil {
+		return false, fmt.Errorf("current_kubernetes_version is invalid: %w", err)
+	}
+
+	targetVersion, err := semver.ParseTolerant(target)
+	if err != nil {
+		return false, fmt.Errorf("target_kubernetes_version is invalid: %w", err)
+	}
+
+	switch {
+	case targetVersion.LT(currentVersion):
+		return false, fmt.Errorf("kubernetes can't be downgraded from %s to %s", currentVersion, targetVersion)
+	case currentVersion.Major != targetVersion.Major:
+		return false, fmt.Errorf("kubernetes can't be upgraded from %s to %s", currentVersion, targetVersion)
+	case targetVersion.Minor > currentVersion.Minor+1:
+		intermediate := make([]string, 0, targetVersion.Minor-currentVersion.Minor-1)
+
+		for minor := currentVersion.Minor + 1; minor < targetVersion.Minor; minor++ {
+			intermediate = append(intermediate, fmt.Sprintf("%d.%d", currentVersion.Major, minor))
+		}
+
+		return false, fmt.Errorf("kubernetes can only be upgraded one minor version at a time, upgrade from %s through %s first", currentVersion, strings.Join(intermediate, ", "))
+	}
+
+	currentKubernetes, err := compatibility.ParseKubernetesVersion(current)
+	if err != nil {
+		return false, fmt.Errorf("current_kubernetes_version is invalid: %w", err)
+	}
+
+	targetKubernetes, err := compatibility.ParseKubernetesVersion(target)
+	if err != nil {
+		return false, fmt.Errorf("target_kubernetes_version is invalid: %w", err)
+	}
+
+	if err = targetKubernetes.SupportedWith(targetTalos.compatibility); err != nil {
+		return false, err
+	}
+
+	// Talos is upgraded first, the nodes run the current version of Kubernetes on the target version of Talos
+	if currentKubernetes.SupportedWith(targetTalos.compatibility) == nil {
+		return false, nil
+	}
+
+	// Kubernetes is upgraded first, the nodes run the target version of Kubernetes on the current version of Talos
+	if err = targetKubernetes.SupportedWith(currentTalos.compatibility); err != nil {
+		return false, fmt.Errorf("neither Talos nor Kubernetes can be upgraded first: %w", err)
+	}
+
+	return true, nil
+}
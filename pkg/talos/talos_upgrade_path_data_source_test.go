@@ -0,0 +1,205 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos_test
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+
+	"github.com/siderolabs/terraform-provider-talos/pkg/talos"
+)
+
+func TestAccTalosUpgradePathDataSource(t *testing.T) {
+	resource.ParallelTest(t, resource.TestCase{
+		IsUnitTest:               true, // this is a local only data source, so can be unit tested
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccTalosUpgradePathDataSourceConfig("v1.7.6", "v1.8.0", "v1.29.3"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.talos_upgrade_path.this", "target_kubernetes_version", "v1.29.3"),
+					resource.TestCheckResourceAttr("data.talos_upgrade_path.this", "kubernetes_upgrade_first", "false"),
+				),
+			},
+			{
+				Config:      testAccTalosUpgradePathDataSourceConfig("v1.3.5", "v1.8.0", "v1.26.3"),
+				ExpectError: regexp.MustCompile("upgrade through v1.6 first"),
+			},
+			{
+				Config:      testAccTalosUpgradePathDataSourceConfig("v1.6.7", "v1.8.0", "v1.24.3"),
+				ExpectError: regexp.MustCompile("unsupported Kubernetes upgrade path"),
+			},
+		},
+	})
+}
+
+func testAccTalosUpgradePathDataSourceConfig(currentTalosVersion, targetTalosVersion, currentKubernetesVersion string) string {
+	return fmt.Sprintf(`
+data "talos_upgrade_path" "this" {
+  current_talos_version      = "%s"
+  target_talos_version       = "%s"
+  current_kubernetes_version = "%s"
+}
+`, currentTalosVersion, targetTalosVersion, currentKubernetesVersion)
+}
+
+func TestCheckTalosUpgradePath(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name        string
+		current     string
+		target      string
+		expectedErr string
+	}{
+		{
+			name:    "direct",
+			current: "v1.6.7",
+			target:  "v1.8.0",
+		},
+		{
+			name:        "one intermediate version",
+			current:     "v1.3.5",
+			target:      "v1.8.0",
+			expectedErr: "host version 1.3.5 is too old to upgrade to Talos 1.8.0, upgrade through v1.6 first",
+		},
+		{
+			name:        "several intermediate versions",
+			current:     "v1.0.0",
+			target:      "v1.8.0",
+			expectedErr: "upgrade through v1.4, v1.7 first",
+		},
+		{
+			name:        "downgrade",
+			current:     "v1.8.0",
+			target:      "v1.6.0",
+			expectedErr: "host version 1.8.0 is too new to downgrade to Talos 1.6.0",
+		},
+		{
+			name:        "unknown target",
+			current:     "v1.8.0",
+			target:      "v1.99.0",
+			expectedErr: "upgrades to version 1.99.0 are not supported",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			current, err := talos.ParseTalosCompatibilityVersion(tt.current)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			target, err := talos.ParseTalosCompatibilityVersion(tt.target)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = talos.CheckTalosUpgradePath(current, target)
+
+			if tt.expectedErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestCheckKubernetesUpgradePath(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name                   string
+		currentTalos           string
+		targetTalos            string
+		current                string
+		target                 string
+		kubernetesUpgradeFirst bool
+		expectedErr            string
+	}{
+		{
+			name:         "talos first",
+			currentTalos: "v1.7.6",
+			targetTalos:  "v1.8.0",
+			current:      "v1.29.3",
+			target:       "v1.30.0",
+		},
+		{
+			name:                   "kubernetes first",
+			currentTalos:           "v1.5.5",
+			targetTalos:            "v1.8.0",
+			current:                "1.25.5",
+			target:                 "1.26.0",
+			kubernetesUpgradeFirst: true,
+		},
+		{
+			name:         "skipped minor versions",
+			currentTalos: "v1.8.0",
+			targetTalos:  "v1.8.0",
+			current:      "v1.28.1",
+			target:       "v1.31.0",
+			expectedErr:  "upgrade from 1.28.1 through 1.29, 1.30 first",
+		},
+		{
+			name:         "downgrade",
+			currentTalos: "v1.8.0",
+			targetTalos:  "v1.8.0",
+			current:      "v1.30.0",
+			target:       "v1.29.0",
+			expectedErr:  "kubernetes can't be downgraded from 1.30.0 to 1.29.0",
+		},
+		{
+			name:         "unsupported target",
+			currentTalos: "v1.6.7",
+			targetTalos:  "v1.8.0",
+			current:      "v1.24.3",
+			target:       "v1.25.0",
+			expectedErr:  "version of Kubernetes 1.25.0 is too old to be used with Talos 1.8.0",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			currentTalos, err := talos.ParseTalosCompatibilityVersion(tt.currentTalos)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			targetTalos, err := talos.ParseTalosCompatibilityVersion(tt.targetTalos)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			kubernetesUpgradeFirst, err := talos.CheckKubernetesUpgradePath(tt.current, tt.target, currentTalos, targetTalos)
+
+			if tt.expectedErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+
+				if kubernetesUpgradeFirst != tt.kubernetesUpgradeFirst {
+					t.Errorf("expected kubernetes_upgrade_first %v, got %v", tt.kubernetesUpgradeFirst, kubernetesUpgradeFirst)
+				}
+
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/siderolabs/talos/pkg/machinery/config/configloader"
+	"github.com/siderolabs/talos/pkg/machinery/config/validation"
+)
+
+type talosValidateFunction struct{}
+
+var _ function.Function = &talosValidateFunction{}
+
+var validateFunctionResultAttributeTypes = map[string]attr.Type{
+	"valid":    types.BoolType,
+	"warnings": types.ListType{ElemType: types.StringType},
+}
+
+// validationMode implements validation.RuntimeMode for the modes Talos can run in.
+type validationMode struct {
+	name            string
+	requiresInstall bool
+	inContainer     bool
+}
+
+var validationModes = map[string]validationMode{
+	"cloud":     {name: "cloud"},
+	"container": {name: "container", inContainer: true},
+	"metal":     {name: "metal", requiresInstall: true},
+}
+
+func (m validationMode) String() string {
+	return m.name
+}
+
+func (m validationMode) RequiresInstall() bool {
+	return m.requiresInstall
+}
+
+func (m validationMode) InContainer() bool {
+	return m.inContainer
+}
+
+// NewTalosValidateFunction implements the function.Function interface.
+func NewTalosValidateFunction() function.Function {
+	return &talosValidateFunction{}
+}
+
+func (f *talosValidateFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "validate"
+}
+
+func (f *talosValidateFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Validates a machine configuration",
+		Description: "Validates a machine configuration for the given runtime mode (`cloud`, `container` or `metal`), the function call fails if the machine configuration is invalid. " +
+			"Otherwise returns an object with `valid` set to true and the validation `warnings` (e.g. deprecated fields).",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "machine_configuration",
+				Description: "The machine configuration to validate",
+			},
+			function.StringParameter{
+				Name:        "mode",
+				Description: "The runtime mode to validate the machine configuration for",
+			},
+		},
+		Return: function.ObjectReturn{
+			AttributeTypes: validateFunctionResultAttributeTypes,
+		},
+	}
+}
+
+func (f *talosValidateFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var machineConfiguration, mode string
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &machineConfiguration, &mode))
+
+	if resp.Error != nil {
+		return
+	}
+
+	runtimeMode, ok := validationModes[mode]
+	if !ok {
+		resp.Error = function.NewArgumentFuncError(1, fmt.Sprintf("unknown mode %q, expected one of cloud, container, metal", mode))
+
+		return
+	}
+
+	cfg, err := configloader.NewFromBytes([]byte(machineConfiguration))
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("failed to load machine configuration: %s", err))
+
+		return
+	}
+
+	warnings, err := cfg.Validate(runtimeMode, validation.WithLocal())
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("machine configuration is invalid: %s", err))
+
+		return
+	}
+
+	warningValues := make([]attr.Value, len(warnings))
+
+	for i, warning := range warnings {
+		warningValues[i] = types.StringValue(warning)
+	}
+
+	result, diags := types.ObjectValue(validateFunctionResultAttributeTypes, map[string]attr.Value{
+		"valid":    types.BoolValue(true),
+		"warnings": types.ListValueMust(types.StringType, warningValues),
+	})
+	if diags.HasError() {
+		resp.Error = function.FuncErrorFromDiags(ctx, diags)
+
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, result))
+}
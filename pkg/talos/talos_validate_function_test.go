@@ -0,0 +1,140 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestAccTalosValidateFunction(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		IsUnitTest:               true, // this is a local only function, so can be unit tested
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: testAccTalosValidateFunctionConfig("metal"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckOutput("valid", "true"),
+					resource.TestCheckOutput("warnings", "0"),
+				),
+			},
+			{
+				Config: testAccTalosValidateFunctionWarningsConfig(),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckOutput("valid", "true"),
+					resource.TestMatchOutput("warnings", regexp.MustCompile(`machine\.install\.extensions is deprecated`)),
+				),
+			},
+			{
+				Config:      testAccTalosValidateFunctionConfig("baremetal"),
+				ExpectError: regexp.MustCompile(`unknown mode "baremetal"`),
+			},
+			{
+				Config:      testAccTalosValidateFunctionInvalidConfig(),
+				ExpectError: regexp.MustCompile("machine configuration is invalid"),
+			},
+		},
+	})
+}
+
+func testAccTalosValidateFunctionConfig(mode string) string {
+	return `
+resource "talos_machine_secrets" "this" {}
+
+data "talos_machine_configuration" "this" {
+  cluster_name     = "example-cluster"
+  cluster_endpoint = "https://cluster.local:6443"
+  machine_type     = "controlplane"
+  machine_secrets  = talos_machine_secrets.this.machine_secrets
+}
+
+output "valid" {
+  value = provider::talos::validate(data.talos_machine_configuration.this.machine_configuration, "` + mode + `").valid
+
+  sensitive = true
+}
+
+output "warnings" {
+  value = length(provider::talos::validate(data.talos_machine_configuration.this.machine_configuration, "` + mode + `").warnings)
+
+  sensitive = true
+}
+`
+}
+
+func testAccTalosValidateFunctionWarningsConfig() string {
+	return `
+resource "talos_machine_secrets" "this" {}
+
+data "talos_machine_configuration" "this" {
+  cluster_name     = "example-cluster"
+  cluster_endpoint = "https://cluster.local:6443"
+  machine_type     = "controlplane"
+  machine_secrets  = talos_machine_secrets.this.machine_secrets
+  config_patches = [
+    yamlencode({
+      machine = {
+        install = {
+          extensions = [
+            {
+              image = "ghcr.io/siderolabs/gvisor:20231214.0-v1.6.0"
+            },
+          ]
+        }
+      }
+    }),
+  ]
+}
+
+output "valid" {
+  value = provider::talos::validate(data.talos_machine_configuration.this.machine_configuration, "metal").valid
+
+  sensitive = true
+}
+
+output "warnings" {
+  value = join("\n", provider::talos::validate(data.talos_machine_configuration.this.machine_configuration, "metal").warnings)
+
+  sensitive = true
+}
+`
+}
+
+func testAccTalosValidateFunctionInvalidConfig() string {
+	return `
+resource "talos_machine_secrets" "this" {}
+
+data "talos_machine_configuration" "this" {
+  cluster_name     = "example-cluster"
+  cluster_endpoint = "https://cluster.local:6443"
+  machine_type     = "controlplane"
+  machine_secrets  = talos_machine_secrets.this.machine_secrets
+  config_patches = [
+    yamlencode({
+      cluster = {
+        network = {
+          cni = {
+            name = "invalid"
+          }
+        }
+      }
+    }),
+  ]
+}
+
+output "valid" {
+  value = provider::talos::validate(data.talos_machine_configuration.this.machine_configuration, "metal").valid
+
+  sensitive = true
+}
+`
+}
@@ -0,0 +1,72 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+
+	"github.com/siderolabs/talos/pkg/machinery/client"
+	clientconfig "github.com/siderolabs/talos/pkg/machinery/client/config"
+	"google.golang.org/grpc"
+)
+
+// tlsOverrides relaxes the verification of the Talos API certificate when the dialed address is not in its SANs.
+type tlsOverrides struct {
+	serverName         string
+	insecureSkipVerify bool
+}
+
+type tlsOverridesContextKey struct{}
+
+// withTLSOverrides returns a context carrying the TLS overrides, talosClientOp applies them to the clients it creates if they are present in the context.
+func withTLSOverrides(ctx context.Context, overrides tlsOverrides) context.Context {
+	if overrides == (tlsOverrides{}) {
+		return ctx
+	}
+
+	return context.WithValue(ctx, tlsOverridesContextKey{}, overrides)
+}
+
+// configClientOptions returns the Talos client options to authenticate with the client configuration, applying the TLS overrides in the context.
+func configClientOptions(ctx context.Context, tc *clientconfig.Config) ([]client.OptionFunc, error) {
+	overrides, ok := ctx.Value(tlsOverridesContextKey{}).(tlsOverrides)
+	if !ok {
+		return []client.OptionFunc{client.WithConfig(tc)}, nil
+	}
+
+	var opts []client.OptionFunc
+
+	if overrides.serverName != "" {
+		// the TLS credentials verify the certificate against the authority if no server name is set
+		opts = append(opts, client.WithGRPCDialOptions(grpc.WithAuthority(overrides.serverName)))
+	}
+
+	if !overrides.insecureSkipVerify {
+		return append(opts, client.WithConfig(tc)), nil
+	}
+
+	configContext := tc.Contexts[tc.Context]
+	if configContext == nil {
+		return nil, errors.New("the client configuration has no current context")
+	}
+
+	crt, err := client.CertificateFromConfigContext(configContext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the client certificate: %w", err)
+	}
+
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: true, //nolint:gosec
+	}
+
+	if crt != nil {
+		tlsConfig.Certificates = []tls.Certificate{*crt}
+	}
+
+	return append(opts, client.WithTLSConfig(tlsConfig)), nil
+}
@@ -0,0 +1,86 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	clientconfig "github.com/siderolabs/talos/pkg/machinery/client/config"
+	"github.com/siderolabs/talos/pkg/machinery/config/generate/secrets"
+	"github.com/siderolabs/talos/pkg/machinery/role"
+)
+
+func TestConfigClientOptions(t *testing.T) {
+	t.Parallel()
+
+	secretsBundle, err := secrets.NewBundle(secrets.NewFixedClock(time.Now()), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clientCert, err := secretsBundle.GenerateTalosAPIClientCertificate(role.MakeSet(role.Admin))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tc := clientconfig.NewConfig("test", nil, secretsBundle.Certs.OS.Crt, clientCert)
+
+	for _, tt := range []struct {
+		name          string
+		overrides     tlsOverrides
+		config        *clientconfig.Config
+		expectedOpts  int
+		expectedError string
+	}{
+		{
+			name:         "no overrides",
+			config:       tc,
+			expectedOpts: 1,
+		},
+		{
+			name:         "server name",
+			overrides:    tlsOverrides{serverName: "talos.example.com"},
+			config:       tc,
+			expectedOpts: 2,
+		},
+		{
+			name:         "insecure skip verify",
+			overrides:    tlsOverrides{insecureSkipVerify: true},
+			config:       tc,
+			expectedOpts: 1,
+		},
+		{
+			name:          "insecure skip verify without context",
+			overrides:     tlsOverrides{insecureSkipVerify: true},
+			config:        &clientconfig.Config{},
+			expectedError: "has no current context",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			opts, err := configClientOptions(withTLSOverrides(context.Background(), tt.overrides), tt.config)
+
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectedError, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(opts) != tt.expectedOpts {
+				t.Errorf("expected %d options, got %d", tt.expectedOpts, len(opts))
+			}
+		})
+	}
+}
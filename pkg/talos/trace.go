@@ -0,0 +1,120 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	machineapi "github.com/siderolabs/talos/pkg/machinery/api/machine"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// traceDialOptions returns the gRPC dial options logging every Talos API call with tflog,
+// the calls are logged at the debug level, and with the redacted request at the trace level.
+func traceDialOptions() []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(traceUnaryInterceptor),
+		grpc.WithChainStreamInterceptor(traceStreamInterceptor),
+	}
+}
+
+func traceUnaryInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	var p peer.Peer
+
+	start := time.Now()
+
+	err := invoker(ctx, method, req, reply, cc, append(opts, grpc.Peer(&p))...)
+
+	traceCall(ctx, method, req, &p, time.Since(start), err)
+
+	return err
+}
+
+func traceStreamInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	var p peer.Peer
+
+	start := time.Now()
+
+	stream, err := streamer(ctx, desc, cc, method, append(opts, grpc.Peer(&p))...)
+
+	// the stream is logged once it is opened, the messages are not logged
+	traceCall(ctx, method, nil, &p, time.Since(start), err)
+
+	return stream, err
+}
+
+func traceCall(ctx context.Context, method string, req any, p *peer.Peer, duration time.Duration, err error) {
+	fields := map[string]any{
+		"method":    method,
+		"duration":  duration.String(),
+		"grpc_code": status.Code(err).String(),
+	}
+
+	if nodes := traceNodes(ctx); nodes != "" {
+		fields["node"] = nodes
+	}
+
+	if p.Addr != nil {
+		fields["endpoint"] = p.Addr.String()
+	}
+
+	if applyReq, ok := req.(*machineapi.ApplyConfigurationRequest); ok {
+		fields["apply_mode"] = applyReq.GetMode().String()
+		fields["dry_run"] = applyReq.GetDryRun()
+	}
+
+	if err != nil {
+		fields["error"] = err.Error()
+	}
+
+	tflog.Debug(ctx, "Talos API call", fields)
+
+	if msg, ok := req.(proto.Message); ok {
+		tflog.Trace(ctx, "Talos API request", map[string]any{
+			"method":  method,
+			"request": traceRequest(msg),
+		})
+	}
+}
+
+// traceNodes returns the nodes the call is proxied to by apid.
+func traceNodes(ctx context.Context) string {
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	if nodes := md.Get("nodes"); len(nodes) > 0 {
+		return strings.Join(nodes, ",")
+	}
+
+	return strings.Join(md.Get("node"), ",")
+}
+
+// traceRequest returns the request as JSON, the requests carrying secrets are redacted.
+func traceRequest(msg proto.Message) string {
+	if req, ok := msg.(*machineapi.ApplyConfigurationRequest); ok {
+		// the machine configuration contains the cluster secrets
+		redacted := proto.Clone(req).(*machineapi.ApplyConfigurationRequest) //nolint:forcetypeassert,errcheck
+		redacted.Data = []byte("<redacted>")
+
+		msg = redacted
+	}
+
+	data, err := protojson.Marshal(msg)
+	if err != nil {
+		return err.Error()
+	}
+
+	return string(data)
+}
@@ -0,0 +1,49 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	machineapi "github.com/siderolabs/talos/pkg/machinery/api/machine"
+	"github.com/siderolabs/talos/pkg/machinery/client"
+)
+
+func TestTraceRequest(t *testing.T) {
+	t.Parallel()
+
+	req := &machineapi.ApplyConfigurationRequest{
+		Data:   []byte("machine:\n  token: secret\n"),
+		Mode:   machineapi.ApplyConfigurationRequest_STAGED,
+		DryRun: true,
+	}
+
+	traced := traceRequest(req)
+
+	if strings.Contains(traced, base64.StdEncoding.EncodeToString(req.Data)) {
+		t.Errorf("expected the machine configuration to be redacted, got %s", traced)
+	}
+
+	if !strings.Contains(traced, "STAGED") {
+		t.Errorf("expected the apply mode to be traced, got %s", traced)
+	}
+
+	if string(req.Data) != "machine:\n  token: secret\n" {
+		t.Error("expected the request not to be modified")
+	}
+
+	ctx := client.WithNode(context.Background(), "10.5.0.2")
+
+	if nodes := traceNodes(ctx); nodes != "10.5.0.2" {
+		t.Errorf("expected the node to be traced, got %q", nodes)
+	}
+
+	if nodes := traceNodes(context.Background()); nodes != "" {
+		t.Errorf("expected no node, got %q", nodes)
+	}
+}
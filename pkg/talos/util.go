@@ -5,29 +5,61 @@
 package talos
 
 import (
+	"bytes"
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
 	"crypto/tls"
 	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"maps"
+	"net"
+	"net/http"
 	"net/url"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/cosi-project/runtime/pkg/safe"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/siderolabs/crypto/x509"
 	sideronet "github.com/siderolabs/net"
+	machineapi "github.com/siderolabs/talos/pkg/machinery/api/machine"
 	"github.com/siderolabs/talos/pkg/machinery/client"
 	clientconfig "github.com/siderolabs/talos/pkg/machinery/client/config"
 	"github.com/siderolabs/talos/pkg/machinery/config"
 	"github.com/siderolabs/talos/pkg/machinery/config/bundle"
+	"github.com/siderolabs/talos/pkg/machinery/config/configloader"
 	"github.com/siderolabs/talos/pkg/machinery/config/configpatcher"
 	"github.com/siderolabs/talos/pkg/machinery/config/encoder"
 	"github.com/siderolabs/talos/pkg/machinery/config/generate"
 	"github.com/siderolabs/talos/pkg/machinery/config/generate/secrets"
 	"github.com/siderolabs/talos/pkg/machinery/config/machine"
+	"github.com/siderolabs/talos/pkg/machinery/config/types/runtime/extensions"
+	"github.com/siderolabs/talos/pkg/machinery/config/types/v1alpha1"
+	"github.com/siderolabs/talos/pkg/machinery/config/validation"
 	"github.com/siderolabs/talos/pkg/machinery/constants"
 	"github.com/siderolabs/talos/pkg/machinery/gendata"
+	"github.com/siderolabs/talos/pkg/machinery/resources/runtime"
+	"golang.org/x/crypto/hkdf"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/stats"
+	"gopkg.in/yaml.v3"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
 )
 
 type machineConfigGenerateOptions struct { //nolint:govet
@@ -37,9 +69,14 @@ type machineConfigGenerateOptions struct { //nolint:govet
 	machineSecrets    *secrets.Bundle
 	kubernetesVersion string
 	talosVersion      string
+	configContract    string
+	localAPIPort      int
+	additionalSANs    []string
 	docsEnabled       bool
 	examplesEnabled   bool
 	configPatches     []string
+	registryOptions   []generate.Option
+	diskEncryption    *v1alpha1.SystemDiskEncryptionConfig
 }
 
 func (m *machineConfigGenerateOptions) generate() (string, error) {
@@ -55,13 +92,34 @@ func (m *machineConfigGenerateOptions) generate() (string, error) {
 		generate.WithSecretsBundle(m.machineSecrets),
 	)
 
-	versionContract, err := validateVersionContract(m.talosVersion)
+	contractVersion := m.talosVersion
+
+	// a pinned config contract takes precedence over the one derived from the talos version
+	if m.configContract != "" {
+		contractVersion = m.configContract
+	}
+
+	versionContract, err := validateVersionContract(contractVersion)
 	if err != nil {
 		return "", err
 	}
 
 	genOptions = append(genOptions, generate.WithVersionContract(versionContract))
 
+	if m.localAPIPort != 0 {
+		genOptions = append(genOptions, generate.WithLocalAPIServerPort(m.localAPIPort))
+	}
+
+	if len(m.additionalSANs) > 0 {
+		genOptions = append(genOptions, generate.WithAdditionalSubjectAltNames(m.additionalSANs))
+	}
+
+	genOptions = append(genOptions, m.registryOptions...)
+
+	if m.diskEncryption != nil {
+		genOptions = append(genOptions, generate.WithSystemDiskEncryption(m.diskEncryption))
+	}
+
 	commentsFlags := encoder.CommentsDisabled
 
 	if m.docsEnabled {
@@ -72,6 +130,12 @@ func (m *machineConfigGenerateOptions) generate() (string, error) {
 		commentsFlags |= encoder.CommentsExamples
 	}
 
+	// the config bundle also generates a talosconfig, which requires the OS CA key,
+	// so the worker machine configuration is generated directly from the worker machine secrets which don't have it
+	if m.machineSecrets.Certs.OS == nil || len(m.machineSecrets.Certs.OS.Key) == 0 {
+		return m.generateFromInput(genOptions, commentsFlags)
+	}
+
 	configBundleOpts := []bundle.Option{
 		bundle.WithInputOptions(
 			&bundle.InputOptions{
@@ -121,6 +185,104 @@ func (m *machineConfigGenerateOptions) generate() (string, error) {
 	return string(machineConfigBytes), nil
 }
 
+// extensionServiceConfigDocuments validates the extension services and returns their ExtensionServiceConfig documents, sorted by extension service name.
+func extensionServiceConfigDocuments(services map[string]machineConfigurationExtensionService) ([]string, error) {
+	documents := make([]string, 0, len(services))
+
+	for _, name := range slices.Sorted(maps.Keys(services)) {
+		service := services[name]
+
+		serviceConfig := extensions.NewServicesConfigV1Alpha1()
+		serviceConfig.ServiceName = name
+
+		for _, key := range slices.Sorted(maps.Keys(service.Environment)) {
+			if _, ok := service.SensitiveEnvironment[key]; ok {
+				return nil, fmt.Errorf("%s: the environment variable %q is set in both environment and sensitive_environment", name, key)
+			}
+		}
+
+		environment := map[string]string{}
+
+		for _, env := range []map[string]types.String{service.Environment, service.SensitiveEnvironment} {
+			for key, value := range env {
+				if key == "" || strings.Contains(key, "=") {
+					return nil, fmt.Errorf("%s: invalid environment variable name %q", name, key)
+				}
+
+				environment[key] = value.ValueString()
+			}
+		}
+
+		for _, key := range slices.Sorted(maps.Keys(environment)) {
+			serviceConfig.ServiceEnvironment = append(serviceConfig.ServiceEnvironment, key+"="+environment[key])
+		}
+
+		for _, mountPath := range slices.Sorted(maps.Keys(service.ConfigFiles)) {
+			serviceConfig.ServiceConfigFiles = append(serviceConfig.ServiceConfigFiles, extensions.ConfigFile{
+				ConfigFileContent:   service.ConfigFiles[mountPath].ValueString(),
+				ConfigFileMountPath: mountPath,
+			})
+		}
+
+		if _, err := serviceConfig.Validate(nil); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+
+		document, err := encoder.NewEncoder(serviceConfig, encoder.WithComments(encoder.CommentsDisabled)).Encode()
+		if err != nil {
+			return nil, err
+		}
+
+		documents = append(documents, string(document))
+	}
+
+	return documents, nil
+}
+
+// generateFromInput generates the machine configuration without a config bundle.
+func (m *machineConfigGenerateOptions) generateFromInput(genOptions []generate.Option, commentsFlags encoder.CommentsFlags) (string, error) {
+	input, err := generate.NewInput(
+		m.clusterName,
+		m.clusterEndpoint,
+		strings.TrimPrefix(m.kubernetesVersion, "v"),
+		genOptions...,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	machineConfig, err := input.Config(m.machineType)
+	if err != nil {
+		return "", err
+	}
+
+	patches, err := configpatcher.LoadPatches(m.configPatches)
+	if err != nil {
+		return "", fmt.Errorf("error parsing config patch: %w", err)
+	}
+
+	if len(patches) > 0 {
+		var patched configpatcher.Output
+
+		patched, err = configpatcher.Apply(configpatcher.WithConfig(machineConfig), patches)
+		if err != nil {
+			return "", fmt.Errorf("error patching config: %w", err)
+		}
+
+		machineConfig, err = patched.Config()
+		if err != nil {
+			return "", err
+		}
+	}
+
+	machineConfigBytes, err := machineConfig.EncodeBytes(encoder.WithComments(commentsFlags))
+	if err != nil {
+		return "", err
+	}
+
+	return string(machineConfigBytes), nil
+}
+
 // GenerateInstallerImage generates the installer image name.
 func GenerateInstallerImage() string {
 	return fmt.Sprintf("%s/%s/installer:%s", gendata.ImagesRegistry, gendata.ImagesUsername, gendata.VersionTag)
@@ -132,7 +294,8 @@ func secretsBundleTomachineSecrets(secretsBundle *secrets.Bundle) (talosMachineS
 	}
 
 	model := talosMachineSecretsResourceModelV1{
-		ID: types.StringValue("machine_secrets"),
+		ID:       types.StringValue("machine_secrets"),
+		Triggers: types.MapNull(types.StringType),
 		MachineSecrets: machineSecrets{
 			Cluster: machineSecretsCluster{
 				ID:     types.StringValue(secretsBundle.Cluster.ID),
@@ -174,6 +337,8 @@ func secretsBundleTomachineSecrets(secretsBundle *secrets.Bundle) (talosMachineS
 		model.MachineSecrets.Secrets.AESCBCEncryptionSecret = types.StringValue(secretsBundle.Secrets.AESCBCEncryptionSecret)
 	}
 
+	model.WorkerMachineSecrets = workerMachineSecrets(model.MachineSecrets)
+
 	generateInput, err := generate.NewInput("", "", "", generate.WithSecretsBundle(secretsBundle))
 	if err != nil {
 		return model, err
@@ -193,6 +358,50 @@ func secretsBundleTomachineSecrets(secretsBundle *secrets.Bundle) (talosMachineS
 	return model, nil
 }
 
+// genBootstrapToken generates a Kubernetes bootstrap token in the `[a-z0-9]{6}.[a-z0-9]{16}` format.
+func genBootstrapToken() (string, error) {
+	return readBootstrapToken(rand.Reader)
+}
+
+// deriveBootstrapToken derives a token in the `[a-z0-9]{6}.[a-z0-9]{16}` format from the seed,
+// the same seed and purpose always derive the same token.
+func deriveBootstrapToken(seed, purpose string) (string, error) {
+	return readBootstrapToken(hkdf.New(sha256.New, []byte(seed), nil, []byte("talos "+purpose)))
+}
+
+// readBootstrapToken reads a token in the `[a-z0-9]{6}.[a-z0-9]{16}` format from r.
+func readBootstrapToken(r io.Reader) (string, error) {
+	const validBootstrapTokenChars = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+	// 256 mod 36 = 4, the bytes >= 252 are discarded so that the characters are evenly distributed
+	const maxByteValue = 252
+
+	token := make([]byte, 6+1+16)
+	b := make([]byte, 1)
+
+	for i := range token {
+		if i == 6 {
+			token[i] = '.'
+
+			continue
+		}
+
+		for {
+			if _, err := io.ReadFull(r, b); err != nil {
+				return "", err
+			}
+
+			if b[0] < maxByteValue {
+				break
+			}
+		}
+
+		token[i] = validBootstrapTokenChars[int(b[0])%len(validBootstrapTokenChars)]
+	}
+
+	return string(token), nil
+}
+
 func machineSecretsToSecretsBundle(model talosMachineSecretsResourceModelV1) (*secrets.Bundle, error) {
 	secretsBundle := &secrets.Bundle{
 		Cluster: &secrets.Cluster{
@@ -291,12 +500,76 @@ func validateVersionContract(version string) (*config.VersionContract, error) {
 	return versionContract, nil
 }
 
+type maintenanceModeContextKey struct{}
+
+// errMaintenanceMode is returned by the operations which can't run on a node in maintenance mode.
+var errMaintenanceMode = errors.New("the node is in maintenance mode")
+
+// maintenanceMode returns true if talosClientOpEndpoints reached the node through the maintenance API, i.e. the node isn't configured.
+func maintenanceMode(ctx context.Context) bool {
+	maintenance, _ := ctx.Value(maintenanceModeContextKey{}).(bool) //nolint:errcheck
+
+	return maintenance
+}
+
 func talosClientOp(ctx context.Context, endpoint, node string, tc *clientconfig.Config, opFunc func(ctx context.Context, c *client.Client) error) error {
+	return talosClientOpEndpoints(ctx, []string{endpoint}, node, tc, opFunc)
+}
+
+// talosClientOpEndpoints is like talosClientOp, but the client is created with several endpoints,
+// the client load balances the requests over the reachable endpoints, so a single unreachable endpoint doesn't fail the operation.
+func talosClientOpEndpoints(ctx context.Context, endpoints []string, node string, tc *clientconfig.Config, opFunc func(ctx context.Context, c *client.Client) error) error {
+	ctx, release, err := acquireOperation(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer release()
+
+	endpoints = normalizeEndpoints(endpoints)
+	node = normalizeNode(node)
+
+	var opts []client.OptionFunc
+
+	if opStats := operationStatsFromContext(ctx); opStats != nil {
+		opts = append(opts, client.WithGRPCDialOptions(grpc.WithStatsHandler(opStats)))
+	}
+
+	opts = append(opts, client.WithGRPCDialOptions(proxyDialOptionsFromContext(ctx)...), client.WithGRPCDialOptions(traceDialOptions()...))
+
 	nodeCtx := client.WithNode(ctx, node)
 
-	c, err := client.New(ctx, client.WithTLSConfig(&tls.Config{
+	// Omni routes the requests to the node itself, the node can't be reached in maintenance mode through it
+	if omniOpts, ok := omniClientOptionsFromContext(ctx); ok {
+		c, err := client.New(ctx, append(opts, omniOpts...)...)
+		if err != nil {
+			return err
+		}
+
+		defer c.Close() //nolint:errcheck
+
+		return opFunc(nodeCtx, c)
+	}
+
+	configOpts, err := configClientOptions(ctx, tc)
+	if err != nil {
+		return err
+	}
+
+	configOpts = append(slices.Clone(opts), configOpts...)
+
+	if kubeSpanResolutionFromContext(ctx) && net.ParseIP(node) == nil {
+		address, err := resolveKubeSpanNode(ctx, endpoints, node, configOpts)
+		if err != nil {
+			return err
+		}
+
+		nodeCtx = client.WithNode(ctx, address)
+	}
+
+	c, err := client.New(ctx, append(opts, client.WithTLSConfig(&tls.Config{
 		InsecureSkipVerify: true,
-	}), client.WithEndpoints(endpoint))
+	}), client.WithEndpoints(endpoints...))...)
 	if err != nil {
 		return err
 	}
@@ -305,16 +578,159 @@ func talosClientOp(ctx context.Context, endpoint, node string, tc *clientconfig.
 	if err != nil {
 		c.Close() //nolint:errcheck
 
-		c, err = client.New(ctx, client.WithConfig(tc), client.WithEndpoints(endpoint))
+		c, err = client.New(ctx, append(configOpts, client.WithEndpoints(endpoints...))...)
 		if err != nil {
 			return err
 		}
+	} else {
+		nodeCtx = context.WithValue(nodeCtx, maintenanceModeContextKey{}, true)
 	}
 	defer c.Close() //nolint:errcheck
 
 	return opFunc(nodeCtx, c)
 }
 
+// normalizeEndpoint returns the endpoint as host:port the way talosctl does, defaulting the port to the apid port.
+//
+// IPv6 addresses are bracketed, so both plain IPv6 literals and bracketed addresses without a port are accepted.
+func normalizeEndpoint(endpoint string) string {
+	if _, _, err := net.SplitHostPort(endpoint); err == nil {
+		return endpoint
+	}
+
+	return net.JoinHostPort(normalizeNode(endpoint), strconv.Itoa(constants.ApidPort))
+}
+
+// normalizeEndpoints returns the endpoints normalized with normalizeEndpoint.
+func normalizeEndpoints(endpoints []string) []string {
+	result := make([]string, 0, len(endpoints))
+
+	for _, endpoint := range endpoints {
+		result = append(result, normalizeEndpoint(endpoint))
+	}
+
+	return result
+}
+
+// normalizeNode returns the bare node address without the port and the brackets, apid expects a bare address in the node metadata.
+func normalizeNode(node string) string {
+	if host, _, err := net.SplitHostPort(node); err == nil {
+		return host
+	}
+
+	if strings.HasPrefix(node, "[") && strings.HasSuffix(node, "]") {
+		return node[1 : len(node)-1]
+	}
+
+	return node
+}
+
+// normalizeNodes returns the nodes normalized with normalizeNode.
+func normalizeNodes(nodes []string) []string {
+	result := make([]string, 0, len(nodes))
+
+	for _, node := range nodes {
+		result = append(result, normalizeNode(node))
+	}
+
+	return result
+}
+
+// clientEndpoints returns the endpoint followed by the failover endpoints, skipping duplicates,
+// the port of the endpoints is overridden when port is set.
+func clientEndpoints(endpoint types.String, endpoints []types.String, port types.Int64) []string {
+	result := []string{endpoint.ValueString()}
+
+	for _, e := range endpoints {
+		if !slices.Contains(result, e.ValueString()) {
+			result = append(result, e.ValueString())
+		}
+	}
+
+	return endpointsWithPort(result, port)
+}
+
+// endpointsWithPort returns the endpoints with the port replaced, the endpoints are returned as is when port is not set.
+func endpointsWithPort(endpoints []string, port types.Int64) []string {
+	if port.IsNull() || port.IsUnknown() {
+		return endpoints
+	}
+
+	result := make([]string, 0, len(endpoints))
+
+	for _, endpoint := range endpoints {
+		result = append(result, net.JoinHostPort(normalizeNode(endpoint), strconv.FormatInt(port.ValueInt64(), 10)))
+	}
+
+	return result
+}
+
+var operationStatsAttrTypes = map[string]attr.Type{
+	"rpc_count":      types.Int64Type,
+	"bytes_sent":     types.Int64Type,
+	"bytes_received": types.Int64Type,
+	"retries":        types.Int64Type,
+	"duration":       types.StringType,
+}
+
+type operationStatsContextKey struct{}
+
+// operationStats collects statistics of the Talos API calls done during a resource operation.
+//
+// It implements grpc stats.Handler, talosClientOp attaches it to the clients it creates if it is present in the context.
+type operationStats struct {
+	start         time.Time
+	rpcCount      atomic.Int64
+	bytesSent     atomic.Int64
+	bytesReceived atomic.Int64
+	retries       atomic.Int64
+}
+
+func withOperationStats(ctx context.Context) (context.Context, *operationStats) {
+	opStats := &operationStats{
+		start: time.Now(),
+	}
+
+	return context.WithValue(ctx, operationStatsContextKey{}, opStats), opStats
+}
+
+func operationStatsFromContext(ctx context.Context) *operationStats {
+	opStats, _ := ctx.Value(operationStatsContextKey{}).(*operationStats) //nolint:errcheck
+
+	return opStats
+}
+
+func (s *operationStats) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+func (s *operationStats) HandleRPC(_ context.Context, rs stats.RPCStats) {
+	switch rs := rs.(type) {
+	case *stats.Begin:
+		s.rpcCount.Add(1)
+	case *stats.OutPayload:
+		s.bytesSent.Add(int64(rs.WireLength))
+	case *stats.InPayload:
+		s.bytesReceived.Add(int64(rs.WireLength))
+	}
+}
+
+func (s *operationStats) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (s *operationStats) HandleConn(context.Context, stats.ConnStats) {}
+
+func (s *operationStats) value() (types.Object, diag.Diagnostics) {
+	return types.ObjectValue(operationStatsAttrTypes, map[string]attr.Value{
+		"rpc_count":      types.Int64Value(s.rpcCount.Load()),
+		"bytes_sent":     types.Int64Value(s.bytesSent.Load()),
+		"bytes_received": types.Int64Value(s.bytesReceived.Load()),
+		"retries":        types.Int64Value(s.retries.Load()),
+		"duration":       types.StringValue(time.Since(s.start).Round(time.Millisecond).String()),
+	})
+}
+
 type talosVersionValidator struct{}
 
 func talosVersionValid() talosVersionValidator {
@@ -399,12 +815,350 @@ func base64ToBytes(in string) ([]byte, error) {
 	return base64.StdEncoding.DecodeString(in)
 }
 
-func talosClientTFConfigToTalosClientConfig(clusterName, ca, cert, key string) (*clientconfig.Config, error) {
+// serviceHealthy returns true if the service passes its health checks, services without health checks are considered healthy.
+func serviceHealthy(svc *machineapi.ServiceInfo) bool {
+	health := svc.GetHealth()
+
+	return health.GetUnknown() || health.GetHealthy()
+}
+
+// serviceReady returns true if the service is running and healthy.
+func serviceReady(svc *machineapi.ServiceInfo) bool {
+	return svc.GetState() == "Running" && serviceHealthy(svc)
+}
+
+// machineConfigurationChanges returns the sorted dot-separated paths of the values which differ between two machine configurations.
+// Documents other than the v1alpha1 configuration are prefixed with their kind (and name).
+func machineConfigurationChanges(a, b string) ([]string, error) {
+	decode := func(in string) (map[string]any, error) {
+		documents := map[string]any{}
+
+		decoder := yaml.NewDecoder(strings.NewReader(in))
+
+		for {
+			var document map[string]any
+
+			if err := decoder.Decode(&document); err != nil {
+				if errors.Is(err, io.EOF) {
+					return documents, nil
+				}
+
+				return nil, err
+			}
+
+			kind, _ := document["kind"].(string) //nolint:errcheck
+
+			key := ""
+
+			if kind != "" {
+				key = kind
+
+				if name, ok := document["name"].(string); ok {
+					key += "/" + name
+				}
+			}
+
+			documents[key] = document
+		}
+	}
+
+	left, err := decode(a)
+	if err != nil {
+		return nil, err
+	}
+
+	right, err := decode(b)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []string
+
+	collectChanges("", left, right, &changes)
+
+	slices.Sort(changes)
+
+	return changes, nil
+}
+
+func collectChanges(prefix string, a, b any, changes *[]string) {
+	left, leftOK := a.(map[string]any)
+	right, rightOK := b.(map[string]any)
+
+	if !leftOK || !rightOK {
+		if !reflect.DeepEqual(a, b) {
+			*changes = append(*changes, prefix)
+		}
+
+		return
+	}
+
+	keys := map[string]struct{}{}
+
+	for key := range left {
+		keys[key] = struct{}{}
+	}
+
+	for key := range right {
+		keys[key] = struct{}{}
+	}
+
+	for key := range keys {
+		childPrefix := key
+
+		if prefix != "" {
+			childPrefix = prefix + "." + key
+		}
+
+		collectChanges(childPrefix, left[key], right[key], changes)
+	}
+}
+
+// machineConfigurationSHA256 returns the hex encoded SHA-256 checksum of the machine configuration, unknown and null values are returned as is.
+func machineConfigurationSHA256(machineConfiguration types.String) types.String {
+	if machineConfiguration.IsNull() || machineConfiguration.IsUnknown() {
+		return machineConfiguration
+	}
+
+	sum := sha256.Sum256([]byte(machineConfiguration.ValueString()))
+
+	return types.StringValue(hex.EncodeToString(sum[:]))
+}
+
+// outputFormatNames are the supported values of the output_format attributes.
+var outputFormatNames = []string{"yaml", "json"}
+
+// formatMachineConfiguration renders the machine configuration in the output format, YAML is returned as is.
+//
+// In JSON each document is encoded on its own and the documents are separated by `---`, so that Talos still loads the machine configuration as YAML.
+func formatMachineConfiguration(machineConfiguration, format string) (string, error) {
+	if format != "json" {
+		return machineConfiguration, nil
+	}
+
+	decoder := yaml.NewDecoder(strings.NewReader(machineConfiguration))
+
+	var documents []string
+
+	for {
+		var document any
+
+		if err := decoder.Decode(&document); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			return "", fmt.Errorf("failed to decode machine configuration: %w", err)
+		}
+
+		if document == nil {
+			continue
+		}
+
+		var buf bytes.Buffer
+
+		encoder := json.NewEncoder(&buf)
+		encoder.SetEscapeHTML(false)
+
+		if err := encoder.Encode(document); err != nil {
+			return "", fmt.Errorf("failed to encode machine configuration: %w", err)
+		}
+
+		documents = append(documents, buf.String())
+	}
+
+	return strings.Join(documents, "---\n"), nil
+}
+
+// isJSONMachineConfiguration returns true if the machine configuration was rendered in the JSON output format.
+func isJSONMachineConfiguration(machineConfiguration string) bool {
+	return strings.HasPrefix(machineConfiguration, "{")
+}
+
+// validationModeNames are the supported values of the validation_mode attributes.
+var validationModeNames = []string{"strict", "permissive", "off"}
+
+// validateConfigPatches strictly decodes each of the config patches,
+// the errors point at the offending patch and, when it can be determined, the YAML path in the patch.
+func validateConfigPatches(attrPath path.Path, configPatches []string) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	for i, configPatch := range configPatches {
+		if _, err := configpatcher.LoadPatches([]string{configPatch}); err != nil {
+			diags.AddAttributeError(attrPath.AtListIndex(i), "invalid config patch", configPatchErrorDetail(i, configPatch, err))
+		}
+	}
+
+	return diags
+}
+
+var yamlErrorLineRegexp = regexp.MustCompile(`line (\d+):`)
+
+// configPatchErrorDetail returns the error of the config patch with the YAML paths of the unknown or mistyped fields.
+func configPatchErrorDetail(index int, configPatch string, err error) string {
+	var paths []string
+
+	if _, unknownKeys, ok := strings.Cut(err.Error(), "unknown keys found during decoding:\n"); ok {
+		var node yaml.Node
+
+		if yaml.Unmarshal([]byte(unknownKeys), &node) == nil {
+			paths = yamlLeafPaths(&node, "")
+		}
+	} else {
+		for _, match := range yamlErrorLineRegexp.FindAllStringSubmatch(err.Error(), -1) {
+			line, _ := strconv.Atoi(match[1]) //nolint:errcheck
+
+			if yamlPath := yamlPathAtLine(configPatch, line); yamlPath != "" && !slices.Contains(paths, yamlPath) {
+				paths = append(paths, yamlPath)
+			}
+		}
+	}
+
+	if len(paths) == 0 {
+		return fmt.Sprintf("config patch %d: %s", index, err)
+	}
+
+	return fmt.Sprintf("config patch %d at %s: %s", index, strings.Join(paths, ", "), err)
+}
+
+// yamlLeafPaths returns the paths of the leaves of the YAML node, e.g. `machine.install.disk` or `machine.network.interfaces[0].dhcp`.
+func yamlLeafPaths(node *yaml.Node, prefix string) []string {
+	switch node.Kind { //nolint:exhaustive
+	case yaml.DocumentNode:
+		var paths []string
+
+		for _, child := range node.Content {
+			paths = append(paths, yamlLeafPaths(child, prefix)...)
+		}
+
+		return paths
+	case yaml.MappingNode:
+		var paths []string
+
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			paths = append(paths, yamlLeafPaths(node.Content[i+1], yamlJoinPath(prefix, node.Content[i].Value))...)
+		}
+
+		return paths
+	case yaml.SequenceNode:
+		var paths []string
+
+		for i, child := range node.Content {
+			paths = append(paths, yamlLeafPaths(child, fmt.Sprintf("%s[%d]", prefix, i))...)
+		}
+
+		return paths
+	default:
+		if prefix == "" {
+			return nil
+		}
+
+		return []string{prefix}
+	}
+}
+
+// yamlPathAtLine returns the path of the YAML field at the line of the (multi-document) YAML.
+func yamlPathAtLine(in string, line int) string {
+	decoder := yaml.NewDecoder(strings.NewReader(in))
+
+	for {
+		var node yaml.Node
+
+		if err := decoder.Decode(&node); err != nil {
+			return ""
+		}
+
+		if yamlPath, ok := yamlNodePathAtLine(&node, "", line); ok {
+			return yamlPath
+		}
+	}
+}
+
+func yamlNodePathAtLine(node *yaml.Node, prefix string, line int) (string, bool) {
+	switch node.Kind { //nolint:exhaustive
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			if yamlPath, ok := yamlNodePathAtLine(child, prefix, line); ok {
+				return yamlPath, true
+			}
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			keyPath := yamlJoinPath(prefix, key.Value)
+
+			if yamlPath, ok := yamlNodePathAtLine(value, keyPath, line); ok {
+				return yamlPath, true
+			}
+
+			if key.Line == line {
+				return keyPath, true
+			}
+		}
+	case yaml.SequenceNode:
+		for i, child := range node.Content {
+			if yamlPath, ok := yamlNodePathAtLine(child, fmt.Sprintf("%s[%d]", prefix, i), line); ok {
+				return yamlPath, true
+			}
+		}
+	default:
+		if node.Line == line && prefix != "" {
+			return prefix, true
+		}
+	}
+
+	return "", false
+}
+
+func yamlJoinPath(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+
+	return prefix + "." + key
+}
+
+// validateMachineConfiguration decodes the machine configuration rejecting unknown fields and validates it,
+// returning the validation warnings. In the `strict` mode warnings are reported as errors, the `off` mode skips the validation.
+func validateMachineConfiguration(machineConfiguration, mode string) ([]string, error) {
+	if mode == "off" {
+		return nil, nil
+	}
+
+	cfg, err := configloader.NewFromBytes([]byte(machineConfiguration))
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []validation.Option{validation.WithLocal()}
+
+	if mode == "strict" {
+		opts = append(opts, validation.WithStrict())
+	}
+
+	// the node install isn't required, as the configuration might be used for cloud or container machines
+	return cfg.Validate(validationModes["cloud"], opts...)
+}
+
+// talosClientTFConfigToTalosClientConfig converts the client configuration to a talos client config.
+//
+// Any additional CA certificates are appended to the trusted CA bundle, so that nodes presenting
+// certificates issued by either CA are accepted (e.g. during CA rotation).
+func talosClientTFConfigToTalosClientConfig(clusterName, ca, cert, key string, additionalCAs ...string) (*clientconfig.Config, error) {
 	caCert, err := base64ToBytes(ca)
 	if err != nil {
 		return nil, err
 	}
 
+	for _, additionalCA := range additionalCAs {
+		additionalCACert, err := base64ToBytes(additionalCA)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding additional CA certificate: %w", err)
+		}
+
+		caCert = append(append(caCert, '\n'), additionalCACert...)
+	}
+
 	clientCert, err := base64ToBytes(cert)
 	if err != nil {
 		return nil, err
@@ -427,3 +1181,114 @@ func talosClientTFConfigToTalosClientConfig(clusterName, ca, cert, key string) (
 
 	return talosConfig, nil
 }
+
+// defaultHealthCheckConcurrency is the default number of nodes waited for concurrently by the cluster health checks.
+const defaultHealthCheckConcurrency = 10
+
+// runConcurrently runs f for every node with at most concurrency calls at once, the errors are aggregated in the order of the nodes.
+func runConcurrently(ctx context.Context, nodes []string, concurrency int, f func(ctx context.Context, node string) error) error {
+	var eg errgroup.Group
+
+	eg.SetLimit(concurrency)
+
+	errs := make([]error, len(nodes))
+
+	for i, node := range nodes {
+		eg.Go(func() error {
+			if err := f(ctx, node); err != nil {
+				errs[i] = fmt.Errorf("%s: %w", node, err)
+			}
+
+			return nil
+		})
+	}
+
+	eg.Wait() //nolint:errcheck
+
+	return errors.Join(errs...)
+}
+
+// waitNodeReady waits for the node to finish booting and report itself as ready.
+func waitNodeReady(ctx context.Context, c *client.Client, node string) error {
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		err := nodeReady(client.WithNode(ctx, node), c)
+		if err == nil {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-ticker.C:
+		}
+	}
+}
+
+func nodeReady(ctx context.Context, c *client.Client) error {
+	machineStatus, err := safe.StateGetByID[*runtime.MachineStatus](ctx, c.COSI, runtime.MachineStatusID)
+	if err != nil {
+		return err
+	}
+
+	spec := machineStatus.TypedSpec()
+
+	if spec.Stage != runtime.MachineStageRunning {
+		return fmt.Errorf("machine stage is %s", spec.Stage)
+	}
+
+	if !spec.Status.Ready {
+		unmet := make([]string, 0, len(spec.Status.UnmetConditions))
+
+		for _, condition := range spec.Status.UnmetConditions {
+			unmet = append(unmet, fmt.Sprintf("%s: %s", condition.Name, condition.Reason))
+		}
+
+		return fmt.Errorf("machine is not ready: %s", strings.Join(unmet, ", "))
+	}
+
+	return nil
+}
+
+// kubernetesHTTPClient returns the HTTP client authenticated with the kubeconfig and the Kubernetes API server address.
+func kubernetesHTTPClient(ctx context.Context, kubeConfig []byte) (*http.Client, string, error) {
+	restConfig, err := clientcmd.RESTConfigFromKubeConfig(kubeConfig)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to parse kubeconfig: %w", err)
+	}
+
+	if dialer, ok := ctx.Value(proxyDialerContextKey{}).(proxyDialer); ok {
+		restConfig.Dial = dialer.DialContext
+	}
+
+	httpClient, err := rest.HTTPClientFor(restConfig)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create the Kubernetes client: %w", err)
+	}
+
+	return httpClient, restConfig.Host, nil
+}
+
+func kubernetesAPIReady(ctx context.Context, httpClient *http.Client, host string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(host, "/")+"/readyz", nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close() //nolint:errcheck
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024)) //nolint:errcheck
+
+		return fmt.Errorf("kubernetes API server is not ready: %s: %s", resp.Status, bytes.TrimSpace(body))
+	}
+
+	return nil
+}
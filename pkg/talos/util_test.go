@@ -0,0 +1,794 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package talos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	machineapi "github.com/siderolabs/talos/pkg/machinery/api/machine"
+	"github.com/siderolabs/talos/pkg/machinery/config/configloader"
+	"github.com/siderolabs/talos/pkg/machinery/config/generate/secrets"
+	"github.com/siderolabs/talos/pkg/machinery/config/machine"
+	"github.com/siderolabs/talos/pkg/machinery/gendata"
+)
+
+func TestServiceReady(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name    string
+		service *machineapi.ServiceInfo
+		healthy bool
+		ready   bool
+	}{
+		{
+			name: "running and healthy",
+			service: &machineapi.ServiceInfo{
+				State:  "Running",
+				Health: &machineapi.ServiceHealth{Healthy: true},
+			},
+			healthy: true,
+			ready:   true,
+		},
+		{
+			name: "running without health checks",
+			service: &machineapi.ServiceInfo{
+				State:  "Running",
+				Health: &machineapi.ServiceHealth{Unknown: true},
+			},
+			healthy: true,
+			ready:   true,
+		},
+		{
+			name: "running and unhealthy",
+			service: &machineapi.ServiceInfo{
+				State:  "Running",
+				Health: &machineapi.ServiceHealth{LastMessage: "connection refused"},
+			},
+			healthy: false,
+			ready:   false,
+		},
+		{
+			name: "not running",
+			service: &machineapi.ServiceInfo{
+				State:  "Waiting",
+				Health: &machineapi.ServiceHealth{Healthy: true},
+			},
+			healthy: true,
+			ready:   false,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if healthy := serviceHealthy(tt.service); healthy != tt.healthy {
+				t.Errorf("expected healthy %v, got %v", tt.healthy, healthy)
+			}
+
+			if ready := serviceReady(tt.service); ready != tt.ready {
+				t.Errorf("expected ready %v, got %v", tt.ready, ready)
+			}
+		})
+	}
+}
+
+const testMachineConfiguration = `version: v1alpha1
+machine:
+  type: worker
+  token: abcdef.0123456789abcdef
+  network:
+    hostname: worker-1
+cluster:
+  controlPlane:
+    endpoint: https://cluster.local:6443
+`
+
+const testMachineConfigurationJSON = `{"cluster":{"controlPlane":{"endpoint":"https://cluster.local:6443"}},"machine":{"network":{"hostname":"worker-1"},"token":"abcdef.0123456789abcdef","type":"worker"},"version":"v1alpha1"}
+`
+
+const testKmsgLogConfig = `apiVersion: v1alpha1
+kind: KmsgLogConfig
+name: remote-log
+url: tcp://192.168.3.7:3478/
+`
+
+func TestMachineConfigGenerateMultiDocument(t *testing.T) {
+	t.Parallel()
+
+	secretsBundle, err := secrets.NewBundle(secrets.NewFixedClock(time.Now()), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, machineType := range []machine.Type{machine.TypeControlPlane, machine.TypeWorker} {
+		t.Run(machineType.String(), func(t *testing.T) {
+			t.Parallel()
+
+			genOptions := &machineConfigGenerateOptions{
+				machineType:     machineType,
+				clusterName:     "test",
+				clusterEndpoint: "https://cluster.local:6443",
+				machineSecrets:  secretsBundle,
+				talosVersion:    gendata.VersionTag,
+				configPatches: []string{
+					testKmsgLogConfig,
+					`machine:
+  network:
+    hostname: test
+---
+apiVersion: v1alpha1
+kind: KmsgLogConfig
+name: remote-log
+url: tcp://192.168.3.8:3478/
+`,
+				},
+			}
+
+			machineConfiguration, err := genOptions.generate()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			cfg, err := configloader.NewFromBytes([]byte(machineConfiguration))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if cfg.Machine().Network().Hostname() != "test" {
+				t.Errorf("expected the v1alpha1 configuration to be patched, got hostname %q", cfg.Machine().Network().Hostname())
+			}
+
+			if len(cfg.Documents()) != 2 {
+				t.Fatalf("expected 2 documents, got %d", len(cfg.Documents()))
+			}
+
+			if !strings.Contains(machineConfiguration, "url: tcp://192.168.3.8:3478/") {
+				t.Errorf("expected the additional document to be patched, got:\n%s", machineConfiguration)
+			}
+		})
+	}
+}
+
+func TestValidateMachineConfiguration(t *testing.T) {
+	t.Parallel()
+
+	secretsBundle, err := secrets.NewBundle(secrets.NewFixedClock(time.Now()), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	genOptions := &machineConfigGenerateOptions{
+		machineType:     machine.TypeControlPlane,
+		clusterName:     "test",
+		clusterEndpoint: "https://cluster.local:6443",
+		machineSecrets:  secretsBundle,
+		talosVersion:    gendata.VersionTag,
+	}
+
+	machineConfiguration, err := genOptions.generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	genOptions.configPatches = []string{`machine:
+  install:
+    extensions:
+      - image: ghcr.io/siderolabs/gvisor:20231214.0-v1.6.0
+`}
+
+	deprecatedMachineConfiguration, err := genOptions.generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unknownFieldMachineConfiguration := strings.Replace(machineConfiguration, "\nmachine:\n", "\nmachine:\n    extraArgss:\n        foo: bar\n", 1)
+
+	for _, tt := range []struct {
+		name                 string
+		machineConfiguration string
+		mode                 string
+		expectedWarnings     int
+		expectedError        string
+	}{
+		{
+			name:                 "valid strict",
+			machineConfiguration: machineConfiguration,
+			mode:                 "strict",
+		},
+		{
+			name:                 "deprecated permissive",
+			machineConfiguration: deprecatedMachineConfiguration,
+			mode:                 "permissive",
+			expectedWarnings:     1,
+		},
+		{
+			name:                 "deprecated strict",
+			machineConfiguration: deprecatedMachineConfiguration,
+			mode:                 "strict",
+			expectedError:        "machine.install.extensions is deprecated",
+		},
+		{
+			name:                 "unknown field permissive",
+			machineConfiguration: unknownFieldMachineConfiguration,
+			mode:                 "permissive",
+			expectedError:        "unknown keys found during decoding",
+		},
+		{
+			name:                 "unknown field off",
+			machineConfiguration: unknownFieldMachineConfiguration,
+			mode:                 "off",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			warnings, err := validateMachineConfiguration(tt.machineConfiguration, tt.mode)
+
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectedError, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(warnings) != tt.expectedWarnings {
+				t.Errorf("expected %d warnings, got %v", tt.expectedWarnings, warnings)
+			}
+		})
+	}
+}
+
+func TestMachineConfigurationChanges(t *testing.T) {
+	t.Parallel()
+
+	secretsBundle, err := secrets.NewBundle(secrets.NewFixedClock(time.Now()), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	genOptions := &machineConfigGenerateOptions{
+		machineType:     machine.TypeControlPlane,
+		clusterName:     "test",
+		clusterEndpoint: "https://cluster.local:6443",
+		machineSecrets:  secretsBundle,
+		talosVersion:    "v1.8.0",
+		configPatches:   []string{testKmsgLogConfig},
+	}
+
+	machineConfiguration, err := genOptions.generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	genOptions.configContract = "v1.6"
+
+	pinnedMachineConfiguration, err := genOptions.generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changes, err := machineConfigurationChanges(machineConfiguration, machineConfiguration)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(changes) != 0 {
+		t.Errorf("expected no changes, got %v", changes)
+	}
+
+	changes, err = machineConfigurationChanges(pinnedMachineConfiguration, machineConfiguration)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.Contains(changes, "machine.features.hostDNS") {
+		t.Errorf("expected machine.features.hostDNS to change, got %v", changes)
+	}
+
+	changes, err = machineConfigurationChanges(machineConfiguration, strings.Replace(machineConfiguration, "url: tcp://192.168.3.7:3478/", "url: tcp://192.168.3.8:3478/", 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.Equal(changes, []string{"KmsgLogConfig/remote-log.url"}) {
+		t.Errorf("expected the additional document change, got %v", changes)
+	}
+}
+
+func TestClientEndpoints(t *testing.T) {
+	t.Parallel()
+
+	endpoints := clientEndpoints(types.StringValue("10.5.0.2"), []types.String{
+		types.StringValue("10.5.0.3"),
+		types.StringValue("10.5.0.2"),
+		types.StringValue("10.5.0.4"),
+	}, types.Int64Null())
+
+	if expected := []string{"10.5.0.2", "10.5.0.3", "10.5.0.4"}; !slices.Equal(endpoints, expected) {
+		t.Errorf("expected %v, got %v", expected, endpoints)
+	}
+
+	endpoints = clientEndpoints(types.StringValue("10.5.0.2:50000"), []types.String{
+		types.StringValue("fd00::3"),
+		types.StringValue("[fd00::4]:50000"),
+	}, types.Int64Value(50001))
+
+	if expected := []string{"10.5.0.2:50001", "[fd00::3]:50001", "[fd00::4]:50001"}; !slices.Equal(endpoints, expected) {
+		t.Errorf("expected %v, got %v", expected, endpoints)
+	}
+
+	if endpoints = clientEndpoints(types.StringValue("10.5.0.2"), nil, types.Int64Null()); !slices.Equal(endpoints, []string{"10.5.0.2"}) {
+		t.Errorf("expected the endpoint only, got %v", endpoints)
+	}
+}
+
+func TestRunConcurrently(t *testing.T) {
+	t.Parallel()
+
+	var running, maxRunning atomic.Int64
+
+	err := runConcurrently(context.Background(), []string{"10.5.0.2", "10.5.0.3", "10.5.0.4", "10.5.0.5", "10.5.0.6"}, 2, func(_ context.Context, node string) error {
+		current := running.Add(1)
+		defer running.Add(-1)
+
+		for {
+			previous := maxRunning.Load()
+			if current <= previous || maxRunning.CompareAndSwap(previous, current) {
+				break
+			}
+		}
+
+		time.Sleep(10 * time.Millisecond)
+
+		if node == "10.5.0.3" || node == "10.5.0.6" {
+			return errors.New("machine stage is booting")
+		}
+
+		return nil
+	})
+
+	if expected := "10.5.0.3: machine stage is booting\n10.5.0.6: machine stage is booting"; err == nil || err.Error() != expected {
+		t.Errorf("expected error %q, got %v", expected, err)
+	}
+
+	if maxRunning.Load() > 2 {
+		t.Errorf("expected at most 2 concurrent calls, got %d", maxRunning.Load())
+	}
+}
+
+func TestKubernetesAPIReady(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name          string
+		status        int
+		body          string
+		expectedError string
+	}{
+		{
+			name:   "ready",
+			status: http.StatusOK,
+			body:   "ok",
+		},
+		{
+			name:          "not ready",
+			status:        http.StatusInternalServerError,
+			body:          "[-]poststarthook/rbac/bootstrap-roles failed: reason withheld\nreadyz check failed\n",
+			expectedError: "kubernetes API server is not ready: 500 Internal Server Error: [-]poststarthook/rbac/bootstrap-roles failed",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/readyz" {
+					w.WriteHeader(http.StatusNotFound)
+
+					return
+				}
+
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body) //nolint:errcheck
+			}))
+			t.Cleanup(server.Close)
+
+			err := kubernetesAPIReady(context.Background(), server.Client(), server.URL+"/")
+
+			if tt.expectedError == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+				t.Errorf("expected error containing %q, got %v", tt.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestFormatMachineConfiguration(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name                 string
+		machineConfiguration string
+		format               string
+		expected             string
+	}{
+		{
+			name:                 "yaml",
+			machineConfiguration: testMachineConfiguration,
+			format:               "yaml",
+			expected:             testMachineConfiguration,
+		},
+		{
+			name:                 "default",
+			machineConfiguration: testMachineConfiguration,
+			expected:             testMachineConfiguration,
+		},
+		{
+			name:                 "json",
+			machineConfiguration: testMachineConfiguration,
+			format:               "json",
+			expected:             testMachineConfigurationJSON,
+		},
+		{
+			name:                 "json multiple documents",
+			machineConfiguration: testMachineConfiguration + "---\n" + testKmsgLogConfig,
+			format:               "json",
+			expected:             testMachineConfigurationJSON + "---\n" + `{"apiVersion":"v1alpha1","kind":"KmsgLogConfig","name":"remote-log","url":"tcp://192.168.3.7:3478/"}` + "\n",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			formatted, err := formatMachineConfiguration(tt.machineConfiguration, tt.format)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if formatted != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, formatted)
+			}
+
+			if !machineConfigurationEqual(tt.machineConfiguration, formatted) {
+				t.Errorf("expected the formatted machine configuration to load as the original one")
+			}
+		})
+	}
+}
+
+func TestExtensionServiceConfigDocuments(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name        string
+		services    map[string]machineConfigurationExtensionService
+		expected    []string
+		expectedErr string
+	}{
+		{
+			name: "environment and config files",
+			services: map[string]machineConfigurationExtensionService{
+				"tailscale": {
+					Environment: map[string]types.String{
+						"TS_ROUTES": types.StringValue("10.5.0.0/16"),
+					},
+					SensitiveEnvironment: map[string]types.String{
+						"TS_AUTHKEY": types.StringValue("tskey-auth-secret"),
+					},
+				},
+				"nut-client": {
+					ConfigFiles: map[string]types.String{
+						"/usr/local/etc/nut/upsmon.conf": types.StringValue("MONITOR ${upsmonHost} 1 remote username password"),
+					},
+				},
+			},
+			expected: []string{
+				`apiVersion: v1alpha1
+kind: ExtensionServiceConfig
+name: nut-client
+configFiles:
+    - content: MONITOR ${upsmonHost} 1 remote username password
+      mountPath: /usr/local/etc/nut/upsmon.conf
+`,
+				`apiVersion: v1alpha1
+kind: ExtensionServiceConfig
+name: tailscale
+environment:
+    - TS_AUTHKEY=tskey-auth-secret
+    - TS_ROUTES=10.5.0.0/16
+`,
+			},
+		},
+		{
+			name: "empty",
+			services: map[string]machineConfigurationExtensionService{
+				"tailscale": {},
+			},
+			expectedErr: `tailscale: no config files found for extension "tailscale"`,
+		},
+		{
+			name: "duplicate environment variable",
+			services: map[string]machineConfigurationExtensionService{
+				"tailscale": {
+					Environment: map[string]types.String{
+						"TS_AUTHKEY": types.StringValue("tskey-auth-secret"),
+					},
+					SensitiveEnvironment: map[string]types.String{
+						"TS_AUTHKEY": types.StringValue("tskey-auth-secret"),
+					},
+				},
+			},
+			expectedErr: `tailscale: the environment variable "TS_AUTHKEY" is set in both environment and sensitive_environment`,
+		},
+		{
+			name: "invalid environment variable",
+			services: map[string]machineConfigurationExtensionService{
+				"tailscale": {
+					Environment: map[string]types.String{
+						"TS_AUTHKEY=": types.StringValue("tskey-auth-secret"),
+					},
+				},
+			},
+			expectedErr: `tailscale: invalid environment variable name "TS_AUTHKEY="`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			documents, err := extensionServiceConfigDocuments(tt.services)
+			if tt.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectedErr, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !slices.Equal(documents, tt.expected) {
+				t.Errorf("expected %q, got %q", tt.expected, documents)
+			}
+		})
+	}
+}
+
+func TestNormalizeEndpoint(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name         string
+		endpoint     string
+		expected     string
+		expectedNode string
+	}{
+		{
+			name:         "ipv4",
+			endpoint:     "10.5.0.2",
+			expected:     "10.5.0.2:50000",
+			expectedNode: "10.5.0.2",
+		},
+		{
+			name:         "ipv4 with port",
+			endpoint:     "10.5.0.2:50001",
+			expected:     "10.5.0.2:50001",
+			expectedNode: "10.5.0.2",
+		},
+		{
+			name:         "hostname",
+			endpoint:     "talos.example.com",
+			expected:     "talos.example.com:50000",
+			expectedNode: "talos.example.com",
+		},
+		{
+			name:         "ipv6",
+			endpoint:     "fd00::2",
+			expected:     "[fd00::2]:50000",
+			expectedNode: "fd00::2",
+		},
+		{
+			name:         "bracketed ipv6",
+			endpoint:     "[fd00::2]",
+			expected:     "[fd00::2]:50000",
+			expectedNode: "fd00::2",
+		},
+		{
+			name:         "bracketed ipv6 with port",
+			endpoint:     "[fd00::2]:50001",
+			expected:     "[fd00::2]:50001",
+			expectedNode: "fd00::2",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if endpoint := normalizeEndpoint(tt.endpoint); endpoint != tt.expected {
+				t.Errorf("expected endpoint %q, got %q", tt.expected, endpoint)
+			}
+
+			if node := normalizeNode(tt.endpoint); node != tt.expectedNode {
+				t.Errorf("expected node %q, got %q", tt.expectedNode, node)
+			}
+		})
+	}
+}
+
+func TestValidateConfigPatches(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name          string
+		configPatches []string
+		expectedPath  path.Path
+		expectedError string
+	}{
+		{
+			name: "valid",
+			configPatches: []string{
+				"machine:\n  install:\n    disk: /dev/sda\n",
+				"- op: add\n  path: /machine/install/wipe\n  value: true\n",
+			},
+		},
+		{
+			name: "unknown key",
+			configPatches: []string{
+				"machine:\n  install:\n    disk: /dev/sda\n",
+				"machine:\n  install:\n    dsik: /dev/sda\n",
+			},
+			expectedPath:  path.Root("config_patches").AtListIndex(1),
+			expectedError: "config patch 1 at machine.install.dsik: unknown keys found during decoding",
+		},
+		{
+			name: "wrong type",
+			configPatches: []string{
+				"machine:\n  install:\n    wipe: sure\n",
+			},
+			expectedPath:  path.Root("config_patches").AtListIndex(0),
+			expectedError: "config patch 0 at machine.install.wipe: ",
+		},
+		{
+			name: "unknown key in list",
+			configPatches: []string{
+				"machine:\n  network:\n    interfaces:\n      - interface: eth0\n        dhcpp: true\n",
+			},
+			expectedPath:  path.Root("config_patches").AtListIndex(0),
+			expectedError: "at machine.network.interfaces[0].dhcpp",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			diags := validateConfigPatches(path.Root("config_patches"), tt.configPatches)
+
+			if tt.expectedError == "" {
+				if diags.HasError() {
+					t.Fatalf("unexpected errors: %v", diags)
+				}
+
+				return
+			}
+
+			if diags.ErrorsCount() != 1 {
+				t.Fatalf("expected one error, got %v", diags)
+			}
+
+			withPath, ok := diags.Errors()[0].(diag.DiagnosticWithPath)
+			if !ok || !withPath.Path().Equal(tt.expectedPath) {
+				t.Errorf("expected the error at %s, got %v", tt.expectedPath, diags.Errors()[0])
+			}
+
+			if !strings.Contains(diags.Errors()[0].Detail(), tt.expectedError) {
+				t.Errorf("expected error containing %q, got %q", tt.expectedError, diags.Errors()[0].Detail())
+			}
+		})
+	}
+}
+
+func TestMachineConfigurationSHA256(t *testing.T) {
+	t.Parallel()
+
+	// echo -n "version: v1alpha1" | sha256sum
+	if sum := machineConfigurationSHA256(types.StringValue("version: v1alpha1")); sum.ValueString() != "a70cefa7c1d0994eefd697feefea07b2441d21de53704d9cbd81947b70fc09c3" {
+		t.Errorf("unexpected checksum %s", sum.ValueString())
+	}
+
+	if sum := machineConfigurationSHA256(types.StringUnknown()); !sum.IsUnknown() {
+		t.Errorf("expected unknown checksum, got %v", sum)
+	}
+
+	if sum := machineConfigurationSHA256(types.StringNull()); !sum.IsNull() {
+		t.Errorf("expected null checksum, got %v", sum)
+	}
+}
+
+func TestDeriveBootstrapToken(t *testing.T) {
+	t.Parallel()
+
+	tokenFormat := regexp.MustCompile(`^[a-z0-9]{6}\.[a-z0-9]{16}$`)
+
+	token, err := deriveBootstrapToken("0123456789abcdef", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !tokenFormat.MatchString(token) {
+		t.Fatalf("unexpected token format: %q", token)
+	}
+
+	// the derivation is part of the contract of the gen_token function, changing it changes the tokens of the existing configurations
+	if expected := "508o5r.sygofr2vs9te1pqp"; token != expected {
+		t.Errorf("expected %q, got %q", expected, token)
+	}
+
+	again, err := deriveBootstrapToken("0123456789abcdef", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if again != token {
+		t.Errorf("expected the same seed to derive the same token, got %q and %q", token, again)
+	}
+
+	for _, tt := range []struct {
+		seed    string
+		purpose string
+	}{
+		{
+			seed:    "0123456789abcdeg",
+			purpose: "token",
+		},
+		{
+			seed:    "0123456789abcdef",
+			purpose: "bootstrap token",
+		},
+	} {
+		other, err := deriveBootstrapToken(tt.seed, tt.purpose)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !tokenFormat.MatchString(other) {
+			t.Fatalf("unexpected token format: %q", other)
+		}
+
+		if other == token {
+			t.Errorf("expected seed %q and purpose %q to derive a different token", tt.seed, tt.purpose)
+		}
+	}
+
+	generated, err := genBootstrapToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !tokenFormat.MatchString(generated) {
+		t.Fatalf("unexpected token format: %q", generated)
+	}
+}